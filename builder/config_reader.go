@@ -10,16 +10,26 @@ import (
 
 	"github.com/buildpacks/pack/internal/config"
 	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/blob"
 	"github.com/buildpacks/pack/pkg/dist"
 )
 
 // Config is a builder configuration file
 type Config struct {
 	Description string              `toml:"description"`
+	BaseBuilder string              `toml:"base-builder"`
 	Buildpacks  BuildpackCollection `toml:"buildpacks"`
 	Order       dist.Order          `toml:"order"`
 	Stack       StackConfig         `toml:"stack"`
 	Lifecycle   LifecycleConfig     `toml:"lifecycle"`
+	Build       BuildConfig         `toml:"build"`
+
+	// Compression is how the layers of the builder are compressed when it is published
+	Compression dist.LayerCompression `toml:"compression"`
+
+	// RemoveBuildpacks lists buildpacks of the base builder to leave out, in the form of
+	// '<id>' for every version or '<id>@<version>' for a single version
+	RemoveBuildpacks []string `toml:"remove-buildpacks"`
 }
 
 // BuildpackCollection is a list of BuildpackConfigs
@@ -50,7 +60,33 @@ type StackConfig struct {
 // LifecycleConfig details the configuration of the Lifecycle
 type LifecycleConfig struct {
 	URI     string `toml:"uri"`
+	Digest  string `toml:"digest"`
 	Version string `toml:"version"`
+	Image   string `toml:"image"`
+}
+
+// BuildConfig details the configuration of the build environment provided by the builder
+type BuildConfig struct {
+	Env []BuildEnv `toml:"env"`
+}
+
+// Modes of a build environment variable. A default value is only used when the variable
+// isn't provided for the build, while an override value is always used.
+const (
+	BuildEnvModeDefault  = "default"
+	BuildEnvModeOverride = "override"
+)
+
+// BuildEnv is an environment variable made available to buildpacks in /platform/env
+type BuildEnv struct {
+	Name  string `toml:"name" json:"name" yaml:"name"`
+	Value string `toml:"value" json:"value" yaml:"value"`
+	Mode  string `toml:"mode,omitempty" json:"mode,omitempty" yaml:"mode,omitempty"`
+}
+
+// IsOverride returns true if the value is used even when the variable is provided for the build
+func (e BuildEnv) IsOverride() bool {
+	return e.Mode == BuildEnvModeOverride
 }
 
 // ReadConfig reads a builder configuration from the file path provided and returns the
@@ -67,7 +103,7 @@ func ReadConfig(path string) (config Config, warnings []string, err error) {
 		return Config{}, nil, errors.Wrapf(err, "parse contents of '%s'", path)
 	}
 
-	if len(config.Order) == 0 {
+	if len(config.Order) == 0 && config.BaseBuilder == "" {
 		warnings = append(warnings, fmt.Sprintf("empty %s definition", style.Symbol("order")))
 	}
 
@@ -76,11 +112,19 @@ func ReadConfig(path string) (config Config, warnings []string, err error) {
 
 // ValidateConfig validates the config
 func ValidateConfig(c Config) error {
+	if c.BaseBuilder != "" && c.Stack.BuildImage != "" {
+		return errors.New("stack.build-image cannot be used with base-builder")
+	}
+
+	if c.BaseBuilder == "" && len(c.RemoveBuildpacks) > 0 {
+		return errors.New("remove-buildpacks requires base-builder")
+	}
+
 	if c.Stack.ID == "" {
 		return errors.New("stack.id is required")
 	}
 
-	if c.Stack.BuildImage == "" {
+	if c.Stack.BuildImage == "" && c.BaseBuilder == "" {
 		return errors.New("stack.build-image is required")
 	}
 
@@ -88,9 +132,61 @@ func ValidateConfig(c Config) error {
 		return errors.New("stack.run-image is required")
 	}
 
+	names := map[string]bool{}
+	for _, env := range c.Build.Env {
+		if env.Name == "" {
+			return errors.New("build.env.name is required")
+		}
+
+		if env.Mode != "" && env.Mode != BuildEnvModeDefault && env.Mode != BuildEnvModeOverride {
+			return errors.Errorf("build.env.mode of %s must be %s or %s", style.Symbol(env.Name), style.Symbol(BuildEnvModeDefault), style.Symbol(BuildEnvModeOverride))
+		}
+
+		if names[env.Name] {
+			return errors.Errorf("build.env %s is defined more than once", style.Symbol(env.Name))
+		}
+		names[env.Name] = true
+	}
+
+	for _, bp := range c.Buildpacks {
+		if err := validateDigest(bp.Digest, bp.URI, "buildpacks"); err != nil {
+			return errors.Wrapf(err, "invalid buildpack %s", style.Symbol(bp.DisplayString()))
+		}
+	}
+
+	if c.Lifecycle.Digest != "" {
+		if c.Lifecycle.URI == "" && c.Lifecycle.Version == "" {
+			return errors.New("lifecycle.digest requires lifecycle.uri or lifecycle.version")
+		}
+
+		if err := blob.ValidateDigest(c.Lifecycle.Digest); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Compression.Validate(); err != nil {
+		return err
+	}
+
+	if !c.Compression.IsGzip() {
+		return errors.Errorf("compression.algorithm %s is not supported for builders yet", style.Symbol(c.Compression.Algorithm))
+	}
+
 	return nil
 }
 
+func validateDigest(digest, uri, key string) error {
+	if digest == "" {
+		return nil
+	}
+
+	if uri == "" {
+		return errors.Errorf("%s.digest requires %s.uri", key, key)
+	}
+
+	return blob.ValidateDigest(digest)
+}
+
 // parseConfig reads a builder configuration from file
 func parseConfig(file *os.File) (Config, error) {
 	builderConfig := Config{}
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/heroku/color"
@@ -11,6 +12,7 @@ import (
 	"github.com/sclevine/spec/report"
 
 	"github.com/buildpacks/pack/builder"
+	"github.com/buildpacks/pack/pkg/dist"
 	h "github.com/buildpacks/pack/testhelpers"
 )
 
@@ -55,6 +57,15 @@ func testConfig(t *testing.T, when spec.G, it spec.S) {
 [[order]]
 [[order.group]]
   id = "buildpack/1"
+
+[[build.env]]
+  name = "BP_MIRROR"
+  value = "https://mirror.example.com"
+
+[[build.env]]
+  name = "HTTPS_PROXY"
+  value = "http://proxy.example.com"
+  mode = "override"
 `), 0666))
 			})
 
@@ -77,6 +88,11 @@ func testConfig(t *testing.T, when spec.G, it spec.S) {
 				h.AssertEq(t, builderConfig.Buildpacks[2].ImageName, "")
 
 				h.AssertEq(t, builderConfig.Order[0].Group[0].ID, "buildpack/1")
+
+				h.AssertEq(t, builderConfig.Build.Env, []builder.BuildEnv{
+					{Name: "BP_MIRROR", Value: "https://mirror.example.com"},
+					{Name: "HTTPS_PROXY", Value: "http://proxy.example.com", Mode: "override"},
+				})
 			})
 		})
 
@@ -128,6 +144,28 @@ func testConfig(t *testing.T, when spec.G, it spec.S) {
 
 					h.AssertSliceContainsOnly(t, warns, "empty 'order' definition")
 				})
+
+				when("base-builder is set", func() {
+					it.Before(func() {
+						h.AssertNil(t, ioutil.WriteFile(builderConfigPath, []byte(`
+base-builder = "some/builder"
+remove-buildpacks = ["some.other.buildpack"]
+
+[[buildpacks]]
+  id = "some.buildpack"
+  version = "some.buildpack.version"
+`), 0666))
+					})
+
+					it("doesn't return warnings, as the order of the base builder is used", func() {
+						config, warns, err := builder.ReadConfig(builderConfigPath)
+						h.AssertNil(t, err)
+
+						h.AssertEq(t, len(warns), 0)
+						h.AssertEq(t, config.BaseBuilder, "some/builder")
+						h.AssertEq(t, config.RemoveBuildpacks, []string{"some.other.buildpack"})
+					})
+				})
 			})
 
 			when("unknown buildpack key is present", func() {
@@ -193,5 +231,137 @@ uri = "noop-buildpack.tgz"
 				}}
 			h.AssertError(t, builder.ValidateConfig(config), "stack.run-image is required")
 		})
+
+		when("base-builder is set", func() {
+			it("doesn't require a build image", func() {
+				config := builder.Config{
+					BaseBuilder: "some/builder",
+					Stack: builder.StackConfig{
+						ID:       testID,
+						RunImage: testRunImage,
+					}}
+				h.AssertNil(t, builder.ValidateConfig(config))
+			})
+
+			it("returns error if a build image is also set", func() {
+				config := builder.Config{
+					BaseBuilder: "some/builder",
+					Stack: builder.StackConfig{
+						ID:         testID,
+						BuildImage: testBuildImage,
+						RunImage:   testRunImage,
+					}}
+				h.AssertError(t, builder.ValidateConfig(config), "stack.build-image cannot be used with base-builder")
+			})
+		})
+
+		it("returns error if buildpacks are removed without a base builder", func() {
+			config := builder.Config{
+				RemoveBuildpacks: []string{"some/buildpack"},
+				Stack: builder.StackConfig{
+					ID:         testID,
+					BuildImage: testBuildImage,
+					RunImage:   testRunImage,
+				}}
+			h.AssertError(t, builder.ValidateConfig(config), "remove-buildpacks requires base-builder")
+		})
+
+		when("build env is set", func() {
+			var config builder.Config
+
+			it.Before(func() {
+				config = builder.Config{
+					Stack: builder.StackConfig{
+						ID:         testID,
+						BuildImage: testBuildImage,
+						RunImage:   testRunImage,
+					}}
+			})
+
+			it("returns error if a name is missing", func() {
+				config.Build.Env = []builder.BuildEnv{{Value: "some-value"}}
+				h.AssertError(t, builder.ValidateConfig(config), "build.env.name is required")
+			})
+
+			it("returns error if the mode is unknown", func() {
+				config.Build.Env = []builder.BuildEnv{{Name: "SOME_VAR", Value: "some-value", Mode: "append"}}
+				h.AssertError(t, builder.ValidateConfig(config), "build.env.mode of 'SOME_VAR' must be 'default' or 'override'")
+			})
+
+			it("returns error if a variable is defined more than once", func() {
+				config.Build.Env = []builder.BuildEnv{
+					{Name: "SOME_VAR", Value: "some-value"},
+					{Name: "SOME_VAR", Value: "other-value", Mode: "override"},
+				}
+				h.AssertError(t, builder.ValidateConfig(config), "build.env 'SOME_VAR' is defined more than once")
+			})
+		})
+
+		it("returns error if the layer compression isn't gzip", func() {
+			config := builder.Config{
+				Stack: builder.StackConfig{
+					ID:         testID,
+					BuildImage: testBuildImage,
+					RunImage:   testRunImage,
+				},
+				Compression: dist.LayerCompression{Algorithm: dist.CompressionZstd},
+			}
+			h.AssertError(t, builder.ValidateConfig(config), "compression.algorithm 'zstd' is not supported for builders")
+		})
+
+		when("digests are set", func() {
+			var config builder.Config
+
+			it.Before(func() {
+				config = builder.Config{
+					Stack: builder.StackConfig{
+						ID:         testID,
+						BuildImage: testBuildImage,
+						RunImage:   testRunImage,
+					},
+				}
+			})
+
+			it("accepts sha256 digests of buildpack and lifecycle URIs", func() {
+				digest := "sha256:" + strings.Repeat("a", 64)
+				config.Buildpacks = builder.BuildpackCollection{{
+					ImageOrURI: dist.ImageOrURI{BuildpackURI: dist.BuildpackURI{URI: "https://example.com/bp.tgz", Digest: digest}},
+				}}
+				config.Lifecycle = builder.LifecycleConfig{URI: "https://example.com/lifecycle.tgz", Digest: digest}
+				h.AssertNil(t, builder.ValidateConfig(config))
+			})
+
+			it("accepts a sha256 digest of the lifecycle version", func() {
+				config.Lifecycle = builder.LifecycleConfig{Version: "0.13.0", Digest: "sha256:" + strings.Repeat("a", 64)}
+				h.AssertNil(t, builder.ValidateConfig(config))
+			})
+
+			it("returns error if the lifecycle digest is invalid", func() {
+				config.Lifecycle = builder.LifecycleConfig{Version: "0.13.0", Digest: "md5:abc"}
+				h.AssertError(t, builder.ValidateConfig(config), "invalid digest 'md5:abc'")
+			})
+
+			it("returns error if a buildpack digest is invalid", func() {
+				config.Buildpacks = builder.BuildpackCollection{{
+					ImageOrURI: dist.ImageOrURI{BuildpackURI: dist.BuildpackURI{URI: "https://example.com/bp.tgz", Digest: "md5:abc"}},
+				}}
+				h.AssertError(t, builder.ValidateConfig(config), "invalid digest 'md5:abc'")
+			})
+
+			it("returns error if a buildpack digest is set without a uri", func() {
+				config.Buildpacks = builder.BuildpackCollection{{
+					ImageOrURI: dist.ImageOrURI{
+						BuildpackURI: dist.BuildpackURI{Digest: "sha256:" + strings.Repeat("a", 64)},
+						ImageRef:     dist.ImageRef{ImageName: "some/buildpack"},
+					},
+				}}
+				h.AssertError(t, builder.ValidateConfig(config), "buildpacks.digest requires buildpacks.uri")
+			})
+
+			it("returns error if the lifecycle digest is set without a uri or version", func() {
+				config.Lifecycle = builder.LifecycleConfig{Image: "buildpacksio/lifecycle:0.13.0", Digest: "sha256:" + strings.Repeat("a", 64)}
+				h.AssertError(t, builder.ValidateConfig(config), "lifecycle.digest requires lifecycle.uri or lifecycle.version")
+			})
+		})
 	})
 }
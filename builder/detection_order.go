@@ -8,6 +8,13 @@ type DetectionOrderEntry struct {
 	dist.BuildpackRef   `yaml:",inline"`
 	Cyclical            bool           `json:"cyclic,omitempty" yaml:"cyclic,omitempty" toml:"cyclic,omitempty"`
 	GroupDetectionOrder DetectionOrder `json:"buildpacks,omitempty" yaml:"buildpacks,omitempty" toml:"buildpacks,omitempty"`
+
+	// Detect is the outcome of the buildpack's detect against an app (pass, fail, skip or err),
+	// empty if it was not run.
+	Detect string `json:"detect,omitempty" yaml:"detect,omitempty" toml:"detect,omitempty"`
+
+	// Selected is set on the group that passed detection against an app.
+	Selected bool `json:"selected,omitempty" yaml:"selected,omitempty" toml:"selected,omitempty"`
 }
 
 type DetectionOrder []DetectionOrderEntry
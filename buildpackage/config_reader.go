@@ -8,6 +8,7 @@ import (
 
 	"github.com/buildpacks/pack/internal/config"
 	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/blob"
 	"github.com/buildpacks/pack/pkg/buildpack"
 	"github.com/buildpacks/pack/pkg/dist"
 )
@@ -16,9 +17,10 @@ const defaultOS = "linux"
 
 // Config encapsulates the possible configuration options for buildpackage creation.
 type Config struct {
-	Buildpack    dist.BuildpackURI `toml:"buildpack"`
-	Dependencies []dist.ImageOrURI `toml:"dependencies"`
-	Platform     dist.Platform     `toml:"platform"`
+	Buildpack    dist.BuildpackURI     `toml:"buildpack"`
+	Dependencies []dist.ImageOrURI     `toml:"dependencies"`
+	Platform     dist.Platform         `toml:"platform"`
+	Compression  dist.LayerCompression `toml:"compression"`
 }
 
 func DefaultConfig() Config {
@@ -74,12 +76,16 @@ func (r *ConfigReader) Read(path string) (Config, error) {
 			style.Symbol("platform.os"), style.Symbol("linux"), style.Symbol("windows"), style.Symbol(packageConfig.Platform.OS))
 	}
 
+	if err := packageConfig.Compression.Validate(); err != nil {
+		return packageConfig, errors.Wrapf(err, "invalid %s configuration", style.Symbol("compression"))
+	}
+
 	configDir, err := filepath.Abs(filepath.Dir(path))
 	if err != nil {
 		return packageConfig, err
 	}
 
-	if err := validateURI(packageConfig.Buildpack.URI, configDir); err != nil {
+	if err := validateURI(packageConfig.Buildpack, configDir); err != nil {
 		return packageConfig, err
 	}
 
@@ -93,23 +99,25 @@ func (r *ConfigReader) Read(path string) (Config, error) {
 		}
 
 		if dep.URI != "" {
-			if err := validateURI(dep.URI, configDir); err != nil {
+			if err := validateURI(dep.BuildpackURI, configDir); err != nil {
 				return packageConfig, err
 			}
+		} else if dep.Digest != "" {
+			return packageConfig, errors.Errorf("dependency %s can't be pinned with %s, as only dependencies configured with %s are verified against digests", style.Symbol(dep.ImageName), style.Symbol("digest"), style.Symbol("uri"))
 		}
 	}
 
 	return packageConfig, nil
 }
 
-func validateURI(uri, relativeBaseDir string) error {
-	locatorType, err := buildpack.GetLocatorType(uri, relativeBaseDir, nil)
+func validateURI(bpURI dist.BuildpackURI, relativeBaseDir string) error {
+	locatorType, err := buildpack.GetLocatorType(blob.WithDigest(bpURI.URI, bpURI.Digest), relativeBaseDir, nil)
 	if err != nil {
 		return err
 	}
 
 	if locatorType == buildpack.InvalidLocator {
-		return errors.Errorf("invalid locator %s", style.Symbol(uri))
+		return errors.Errorf("invalid locator %s", style.Symbol(bpURI.URI))
 	}
 
 	return nil
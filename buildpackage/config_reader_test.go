@@ -11,6 +11,7 @@ import (
 	"github.com/sclevine/spec/report"
 
 	"github.com/buildpacks/pack/buildpackage"
+	"github.com/buildpacks/pack/pkg/dist"
 	h "github.com/buildpacks/pack/testhelpers"
 )
 
@@ -107,6 +108,60 @@ func testBuildpackageConfigReader(t *testing.T, when spec.G, it spec.S) {
 			h.AssertError(t, err, "only ['linux', 'windows'] is permitted")
 		})
 
+		it("reads the layer compression", func() {
+			configFile := filepath.Join(tmpDir, "package.toml")
+
+			err := ioutil.WriteFile(configFile, []byte(compressionPackageToml), os.ModePerm)
+			h.AssertNil(t, err)
+
+			config, err := buildpackage.NewConfigReader().Read(configFile)
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.Compression, dist.LayerCompression{Algorithm: "zstd", Level: 19})
+		})
+
+		it("returns an error when the compression level is invalid", func() {
+			configFile := filepath.Join(tmpDir, "package.toml")
+
+			err := ioutil.WriteFile(configFile, []byte(invalidCompressionPackageToml), os.ModePerm)
+			h.AssertNil(t, err)
+
+			_, err = buildpackage.NewConfigReader().Read(configFile)
+			h.AssertError(t, err, "invalid 'compression' configuration")
+			h.AssertError(t, err, "compression level for 'gzip' must be between 1 and 9")
+		})
+
+		it("reads the digests of buildpacks", func() {
+			configFile := filepath.Join(tmpDir, "package.toml")
+
+			err := ioutil.WriteFile(configFile, []byte(digestPackageToml), os.ModePerm)
+			h.AssertNil(t, err)
+
+			config, err := buildpackage.NewConfigReader().Read(configFile)
+			h.AssertNil(t, err)
+			h.AssertEq(t, config.Buildpack.Digest, "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+			h.AssertEq(t, config.Dependencies[0].Digest, "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+		})
+
+		it("returns an error when a digest is invalid", func() {
+			configFile := filepath.Join(tmpDir, "package.toml")
+
+			err := ioutil.WriteFile(configFile, []byte(invalidDigestPackageToml), os.ModePerm)
+			h.AssertNil(t, err)
+
+			_, err = buildpackage.NewConfigReader().Read(configFile)
+			h.AssertError(t, err, "invalid digest 'sha256:abc'")
+		})
+
+		it("returns an error when an image dependency has a digest", func() {
+			configFile := filepath.Join(tmpDir, "package.toml")
+
+			err := ioutil.WriteFile(configFile, []byte(imageDigestPackageToml), os.ModePerm)
+			h.AssertNil(t, err)
+
+			_, err = buildpackage.NewConfigReader().Read(configFile)
+			h.AssertError(t, err, "dependency 'some/image' can't be pinned with 'digest'")
+		})
+
 		it("returns an error when dependency uri is invalid", func() {
 			configFile := filepath.Join(tmpDir, "package.toml")
 
@@ -278,3 +333,45 @@ const missingBuildpackPackageToml = `
 [[dependencies]]
 uri = "bp/b"
 `
+
+const compressionPackageToml = `
+[buildpack]
+uri = "https://example.com/bp/a.tgz"
+
+[compression]
+algorithm = "zstd"
+level = 19
+`
+
+const invalidCompressionPackageToml = `
+[buildpack]
+uri = "https://example.com/bp/a.tgz"
+
+[compression]
+level = 12
+`
+
+const digestPackageToml = `
+[buildpack]
+uri = "https://example.com/bp/a.tgz"
+digest = "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+[[dependencies]]
+uri = "https://example.com/bp/b.tgz"
+digest = "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+`
+
+const invalidDigestPackageToml = `
+[buildpack]
+uri = "https://example.com/bp/a.tgz"
+digest = "sha256:abc"
+`
+
+const imageDigestPackageToml = `
+[buildpack]
+uri = "https://example.com/bp/a.tgz"
+
+[[dependencies]]
+image = "some/image"
+digest = "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+`
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/commands"
+	"github.com/buildpacks/pack/internal/engine"
+	"github.com/buildpacks/pack/pkg/client"
+)
+
+// lazyClient is the client the commands are created with. The flags of a command that configure the client are
+// only parsed when it runs, so the client it wraps is created on first use, see initClient. Commands that don't
+// use the client don't need a reachable container engine.
+type lazyClient struct {
+	once   sync.Once
+	init   func() (*client.Client, error)
+	client commands.PackClient
+	err    error
+}
+
+func (c *lazyClient) get() (commands.PackClient, error) {
+	c.once.Do(func() {
+		if c.init == nil {
+			c.err = errors.New("pack client isn't configured, the command was run without its persistent flags being parsed")
+			return
+		}
+		c.client, c.err = c.init()
+	})
+
+	return c.client, c.err
+}
+
+func (c *lazyClient) InspectBuilder(name string, daemon bool, modifiers ...client.BuilderInspectionModifier) (*client.BuilderInfo, error) {
+	pc, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	return pc.InspectBuilder(name, daemon, modifiers...)
+}
+
+func (c *lazyClient) DiffBuilders(first, second string) (*client.BuilderDiff, error) {
+	pc, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	return pc.DiffBuilders(first, second)
+}
+
+func (c *lazyClient) InspectImage(name string, daemon bool) (*client.ImageInfo, error) {
+	pc, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	return pc.InspectImage(name, daemon)
+}
+
+func (c *lazyClient) Rebase(ctx context.Context, opts client.RebaseOptions) error {
+	pc, err := c.get()
+	if err != nil {
+		return err
+	}
+	return pc.Rebase(ctx, opts)
+}
+
+func (c *lazyClient) CheckRebase(ctx context.Context, opts client.RebaseOptions) (*client.RebaseReport, error) {
+	pc, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	return pc.CheckRebase(ctx, opts)
+}
+
+func (c *lazyClient) CreateBuilder(ctx context.Context, opts client.CreateBuilderOptions) error {
+	pc, err := c.get()
+	if err != nil {
+		return err
+	}
+	return pc.CreateBuilder(ctx, opts)
+}
+
+func (c *lazyClient) NewBuildpack(ctx context.Context, opts client.NewBuildpackOptions) error {
+	pc, err := c.get()
+	if err != nil {
+		return err
+	}
+	return pc.NewBuildpack(ctx, opts)
+}
+
+func (c *lazyClient) PackageBuildpack(ctx context.Context, opts client.PackageBuildpackOptions) error {
+	pc, err := c.get()
+	if err != nil {
+		return err
+	}
+	return pc.PackageBuildpack(ctx, opts)
+}
+
+func (c *lazyClient) Build(ctx context.Context, opts client.BuildOptions) error {
+	pc, err := c.get()
+	if err != nil {
+		return err
+	}
+	return pc.Build(ctx, opts)
+}
+
+func (c *lazyClient) Detect(ctx context.Context, opts client.DetectOptions) (*client.DetectResult, error) {
+	pc, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	return pc.Detect(ctx, opts)
+}
+
+func (c *lazyClient) RegisterBuildpack(ctx context.Context, opts client.RegisterBuildpackOptions) error {
+	pc, err := c.get()
+	if err != nil {
+		return err
+	}
+	return pc.RegisterBuildpack(ctx, opts)
+}
+
+func (c *lazyClient) YankBuildpack(opts client.YankBuildpackOptions) error {
+	pc, err := c.get()
+	if err != nil {
+		return err
+	}
+	return pc.YankBuildpack(opts)
+}
+
+func (c *lazyClient) InspectBuildpack(opts client.InspectBuildpackOptions) (*client.BuildpackInfo, error) {
+	pc, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	return pc.InspectBuildpack(opts)
+}
+
+func (c *lazyClient) PullBuildpack(ctx context.Context, opts client.PullBuildpackOptions) error {
+	pc, err := c.get()
+	if err != nil {
+		return err
+	}
+	return pc.PullBuildpack(ctx, opts)
+}
+
+func (c *lazyClient) ExtractBuildpack(ctx context.Context, opts client.ExtractBuildpackOptions) error {
+	pc, err := c.get()
+	if err != nil {
+		return err
+	}
+	return pc.ExtractBuildpack(ctx, opts)
+}
+
+func (c *lazyClient) DownloadSBOM(name string, opts client.DownloadSBOMOptions) error {
+	pc, err := c.get()
+	if err != nil {
+		return err
+	}
+	return pc.DownloadSBOM(name, opts)
+}
+
+func (c *lazyClient) CreateBundle(ctx context.Context, opts client.CreateBundleOptions) error {
+	pc, err := c.get()
+	if err != nil {
+		return err
+	}
+	return pc.CreateBundle(ctx, opts)
+}
+
+func (c *lazyClient) LoadBundle(ctx context.Context, opts client.LoadBundleOptions) error {
+	pc, err := c.get()
+	if err != nil {
+		return err
+	}
+	return pc.LoadBundle(ctx, opts)
+}
+
+func (c *lazyClient) ContainerEngine(ctx context.Context) (engine.Engine, error) {
+	pc, err := c.get()
+	if err != nil {
+		return engine.Engine{}, err
+	}
+	return pc.ContainerEngine(ctx)
+}
@@ -1,16 +1,22 @@
 package cmd
 
 import (
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/heroku/color"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/buildpacks/pack"
 	"github.com/buildpacks/pack/buildpackage"
+	"github.com/buildpacks/pack/internal/auth"
 	builderwriter "github.com/buildpacks/pack/internal/builder/writer"
 	"github.com/buildpacks/pack/internal/commands"
 	"github.com/buildpacks/pack/internal/config"
 	imagewriter "github.com/buildpacks/pack/internal/inspectimage/writer"
 	"github.com/buildpacks/pack/internal/term"
+	"github.com/buildpacks/pack/pkg/blob"
 	"github.com/buildpacks/pack/pkg/client"
 	"github.com/buildpacks/pack/pkg/logging"
 )
@@ -32,15 +38,14 @@ func NewPackCommand(logger ConfigurableLogger) (*cobra.Command, error) {
 		return nil, err
 	}
 
-	packClient, err := initClient(logger, cfg)
-	if err != nil {
-		return nil, err
-	}
+	packClient := &lazyClient{}
 
 	rootCmd := &cobra.Command{
 		Use:   "pack",
 		Short: "CLI for building apps using Cloud Native Buildpacks",
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			offline := cfg.Offline
+			dockerContext := os.Getenv("PACK_DOCKER_CONTEXT")
 			if fs := cmd.Flags(); fs != nil {
 				if flag, err := fs.GetBool("no-color"); err == nil && flag {
 					color.Disable(flag)
@@ -60,7 +65,21 @@ func NewPackCommand(logger ConfigurableLogger) (*cobra.Command, error) {
 				if flag, err := fs.GetBool("timestamps"); err == nil {
 					logger.WantTime(flag)
 				}
+				if values, err := fs.GetStringArray("redact"); err == nil {
+					logging.AddRedactions(logger, values...)
+				}
+				if flag, err := fs.GetBool("offline"); err == nil && flag {
+					offline = true
+				}
+				if flag, err := fs.GetString("docker-context"); err == nil && flag != "" {
+					dockerContext = flag
+				}
 			}
+
+			packClient.init = func() (*client.Client, error) {
+				return initClient(logger, cfg, dockerContext, offline)
+			}
+			return nil
 		},
 	}
 
@@ -68,13 +87,18 @@ func NewPackCommand(logger ConfigurableLogger) (*cobra.Command, error) {
 	rootCmd.PersistentFlags().Bool("timestamps", false, "Enable timestamps in output")
 	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Show less output")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Show more output")
+	rootCmd.PersistentFlags().StringArray("redact", nil, "Value to mask in the output, in addition to credentials in URLs and values of variables named like *TOKEN*, *SECRET* or *PASSWORD*.\nRepeat for each value to mask.")
+	rootCmd.PersistentFlags().String("docker-context", "", "Docker context of the daemon to use (defaults to $PACK_DOCKER_CONTEXT, the daemon at $DOCKER_HOST, $DOCKER_CONTEXT, or the current context of the Docker CLI)")
+	rootCmd.PersistentFlags().Bool("offline", false, "Work without network access, using only images on the daemon and cached buildpacks")
 	rootCmd.Flags().Bool("version", false, "Show current 'pack' version")
 
 	commands.AddHelpFlag(rootCmd, "pack")
 
 	rootCmd.AddCommand(commands.Build(logger, cfg, packClient))
+	rootCmd.AddCommand(commands.Detect(logger, cfg, packClient))
 	rootCmd.AddCommand(commands.NewBuilderCommand(logger, cfg, packClient))
 	rootCmd.AddCommand(commands.NewBuildpackCommand(logger, cfg, packClient, buildpackage.NewConfigReader()))
+	rootCmd.AddCommand(commands.NewBundleCommand(logger, cfg, packClient))
 	rootCmd.AddCommand(commands.NewConfigCommand(logger, cfg, cfgPath, packClient))
 	rootCmd.AddCommand(commands.InspectImage(logger, imagewriter.NewFactory(), cfg, packClient))
 	rootCmd.AddCommand(commands.NewStackCommand(logger))
@@ -109,10 +133,10 @@ func NewPackCommand(logger ConfigurableLogger) (*cobra.Command, error) {
 	}
 
 	rootCmd.AddCommand(commands.CompletionCommand(logger, packHome))
-	rootCmd.AddCommand(commands.Report(logger, packClient.Version(), cfgPath))
-	rootCmd.AddCommand(commands.Version(logger, packClient.Version()))
+	rootCmd.AddCommand(commands.Report(logger, pack.Version, cfgPath, packClient))
+	rootCmd.AddCommand(commands.Version(logger, pack.Version))
 
-	rootCmd.Version = packClient.Version()
+	rootCmd.Version = pack.Version
 	rootCmd.SetVersionTemplate(`{{.Version}}{{"\n"}}`)
 	rootCmd.SetOut(logging.GetWriterForLevel(logger, logging.InfoLevel))
 	rootCmd.SetErr(logging.GetWriterForLevel(logger, logging.ErrorLevel))
@@ -133,10 +157,15 @@ func initConfig() (config.Config, string, error) {
 	return cfg, path, nil
 }
 
-func initClient(logger logging.Logger, cfg config.Config) (*client.Client, error) {
-	dc, err := tryInitSSHDockerClient()
+func initClient(logger logging.Logger, cfg config.Config, dockerContext string, offline bool) (*client.Client, error) {
+	dc, dockerHost, err := tryInitDockerClient(dockerContext)
 	if err != nil {
 		return nil, err
 	}
-	return client.NewClient(client.WithLogger(logger), client.WithExperimental(cfg.Experimental), client.WithRegistryMirrors(cfg.RegistryMirrors), client.WithDockerClient(dc))
+	keychain := auth.NewKeychain(cfg.RegistryCredentials, authn.DefaultKeychain)
+	downloaderOptions := []blob.DownloaderOption{
+		blob.WithAuthenticator(auth.NewDownloadAuthenticator(cfg.DownloadCredentials, auth.DefaultNetrcPath())),
+		blob.WithCACertFile(cfg.DownloadCACerts),
+	}
+	return client.NewClient(client.WithLogger(logger), client.WithExperimental(cfg.Experimental), client.WithOffline(offline), client.WithRegistryMirrors(cfg.RegistryMirrors), client.WithLifecycleMirror(cfg.LifecycleMirror), client.WithFetchConcurrency(cfg.FetchConcurrency), client.WithDockerClient(dc), client.WithDockerHost(dockerHost), client.WithKeychain(keychain), client.WithDownloaderOptions(downloaderOptions...))
 }
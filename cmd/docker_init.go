@@ -13,15 +13,61 @@ import (
 	"strings"
 
 	dockerClient "github.com/docker/docker/client"
+	"github.com/docker/go-connections/tlsconfig"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/term"
 
+	"github.com/buildpacks/pack/internal/dockercontext"
 	"github.com/buildpacks/pack/internal/sshdialer"
 	"github.com/buildpacks/pack/pkg/client"
 )
 
-func tryInitSSHDockerClient() (dockerClient.CommonAPIClient, error) {
-	dockerHost := os.Getenv("DOCKER_HOST")
+// tryInitDockerClient creates a docker client for the endpoint of the docker context contextName (see dockercontext.Resolve),
+// or for DOCKER_HOST if it's an ssh host. It returns a nil client when the default client, configured by the environment, should be used.
+// The docker host the client is connected to is returned as well, it's empty if it's DOCKER_HOST.
+func tryInitDockerClient(contextName string) (dockerClient.CommonAPIClient, string, error) {
+	endpoint, ok, err := dockercontext.Resolve(contextName)
+	if err != nil {
+		return nil, "", err
+	}
+	if !ok {
+		dc, err := tryInitSSHDockerClient(os.Getenv("DOCKER_HOST"))
+		return dc, "", err
+	}
+
+	dc, err := tryInitSSHDockerClient(endpoint.Host)
+	if err != nil || dc != nil {
+		return dc, endpoint.Host, err
+	}
+
+	dockerClientOpts := []dockerClient.Opt{
+		dockerClient.WithVersion(client.DockerAPIVersion),
+	}
+	if endpoint.HasTLS() {
+		tlsConfig, err := tlsconfig.Client(tlsconfig.Options{
+			CAFile:             endpoint.CACert,
+			CertFile:           endpoint.Cert,
+			KeyFile:            endpoint.Key,
+			InsecureSkipVerify: endpoint.SkipTLSVerify,
+			ExclusiveRootPools: true,
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("creating tls config of docker context %s: %w", endpoint.Context, err)
+		}
+		dockerClientOpts = append(dockerClientOpts, dockerClient.WithHTTPClient(&http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		}))
+	}
+	dockerClientOpts = append(dockerClientOpts, dockerClient.WithHost(endpoint.Host))
+
+	dc, err = dockerClient.NewClientWithOpts(dockerClientOpts...)
+	if err != nil {
+		return nil, "", fmt.Errorf("creating docker client for docker context %s: %w", endpoint.Context, err)
+	}
+	return dc, endpoint.Host, nil
+}
+
+func tryInitSSHDockerClient(dockerHost string) (dockerClient.CommonAPIClient, error) {
 	_url, err := url.Parse(dockerHost)
 	isSSH := err == nil && _url.Scheme == "ssh"
 
@@ -23,7 +23,10 @@ func main() {
 	}
 
 	ctx := commands.CreateCancellableContext()
-	if err := rootCmd.ExecuteContext(ctx); err != nil {
+	err = rootCmd.ExecuteContext(ctx)
+	// write the final line of output if it doesn't end with a line feed
+	_ = logger.Flush()
+	if err != nil {
 		if _, isSoftError := err.(client.SoftError); isSoftError {
 			os.Exit(2)
 		}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/blob"
+)
+
+type downloadAuthenticator struct {
+	credentials []config.DownloadCredential
+	netrcPath   string
+}
+
+// NewDownloadAuthenticator returns an authenticator for downloads from the hosts configured in credentials, which
+// defers to the netrc file at netrcPath for any other host. The netrc file is ignored if netrcPath is empty.
+func NewDownloadAuthenticator(credentials []config.DownloadCredential, netrcPath string) blob.Authenticator {
+	return &downloadAuthenticator{
+		credentials: credentials,
+		netrcPath:   netrcPath,
+	}
+}
+
+// DefaultNetrcPath returns the path of the netrc file set with the NETRC environment variable, or of .netrc in the
+// home directory
+func DefaultNetrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// Authenticate adds a bearer token or basic auth to a request, if credentials are configured for its host
+func (a *downloadAuthenticator) Authenticate(req *http.Request) error {
+	for _, cred := range a.credentials {
+		if !hostMatches(cred.Host, req.URL) {
+			continue
+		}
+
+		token, err := readSecret(cred.TokenEnv, cred.TokenFile)
+		if err != nil {
+			return errors.Wrapf(err, "reading token for host %s", style.Symbol(cred.Host))
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+			return nil
+		}
+
+		password, err := readSecret(cred.PasswordEnv, cred.PasswordFile)
+		if err != nil {
+			return errors.Wrapf(err, "reading password for host %s", style.Symbol(cred.Host))
+		}
+		req.SetBasicAuth(cred.Username, password)
+		return nil
+	}
+
+	if a.netrcPath == "" {
+		return nil
+	}
+
+	login, password, ok, err := netrcCredentials(a.netrcPath, req.URL.Hostname())
+	if err != nil {
+		return errors.Wrapf(err, "reading netrc file %s", style.Symbol(a.netrcPath))
+	}
+	if ok {
+		req.SetBasicAuth(login, password)
+	}
+
+	return nil
+}
+
+// hostMatches returns true if host, with or without a port, is the host of u
+func hostMatches(host string, u *url.URL) bool {
+	return strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname())
+}
+
+// netrcCredentials returns the login and password of the machine with the name host in a netrc file, or of the
+// default entry if there is none
+func netrcCredentials(path, host string) (string, string, bool, error) {
+	contents, err := os.ReadFile(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return "", "", false, nil
+	} else if err != nil {
+		return "", "", false, err
+	}
+
+	type entry struct {
+		login, password string
+	}
+
+	var (
+		current  *entry
+		matched  *entry
+		fallback *entry
+	)
+	fields := strings.Fields(string(contents))
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			current = nil
+			if i+1 < len(fields) {
+				i++
+				if strings.EqualFold(fields[i], host) && matched == nil {
+					matched = &entry{}
+					current = matched
+				}
+			}
+		case "default":
+			current = nil
+			if fallback == nil {
+				fallback = &entry{}
+				current = fallback
+			}
+		case "login", "password":
+			if i+1 >= len(fields) {
+				break
+			}
+			i++
+			if current == nil {
+				continue
+			}
+			if fields[i-1] == "login" {
+				current.login = fields[i]
+			} else {
+				current.password = fields[i]
+			}
+		}
+	}
+
+	if matched == nil {
+		matched = fallback
+	}
+	if matched == nil {
+		return "", "", false, nil
+	}
+
+	return matched.login, matched.password, true, nil
+}
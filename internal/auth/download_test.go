@@ -0,0 +1,124 @@
+package auth_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpacks/pack/internal/auth"
+	"github.com/buildpacks/pack/internal/config"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestDownloadAuthenticator(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "DownloadAuthenticator", testDownloadAuthenticator, spec.Sequential(), spec.Report(report.Terminal{}))
+}
+
+func testDownloadAuthenticator(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir    string
+		netrcPath string
+	)
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "download-auth")
+		h.AssertNil(t, err)
+		netrcPath = filepath.Join(tmpDir, ".netrc")
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	authenticate := func(credentials []config.DownloadCredential, uri string) *http.Request {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		h.AssertNil(t, err)
+		h.AssertNil(t, auth.NewDownloadAuthenticator(credentials, netrcPath).Authenticate(req))
+		return req
+	}
+
+	when("a token is configured for the host", func() {
+		it("sets a bearer token", func() {
+			tokenFile := filepath.Join(tmpDir, "token")
+			h.AssertNil(t, os.WriteFile(tokenFile, []byte("some-token\n"), 0600))
+
+			req := authenticate([]config.DownloadCredential{
+				{Host: "artifacts.example.com", TokenFile: tokenFile},
+			}, "https://artifacts.example.com/buildpack.tgz")
+			h.AssertEq(t, req.Header.Get("Authorization"), "Bearer some-token")
+		})
+	})
+
+	when("a password is configured for the host and port", func() {
+		it("sets basic auth", func() {
+			h.AssertNil(t, os.Setenv("PACK_TEST_DOWNLOAD_PASSWORD", "some-password"))
+			defer os.Unsetenv("PACK_TEST_DOWNLOAD_PASSWORD")
+
+			req := authenticate([]config.DownloadCredential{
+				{Host: "nexus.example.com:8443", Username: "some-user", PasswordEnv: "PACK_TEST_DOWNLOAD_PASSWORD"},
+			}, "https://nexus.example.com:8443/buildpack.tgz")
+			username, password, ok := req.BasicAuth()
+			h.AssertEq(t, ok, true)
+			h.AssertEq(t, username, "some-user")
+			h.AssertEq(t, password, "some-password")
+		})
+	})
+
+	when("the secret file doesn't exist", func() {
+		it("errors", func() {
+			req, err := http.NewRequest(http.MethodGet, "https://artifacts.example.com/buildpack.tgz", nil)
+			h.AssertNil(t, err)
+			err = auth.NewDownloadAuthenticator([]config.DownloadCredential{
+				{Host: "artifacts.example.com", TokenFile: filepath.Join(tmpDir, "missing")},
+			}, netrcPath).Authenticate(req)
+			h.AssertError(t, err, "reading token for host 'artifacts.example.com'")
+		})
+	})
+
+	when("no credentials are configured for the host", func() {
+		it.Before(func() {
+			h.AssertNil(t, os.WriteFile(netrcPath, []byte(`
+machine other.example.com login other-user password other-password
+machine artifacts.example.com
+  login netrc-user
+  password netrc-password
+default login default-user password default-password
+`), 0600))
+		})
+
+		it("uses the credentials of the matching machine in the netrc file", func() {
+			req := authenticate([]config.DownloadCredential{
+				{Host: "nexus.example.com", TokenEnv: "UNUSED"},
+			}, "https://artifacts.example.com/buildpack.tgz")
+			username, password, ok := req.BasicAuth()
+			h.AssertEq(t, ok, true)
+			h.AssertEq(t, username, "netrc-user")
+			h.AssertEq(t, password, "netrc-password")
+		})
+
+		it("uses the default credentials of the netrc file for other hosts", func() {
+			req := authenticate(nil, "https://unknown.example.com/buildpack.tgz")
+			username, password, ok := req.BasicAuth()
+			h.AssertEq(t, ok, true)
+			h.AssertEq(t, username, "default-user")
+			h.AssertEq(t, password, "default-password")
+		})
+
+		when("there is no netrc file", func() {
+			it("doesn't authenticate", func() {
+				h.AssertNil(t, os.Remove(netrcPath))
+				req := authenticate(nil, "https://artifacts.example.com/buildpack.tgz")
+				h.AssertEq(t, req.Header.Get("Authorization"), "")
+			})
+		})
+	})
+}
@@ -0,0 +1,124 @@
+// Package auth resolves registry credentials configured in the pack config.
+package auth
+
+import (
+	"os"
+	"strings"
+
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/style"
+)
+
+// identityTokenUsername is the username docker credential helpers return for identity tokens.
+const identityTokenUsername = "<token>"
+
+type keychain struct {
+	credentials []config.RegistryCredential
+	fallback    authn.Keychain
+}
+
+// NewKeychain returns a keychain that resolves credentials for the registries configured in credentials,
+// and defers to fallback for any other registry.
+func NewKeychain(credentials []config.RegistryCredential, fallback authn.Keychain) authn.Keychain {
+	if len(credentials) == 0 {
+		return fallback
+	}
+
+	return &keychain{
+		credentials: credentials,
+		fallback:    fallback,
+	}
+}
+
+func (k *keychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	registry := target.RegistryStr()
+	for _, cred := range k.credentials {
+		if normalizeRegistry(cred.Registry) != normalizeRegistry(registry) {
+			continue
+		}
+
+		authConfig, err := resolveCredential(cred)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving credentials for registry %s", style.Symbol(cred.Registry))
+		}
+		return authn.FromConfig(authConfig), nil
+	}
+
+	return k.fallback.Resolve(target)
+}
+
+func resolveCredential(cred config.RegistryCredential) (authn.AuthConfig, error) {
+	if cred.Helper != "" {
+		return resolveHelper(cred)
+	}
+
+	token, err := readSecret(cred.TokenEnv, cred.TokenFile)
+	if err != nil {
+		return authn.AuthConfig{}, errors.Wrap(err, "reading token")
+	}
+	if token != "" {
+		return authn.AuthConfig{RegistryToken: token}, nil
+	}
+
+	identityToken, err := readSecret(cred.IdentityTokenEnv, cred.IdentityTokenFile)
+	if err != nil {
+		return authn.AuthConfig{}, errors.Wrap(err, "reading identity token")
+	}
+	if identityToken != "" {
+		return authn.AuthConfig{Username: cred.Username, IdentityToken: identityToken}, nil
+	}
+
+	password, err := readSecret(cred.PasswordEnv, cred.PasswordFile)
+	if err != nil {
+		return authn.AuthConfig{}, errors.Wrap(err, "reading password")
+	}
+
+	return authn.AuthConfig{Username: cred.Username, Password: password}, nil
+}
+
+func resolveHelper(cred config.RegistryCredential) (authn.AuthConfig, error) {
+	program := client.NewShellProgramFunc("docker-credential-" + cred.Helper)
+	creds, err := client.Get(program, cred.Registry)
+	if err != nil {
+		return authn.AuthConfig{}, errors.Wrapf(err, "getting credentials from helper %s", style.Symbol(cred.Helper))
+	}
+
+	if creds.Username == identityTokenUsername {
+		return authn.AuthConfig{IdentityToken: creds.Secret}, nil
+	}
+
+	return authn.AuthConfig{Username: creds.Username, Password: creds.Secret}, nil
+}
+
+func readSecret(envVar, file string) (string, error) {
+	if envVar != "" {
+		value, ok := os.LookupEnv(envVar)
+		if !ok {
+			return "", errors.Errorf("environment variable %s is not set", style.Symbol(envVar))
+		}
+		return value, nil
+	}
+
+	if file != "" {
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(contents)), nil
+	}
+
+	return "", nil
+}
+
+func normalizeRegistry(registry string) string {
+	reg, err := name.NewRegistry(registry)
+	if err != nil {
+		return registry
+	}
+	return reg.RegistryStr()
+}
@@ -0,0 +1,173 @@
+package auth_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpacks/pack/internal/auth"
+	"github.com/buildpacks/pack/internal/config"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestKeychain(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "Keychain", testKeychain, spec.Sequential(), spec.Report(report.Terminal{}))
+}
+
+func testKeychain(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir   string
+		fallback = authn.NewMultiKeychain(staticKeychain{authn.AuthConfig{Username: "fallback-user", Password: "fallback-password"}})
+	)
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "keychain")
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	resolve := func(keychain authn.Keychain, ref string) *authn.AuthConfig {
+		t.Helper()
+		parsed, err := name.ParseReference(ref)
+		h.AssertNil(t, err)
+		authenticator, err := keychain.Resolve(parsed.Context())
+		h.AssertNil(t, err)
+		authConfig, err := authenticator.Authorization()
+		h.AssertNil(t, err)
+		return authConfig
+	}
+
+	when("no credentials are configured", func() {
+		it("returns the fallback keychain", func() {
+			h.AssertSameInstance(t, auth.NewKeychain(nil, fallback), fallback)
+		})
+	})
+
+	when("credentials are configured for another registry", func() {
+		it("uses the fallback keychain", func() {
+			keychain := auth.NewKeychain([]config.RegistryCredential{{Registry: "other.example.com", Username: "user", PasswordFile: "/does/not/exist"}}, fallback)
+			authConfig := resolve(keychain, "registry.example.com/some/image")
+			h.AssertEq(t, authConfig.Username, "fallback-user")
+		})
+	})
+
+	when("password is read from an environment variable", func() {
+		it.Before(func() {
+			h.AssertNil(t, os.Setenv("PACK_TEST_REGISTRY_PASSWORD", "env-password"))
+		})
+
+		it.After(func() {
+			h.AssertNil(t, os.Unsetenv("PACK_TEST_REGISTRY_PASSWORD"))
+		})
+
+		it("returns basic credentials", func() {
+			keychain := auth.NewKeychain([]config.RegistryCredential{{Registry: "registry.example.com", Username: "user", PasswordEnv: "PACK_TEST_REGISTRY_PASSWORD"}}, fallback)
+			authConfig := resolve(keychain, "registry.example.com/some/image")
+			h.AssertEq(t, authConfig.Username, "user")
+			h.AssertEq(t, authConfig.Password, "env-password")
+		})
+	})
+
+	when("the environment variable is not set", func() {
+		it("returns an error", func() {
+			keychain := auth.NewKeychain([]config.RegistryCredential{{Registry: "registry.example.com", Username: "user", PasswordEnv: "PACK_TEST_UNSET_PASSWORD"}}, fallback)
+			parsed, err := name.ParseReference("registry.example.com/some/image")
+			h.AssertNil(t, err)
+			_, err = keychain.Resolve(parsed.Context())
+			h.AssertError(t, err, "environment variable 'PACK_TEST_UNSET_PASSWORD' is not set")
+		})
+	})
+
+	when("password is read from a file", func() {
+		it("returns basic credentials without surrounding whitespace", func() {
+			passwordFile := filepath.Join(tmpDir, "password")
+			h.AssertNil(t, os.WriteFile(passwordFile, []byte("file-password\n"), 0600))
+
+			keychain := auth.NewKeychain([]config.RegistryCredential{{Registry: "registry.example.com", Username: "user", PasswordFile: passwordFile}}, fallback)
+			authConfig := resolve(keychain, "registry.example.com/some/image")
+			h.AssertEq(t, authConfig.Username, "user")
+			h.AssertEq(t, authConfig.Password, "file-password")
+		})
+	})
+
+	when("a registry token is configured", func() {
+		it("returns a registry token", func() {
+			tokenFile := filepath.Join(tmpDir, "token")
+			h.AssertNil(t, os.WriteFile(tokenFile, []byte("some-token"), 0600))
+
+			keychain := auth.NewKeychain([]config.RegistryCredential{{Registry: "registry.example.com", TokenFile: tokenFile}}, fallback)
+			authConfig := resolve(keychain, "registry.example.com/some/image")
+			h.AssertEq(t, authConfig.RegistryToken, "some-token")
+		})
+	})
+
+	when("an identity token is configured", func() {
+		it("returns an identity token", func() {
+			tokenFile := filepath.Join(tmpDir, "identity-token")
+			h.AssertNil(t, os.WriteFile(tokenFile, []byte("some-identity-token"), 0600))
+
+			keychain := auth.NewKeychain([]config.RegistryCredential{{Registry: "registry.example.com", IdentityTokenFile: tokenFile}}, fallback)
+			authConfig := resolve(keychain, "registry.example.com/some/image")
+			h.AssertEq(t, authConfig.IdentityToken, "some-identity-token")
+		})
+	})
+
+	when("credentials are configured for docker hub", func() {
+		it("matches images without a registry", func() {
+			passwordFile := filepath.Join(tmpDir, "password")
+			h.AssertNil(t, os.WriteFile(passwordFile, []byte("hub-password"), 0600))
+
+			keychain := auth.NewKeychain([]config.RegistryCredential{{Registry: "docker.io", Username: "hub-user", PasswordFile: passwordFile}}, fallback)
+			authConfig := resolve(keychain, "some/image")
+			h.AssertEq(t, authConfig.Username, "hub-user")
+			h.AssertEq(t, authConfig.Password, "hub-password")
+		})
+	})
+
+	when("a credential helper is configured", func() {
+		var oldPath string
+
+		it.Before(func() {
+			h.SkipIf(t, runtime.GOOS == "windows", "credential helper script is a shell script")
+
+			script := fmt.Sprintf("#!/bin/sh\nread server\nif [ \"$server\" = \"registry.example.com\" ]; then\n  echo '{\"ServerURL\":\"registry.example.com\",\"Username\":\"%s\",\"Secret\":\"helper-secret\"}'\nelse\n  echo 'credentials not found in native keychain'\n  exit 1\nfi\n", "helper-user")
+			h.AssertNil(t, os.WriteFile(filepath.Join(tmpDir, "docker-credential-fake"), []byte(script), 0700))
+
+			oldPath = os.Getenv("PATH")
+			h.AssertNil(t, os.Setenv("PATH", tmpDir+string(os.PathListSeparator)+oldPath))
+		})
+
+		it.After(func() {
+			h.AssertNil(t, os.Setenv("PATH", oldPath))
+		})
+
+		it("returns the credentials from the helper", func() {
+			keychain := auth.NewKeychain([]config.RegistryCredential{{Registry: "registry.example.com", Helper: "fake"}}, fallback)
+			authConfig := resolve(keychain, "registry.example.com/some/image")
+			h.AssertEq(t, authConfig.Username, "helper-user")
+			h.AssertEq(t, authConfig.Password, "helper-secret")
+		})
+	})
+}
+
+type staticKeychain struct {
+	authConfig authn.AuthConfig
+}
+
+func (k staticKeychain) Resolve(authn.Resource) (authn.Authenticator, error) {
+	return authn.FromConfig(k.authConfig), nil
+}
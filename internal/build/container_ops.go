@@ -1,6 +1,7 @@
 package build
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"io/ioutil"
 	"os"
 	"runtime"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/buildpacks/lifecycle/platform"
@@ -20,6 +22,7 @@ import (
 	"github.com/buildpacks/pack/internal/builder"
 	"github.com/buildpacks/pack/internal/container"
 	"github.com/buildpacks/pack/internal/paths"
+	"github.com/buildpacks/pack/internal/stringset"
 	"github.com/buildpacks/pack/pkg/archive"
 )
 
@@ -56,6 +59,21 @@ func CopyOutTo(src, dest string) ContainerOperation {
 	}, src)
 }
 
+// ReadToml decodes the TOML file at src on the container into v.
+func ReadToml(src string, v interface{}) ContainerOperation {
+	return CopyOut(func(reader io.ReadCloser) error {
+		defer reader.Close()
+
+		tr := tar.NewReader(reader)
+		if _, err := tr.Next(); err != nil {
+			return errors.Wrapf(err, "reading %s", src)
+		}
+
+		_, err := toml.NewDecoder(tr).Decode(v)
+		return errors.Wrapf(err, "decoding %s", src)
+	}, src)
+}
+
 // CopyDir copies a local directory (src) to the destination on the container while filtering files and changing it's UID/GID.
 // if includeRoot is set the UID/GID will be set on the dst directory.
 func CopyDir(src, dst string, uid, gid int, os string, includeRoot bool, fileFilter func(string) bool) ContainerOperation {
@@ -248,6 +266,43 @@ func createReader(src, dst string, uid, gid int, includeRoot bool, fileFilter fu
 	return archive.ReadZipAsTar(src, dst, uid, gid, -1, false, fileFilter), nil
 }
 
+// EnsureVolumeOwnership makes the volumes owned by the UID/GID-based user, for engines that can't chown volumes as
+// they're mounted, e.g. rootless Docker. The mount points of the volumes are copied over them as directories owned by
+// the user, the daemon applies their ownership to the volumes. Volumes mounted read-only are left as they are.
+func EnsureVolumeOwnership(uid, gid int, volumeNames ...string) ContainerOperation {
+	return func(ctrClient client.CommonAPIClient, ctx context.Context, containerID string, stdout, stderr io.Writer) error {
+		info, err := ctrClient.ContainerInspect(ctx, containerID)
+		if err != nil {
+			return err
+		}
+
+		names := stringset.FromSlice(volumeNames)
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, mnt := range info.Mounts {
+			if _, ok := names[mnt.Name]; !ok || !mnt.RW {
+				continue
+			}
+
+			if err := tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeDir,
+				Name:     strings.TrimPrefix(mnt.Destination, "/") + "/",
+				Mode:     0755,
+				Uid:      uid,
+				Gid:      gid,
+				ModTime:  archive.NormalizedDateTime,
+			}); err != nil {
+				return errors.Wrapf(err, "writing header for %s", mnt.Destination)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+
+		return copyDir(ctx, ctrClient, containerID, &buf)
+	}
+}
+
 // EnsureVolumeAccess grants full access permissions to volumes for UID/GID-based user
 // When UID/GID are 0 it grants explicit full access to BUILTIN\Administrators and any other UID/GID grants full access to BUILTIN\Users
 // Changing permissions on volumes through stopped containers does not work on Docker for Windows so we start the container and make change using icacls
@@ -526,6 +526,38 @@ drwsrwsrwt    2 123      456 (.*) some-vol
 			h.AssertContains(t, outBuf.String(), `BUILTIN\Users:(OI)(CI)(F)`)
 		})
 	})
+
+	when("#EnsureVolumeOwnership", func() {
+		it("changes owner of volume", func() {
+			h.SkipIf(t, osType == "windows", "volumes are owned through EnsureVolumeAccess on windows")
+
+			ctx := context.Background()
+
+			ctr, err := createContainer(ctx, imageName, "/my-volume", osType, "ls", "-aln", "/my-volume")
+			h.AssertNil(t, err)
+			defer cleanupContainer(ctx, ctr.ID)
+
+			inspect, err := ctrClient.ContainerInspect(ctx, ctr.ID)
+			h.AssertNil(t, err)
+
+			var ctrVolumes []string
+			for _, m := range inspect.Mounts {
+				if m.Type == mount.TypeVolume {
+					ctrVolumes = append(ctrVolumes, m.Name)
+				}
+			}
+
+			var outBuf, errBuf bytes.Buffer
+			ownVolumeOp := build.EnsureVolumeOwnership(123, 456, ctrVolumes...)
+			err = ownVolumeOp(ctrClient, ctx, ctr.ID, &outBuf, &errBuf)
+			h.AssertNil(t, err)
+			err = container.RunWithHandler(ctx, ctrClient, ctr.ID, container.DefaultHandler(&outBuf, &errBuf))
+			h.AssertNil(t, err)
+
+			h.AssertEq(t, errBuf.String(), "")
+			h.AssertContainsMatch(t, outBuf.String(), `drwxr-xr-x +[0-9]+ 123 +456 .* \.\n`)
+		})
+	})
 }
 
 func createContainer(ctx context.Context, imageName, containerDir, osType string, cmd ...string) (dcontainer.ContainerCreateCreatedBody, error) {
@@ -0,0 +1,121 @@
+package build
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/buildpacks/lifecycle/buildpack"
+	"github.com/buildpacks/lifecycle/platform"
+)
+
+const (
+	DetectStatusPass  = "pass"
+	DetectStatusFail  = "fail"
+	DetectStatusSkip  = "skip"
+	DetectStatusError = "err"
+)
+
+// DetectResult is the outcome of running the detect phase on its own.
+type DetectResult struct {
+	// Group is the buildpack group that passed detection. It is empty when detection failed.
+	Group buildpack.Group
+
+	// Plan is the build plan resolved for Group.
+	Plan platform.BuildPlan
+
+	// Trials lists every group of the order that was tried, in the order they were tried.
+	Trials []DetectTrial
+}
+
+// DetectTrial is the outcome of trying a single group of the order.
+type DetectTrial struct {
+	Buildpacks []DetectedBuildpack
+}
+
+// DetectedBuildpack is the outcome of a single buildpack's detect.
+type DetectedBuildpack struct {
+	ID      string
+	Version string
+
+	// Status is one of pass, fail, skip or err.
+	Status string
+
+	// Reason explains a status that was changed while resolving the build plan, e.g. an unmet require.
+	Reason string
+
+	// Output is what the buildpack's detect printed.
+	Output string
+}
+
+var (
+	detectOutputHeader = regexp.MustCompile(`^======== (?:Output|Error): (\S+) ========$`)
+	detectResultLine   = regexp.MustCompile(`^(pass|fail|skip|err):\s+(\S+)(?: \(\d+\))?$`)
+	detectPlanLine     = regexp.MustCompile(`^(fail|skip): (\S+) ((?:requires|provides unused) .+)$`)
+)
+
+const detectResultsHeader = "======== Results ========"
+
+// parseDetectOutput reconstructs the outcome of each buildpack from the debug output of the detector.
+func parseDetectOutput(output string) []DetectTrial {
+	var (
+		trials  []DetectTrial
+		outputs = map[string]*strings.Builder{}
+		current string
+	)
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if matches := detectOutputHeader.FindStringSubmatch(line); matches != nil {
+			current = matches[1]
+			if _, ok := outputs[current]; !ok {
+				outputs[current] = &strings.Builder{}
+			}
+			continue
+		}
+
+		if line == detectResultsHeader {
+			current = ""
+			trials = append(trials, DetectTrial{})
+			continue
+		}
+
+		if current != "" {
+			outputs[current].WriteString(line + "\n")
+			continue
+		}
+
+		if len(trials) > 0 {
+			trial := &trials[len(trials)-1]
+
+			if matches := detectResultLine.FindStringSubmatch(line); matches != nil {
+				id, version := splitBuildpackRef(matches[2])
+				detected := DetectedBuildpack{ID: id, Version: version, Status: matches[1]}
+				if out, ok := outputs[matches[2]]; ok {
+					detected.Output = strings.TrimSpace(out.String())
+					delete(outputs, matches[2])
+				}
+				trial.Buildpacks = append(trial.Buildpacks, detected)
+				continue
+			}
+
+			if matches := detectPlanLine.FindStringSubmatch(line); matches != nil {
+				for i, bp := range trial.Buildpacks {
+					if bp.ID+"@"+bp.Version == matches[2] {
+						trial.Buildpacks[i].Status = matches[1]
+						trial.Buildpacks[i].Reason = matches[3]
+					}
+				}
+			}
+		}
+	}
+
+	return trials
+}
+
+func splitBuildpackRef(ref string) (id, version string) {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
@@ -1,13 +1,19 @@
 package build
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"math/rand"
 	"strconv"
+	"strings"
 
 	"github.com/buildpacks/lifecycle/api"
 	"github.com/buildpacks/lifecycle/auth"
+	"github.com/docker/docker/api/types"
+	dcontainer "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -34,6 +40,8 @@ type LifecycleExecution struct {
 	os           string
 	mountPaths   mountPaths
 	opts         LifecycleOptions
+	secretsDir   string
+	redactions   []string
 }
 
 func NewLifecycleExecution(logger logging.Logger, docker client.CommonAPIClient, opts LifecycleOptions) (*LifecycleExecution, error) {
@@ -65,9 +73,27 @@ func NewLifecycleExecution(logger logging.Logger, docker client.CommonAPIClient,
 		exec.logger = opts.Termui
 	}
 
+	if len(opts.Secrets) > 0 {
+		if osType == "windows" {
+			return nil, errors.New("secrets are not supported for Windows builds")
+		}
+		if exec.secretsDir, err = writeSecrets(opts.Secrets); err != nil {
+			return nil, err
+		}
+		exec.redactions = secretRedactions(opts.Secrets)
+		logging.AddRedactions(exec.logger, exec.redactions...)
+	}
+
 	return exec, nil
 }
 
+func (l *LifecycleExecution) keychain() authn.Keychain {
+	if l.opts.Keychain != nil {
+		return l.opts.Keychain
+	}
+	return authn.DefaultKeychain
+}
+
 func findLatestSupported(apis []*api.Version) (*api.Version, error) {
 	for i := len(SupportedPlatformAPIVersions) - 1; i >= 0; i-- {
 		for _, version := range apis {
@@ -108,6 +134,16 @@ func (l *LifecycleExecution) LayersVolume() string {
 	return l.layersVolume
 }
 
+// isManagedVolume reports whether the volume is created by pack for the build, i.e. the layers, app or cache volumes
+func (l *LifecycleExecution) isManagedVolume(name string) bool {
+	return name == l.layersVolume || name == l.appVolume || strings.HasPrefix(name, cache.VolumePrefix)
+}
+
+// isManagedHostPath reports whether the host path is created by pack for the build, i.e. the secrets dir
+func (l *LifecycleExecution) isManagedHostPath(path string) bool {
+	return l.secretsDir != "" && path == l.secretsDir
+}
+
 func (l *LifecycleExecution) PlatformAPI() *api.Version {
 	return l.platformAPI
 }
@@ -121,6 +157,10 @@ func (l *LifecycleExecution) PrevImageName() string {
 }
 
 func (l *LifecycleExecution) Run(ctx context.Context, phaseFactoryCreator PhaseFactoryCreator) error {
+	if err := l.populateAppVolume(ctx); err != nil {
+		return err
+	}
+
 	phaseFactory := phaseFactoryCreator(l)
 	var buildCache Cache
 	if l.opts.CacheImage != "" {
@@ -193,6 +233,9 @@ func (l *LifecycleExecution) Cleanup() error {
 	if err := l.docker.VolumeRemove(context.Background(), l.appVolume, true); err != nil {
 		reterr = errors.Wrapf(err, "failed to clean up app volume %s", l.appVolume)
 	}
+	if err := removeSecrets(l.secretsDir); err != nil {
+		reterr = errors.Wrap(err, "failed to clean up secrets")
+	}
 	return reterr
 }
 
@@ -255,8 +298,10 @@ func (l *LifecycleExecution) Create(ctx context.Context, publish bool, dockerHos
 		WithArgs(repoName),
 		WithNetwork(networkMode),
 		cacheOpts,
+		// the creator runs detect and build, it needs the secrets too
+		WithSecrets(l),
 		WithContainerOperations(WriteProjectMetadata(l.mountPaths.projectPath(), l.opts.ProjectMetadata, l.os)),
-		WithContainerOperations(CopyDir(l.opts.AppPath, l.mountPaths.appDir(), l.opts.Builder.UID(), l.opts.Builder.GID(), l.os, true, l.opts.FileFilter)),
+		WithContainerOperations(l.copyApp()),
 		If(l.opts.SBOMDestinationDir != "", WithPostContainerRunOperations(
 			EnsureVolumeAccess(l.opts.Builder.UID(), l.opts.Builder.GID(), l.os, l.layersVolume, l.appVolume),
 			CopyOutTo(l.mountPaths.sbomDir(), l.opts.SBOMDestinationDir))),
@@ -266,7 +311,7 @@ func (l *LifecycleExecution) Create(ctx context.Context, publish bool, dockerHos
 	}
 
 	if publish {
-		authConfig, err := auth.BuildEnvVar(authn.DefaultKeychain, repoName)
+		authConfig, err := auth.BuildEnvVar(l.keychain(), repoName)
 		if err != nil {
 			return err
 		}
@@ -286,28 +331,106 @@ func (l *LifecycleExecution) Create(ctx context.Context, publish bool, dockerHos
 }
 
 func (l *LifecycleExecution) Detect(ctx context.Context, networkMode string, volumes []string, phaseFactory PhaseFactory) error {
+	return l.detect(ctx, networkMode, volumes, phaseFactory, WithArgs(l.withLogLevel()...))
+}
+
+// RunDetect runs only the detect phase and reports the buildpack group and build plan that were resolved,
+// along with the outcome of each buildpack that was tried. When detection fails the outcomes are still
+// returned alongside the error.
+func (l *LifecycleExecution) RunDetect(ctx context.Context, phaseFactoryCreator PhaseFactoryCreator) (DetectResult, error) {
+	var (
+		result DetectResult
+		output bytes.Buffer
+	)
+
+	if err := l.populateAppVolume(ctx); err != nil {
+		return result, err
+	}
+
+	phaseFactory := phaseFactoryCreator(l)
+	err := l.detect(ctx, l.opts.Network, l.opts.Volumes, phaseFactory,
+		// the outcome of each buildpack is only logged at debug level
+		WithArgs("-log-level", "debug"),
+		WithInfoWriter(io.MultiWriter(&output, logging.NewPrefixWriter(logging.GetWriterForLevel(l.logger, logging.DebugLevel), "detector", logging.WithRedactions(l.redactions...)))),
+		WithPostContainerRunOperations(
+			ReadToml(l.mountPaths.groupPath(), &result.Group),
+			ReadToml(l.mountPaths.planPath(), &result.Plan),
+		),
+	)
+
+	// the output is returned to callers as is, so it's redacted like the logs
+	detectOutput := logging.GetRedactor(l.logger).Redact(output.String())
+	result.Trials = parseDetectOutput(logging.NewRedactor(l.redactions...).Redact(detectOutput))
+	return result, err
+}
+
+func (l *LifecycleExecution) detect(ctx context.Context, networkMode string, volumes []string, phaseFactory PhaseFactory, ops ...PhaseConfigProviderOperation) error {
 	flags := []string{"-app", l.mountPaths.appDir()}
-	configProvider := NewPhaseConfigProvider(
-		"detector",
-		l,
+	ops = append([]PhaseConfigProviderOperation{
 		WithLogPrefix("detector"),
-		WithArgs(
-			l.withLogLevel()...,
-		),
 		WithNetwork(networkMode),
 		WithBinds(volumes...),
+		WithSecrets(l),
 		WithContainerOperations(
 			EnsureVolumeAccess(l.opts.Builder.UID(), l.opts.Builder.GID(), l.os, l.layersVolume, l.appVolume),
-			CopyDir(l.opts.AppPath, l.mountPaths.appDir(), l.opts.Builder.UID(), l.opts.Builder.GID(), l.os, true, l.opts.FileFilter),
+			l.copyApp(),
 		),
 		WithFlags(flags...),
-	)
+	}, ops...)
 
-	detect := phaseFactory.New(configProvider)
+	detect := phaseFactory.New(NewPhaseConfigProvider("detector", l, ops...))
 	defer detect.Cleanup()
 	return detect.Run(ctx)
 }
 
+// copyApp copies the app to the app volume of the phase, unless the volume is read-only, see populateAppVolume
+func (l *LifecycleExecution) copyApp() ContainerOperation {
+	if l.opts.Container.ReadOnlyApp {
+		return func(client.CommonAPIClient, context.Context, string, io.Writer, io.Writer) error {
+			return nil
+		}
+	}
+	return CopyDir(l.opts.AppPath, l.mountPaths.appDir(), l.opts.Builder.UID(), l.opts.Builder.GID(), l.os, true, l.opts.FileFilter)
+}
+
+// populateAppVolume copies the app to the app volume through a container that's never started,
+// as the daemon refuses to copy to a volume the container of a phase mounts read-only
+func (l *LifecycleExecution) populateAppVolume(ctx context.Context) error {
+	if !l.opts.Container.ReadOnlyApp {
+		return nil
+	}
+
+	// the container is never started, the command only keeps the daemon from refusing to create it
+	cmd := []string{"true"}
+	hostConfig := &dcontainer.HostConfig{
+		Binds: []string{fmt.Sprintf("%s:%s", l.appVolume, l.mountPaths.appDir())},
+	}
+	if l.os == "windows" {
+		cmd = []string{"cmd", "/c", "exit 0"}
+		hostConfig.Isolation = dcontainer.IsolationProcess
+	}
+
+	ctr, err := l.docker.ContainerCreate(ctx,
+		&dcontainer.Config{
+			Image:  l.opts.Builder.Name(),
+			Cmd:    cmd,
+			Labels: map[string]string{"author": "pack"},
+		},
+		hostConfig,
+		nil, nil, "",
+	)
+	if err != nil {
+		return errors.Wrap(err, "creating container to copy app")
+	}
+	defer l.docker.ContainerRemove(context.Background(), ctr.ID, types.ContainerRemoveOptions{Force: true})
+
+	copyApp := CopyDir(l.opts.AppPath, l.mountPaths.appDir(), l.opts.Builder.UID(), l.opts.Builder.GID(), l.os, true, l.opts.FileFilter)
+	if err := copyApp(l.docker, ctx, ctr.ID, ioutil.Discard, ioutil.Discard); err != nil {
+		return errors.Wrap(err, "copying app")
+	}
+	return nil
+}
+
 func (l *LifecycleExecution) Restore(ctx context.Context, networkMode string, buildCache Cache, phaseFactory PhaseFactory) error {
 	flagsOpt := NullOp()
 	cacheOpt := NullOp()
@@ -422,7 +545,7 @@ func (l *LifecycleExecution) newAnalyze(repoName, networkMode string, publish bo
 	}
 
 	if publish {
-		authConfig, err := auth.BuildEnvVar(authn.DefaultKeychain, repoName)
+		authConfig, err := auth.BuildEnvVar(l.keychain(), repoName)
 		if err != nil {
 			return nil, err
 		}
@@ -482,6 +605,7 @@ func (l *LifecycleExecution) Build(ctx context.Context, networkMode string, volu
 		WithArgs(l.withLogLevel()...),
 		WithNetwork(networkMode),
 		WithBinds(volumes...),
+		WithSecrets(l),
 		WithFlags(flags...),
 	)
 
@@ -553,7 +677,7 @@ func (l *LifecycleExecution) newExport(repoName, runImage string, publish bool,
 	}
 
 	if publish {
-		authConfig, err := auth.BuildEnvVar(authn.DefaultKeychain, repoName, runImage)
+		authConfig, err := auth.BuildEnvVar(l.keychain(), repoName, runImage)
 		if err != nil {
 			return nil, err
 		}
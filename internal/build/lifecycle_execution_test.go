@@ -9,11 +9,13 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/buildpacks/pack/internal/cache"
 
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 
 	"github.com/apex/log"
@@ -21,6 +23,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 	"github.com/heroku/color"
+	"github.com/pkg/errors"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 
@@ -566,6 +569,22 @@ func testLifecycleExecution(t *testing.T, when spec.G, it spec.S) {
 				h.AssertSliceContains(t, configProvider.ContainerConfig().Env, "CNB_REGISTRY_AUTH={}")
 			})
 
+			it("configures the phase with registry access from the provided keychain", func() {
+				lifecycle := newTestLifecycleExec(t, false, func(options *build.LifecycleOptions) {
+					options.Keychain = staticKeychain{authn.AuthConfig{Username: "some-user", Password: "some-password"}}
+				})
+				fakePhaseFactory := fakes.NewFakePhaseFactory()
+
+				err := lifecycle.Create(context.Background(), true, "", false, "test", "some-repo-name", "test", fakeBuildCache, fakeLaunchCache, []string{}, []string{}, fakePhaseFactory)
+				h.AssertNil(t, err)
+
+				lastCallIndex := len(fakePhaseFactory.NewCalledWithProvider) - 1
+				h.AssertNotEq(t, lastCallIndex, -1)
+
+				configProvider := fakePhaseFactory.NewCalledWithProvider[lastCallIndex]
+				h.AssertSliceContainsMatch(t, configProvider.ContainerConfig().Env, `CNB_REGISTRY_AUTH={"index.docker.io":"Basic .+"}`)
+			})
+
 			when("using a cache image", func() {
 				it.Before(func() {
 					fakeBuildCache.ReturnForType = cache.Image
@@ -1119,6 +1138,212 @@ func testLifecycleExecution(t *testing.T, when spec.G, it spec.S) {
 			h.AssertFunctionName(t, configProvider.ContainerOps()[0], "EnsureVolumeAccess")
 			h.AssertFunctionName(t, configProvider.ContainerOps()[1], "CopyDir")
 		})
+
+		when("the app is read-only", func() {
+			it("mounts the app volume read-only and doesn't copy the app dir", func() {
+				lifecycle := newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+					opts.Container.ReadOnlyApp = true
+				})
+				fakePhaseFactory := fakes.NewFakePhaseFactory()
+
+				err := lifecycle.Detect(context.Background(), "test", []string{}, fakePhaseFactory)
+				h.AssertNil(t, err)
+
+				lastCallIndex := len(fakePhaseFactory.NewCalledWithProvider) - 1
+				h.AssertNotEq(t, lastCallIndex, -1)
+
+				configProvider := fakePhaseFactory.NewCalledWithProvider[lastCallIndex]
+				h.AssertSliceContainsMatch(t, configProvider.HostConfig().Binds, "^pack-app-[a-z_]+:/workspace:ro$")
+
+				h.AssertEq(t, len(configProvider.ContainerOps()), 2)
+				h.AssertFunctionName(t, configProvider.ContainerOps()[0], "EnsureVolumeAccess")
+				h.AssertFunctionName(t, configProvider.ContainerOps()[1], "copyApp")
+			})
+		})
+	})
+
+	when("secrets are given", func() {
+		var (
+			lifecycle   *build.LifecycleExecution
+			secretsBind = func(provider *build.PhaseConfigProvider) string {
+				for _, bind := range provider.HostConfig().Binds {
+					if strings.HasSuffix(bind, ":/platform/secrets:ro") {
+						return bind
+					}
+				}
+				return ""
+			}
+		)
+
+		it.Before(func() {
+			lifecycle = newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+				opts.Secrets = map[string][]byte{"npmrc": []byte("//registry.example.com/:_authToken=some-secret-token\n")}
+			})
+		})
+
+		it.After(func() {
+			// the volumes can't be removed without a daemon, the secrets are removed regardless
+			_ = lifecycle.Cleanup()
+		})
+
+		it("mounts them read-only to the detector and the builder", func() {
+			fakePhaseFactory := fakes.NewFakePhaseFactory()
+			h.AssertNil(t, lifecycle.Detect(context.Background(), "test", []string{}, fakePhaseFactory))
+			h.AssertNil(t, lifecycle.Build(context.Background(), "test", []string{}, fakePhaseFactory))
+
+			h.AssertEq(t, len(fakePhaseFactory.NewCalledWithProvider), 2)
+			for _, provider := range fakePhaseFactory.NewCalledWithProvider {
+				bind := secretsBind(provider)
+				h.AssertNotEq(t, bind, "")
+
+				contents, err := ioutil.ReadFile(filepath.Join(strings.TrimSuffix(bind, ":/platform/secrets:ro"), "npmrc"))
+				h.AssertNil(t, err)
+				h.AssertEq(t, string(contents), "//registry.example.com/:_authToken=some-secret-token\n")
+			}
+		})
+
+		it("doesn't mount them to other phases", func() {
+			fakeCache := fakes.NewFakeCache()
+			fakeCache.ReturnForName = "some-cache"
+			fakeCache.ReturnForType = cache.Volume
+			fakePhaseFactory := fakes.NewFakePhaseFactory()
+
+			h.AssertNil(t, lifecycle.Restore(context.Background(), "test", fakeCache, fakePhaseFactory))
+
+			h.AssertEq(t, secretsBind(fakePhaseFactory.NewCalledWithProvider[0]), "")
+		})
+
+		it("redacts them from the logs of the phases", func() {
+			var prefixed, unprefixed bytes.Buffer
+			prefixedProvider := build.NewPhaseConfigProvider("some-name", lifecycle, build.WithInfoWriter(&prefixed), build.WithLogPrefix("some-prefix"))
+			unprefixedProvider := build.NewPhaseConfigProvider("some-name", lifecycle, build.WithInfoWriter(&unprefixed))
+
+			for _, writer := range []io.Writer{prefixedProvider.InfoWriter(), unprefixedProvider.InfoWriter()} {
+				_, err := writer.Write([]byte("using token some-secret-token\n"))
+				h.AssertNil(t, err)
+			}
+
+			h.AssertEq(t, prefixed.String(), "[some-prefix] using token [REDACTED]\n")
+			h.AssertEq(t, unprefixed.String(), "using token [REDACTED]\n")
+		})
+
+		it("removes them on cleanup", func() {
+			fakePhaseFactory := fakes.NewFakePhaseFactory()
+			h.AssertNil(t, lifecycle.Build(context.Background(), "test", []string{}, fakePhaseFactory))
+			secretsDir := strings.TrimSuffix(secretsBind(fakePhaseFactory.NewCalledWithProvider[0]), ":/platform/secrets:ro")
+
+			_ = lifecycle.Cleanup()
+
+			_, err := os.Stat(secretsDir)
+			h.AssertEq(t, os.IsNotExist(err), true)
+		})
+	})
+
+	when("#RunDetect", func() {
+		it("runs only the detector with debug logging and reads the group and plan", func() {
+			lifecycle := newTestLifecycleExec(t, false)
+			fakePhase := &fakes.FakePhase{}
+			fakePhaseFactory := fakes.NewFakePhaseFactory(fakes.WhichReturnsForNew(fakePhase))
+
+			_, err := lifecycle.RunDetect(context.Background(), func(*build.LifecycleExecution) build.PhaseFactory {
+				return fakePhaseFactory
+			})
+			h.AssertNil(t, err)
+
+			h.AssertEq(t, fakePhaseFactory.NewCallCount, 1)
+			h.AssertEq(t, fakePhase.RunCallCount, 1)
+			h.AssertEq(t, fakePhase.CleanupCallCount, 1)
+
+			configProvider := fakePhaseFactory.NewCalledWithProvider[0]
+			h.AssertEq(t, configProvider.Name(), "detector")
+			h.AssertIncludeAllExpectedPatterns(t,
+				configProvider.ContainerConfig().Cmd,
+				[]string{"-log-level", "debug"},
+			)
+			h.AssertEq(t, len(configProvider.PostContainerRunOps()), 2)
+			h.AssertFunctionName(t, configProvider.PostContainerRunOps()[0], "CopyOut")
+			h.AssertFunctionName(t, configProvider.PostContainerRunOps()[1], "CopyOut")
+		})
+
+		it("reports the outcome of each buildpack from the detector output", func() {
+			lifecycle := newTestLifecycleExec(t, false)
+			fakePhase := &detectOutputPhase{
+				output: `======== Output: some/required@1.0 ========
+no go.mod found
+======== Results ========
+fail: some/required@1.0
+skip: some/optional@2.0
+======== Output: some/other@1.0 ========
+found package.json
+pass: looks like a result but is output
+======== Results ========
+pass: some/other@1.0
+pass: some/helper@3.0
+Resolving plan... (try #1)
+skip: some/helper@3.0 provides unused cache
+some/other 1.0
+`,
+				err: errors.New("failed with status code: 20"),
+			}
+
+			result, err := lifecycle.RunDetect(context.Background(), func(*build.LifecycleExecution) build.PhaseFactory {
+				return &detectOutputPhaseFactory{phase: fakePhase}
+			})
+			h.AssertError(t, err, "failed with status code: 20")
+
+			h.AssertEq(t, result.Trials, []build.DetectTrial{
+				{Buildpacks: []build.DetectedBuildpack{
+					{ID: "some/required", Version: "1.0", Status: "fail", Output: "no go.mod found"},
+					{ID: "some/optional", Version: "2.0", Status: "skip"},
+				}},
+				{Buildpacks: []build.DetectedBuildpack{
+					{ID: "some/other", Version: "1.0", Status: "pass", Output: "found package.json\npass: looks like a result but is output"},
+					{ID: "some/helper", Version: "3.0", Status: "skip", Reason: "provides unused cache"},
+				}},
+			})
+		})
+
+		it("redacts secrets from the output of each buildpack", func() {
+			lifecycle := newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+				opts.Secrets = map[string][]byte{"npmrc": []byte("//registry.example.com/:_authToken=some-secret-token\n")}
+			})
+			fakePhase := &detectOutputPhase{
+				output: `======== Output: some/node@1.0 ========
+using token some-secret-token
+======== Results ========
+pass: some/node@1.0
+`,
+			}
+
+			result, err := lifecycle.RunDetect(context.Background(), func(*build.LifecycleExecution) build.PhaseFactory {
+				return &detectOutputPhaseFactory{phase: fakePhase}
+			})
+			h.AssertNil(t, err)
+
+			h.AssertEq(t, result.Trials, []build.DetectTrial{
+				{Buildpacks: []build.DetectedBuildpack{
+					{ID: "some/node", Version: "1.0", Status: "pass", Output: "using token [REDACTED]"},
+				}},
+			})
+		})
+
+		it("redacts sensitive values from the output of each buildpack", func() {
+			lifecycle := newTestLifecycleExec(t, false)
+			fakePhase := &detectOutputPhase{
+				output: `======== Output: some/node@1.0 ========
+NPM_TOKEN=abc123
+======== Results ========
+pass: some/node@1.0
+`,
+			}
+
+			result, err := lifecycle.RunDetect(context.Background(), func(*build.LifecycleExecution) build.PhaseFactory {
+				return &detectOutputPhaseFactory{phase: fakePhase}
+			})
+			h.AssertNil(t, err)
+
+			h.AssertEq(t, result.Trials[0].Buildpacks[0].Output, "NPM_TOKEN=[REDACTED]")
+		})
 	})
 
 	when("#Analyze", func() {
@@ -2657,3 +2882,38 @@ func newTestLifecycleExec(t *testing.T, logVerbose bool, ops ...func(*build.Life
 	h.AssertNil(t, err)
 	return lifecycleExec
 }
+
+type staticKeychain struct {
+	authConfig authn.AuthConfig
+}
+
+func (k staticKeychain) Resolve(authn.Resource) (authn.Authenticator, error) {
+	return authn.FromConfig(k.authConfig), nil
+}
+
+type detectOutputPhaseFactory struct {
+	phase *detectOutputPhase
+}
+
+func (f *detectOutputPhaseFactory) New(provider *build.PhaseConfigProvider) build.RunnerCleaner {
+	f.phase.provider = provider
+	return f.phase
+}
+
+type detectOutputPhase struct {
+	provider *build.PhaseConfigProvider
+	output   string
+	err      error
+}
+
+func (p *detectOutputPhase) Run(context.Context) error {
+	_, err := io.WriteString(p.provider.InfoWriter(), p.output)
+	if err != nil {
+		return err
+	}
+	return p.err
+}
+
+func (p *detectOutputPhase) Cleanup() error {
+	return nil
+}
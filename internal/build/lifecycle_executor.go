@@ -10,11 +10,14 @@ import (
 	"github.com/buildpacks/lifecycle/api"
 	"github.com/buildpacks/lifecycle/platform"
 	"github.com/docker/docker/client"
+	"github.com/docker/go-units"
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 
 	"github.com/buildpacks/pack/internal/builder"
 	"github.com/buildpacks/pack/internal/cache"
 	"github.com/buildpacks/pack/internal/container"
+	"github.com/buildpacks/pack/internal/engine"
 	"github.com/buildpacks/pack/pkg/logging"
 )
 
@@ -62,6 +65,21 @@ func init() {
 	rand.Seed(time.Now().UTC().UnixNano())
 }
 
+// ContainerSettings are applied to the container of every phase
+type ContainerSettings struct {
+	// NanoCPUs is the CPU quota in units of 10^-9 CPUs, 0 is unlimited
+	NanoCPUs int64
+	// Memory is the memory limit in bytes, 0 is unlimited
+	Memory       int64
+	Ulimits      []*units.Ulimit
+	ExtraHosts   []string
+	DNS          []string
+	Tmpfs        map[string]string
+	SecurityOpts []string
+	// ReadOnlyApp mounts the app volume read-only, the app is copied to it before the phases are run
+	ReadOnlyApp bool
+}
+
 type LifecycleOptions struct {
 	AppPath            string
 	Image              name.Reference
@@ -89,6 +107,11 @@ type LifecycleOptions struct {
 	GID                int
 	PreviousImage      string
 	SBOMDestinationDir string
+	Keychain           authn.Keychain
+	Engine             engine.Engine
+	Container          ContainerSettings
+	// Secrets are the contents of the secrets of the build by their id, they're mounted to /platform/secrets/<id>
+	Secrets map[string][]byte
 }
 
 func NewLifecycleExecutor(logger logging.Logger, docker client.CommonAPIClient) *LifecycleExecutor {
@@ -111,3 +134,14 @@ func (l *LifecycleExecutor) Execute(ctx context.Context, opts LifecycleOptions)
 		lifecycleExec.Run(ctx, NewDefaultPhaseFactory)
 	})
 }
+
+// Detect runs only the detect phase of the lifecycle against the builder's order.
+func (l *LifecycleExecutor) Detect(ctx context.Context, opts LifecycleOptions) (DetectResult, error) {
+	lifecycleExec, err := NewLifecycleExecution(l.logger, l.docker, opts)
+	if err != nil {
+		return DetectResult{}, err
+	}
+
+	defer lifecycleExec.Cleanup()
+	return lifecycleExec.RunDetect(ctx, NewDefaultPhaseFactory)
+}
@@ -38,6 +38,14 @@ func (m mountPaths) stackPath() string {
 	return m.join(m.layersDir(), "stack.toml")
 }
 
+func (m mountPaths) groupPath() string {
+	return m.join(m.layersDir(), "group.toml")
+}
+
+func (m mountPaths) planPath() string {
+	return m.join(m.layersDir(), "plan.toml")
+}
+
 func (m mountPaths) projectPath() string {
 	return m.join(m.layersDir(), "project-metadata.toml")
 }
@@ -58,6 +66,10 @@ func (m mountPaths) launchCacheDir() string {
 	return m.join(m.volume, "launch-cache")
 }
 
+func (m mountPaths) secretsDir() string {
+	return m.join(m.volume, "platform", "secrets")
+}
+
 func (m mountPaths) sbomDir() string {
 	return m.join(m.volume, "layers", "sbom")
 }
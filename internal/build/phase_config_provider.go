@@ -9,6 +9,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 
 	pcontainer "github.com/buildpacks/pack/internal/container"
+	"github.com/buildpacks/pack/internal/engine"
 	"github.com/buildpacks/pack/internal/style"
 	"github.com/buildpacks/pack/pkg/logging"
 )
@@ -31,6 +32,8 @@ type PhaseConfigProvider struct {
 	infoWriter          io.Writer
 	errorWriter         io.Writer
 	handler             pcontainer.Handler
+	engine              engine.Engine
+	redactions          []string
 }
 
 func NewPhaseConfigProvider(name string, lifecycleExec *LifecycleExecution, ops ...PhaseConfigProviderOperation) *PhaseConfigProvider {
@@ -41,6 +44,8 @@ func NewPhaseConfigProvider(name string, lifecycleExec *LifecycleExecution, ops
 		os:          lifecycleExec.os,
 		infoWriter:  logging.GetWriterForLevel(lifecycleExec.logger, logging.InfoLevel),
 		errorWriter: logging.GetWriterForLevel(lifecycleExec.logger, logging.ErrorLevel),
+		engine:      lifecycleExec.opts.Engine,
+		redactions:  lifecycleExec.redactions,
 	}
 
 	provider.ctrConf.Image = lifecycleExec.opts.Builder.Name()
@@ -50,12 +55,18 @@ func NewPhaseConfigProvider(name string, lifecycleExec *LifecycleExecution, ops
 		provider.hostConf.Isolation = container.IsolationProcess
 	}
 
+	appBind := fmt.Sprintf("%s:%s", lifecycleExec.appVolume, lifecycleExec.mountPaths.appDir())
+	if lifecycleExec.opts.Container.ReadOnlyApp {
+		appBind += ":ro"
+	}
+
 	ops = append(ops,
 		WithEnv(fmt.Sprintf("%s=%s", platformAPIEnvVar, lifecycleExec.platformAPI.String())),
 		WithLifecycleProxy(lifecycleExec),
+		WithContainerSettings(lifecycleExec.opts.Container),
 		WithBinds([]string{
 			fmt.Sprintf("%s:%s", lifecycleExec.layersVolume, lifecycleExec.mountPaths.layersDir()),
-			fmt.Sprintf("%s:%s", lifecycleExec.appVolume, lifecycleExec.mountPaths.appDir()),
+			appBind,
 		}...),
 	)
 
@@ -63,6 +74,19 @@ func NewPhaseConfigProvider(name string, lifecycleExec *LifecycleExecution, ops
 		op(provider)
 	}
 
+	// adapting to the engine depends on the binds and user set by the other operations
+	WithEngineCompatibility(lifecycleExec)(provider)
+
+	// the secrets of the build are redacted from the logs of phases that aren't prefixed too, e.g. the creator
+	if len(provider.redactions) > 0 {
+		if _, ok := provider.infoWriter.(*logging.PrefixWriter); !ok {
+			provider.infoWriter = logging.NewPrefixWriter(provider.infoWriter, "", logging.WithRedactions(provider.redactions...))
+		}
+		if _, ok := provider.errorWriter.(*logging.PrefixWriter); !ok {
+			provider.errorWriter = logging.NewPrefixWriter(provider.errorWriter, "", logging.WithRedactions(provider.redactions...))
+		}
+	}
+
 	provider.ctrConf.Cmd = append([]string{"/cnb/lifecycle/" + name}, provider.ctrConf.Cmd...)
 
 	lifecycleExec.logger.Debugf("Running the %s on OS %s with:", style.Symbol(provider.Name()), style.Symbol(provider.os))
@@ -76,6 +100,12 @@ func NewPhaseConfigProvider(name string, lifecycleExec *LifecycleExecution, ops
 	lifecycleExec.logger.Debug("Host Settings:")
 	lifecycleExec.logger.Debugf("  Binds: %s", style.Symbol(strings.Join(provider.hostConf.Binds, " ")))
 	lifecycleExec.logger.Debugf("  Network Mode: %s", style.Symbol(string(provider.hostConf.NetworkMode)))
+	if provider.hostConf.NanoCPUs != 0 || provider.hostConf.Memory != 0 {
+		lifecycleExec.logger.Debugf("  Resources: %s", style.Symbol(fmt.Sprintf("cpus=%g memory=%d", float64(provider.hostConf.NanoCPUs)/1e9, provider.hostConf.Memory)))
+	}
+	if len(provider.hostConf.SecurityOpt) > 0 {
+		lifecycleExec.logger.Debugf("  Security Options: %s", style.Symbol(strings.Join(provider.hostConf.SecurityOpt, " ")))
+	}
 
 	if lifecycleExec.opts.Interactive {
 		provider.handler = lifecycleExec.opts.Termui.Handler()
@@ -150,6 +180,9 @@ func WithDaemonAccess(dockerHost string) PhaseConfigProviderOperation {
 			bind = "/var/run/docker.sock:/var/run/docker.sock"
 			if provider.os == "windows" {
 				bind = `\\.\pipe\docker_engine:\\.\pipe\docker_engine`
+			} else if provider.engine.Socket != "" && (provider.engine.Rootless || provider.engine.IsPodman()) {
+				// rootless and Podman engines don't listen on /var/run/docker.sock, bind the socket pack is connected to
+				bind = fmt.Sprintf("%s:/var/run/docker.sock", provider.engine.Socket)
 			}
 		} else {
 			switch {
@@ -168,6 +201,52 @@ func WithDaemonAccess(dockerHost string) PhaseConfigProviderOperation {
 		if provider.os != "windows" {
 			provider.hostConf.SecurityOpt = []string{"label=disable"}
 		}
+		if provider.engine.UserNamespace && !provider.engine.Rootless {
+			// with user remapping the root of the container can't access the socket of the daemon
+			provider.hostConf.UsernsMode = "host"
+		}
+	}
+}
+
+// WithEngineCompatibility adapts the container to the engine running it: the host paths pack creates are relabeled
+// for SELinux, host paths given by the user are only relabeled if they ask for it with the z or Z option, and on
+// rootless engines the volumes managed by pack are owned by the user of the container.
+func WithEngineCompatibility(lifecycleExec *LifecycleExecution) PhaseConfigProviderOperation {
+	return func(provider *PhaseConfigProvider) {
+		if provider.os == "windows" {
+			return
+		}
+
+		labelsDisabled := false
+		for _, opt := range provider.hostConf.SecurityOpt {
+			if opt == "label=disable" {
+				labelsDisabled = true
+			}
+		}
+
+		var unownedVolumes []string
+		for i, bind := range provider.hostConf.Binds {
+			source := strings.SplitN(bind, ":", 2)[0]
+			switch {
+			case provider.engine.SELinux && !labelsDisabled && lifecycleExec.isManagedHostPath(source):
+				// shared label, as the same path may be bound by several containers
+				provider.hostConf.Binds[i] = withBindOption(bind, "z")
+			case provider.ctrConf.User == linuxContainerAdmin || !lifecycleExec.isManagedVolume(source):
+			case provider.engine.IsPodman():
+				// in a user namespace the volume is owned by a user the container can't write as, Podman chowns it to the user of the container
+				provider.hostConf.Binds[i] = withBindOption(bind, "U")
+			case provider.engine.Rootless:
+				// Docker has no option to chown volumes, they're chowned before the container starts
+				unownedVolumes = append(unownedVolumes, source)
+			}
+		}
+
+		if len(unownedVolumes) > 0 {
+			provider.containerOps = append(
+				[]ContainerOperation{EnsureVolumeOwnership(lifecycleExec.opts.Builder.UID(), lifecycleExec.opts.Builder.GID(), unownedVolumes...)},
+				provider.containerOps...,
+			)
+		}
 	}
 }
 
@@ -183,16 +262,23 @@ func WithImage(image string) PhaseConfigProviderOperation {
 	}
 }
 
-// WithLogPrefix sets a prefix for logs produced by this phase
+// WithLogPrefix sets a prefix for logs produced by this phase, the secrets of the build are redacted from them
 func WithLogPrefix(prefix string) PhaseConfigProviderOperation {
 	return func(provider *PhaseConfigProvider) {
 		if prefix != "" {
-			provider.infoWriter = logging.NewPrefixWriter(provider.infoWriter, prefix)
-			provider.errorWriter = logging.NewPrefixWriter(provider.errorWriter, prefix)
+			provider.infoWriter = logging.NewPrefixWriter(provider.infoWriter, prefix, logging.WithRedactions(provider.redactions...))
+			provider.errorWriter = logging.NewPrefixWriter(provider.errorWriter, prefix, logging.WithRedactions(provider.redactions...))
 		}
 	}
 }
 
+// WithInfoWriter replaces the writer that output produced by this phase is written to
+func WithInfoWriter(writer io.Writer) PhaseConfigProviderOperation {
+	return func(provider *PhaseConfigProvider) {
+		provider.infoWriter = writer
+	}
+}
+
 func WithLifecycleProxy(lifecycleExec *LifecycleExecution) PhaseConfigProviderOperation {
 	return func(provider *PhaseConfigProvider) {
 		if lifecycleExec.opts.HTTPProxy != "" {
@@ -209,6 +295,30 @@ func WithLifecycleProxy(lifecycleExec *LifecycleExecution) PhaseConfigProviderOp
 	}
 }
 
+// WithContainerSettings applies the resource limits, ulimits, hosts, DNS servers, tmpfs mounts and security options of settings
+func WithContainerSettings(settings ContainerSettings) PhaseConfigProviderOperation {
+	return func(provider *PhaseConfigProvider) {
+		if settings.NanoCPUs != 0 {
+			provider.hostConf.NanoCPUs = settings.NanoCPUs
+		}
+		if settings.Memory != 0 {
+			provider.hostConf.Memory = settings.Memory
+		}
+		provider.hostConf.Ulimits = append(provider.hostConf.Ulimits, settings.Ulimits...)
+		provider.hostConf.ExtraHosts = append(provider.hostConf.ExtraHosts, settings.ExtraHosts...)
+		provider.hostConf.DNS = append(provider.hostConf.DNS, settings.DNS...)
+		provider.hostConf.SecurityOpt = append(provider.hostConf.SecurityOpt, settings.SecurityOpts...)
+		if len(settings.Tmpfs) > 0 {
+			if provider.hostConf.Tmpfs == nil {
+				provider.hostConf.Tmpfs = map[string]string{}
+			}
+			for path, options := range settings.Tmpfs {
+				provider.hostConf.Tmpfs[path] = options
+			}
+		}
+	}
+}
+
 func WithNetwork(networkMode string) PhaseConfigProviderOperation {
 	return func(provider *PhaseConfigProvider) {
 		provider.hostConf.NetworkMode = container.NetworkMode(networkMode)
@@ -243,6 +353,20 @@ func WithPostContainerRunOperations(operations ...ContainerOperation) PhaseConfi
 	}
 }
 
+// adds option to the options of bind, e.g. "src:dst:ro" becomes "src:dst:ro,z"
+func withBindOption(bind, option string) string {
+	parts := strings.SplitN(bind, ":", 3)
+	if len(parts) < 3 {
+		return bind + ":" + option
+	}
+	for _, opt := range strings.Split(parts[2], ",") {
+		if opt == option {
+			return bind
+		}
+	}
+	return bind + "," + option
+}
+
 func If(expression bool, operation PhaseConfigProviderOperation) PhaseConfigProviderOperation {
 	if expression {
 		return operation
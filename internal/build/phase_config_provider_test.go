@@ -8,9 +8,11 @@ import (
 	"time"
 
 	ifakes "github.com/buildpacks/imgutil/fakes"
+	"github.com/buildpacks/lifecycle/platform"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/strslice"
 	"github.com/docker/docker/client"
+	"github.com/docker/go-units"
 	"github.com/heroku/color"
 	"github.com/pkg/errors"
 	"github.com/sclevine/spec"
@@ -18,6 +20,7 @@ import (
 
 	"github.com/buildpacks/pack/internal/build"
 	"github.com/buildpacks/pack/internal/build/fakes"
+	"github.com/buildpacks/pack/internal/engine"
 	"github.com/buildpacks/pack/pkg/logging"
 	h "github.com/buildpacks/pack/testhelpers"
 )
@@ -155,6 +158,40 @@ func testPhaseConfigProvider(t *testing.T, when spec.G, it spec.S) {
 					h.AssertSliceContains(t, phaseConfigProvider.HostConfig().Binds, "/var/run/docker.sock:/var/run/docker.sock")
 					h.AssertSliceContains(t, phaseConfigProvider.HostConfig().SecurityOpt, "label=disable")
 				})
+
+				when("the engine is rootless", func() {
+					it("binds the socket of the engine", func() {
+						lifecycle := newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+							opts.Engine = engine.Engine{Kind: engine.Podman, Rootless: true, UserNamespace: true, Socket: "/run/user/1000/podman/podman.sock"}
+						})
+
+						phaseConfigProvider := build.NewPhaseConfigProvider(
+							"some-name",
+							lifecycle,
+							build.WithDaemonAccess(""),
+						)
+
+						h.AssertSliceContains(t, phaseConfigProvider.HostConfig().Binds, "/run/user/1000/podman/podman.sock:/var/run/docker.sock")
+						h.AssertEq(t, phaseConfigProvider.HostConfig().UsernsMode, container.UsernsMode(""))
+					})
+				})
+
+				when("the engine remaps users", func() {
+					it("runs in the user namespace of the host", func() {
+						lifecycle := newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+							opts.Engine = engine.Engine{Kind: engine.Docker, UserNamespace: true, Socket: "/var/run/docker.sock"}
+						})
+
+						phaseConfigProvider := build.NewPhaseConfigProvider(
+							"some-name",
+							lifecycle,
+							build.WithDaemonAccess(""),
+						)
+
+						h.AssertSliceContains(t, phaseConfigProvider.HostConfig().Binds, "/var/run/docker.sock:/var/run/docker.sock")
+						h.AssertEq(t, phaseConfigProvider.HostConfig().UsernsMode, container.UsernsMode("host"))
+					})
+				})
 			})
 
 			when("building for Windows", func() {
@@ -177,6 +214,176 @@ func testPhaseConfigProvider(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("the engine uses SELinux", func() {
+			it("relabels the host paths pack creates", func() {
+				lifecycle := newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+					opts.Engine = engine.Engine{Kind: engine.Docker, SELinux: true}
+					opts.Secrets = map[string][]byte{"npmrc": []byte("some-secret-token")}
+				})
+				defer lifecycle.Cleanup()
+
+				phaseConfigProvider := build.NewPhaseConfigProvider(
+					"some-name",
+					lifecycle,
+					build.WithSecrets(lifecycle),
+				)
+
+				h.AssertSliceContainsMatch(t, phaseConfigProvider.HostConfig().Binds, "^/.+:/platform/secrets:ro,z$")
+				h.AssertSliceContainsMatch(t, phaseConfigProvider.HostConfig().Binds, "^pack-layers-[a-z_]+:/layers$")
+			})
+
+			it("doesn't relabel the host paths of the user", func() {
+				lifecycle := newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+					opts.Engine = engine.Engine{Kind: engine.Docker, SELinux: true}
+				})
+
+				phaseConfigProvider := build.NewPhaseConfigProvider(
+					"some-name",
+					lifecycle,
+					build.WithBinds("/some/host/path:/some/path:ro", "/some/other/host/path:/some/other/path:ro,z", "some-volume:/some/volume/path"),
+				)
+
+				h.AssertSliceContains(t, phaseConfigProvider.HostConfig().Binds,
+					"/some/host/path:/some/path:ro",
+					"/some/other/host/path:/some/other/path:ro,z",
+					"some-volume:/some/volume/path",
+				)
+			})
+
+			it("doesn't relabel when labels are disabled", func() {
+				lifecycle := newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+					opts.Engine = engine.Engine{Kind: engine.Docker, SELinux: true}
+				})
+
+				phaseConfigProvider := build.NewPhaseConfigProvider(
+					"some-name",
+					lifecycle,
+					build.WithDaemonAccess(""),
+				)
+
+				h.AssertSliceContains(t, phaseConfigProvider.HostConfig().Binds, "/var/run/docker.sock:/var/run/docker.sock")
+			})
+		})
+
+		when("the engine is Podman", func() {
+			it("chowns the volumes managed by pack to the user of the container", func() {
+				lifecycle := newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+					opts.Engine = engine.Engine{Kind: engine.Podman, Rootless: true, UserNamespace: true}
+				})
+
+				phaseConfigProvider := build.NewPhaseConfigProvider(
+					"some-name",
+					lifecycle,
+					build.WithBinds("pack-cache-some-image.build:/cache", "some-volume:/some/volume/path"),
+				)
+
+				h.AssertSliceContainsMatch(t, phaseConfigProvider.HostConfig().Binds, "^pack-layers-[a-z_]+:/layers:U$")
+				h.AssertSliceContainsMatch(t, phaseConfigProvider.HostConfig().Binds, "^pack-app-[a-z_]+:/workspace:U$")
+				h.AssertSliceContains(t, phaseConfigProvider.HostConfig().Binds, "pack-cache-some-image.build:/cache:U", "some-volume:/some/volume/path")
+			})
+
+			it("doesn't chown the volumes of containers running as root", func() {
+				lifecycle := newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+					opts.Engine = engine.Engine{Kind: engine.Podman, Rootless: true, UserNamespace: true}
+				})
+
+				phaseConfigProvider := build.NewPhaseConfigProvider(
+					"some-name",
+					lifecycle,
+					build.WithRoot(),
+				)
+
+				h.AssertSliceContainsMatch(t, phaseConfigProvider.HostConfig().Binds, "^pack-layers-[a-z_]+:/layers$")
+			})
+		})
+
+		when("the engine is rootless Docker", func() {
+			it("chowns the volumes managed by pack to the user of the container before it starts", func() {
+				lifecycle := newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+					opts.Engine = engine.Engine{Kind: engine.Docker, Rootless: true, UserNamespace: true}
+				})
+
+				phaseConfigProvider := build.NewPhaseConfigProvider(
+					"some-name",
+					lifecycle,
+					build.WithContainerOperations(build.WriteProjectMetadata("/some/path", platform.ProjectMetadata{}, "linux")),
+				)
+
+				h.AssertSliceContainsMatch(t, phaseConfigProvider.HostConfig().Binds, "^pack-layers-[a-z_]+:/layers$")
+				h.AssertEq(t, len(phaseConfigProvider.ContainerOps()), 2)
+				h.AssertFunctionName(t, phaseConfigProvider.ContainerOps()[0], "EnsureVolumeOwnership")
+			})
+
+			it("doesn't chown the volumes of containers running as root", func() {
+				lifecycle := newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+					opts.Engine = engine.Engine{Kind: engine.Docker, Rootless: true, UserNamespace: true}
+				})
+
+				phaseConfigProvider := build.NewPhaseConfigProvider(
+					"some-name",
+					lifecycle,
+					build.WithRoot(),
+				)
+
+				h.AssertEq(t, len(phaseConfigProvider.ContainerOps()), 0)
+			})
+		})
+
+		when("called with WithContainerSettings", func() {
+			it("sets the resources, hosts and security options on the host config", func() {
+				lifecycle := newTestLifecycleExec(t, false)
+
+				phaseConfigProvider := build.NewPhaseConfigProvider(
+					"some-name",
+					lifecycle,
+					build.WithContainerSettings(build.ContainerSettings{
+						NanoCPUs:     1500000000,
+						Memory:       4 * 1024 * 1024 * 1024,
+						Ulimits:      []*units.Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}},
+						ExtraHosts:   []string{"registry.local:10.0.0.1"},
+						DNS:          []string{"10.0.0.2"},
+						Tmpfs:        map[string]string{"/tmp": "size=64m"},
+						SecurityOpts: []string{"no-new-privileges"},
+					}),
+				)
+
+				hostConfig := phaseConfigProvider.HostConfig()
+				h.AssertEq(t, hostConfig.NanoCPUs, int64(1500000000))
+				h.AssertEq(t, hostConfig.Memory, int64(4*1024*1024*1024))
+				h.AssertEq(t, hostConfig.Ulimits, []*units.Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}})
+				h.AssertEq(t, hostConfig.ExtraHosts, []string{"registry.local:10.0.0.1"})
+				h.AssertEq(t, hostConfig.DNS, []string{"10.0.0.2"})
+				h.AssertEq(t, hostConfig.Tmpfs, map[string]string{"/tmp": "size=64m"})
+				h.AssertEq(t, hostConfig.SecurityOpt, []string{"no-new-privileges"})
+			})
+
+			it("applies the settings of the lifecycle to every phase", func() {
+				lifecycle := newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+					opts.Container = build.ContainerSettings{Memory: 512 * 1024 * 1024, SecurityOpts: []string{"seccomp=unconfined"}}
+				})
+
+				phaseConfigProvider := build.NewPhaseConfigProvider(
+					"some-name",
+					lifecycle,
+					build.WithDaemonAccess(""),
+				)
+
+				h.AssertEq(t, phaseConfigProvider.HostConfig().Memory, int64(512*1024*1024))
+				h.AssertEq(t, phaseConfigProvider.HostConfig().SecurityOpt, []string{"label=disable", "seccomp=unconfined"})
+			})
+
+			it("mounts the app volume read-only", func() {
+				lifecycle := newTestLifecycleExec(t, false, func(opts *build.LifecycleOptions) {
+					opts.Container.ReadOnlyApp = true
+				})
+
+				phaseConfigProvider := build.NewPhaseConfigProvider("some-name", lifecycle)
+
+				h.AssertSliceContainsMatch(t, phaseConfigProvider.HostConfig().Binds, "^pack-app-[a-z_]+:/workspace:ro$")
+				h.AssertSliceContainsMatch(t, phaseConfigProvider.HostConfig().Binds, "^pack-layers-[a-z_]+:/layers$")
+			})
+		})
+
 		when("called with WithEnv", func() {
 			it("sets the environment on the config", func() {
 				lifecycle := newTestLifecycleExec(t, false)
@@ -0,0 +1,86 @@
+package build
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// the minimum length of a value of a secret that's redacted, shorter values are too likely to be part of other output
+const minRedactedLength = 4
+
+// sharedMemoryDir is a tmpfs on most Linux hosts, secrets written to it don't touch the disk
+var sharedMemoryDir = "/dev/shm"
+
+// writeSecrets writes each secret to a file named after its id in a directory only the current user can access.
+// The directory is bind-mounted read-only to the secrets dir of the containers that run buildpacks.
+func writeSecrets(secrets map[string][]byte) (string, error) {
+	baseDir := ""
+	if fi, err := os.Stat(sharedMemoryDir); err == nil && fi.IsDir() {
+		baseDir = sharedMemoryDir
+	}
+
+	tmpDir, err := ioutil.TempDir(baseDir, "pack-secrets-")
+	if err != nil {
+		return "", errors.Wrap(err, "creating secrets dir")
+	}
+
+	// the users of the containers need to read the secrets, the private parent keeps other users of the host out
+	secretsDir := filepath.Join(tmpDir, "secrets")
+	if err := os.Mkdir(secretsDir, 0755); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", errors.Wrap(err, "creating secrets dir")
+	}
+	for id, contents := range secrets {
+		if err := ioutil.WriteFile(filepath.Join(secretsDir, id), contents, 0444); err != nil {
+			os.RemoveAll(tmpDir)
+			return "", errors.Wrapf(err, "writing secret %s", id)
+		}
+	}
+
+	return secretsDir, nil
+}
+
+// removeSecrets removes the directory written by writeSecrets
+func removeSecrets(secretsDir string) error {
+	if secretsDir == "" {
+		return nil
+	}
+	return os.RemoveAll(filepath.Dir(secretsDir))
+}
+
+// secretRedactions returns the values of secrets to redact from logs: the contents, each line,
+// and the value of each line of the form key=value or key: value, e.g. an auth token in an .npmrc file
+func secretRedactions(secrets map[string][]byte) []string {
+	var values []string
+	add := func(value string) {
+		if value = strings.TrimSpace(value); len(value) >= minRedactedLength {
+			values = append(values, value)
+		}
+	}
+
+	for _, contents := range secrets {
+		add(string(contents))
+		for _, line := range strings.Split(string(contents), "\n") {
+			add(line)
+			for _, separator := range []string{"=", ":"} {
+				if i := strings.Index(line, separator); i >= 0 {
+					add(strings.Trim(strings.TrimSpace(line[i+1:]), `"'`))
+				}
+			}
+		}
+	}
+	return values
+}
+
+// WithSecrets mounts the secrets of the build read-only, for buildpacks to read during detect and build
+func WithSecrets(lifecycleExec *LifecycleExecution) PhaseConfigProviderOperation {
+	if lifecycleExec.secretsDir == "" {
+		return NullOp()
+	}
+	return WithBinds(fmt.Sprintf("%s:%s:ro", lifecycleExec.secretsDir, lifecycleExec.mountPaths.secretsDir()))
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/buildpacks/imgutil"
+	"github.com/dustin/go-humanize"
 	"github.com/pkg/errors"
 
 	"github.com/buildpacks/pack/builder"
@@ -65,6 +66,7 @@ type Builder struct {
 	lifecycle            Lifecycle
 	lifecycleDescriptor  LifecycleDescriptor
 	additionalBuildpacks []buildpack.Buildpack
+	removedBuildpacks    []dist.BuildpackInfo
 	metadata             Metadata
 	mixins               []string
 	env                  map[string]string
@@ -72,6 +74,32 @@ type Builder struct {
 	StackID              string
 	replaceOrder         bool
 	order                dist.Order
+	compression          dist.LayerCompression
+	layerCache           layerCache
+}
+
+// gzipLayersImage is a builder image whose layers are compressed into tmpDir before they are added, so that
+// they are published with the configured gzip level
+type gzipLayersImage struct {
+	imgutil.Image
+	compression dist.LayerCompression
+	tmpDir      string
+}
+
+func (i *gzipLayersImage) AddLayer(path string) error {
+	compressedPath, err := buildpack.GzipLayerTar(path, i.tmpDir, i.compression)
+	if err != nil {
+		return err
+	}
+	return i.Image.AddLayer(compressedPath)
+}
+
+func (i *gzipLayersImage) AddLayerWithDiffID(path, diffID string) error {
+	compressedPath, err := buildpack.GzipLayerTar(path, i.tmpDir, i.compression)
+	if err != nil {
+		return err
+	}
+	return i.Image.AddLayerWithDiffID(compressedPath, diffID)
 }
 
 type orderTOML struct {
@@ -234,6 +262,18 @@ func (b *Builder) AddBuildpack(bp buildpack.Buildpack) {
 	b.metadata.Buildpacks = append(b.metadata.Buildpacks, bp.Descriptor().Info)
 }
 
+// RemoveBuildpack removes a buildpack of the base image from the builder
+func (b *Builder) RemoveBuildpack(bpInfo dist.BuildpackInfo) {
+	var buildpacks []dist.BuildpackInfo
+	for _, bp := range b.metadata.Buildpacks {
+		if bp.FullName() != bpInfo.FullName() {
+			buildpacks = append(buildpacks, bp)
+		}
+	}
+	b.metadata.Buildpacks = buildpacks
+	b.removedBuildpacks = append(b.removedBuildpacks, bpInfo)
+}
+
 // SetLifecycle sets the lifecycle of the builder
 func (b *Builder) SetLifecycle(lifecycle Lifecycle) {
 	b.lifecycle = lifecycle
@@ -245,6 +285,16 @@ func (b *Builder) SetEnv(env map[string]string) {
 	b.env = env
 }
 
+// SetBuildEnv sets the environment variables the builder provides to every build
+func (b *Builder) SetBuildEnv(env []builder.BuildEnv) {
+	b.metadata.BuildEnv = env
+}
+
+// BuildEnv returns the environment variables the builder provides to every build
+func (b *Builder) BuildEnv() []builder.BuildEnv {
+	return b.metadata.BuildEnv
+}
+
 // SetOrder sets the order of the builder
 func (b *Builder) SetOrder(order dist.Order) {
 	b.order = order
@@ -256,6 +306,18 @@ func (b *Builder) SetDescription(description string) {
 	b.metadata.Description = description
 }
 
+// SetLayerCompression sets how the layers added to the builder are compressed. It should only be set for
+// builders that are published, as the daemon compresses the layers of images it pushes itself.
+func (b *Builder) SetLayerCompression(compression dist.LayerCompression) {
+	b.compression = compression
+}
+
+// SetLayerCache sets the directory that the layers of buildpacks are cached in by diff ID, so that they can be
+// reused instead of written again when a builder is created again
+func (b *Builder) SetLayerCache(dir string) {
+	b.layerCache = layerCache{dir: dir, maxSize: maxLayerCacheSize}
+}
+
 // SetStack sets the stack of the builder
 func (b *Builder) SetStack(stackConfig builder.StackConfig) {
 	b.metadata.Stack = StackMetadata{
@@ -284,11 +346,16 @@ func (b *Builder) Save(logger logging.Logger, creatorMetadata CreatorMetadata) e
 	}
 	defer os.RemoveAll(tmpDir)
 
+	var image imgutil.Image = b.image
+	if !b.compression.IsDefault() {
+		image = &gzipLayersImage{Image: b.image, compression: b.compression, tmpDir: tmpDir}
+	}
+
 	dirsTar, err := b.defaultDirsLayer(tmpDir)
 	if err != nil {
 		return err
 	}
-	if err := b.image.AddLayer(dirsTar); err != nil {
+	if err := image.AddLayer(dirsTar); err != nil {
 		return errors.Wrap(err, "adding default dirs layer")
 	}
 
@@ -301,7 +368,7 @@ func (b *Builder) Save(logger logging.Logger, creatorMetadata CreatorMetadata) e
 		if err != nil {
 			return err
 		}
-		if err := b.image.AddLayer(lifecycleTar); err != nil {
+		if err := image.AddLayer(lifecycleTar); err != nil {
 			return errors.Wrap(err, "adding lifecycle layer")
 		}
 	}
@@ -315,7 +382,11 @@ func (b *Builder) Save(logger logging.Logger, creatorMetadata CreatorMetadata) e
 		return errors.Wrapf(err, "getting label %s", dist.BuildpackLayersLabel)
 	}
 
-	err = b.addBuildpacks(logger, tmpDir, b.image, b.additionalBuildpacks, bpLayers)
+	if err := b.removeBuildpacks(logger, tmpDir, image, b.removedBuildpacks, bpLayers); err != nil {
+		return err
+	}
+
+	err = b.addBuildpacks(logger, tmpDir, image, b.additionalBuildpacks, bpLayers)
 	if err != nil {
 		return err
 	}
@@ -329,7 +400,7 @@ func (b *Builder) Save(logger logging.Logger, creatorMetadata CreatorMetadata) e
 		if err != nil {
 			return err
 		}
-		if err := b.image.AddLayer(orderTar); err != nil {
+		if err := image.AddLayer(orderTar); err != nil {
 			return errors.Wrap(err, "adding order.tar layer")
 		}
 
@@ -342,7 +413,7 @@ func (b *Builder) Save(logger logging.Logger, creatorMetadata CreatorMetadata) e
 	if err != nil {
 		return err
 	}
-	if err := b.image.AddLayer(stackTar); err != nil {
+	if err := image.AddLayer(stackTar); err != nil {
 		return errors.Wrap(err, "adding stack.tar layer")
 	}
 
@@ -350,12 +421,12 @@ func (b *Builder) Save(logger logging.Logger, creatorMetadata CreatorMetadata) e
 		logger.Debugf("Provided Environment Variables\n  %s", style.Map(b.env, "  ", "\n"))
 	}
 
-	envTar, err := b.envLayer(tmpDir, b.env)
+	envTar, err := b.envLayer(tmpDir, b.mergedEnv(logger))
 	if err != nil {
 		return err
 	}
 
-	if err := b.image.AddLayer(envTar); err != nil {
+	if err := image.AddLayer(envTar); err != nil {
 		return errors.Wrap(err, "adding env layer")
 	}
 
@@ -377,46 +448,103 @@ func (b *Builder) Save(logger logging.Logger, creatorMetadata CreatorMetadata) e
 		return errors.Wrap(err, "failed to set working dir")
 	}
 
-	return b.image.Save()
+	if err := b.image.Save(); err != nil {
+		return err
+	}
+
+	if err := b.layerCache.evict(); err != nil {
+		logger.Debugf("Failed to trim layer cache: %s", err)
+	}
+
+	return nil
 }
 
 // Helpers
 
+func (b *Builder) removeBuildpacks(logger logging.Logger, tmpDir string, image imgutil.Image, removedBuildpacks []dist.BuildpackInfo, bpLayers dist.BuildpackLayers) error {
+	for i, bpInfo := range removedBuildpacks {
+		if _, ok := bpLayers.Get(bpInfo.ID, bpInfo.Version); !ok {
+			continue
+		}
+
+		logger.Debugf("Removing buildpack %s", style.Symbol(bpInfo.FullName()))
+		whiteoutsTar, err := b.whiteoutLayer(filepath.Join(tmpDir, "removed"), i, bpInfo)
+		if err != nil {
+			return err
+		}
+
+		if err := image.AddLayer(whiteoutsTar); err != nil {
+			return errors.Wrap(err, "adding whiteout layer tar")
+		}
+
+		delete(bpLayers[bpInfo.ID], bpInfo.Version)
+		if len(bpLayers[bpInfo.ID]) == 0 {
+			delete(bpLayers, bpInfo.ID)
+		}
+	}
+
+	for id, versions := range bpLayers {
+		for version, layer := range versions {
+			for _, group := range layer.Order {
+				for _, ref := range group.Group {
+					for _, removed := range removedBuildpacks {
+						if ref.FullName() == removed.FullName() {
+							return fmt.Errorf(
+								"buildpack %s is required by buildpack %s and can't be removed",
+								style.Symbol(removed.FullName()),
+								style.Symbol(id+"@"+version),
+							)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 func (b *Builder) addBuildpacks(logger logging.Logger, tmpDir string, image imgutil.Image, additionalBuildpacks []buildpack.Buildpack, bpLayers dist.BuildpackLayers) error {
 	type buildpackToAdd struct {
 		tarPath   string
 		diffID    string
+		size      int64
+		cached    bool
 		buildpack buildpack.Buildpack
 	}
 
+	var (
+		reusedFromBase      int
+		reusedFromBaseBytes int64
+	)
 	buildpacksToAdd := map[string]buildpackToAdd{}
 	for i, bp := range additionalBuildpacks {
-		// create buildpack directory
-		bpTmpDir := filepath.Join(tmpDir, strconv.Itoa(i))
-		if err := os.MkdirAll(bpTmpDir, os.ModePerm); err != nil {
-			return errors.Wrap(err, "creating buildpack temp dir")
-		}
+		bpInfo := bp.Descriptor().Info
 
-		// create tar file
-		bpLayerTar, err := buildpack.ToLayerTar(bpTmpDir, bp)
-		if err != nil {
-			return err
+		// buildpacks from packages are skipped without reading their layer, if they are on the builder already
+		if diffID, ok := buildpack.KnownLayerDiffID(bp); ok {
+			if existingBPInfo, ok := bpLayers[bpInfo.ID][bpInfo.Version]; ok && existingBPInfo.LayerDiffID == diffID {
+				logger.Debugf("Buildpack %s already exists on builder with same contents, skipping...", style.Symbol(bpInfo.FullName()))
+				reusedFromBase++
+				// the size of the layer is only known without reading it when it is in the layer cache
+				if _, size, ok := b.layerCache.get(diffID); ok {
+					reusedFromBaseBytes += size
+				}
+				continue
+			}
 		}
 
-		// generate diff id
-		diffID, err := dist.LayerDiffID(bpLayerTar)
+		bpLayerTar, diffID, size, cached, err := b.buildpackLayerTar(filepath.Join(tmpDir, strconv.Itoa(i)), bp)
 		if err != nil {
-			return errors.Wrapf(err,
-				"getting content hashes for buildpack %s",
-				style.Symbol(bp.Descriptor().Info.FullName()),
-			)
+			return err
 		}
 
-		bpInfo := bp.Descriptor().Info
 		// check against builder layers
 		if existingBPInfo, ok := bpLayers[bpInfo.ID][bpInfo.Version]; ok {
-			if existingBPInfo.LayerDiffID == diffID.String() {
+			if existingBPInfo.LayerDiffID == diffID {
 				logger.Debugf("Buildpack %s already exists on builder with same contents, skipping...", style.Symbol(bpInfo.FullName()))
+				reusedFromBase++
+				reusedFromBaseBytes += size
 				continue
 			} else {
 				whiteoutsTar, err := b.whiteoutLayer(tmpDir, i, bpInfo)
@@ -429,27 +557,33 @@ func (b *Builder) addBuildpacks(logger logging.Logger, tmpDir string, image imgu
 				}
 			}
 
-			logger.Debugf(BuildpackOnBuilderMessage, style.Symbol(bpInfo.FullName()), style.Symbol(existingBPInfo.LayerDiffID), style.Symbol(diffID.String()))
+			logger.Debugf(BuildpackOnBuilderMessage, style.Symbol(bpInfo.FullName()), style.Symbol(existingBPInfo.LayerDiffID), style.Symbol(diffID))
 		}
 
 		// check against other buildpacks to be added
 		if otherAdditionalBP, ok := buildpacksToAdd[bp.Descriptor().Info.FullName()]; ok {
-			if otherAdditionalBP.diffID == diffID.String() {
+			if otherAdditionalBP.diffID == diffID {
 				logger.Debugf("Buildpack %s with same contents is already being added, skipping...", style.Symbol(bpInfo.FullName()))
 				continue
 			}
 
-			logger.Debugf(BuildpackPreviouslyDefinedMessage, style.Symbol(bpInfo.FullName()), style.Symbol(otherAdditionalBP.diffID), style.Symbol(diffID.String()))
+			logger.Debugf(BuildpackPreviouslyDefinedMessage, style.Symbol(bpInfo.FullName()), style.Symbol(otherAdditionalBP.diffID), style.Symbol(diffID))
 		}
 
 		// note: if same id@version is in additionalBuildpacks, last one wins (see warnings above)
 		buildpacksToAdd[bp.Descriptor().Info.FullName()] = buildpackToAdd{
 			tarPath:   bpLayerTar,
-			diffID:    diffID.String(),
+			diffID:    diffID,
+			size:      size,
+			cached:    cached,
 			buildpack: bp,
 		}
 	}
 
+	var (
+		reusedFromCache, written  int
+		reusedBytes, writtenBytes int64
+	)
 	for _, bp := range buildpacksToAdd {
 		logger.Debugf("Adding buildpack %s (diffID=%s)", style.Symbol(bp.buildpack.Descriptor().Info.FullName()), bp.diffID)
 		if err := image.AddLayerWithDiffID(bp.tarPath, bp.diffID); err != nil {
@@ -459,12 +593,78 @@ func (b *Builder) addBuildpacks(logger logging.Logger, tmpDir string, image imgu
 			)
 		}
 
+		if bp.cached {
+			reusedFromCache++
+			reusedBytes += bp.size
+		} else {
+			written++
+			writtenBytes += bp.size
+		}
+
 		dist.AddBuildpackToLayersMD(bpLayers, bp.buildpack.Descriptor(), bp.diffID)
 	}
 
+	if len(additionalBuildpacks) > 0 {
+		logger.Infof(
+			"Buildpack layers: %d (%s) reused from the base image, %d (%s) reused from the layer cache, %d (%s) written",
+			reusedFromBase,
+			humanize.Bytes(uint64(reusedFromBaseBytes)),
+			reusedFromCache,
+			humanize.Bytes(uint64(reusedBytes)),
+			written,
+			humanize.Bytes(uint64(writtenBytes)),
+		)
+	}
+
 	return nil
 }
 
+// buildpackLayerTar returns the path, diff ID and size of the layer tar of a buildpack, and whether it was
+// found in the layer cache rather than written
+func (b *Builder) buildpackLayerTar(bpTmpDir string, bp buildpack.Buildpack) (string, string, int64, bool, error) {
+	if diffID, ok := buildpack.KnownLayerDiffID(bp); ok {
+		if cachedPath, size, ok := b.layerCache.get(diffID); ok {
+			return cachedPath, diffID, size, true, nil
+		}
+	}
+
+	// create buildpack directory
+	if err := os.MkdirAll(bpTmpDir, os.ModePerm); err != nil {
+		return "", "", 0, false, errors.Wrap(err, "creating buildpack temp dir")
+	}
+
+	// create tar file
+	bpLayerTar, err := buildpack.ToLayerTar(bpTmpDir, bp)
+	if err != nil {
+		return "", "", 0, false, err
+	}
+
+	// generate diff id
+	diffID, err := dist.LayerDiffID(bpLayerTar)
+	if err != nil {
+		return "", "", 0, false, errors.Wrapf(err,
+			"getting content hashes for buildpack %s",
+			style.Symbol(bp.Descriptor().Info.FullName()),
+		)
+	}
+
+	info, err := os.Stat(bpLayerTar)
+	if err != nil {
+		return "", "", 0, false, err
+	}
+
+	if cachedPath, size, ok := b.layerCache.get(diffID.String()); ok {
+		return cachedPath, diffID.String(), size, true, nil
+	}
+
+	cachedPath, err := b.layerCache.put(bpLayerTar, diffID.String())
+	if err != nil {
+		return "", "", 0, false, err
+	}
+
+	return cachedPath, diffID.String(), info.Size(), false, nil
+}
+
 func processOrder(buildpacks []dist.BuildpackInfo, order dist.Order) (dist.Order, error) {
 	resolvedOrder := dist.Order{}
 
@@ -772,6 +972,29 @@ func (b *Builder) stackLayer(dest string) (string, error) {
 	return layerTar, nil
 }
 
+// mergedEnv combines the build environment of the builder with the provided environment variables.
+// A provided variable replaces a default value of the builder, but not an override value.
+func (b *Builder) mergedEnv(logger logging.Logger) map[string]string {
+	env := map[string]string{}
+	overrides := map[string]bool{}
+	for _, buildEnv := range b.metadata.BuildEnv {
+		env[buildEnv.Name] = buildEnv.Value
+		overrides[buildEnv.Name] = buildEnv.IsOverride()
+	}
+
+	for k, v := range b.env {
+		if overrides[k] {
+			if env[k] != v {
+				logger.Warnf("Environment variable %s is overridden by the builder and will be ignored", style.Symbol(k))
+			}
+			continue
+		}
+		env[k] = v
+	}
+
+	return env
+}
+
 func (b *Builder) envLayer(dest string, env map[string]string) (string, error) {
 	fh, err := os.Create(filepath.Join(dest, "env.tar"))
 	if err != nil {
@@ -805,7 +1028,7 @@ func (b *Builder) whiteoutLayer(tmpDir string, i int, bpInfo dist.BuildpackInfo)
 		return "", errors.Wrap(err, "creating buildpack whiteouts temp dir")
 	}
 
-	fh, err := os.Create(filepath.Join(bpWhiteoutsTmpDir, "whiteouts.tar"))
+	fh, err := os.Create(filepath.Join(bpWhiteoutsTmpDir, fmt.Sprintf("%s.%s.whiteouts.tar", strings.ReplaceAll(bpInfo.ID, "/", "_"), bpInfo.Version)))
 	if err != nil {
 		return "", err
 	}
@@ -11,7 +11,9 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -980,6 +982,133 @@ func testBuilder(t *testing.T, when spec.G, it spec.S) {
 				h.AssertEq(t, layers["order-buildpack-id"]["order-buildpack-version"].Order[0].Group[1].Optional, false)
 			})
 
+			when("a layer cache is set", func() {
+				var layerCacheDir string
+
+				it.Before(func() {
+					var err error
+					layerCacheDir, err = ioutil.TempDir("", "layer-cache")
+					h.AssertNil(t, err)
+					subject.SetLayerCache(layerCacheDir)
+				})
+
+				it.After(func() {
+					h.AssertNilE(t, os.RemoveAll(layerCacheDir))
+				})
+
+				it("caches the buildpack layers by diff ID", func() {
+					h.AssertNil(t, subject.Save(logger, builder.CreatorMetadata{}))
+					assertImageHasBPLayer(t, baseImage, bp1v1)
+
+					label, err := baseImage.Label("io.buildpacks.buildpack.layers")
+					h.AssertNil(t, err)
+
+					var layers dist.BuildpackLayers
+					h.AssertNil(t, json.Unmarshal([]byte(label), &layers))
+
+					diffID := layers["buildpack-1-id"]["buildpack-1-version-1"].LayerDiffID
+					_, err = os.Stat(filepath.Join(layerCacheDir, "sha256", strings.TrimPrefix(diffID, "sha256:")+".tar"))
+					h.AssertNil(t, err)
+
+					cached, err := ioutil.ReadDir(filepath.Join(layerCacheDir, "sha256"))
+					h.AssertNil(t, err)
+					h.AssertEq(t, len(cached), 4)
+				})
+
+				it("logs how many buildpack layers were written", func() {
+					h.AssertNil(t, subject.Save(logger, builder.CreatorMetadata{}))
+					h.AssertContains(t, outBuf.String(), "Buildpack layers: 0 (0 B) reused from the base image, 0 (0 B) reused from the layer cache, 4 (")
+				})
+
+				it("reuses the buildpack layers that are on the base image", func() {
+					h.AssertNil(t, subject.Save(logger, builder.CreatorMetadata{}))
+
+					rebuilt, err := builder.New(baseImage, "some/builder")
+					h.AssertNil(t, err)
+					rebuilt.SetLayerCache(layerCacheDir)
+					rebuilt.AddBuildpack(bp1v1)
+					rebuilt.AddBuildpack(bp2v1)
+
+					outBuf.Reset()
+					h.AssertNil(t, rebuilt.Save(logger, builder.CreatorMetadata{}))
+					h.AssertContains(t, outBuf.String(), "Buildpack layers: 2 (")
+					h.AssertNotContains(t, outBuf.String(), "Buildpack layers: 2 (0 B)")
+					h.AssertContains(t, outBuf.String(), "reused from the base image, 0 (0 B) reused from the layer cache, 0 (0 B) written")
+				})
+
+				it("reuses the buildpack layers in the layer cache", func() {
+					h.AssertNil(t, subject.Save(logger, builder.CreatorMetadata{}))
+
+					otherBaseImage := fakes.NewImage("other/base/image", "", nil)
+					defer otherBaseImage.Cleanup()
+					h.AssertNil(t, otherBaseImage.SetEnv("CNB_USER_ID", "1234"))
+					h.AssertNil(t, otherBaseImage.SetEnv("CNB_GROUP_ID", "4321"))
+					h.AssertNil(t, otherBaseImage.SetLabel("io.buildpacks.stack.id", "some.stack.id"))
+					h.AssertNil(t, otherBaseImage.SetLabel("io.buildpacks.stack.mixins", `["mixinX", "mixinY", "build:mixinA"]`))
+
+					rebuilt, err := builder.New(otherBaseImage, "some/builder")
+					h.AssertNil(t, err)
+					otherLifecycle := testmocks.NewMockLifecycle(mockController)
+					otherLifecycle.EXPECT().Open().Return(archive.ReadDirAsTar(
+						filepath.Join("testdata", "lifecycle", "platform-0.4"),
+						".", 0, 0, 0755, true, false, nil,
+					), nil).AnyTimes()
+					otherLifecycle.EXPECT().Descriptor().Return(mockLifecycle.Descriptor()).AnyTimes()
+					rebuilt.SetLifecycle(otherLifecycle)
+					rebuilt.SetLayerCache(layerCacheDir)
+					rebuilt.AddBuildpack(bp1v1)
+					rebuilt.AddBuildpack(bp2v1)
+
+					outBuf.Reset()
+					h.AssertNil(t, rebuilt.Save(logger, builder.CreatorMetadata{}))
+					h.AssertContains(t, outBuf.String(), "Buildpack layers: 0 (0 B) reused from the base image, 2 (")
+					h.AssertContains(t, outBuf.String(), "reused from the layer cache, 0 (0 B) written")
+				})
+
+				it("doesn't write compressed layers into the layer cache", func() {
+					subject.SetLayerCompression(dist.LayerCompression{Level: 1})
+					h.AssertNil(t, subject.Save(logger, builder.CreatorMetadata{}))
+
+					cached, err := ioutil.ReadDir(filepath.Join(layerCacheDir, "sha256"))
+					h.AssertNil(t, err)
+					h.AssertEq(t, len(cached), 4)
+					for _, f := range cached {
+						h.AssertEq(t, filepath.Ext(f.Name()), ".tar")
+					}
+				})
+
+				it("evicts the least recently used layers once the cache is too big", func() {
+					oldLayer := filepath.Join(layerCacheDir, "sha256", strings.Repeat("a", 64)+".tar")
+					recentLayer := filepath.Join(layerCacheDir, "sha256", strings.Repeat("b", 64)+".tar")
+					leftover := filepath.Join(layerCacheDir, "sha256", "layer-123")
+					h.AssertNil(t, os.MkdirAll(filepath.Dir(oldLayer), 0750))
+					for _, path := range []string{oldLayer, recentLayer, leftover} {
+						f, err := os.Create(path)
+						h.AssertNil(t, err)
+						h.AssertNil(t, f.Close())
+					}
+					// sparse files, so that the cache is over its max size without using the disk space
+					h.AssertNil(t, os.Truncate(oldLayer, 2<<30))
+					h.AssertNil(t, os.Truncate(recentLayer, 2<<30))
+					dayAgo := time.Now().Add(-24 * time.Hour)
+					h.AssertNil(t, os.Chtimes(oldLayer, dayAgo, dayAgo))
+					h.AssertNil(t, os.Chtimes(leftover, dayAgo, dayAgo))
+
+					h.AssertNil(t, subject.Save(logger, builder.CreatorMetadata{}))
+
+					_, err := os.Stat(oldLayer)
+					h.AssertTrue(t, os.IsNotExist(err))
+					_, err = os.Stat(leftover)
+					h.AssertTrue(t, os.IsNotExist(err))
+					_, err = os.Stat(recentLayer)
+					h.AssertNil(t, err)
+
+					cached, err := ioutil.ReadDir(filepath.Join(layerCacheDir, "sha256"))
+					h.AssertNil(t, err)
+					h.AssertEq(t, len(cached), 5)
+				})
+			})
+
 			when("base image already has buildpack layers label", func() {
 				it.Before(func() {
 					var mdJSON bytes.Buffer
@@ -1117,6 +1246,54 @@ func testBuilder(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("#RemoveBuildpack", func() {
+			it.Before(func() {
+				h.AssertNil(t, baseImage.SetLabel(
+					"io.buildpacks.builder.metadata",
+					`{"buildpacks":[{"id":"buildpack-1-id","version":"buildpack-1-version-1"},{"id":"order-buildpack-id","version":"order-buildpack-version"}]}`,
+				))
+				h.AssertNil(t, baseImage.SetLabel(
+					"io.buildpacks.buildpack.layers",
+					`{
+  "buildpack-1-id": {"buildpack-1-version-1": {"layerDiffID": "sha256:buildpack-1-version-1-diff-id"}},
+  "order-buildpack-id": {"order-buildpack-version": {"layerDiffID": "sha256:order-buildpack-diff-id", "order": [{"group": [{"id": "buildpack-1-id", "version": "buildpack-1-version-1"}]}]}}
+}`,
+				))
+
+				var err error
+				subject, err = builder.New(baseImage, "some/builder")
+				h.AssertNil(t, err)
+			})
+
+			it("removes the buildpack from the image and its metadata", func() {
+				subject.RemoveBuildpack(dist.BuildpackInfo{ID: "order-buildpack-id", Version: "order-buildpack-version"})
+				h.AssertNil(t, subject.Save(logger, builder.CreatorMetadata{}))
+				h.AssertEq(t, baseImage.IsSaved(), true)
+
+				_, err := baseImage.FindLayerWithPath("/cnb/buildpacks/order-buildpack-id/.wh.order-buildpack-version")
+				h.AssertNil(t, err)
+
+				var layers dist.BuildpackLayers
+				_, err = dist.GetLabel(baseImage, "io.buildpacks.buildpack.layers", &layers)
+				h.AssertNil(t, err)
+				_, ok := layers.Get("order-buildpack-id", "order-buildpack-version")
+				h.AssertFalse(t, ok)
+
+				h.AssertEq(t, subject.Buildpacks(), []dist.BuildpackInfo{{ID: "buildpack-1-id", Version: "buildpack-1-version-1"}})
+			})
+
+			when("the buildpack is required by a remaining buildpack", func() {
+				it("returns an error", func() {
+					subject.RemoveBuildpack(dist.BuildpackInfo{ID: "buildpack-1-id", Version: "buildpack-1-version-1"})
+
+					h.AssertError(t,
+						subject.Save(logger, builder.CreatorMetadata{}),
+						"buildpack 'buildpack-1-id@buildpack-1-version-1' is required by buildpack 'order-buildpack-id@order-buildpack-version' and can't be removed",
+					)
+				})
+			})
+		})
+
 		when("#SetOrder", func() {
 			when("the buildpacks exist in the image", func() {
 				it.Before(func() {
@@ -1252,6 +1429,70 @@ func testBuilder(t *testing.T, when spec.G, it spec.S) {
 				)
 			})
 		})
+
+		when("#SetBuildEnv", func() {
+			it.Before(func() {
+				subject.SetBuildEnv([]pubbldr.BuildEnv{
+					{Name: "DEFAULT_KEY", Value: "default-val"},
+					{Name: "OVERRIDE_KEY", Value: "override-val", Mode: pubbldr.BuildEnvModeOverride},
+				})
+			})
+
+			it("adds the build env vars as files to the image", func() {
+				h.AssertNil(t, subject.Save(logger, builder.CreatorMetadata{}))
+				h.AssertEq(t, baseImage.IsSaved(), true)
+
+				layerTar, err := baseImage.FindLayerWithPath("/platform/env/DEFAULT_KEY")
+				h.AssertNil(t, err)
+				h.AssertOnTarEntry(t, layerTar, "/platform/env/DEFAULT_KEY",
+					h.ContentEquals(`default-val`),
+				)
+				h.AssertOnTarEntry(t, layerTar, "/platform/env/OVERRIDE_KEY",
+					h.ContentEquals(`override-val`),
+				)
+			})
+
+			it("stores the build env in the metadata", func() {
+				h.AssertNil(t, subject.Save(logger, builder.CreatorMetadata{}))
+				h.AssertEq(t, baseImage.IsSaved(), true)
+
+				label, err := baseImage.Label("io.buildpacks.builder.metadata")
+				h.AssertNil(t, err)
+
+				var metadata builder.Metadata
+				h.AssertNil(t, json.Unmarshal([]byte(label), &metadata))
+				h.AssertEq(t, metadata.BuildEnv, []pubbldr.BuildEnv{
+					{Name: "DEFAULT_KEY", Value: "default-val"},
+					{Name: "OVERRIDE_KEY", Value: "override-val", Mode: "override"},
+				})
+			})
+
+			when("env vars are also provided", func() {
+				it.Before(func() {
+					subject.SetEnv(map[string]string{
+						"DEFAULT_KEY":  "provided-val",
+						"OVERRIDE_KEY": "provided-val",
+					})
+					h.AssertNil(t, subject.Save(logger, builder.CreatorMetadata{}))
+					h.AssertEq(t, baseImage.IsSaved(), true)
+				})
+
+				it("replaces default values but not override values", func() {
+					layerTar, err := baseImage.FindLayerWithPath("/platform/env/DEFAULT_KEY")
+					h.AssertNil(t, err)
+					h.AssertOnTarEntry(t, layerTar, "/platform/env/DEFAULT_KEY",
+						h.ContentEquals(`provided-val`),
+					)
+					h.AssertOnTarEntry(t, layerTar, "/platform/env/OVERRIDE_KEY",
+						h.ContentEquals(`override-val`),
+					)
+				})
+
+				it("warns that the override value is used", func() {
+					h.AssertContains(t, outBuf.String(), "Warning: Environment variable 'OVERRIDE_KEY' is overridden by the builder and will be ignored")
+				})
+			})
+		})
 	})
 
 	when("builder exists", func() {
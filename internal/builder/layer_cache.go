@@ -0,0 +1,146 @@
+package builder
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxLayerCacheSize is the size the layer cache is trimmed to after a builder is created
+	maxLayerCacheSize = 2 << 30
+
+	// layerCacheMinAge is how long a layer tar is kept after it was last used, as builders that are being
+	// created at the same time may still be reading it
+	layerCacheMinAge = time.Hour
+)
+
+// layerCache is a directory of uncompressed layer tars, addressed by diff ID, so that the layers of buildpacks
+// don't need to be written again when a builder is created again. The least recently used layer tars are
+// evicted once the cache grows over maxSize.
+type layerCache struct {
+	dir     string
+	maxSize int64
+}
+
+func (c layerCache) path(diffID string) string {
+	return filepath.Join(c.dir, strings.Replace(diffID, ":", string(filepath.Separator), 1)+".tar")
+}
+
+// get returns the path and size of the layer tar with the diff ID, if it is cached
+func (c layerCache) get(diffID string) (string, int64, bool) {
+	if c.dir == "" {
+		return "", 0, false
+	}
+
+	info, err := os.Stat(c.path(diffID))
+	if err != nil || !info.Mode().IsRegular() {
+		return "", 0, false
+	}
+
+	// the modification time records when the layer tar was last used, for eviction
+	now := time.Now()
+	_ = os.Chtimes(c.path(diffID), now, now)
+
+	return c.path(diffID), info.Size(), true
+}
+
+// put copies a layer tar with the diff ID into the cache, and returns the path of the cached copy. If the
+// cache is disabled, the layer tar is left where it is.
+func (c layerCache) put(layerTarPath, diffID string) (string, error) {
+	if c.dir == "" {
+		return layerTarPath, nil
+	}
+
+	cachedPath := c.path(diffID)
+	if err := os.MkdirAll(filepath.Dir(cachedPath), 0750); err != nil {
+		return "", errors.Wrap(err, "creating layer cache dir")
+	}
+
+	src, err := os.Open(filepath.Clean(layerTarPath))
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(cachedPath), "layer-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	if _, err := io.Copy(tmpFile, src); err != nil {
+		return "", errors.Wrap(err, "caching layer")
+	}
+	if err := tmpFile.Close(); err != nil {
+		return "", err
+	}
+
+	return cachedPath, os.Rename(tmpFile.Name(), cachedPath)
+}
+
+// evict removes the least recently used layer tars until the cache is no bigger than its max size, along with
+// leftovers of layer tars that were never completely cached. Layer tars used within layerCacheMinAge are kept.
+func (c layerCache) evict() error {
+	if c.dir == "" {
+		return nil
+	}
+
+	type entry struct {
+		path string
+		info os.FileInfo
+	}
+
+	var (
+		entries []entry
+		size    int64
+	)
+	err := filepath.Walk(c.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		if !strings.HasSuffix(info.Name(), ".tar") {
+			if time.Since(info.ModTime()) > layerCacheMinAge {
+				return os.Remove(path)
+			}
+			return nil
+		}
+
+		entries = append(entries, entry{path: path, info: info})
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "reading layer cache")
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].info.ModTime().Before(entries[j].info.ModTime())
+	})
+
+	for _, e := range entries {
+		if size <= c.maxSize || time.Since(e.info.ModTime()) < layerCacheMinAge {
+			break
+		}
+		if err := os.Remove(e.path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "evicting layer from cache")
+		}
+		size -= e.info.Size()
+	}
+
+	return nil
+}
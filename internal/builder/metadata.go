@@ -1,6 +1,9 @@
 package builder
 
-import "github.com/buildpacks/pack/pkg/dist"
+import (
+	"github.com/buildpacks/pack/builder"
+	"github.com/buildpacks/pack/pkg/dist"
+)
 
 const (
 	OrderLabel = "io.buildpacks.buildpack.order"
@@ -12,6 +15,7 @@ type Metadata struct {
 	Stack       StackMetadata        `json:"stack"`
 	Lifecycle   LifecycleMetadata    `json:"lifecycle"`
 	CreatedBy   CreatorMetadata      `json:"createdBy"`
+	BuildEnv    []builder.BuildEnv   `json:"buildEnv,omitempty"`
 }
 
 type CreatorMetadata struct {
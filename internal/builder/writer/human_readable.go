@@ -59,7 +59,13 @@ Stack:
 {{ .Lifecycle }}
 {{ .RunImages }}
 {{ .Buildpacks }}
-{{ .Order }}`
+{{ .Order }}
+{{- if ne (len .Info.BuildEnv) 0 }}
+Build Environment:
+{{- range $index, $env := .Info.BuildEnv }}
+  {{ $env.Name }}={{ $env.Value }}{{ if $env.IsOverride }} (override){{ end }}
+{{- end }}
+{{ end }}`
 )
 
 type HumanReadable struct{}
@@ -137,9 +143,10 @@ func writeBuilderInfo(
 	warnings = append(warnings, buildpacksWarnings...)
 	warnings = append(warnings, lifecycleWarnings...)
 
+	out := logger.Writer()
 	outputTemplate, _ := template.New("").Parse(outputTemplate)
 	err = outputTemplate.Execute(
-		logger.Writer(),
+		out,
 		&struct {
 			Info       client.BuilderInfo
 			Verbose    bool
@@ -158,6 +165,9 @@ func writeBuilderInfo(
 			lifecycleString,
 		},
 	)
+	if flushErr := logging.FlushWriter(out); err == nil {
+		err = flushErr
+	}
 
 	for _, warning := range warnings {
 		logger.Warn(warning)
@@ -439,7 +449,7 @@ func writeDetectionOrderGroup(writer io.Writer, order pubbldr.DetectionOrder, pr
 			}
 
 			groupNumber++
-			_, err = fmt.Fprintf(writer, "Group #%d:\n", groupNumber)
+			_, err = fmt.Fprintf(writer, "Group #%d:%s\n", groupNumber, stringFromSelected(orderEntry.Selected))
 			if err != nil {
 				return fmt.Errorf("writing to detection order group writer: %w", err)
 			}
@@ -477,10 +487,11 @@ func writeAndUpdateEntryPrefix(writer io.Writer, last bool, prefix string) (stri
 func writeDetectionOrderBuildpack(writer io.Writer, entry pubbldr.DetectionOrderEntry) error {
 	_, err := fmt.Fprintf(
 		writer,
-		"%s\t%s%s\n",
+		"%s\t%s%s%s\n",
 		entry.FullName(),
 		stringFromOptional(entry.Optional),
 		stringFromCyclical(entry.Cyclical),
+		stringFromDetect(entry.Detect),
 	)
 
 	if err != nil {
@@ -505,3 +516,19 @@ func stringFromCyclical(cyclical bool) string {
 
 	return ""
 }
+
+func stringFromDetect(detect string) string {
+	if detect != "" {
+		return fmt.Sprintf("[%s]", detect)
+	}
+
+	return ""
+}
+
+func stringFromSelected(selected bool) string {
+	if selected {
+		return " (selected)"
+	}
+
+	return ""
+}
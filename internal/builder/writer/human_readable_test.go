@@ -493,5 +493,64 @@ REMOTE:
 				assert.Contains(outBuf.String(), "Users must build with explicitly specified buildpacks")
 			})
 		})
+
+		when("detection order is annotated with detect results", func() {
+			it("displays the outcome of each buildpack and the selected group", func() {
+				annotatedOrder := pubbldr.DetectionOrder{
+					{GroupDetectionOrder: pubbldr.DetectionOrder{
+						{BuildpackRef: dist.BuildpackRef{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.one", Version: "test.bp.one.version"}}, Detect: "fail"},
+					}},
+					{
+						GroupDetectionOrder: pubbldr.DetectionOrder{
+							{BuildpackRef: dist.BuildpackRef{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.two", Version: "test.bp.two.version"}, Optional: true}, Detect: "skip"},
+							{BuildpackRef: dist.BuildpackRef{BuildpackInfo: dist.BuildpackInfo{ID: "test.bp.three", Version: "test.bp.three.version"}}, Detect: "pass"},
+						},
+						Selected: true,
+					},
+				}
+				localInfo.Order = annotatedOrder
+				remoteInfo.Order = annotatedOrder
+
+				humanReadableWriter := writer.NewHumanReadable()
+
+				logger := logging.NewLogWithWriters(&outBuf, &outBuf)
+				err := humanReadableWriter.Print(logger, localRunImages, localInfo, remoteInfo, nil, nil, sharedBuilderInfo)
+				assert.Nil(err)
+
+				assert.Contains(outBuf.String(), `
+Detection Order:
+ ├ Group #1:
+ │  └ test.bp.one@test.bp.one.version    [fail]
+ └ Group #2: (selected)
+    ├ test.bp.two@test.bp.two.version        (optional)[skip]
+    └ test.bp.three@test.bp.three.version    [pass]
+`)
+			})
+		})
+
+		when("build env is set", func() {
+			it("displays the build env after the detection order", func() {
+				buildEnv := []pubbldr.BuildEnv{
+					{Name: "BP_MIRROR", Value: "https://mirror.example.com"},
+					{Name: "HTTPS_PROXY", Value: "http://proxy.example.com", Mode: pubbldr.BuildEnvModeOverride},
+				}
+				localInfo.BuildEnv = buildEnv
+				remoteInfo.BuildEnv = buildEnv
+
+				humanReadableWriter := writer.NewHumanReadable()
+
+				logger := logging.NewLogWithWriters(&outBuf, &outBuf)
+				err := humanReadableWriter.Print(logger, localRunImages, localInfo, remoteInfo, nil, nil, sharedBuilderInfo)
+				assert.Nil(err)
+
+				assert.Contains(outBuf.String(), `
+ └ test.bp.three@test.bp.three.version
+
+Build Environment:
+  BP_MIRROR=https://mirror.example.com
+  HTTPS_PROXY=http://proxy.example.com (override)
+`)
+			})
+		})
 	})
 }
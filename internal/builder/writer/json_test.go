@@ -459,6 +459,25 @@ func testJSON(t *testing.T, when spec.G, it spec.S) {
 				assert.ContainsJSON(prettifiedJSON, `{"detection_order": []}`)
 			})
 		})
+
+		when("build env is set", func() {
+			it("displays the build env", func() {
+				localInfo.BuildEnv = []pubbldr.BuildEnv{
+					{Name: "HTTPS_PROXY", Value: "http://proxy.example.com", Mode: pubbldr.BuildEnvModeOverride},
+				}
+
+				jsonWriter := writer.NewJSON()
+
+				logger := logging.NewLogWithWriters(&outBuf, &outBuf)
+				err := jsonWriter.Print(logger, localRunImages, localInfo, remoteInfo, nil, nil, sharedBuilderInfo)
+				assert.Nil(err)
+
+				prettifiedJSON, err := validPrettifiedJSONOutput(outBuf)
+				assert.Nil(err)
+
+				assert.ContainsJSON(prettifiedJSON, `{"build_env": [{"name": "HTTPS_PROXY", "value": "http://proxy.example.com", "mode": "override"}]}`)
+			})
+		})
 	})
 }
 
@@ -43,6 +43,7 @@ type BuilderInfo struct {
 	RunImages              []RunImage              `json:"run_images" yaml:"run_images" toml:"run_images"`
 	Buildpacks             []dist.BuildpackInfo    `json:"buildpacks" yaml:"buildpacks" toml:"buildpacks"`
 	pubbldr.DetectionOrder `json:"detection_order" yaml:"detection_order" toml:"detection_order"`
+	BuildEnv               []pubbldr.BuildEnv `json:"build_env,omitempty" yaml:"build_env,omitempty" toml:"build_env,omitempty"`
 }
 
 type StructuredFormat struct {
@@ -85,6 +86,7 @@ func (w *StructuredFormat) Print(
 			RunImages:      runImages(local.RunImage, localRunImages, local.RunImageMirrors),
 			Buildpacks:     local.Buildpacks,
 			DetectionOrder: local.Order,
+			BuildEnv:       local.BuildEnv,
 		}
 	}
 
@@ -107,6 +109,7 @@ func (w *StructuredFormat) Print(
 			RunImages:      runImages(remote.RunImage, localRunImages, remote.RunImageMirrors),
 			Buildpacks:     remote.Buildpacks,
 			DetectionOrder: remote.Order,
+			BuildEnv:       remote.BuildEnv,
 		}
 	}
 
@@ -12,6 +12,9 @@ import (
 	"github.com/buildpacks/pack/internal/paths"
 )
 
+// VolumePrefix is the prefix of the names of cache volumes
+const VolumePrefix = "pack-cache-"
+
 type VolumeCache struct {
 	docker client.CommonAPIClient
 	volume string
@@ -22,7 +25,7 @@ func NewVolumeCache(imageRef name.Reference, suffix string, dockerClient client.
 
 	vol := paths.FilterReservedNames(fmt.Sprintf("%s-%x", sanitizedRef(imageRef), sum[:6]))
 	return &VolumeCache{
-		volume: fmt.Sprintf("pack-cache-%s.%s", vol, suffix),
+		volume: fmt.Sprintf("%s%s.%s", VolumePrefix, vol, suffix),
 		docker: dockerClient,
 	}
 }
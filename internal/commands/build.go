@@ -1,11 +1,11 @@
 package commands
 
 import (
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/docker/go-units"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -37,6 +37,7 @@ type BuildFlags struct {
 	LifecycleImage     string
 	Env                []string
 	EnvFiles           []string
+	EnvPrefixes        []string
 	Buildpacks         []string
 	Volumes            []string
 	AdditionalTags     []string
@@ -44,6 +45,15 @@ type BuildFlags struct {
 	GID                int
 	PreviousImage      string
 	SBOMDestinationDir string
+	CPUs               float64
+	Memory             string
+	Ulimits            []string
+	AddHosts           []string
+	DNS                []string
+	Tmpfs              []string
+	SecurityOpts       []string
+	ReadOnlyApp        bool
+	Secrets            []string
 }
 
 // Build an image from source code
@@ -90,7 +100,7 @@ func Build(logger logging.Logger, cfg config.Config, packClient PackClient) *cob
 
 			buildpacks := flags.Buildpacks
 
-			env, err := parseEnv(flags.EnvFiles, flags.Env)
+			env, err := parseEnv(flags.EnvFiles, flags.Env, flags.EnvPrefixes)
 			if err != nil {
 				return err
 			}
@@ -128,6 +138,16 @@ func Build(logger logging.Logger, cfg config.Config, packClient PackClient) *cob
 			if cmd.Flags().Changed("gid") {
 				gid = flags.GID
 			}
+			var memory int64
+			if flags.Memory != "" {
+				if memory, err = units.RAMInBytes(flags.Memory); err != nil {
+					return errors.Wrapf(err, "parsing memory %s", flags.Memory)
+				}
+			}
+			secrets, err := parseSecrets(flags.Secrets)
+			if err != nil {
+				return err
+			}
 			if err := packClient.Build(cmd.Context(), client.BuildOptions{
 				AppPath:           flags.AppPath,
 				Builder:           builder,
@@ -135,7 +155,7 @@ func Build(logger logging.Logger, cfg config.Config, packClient PackClient) *cob
 				AdditionalMirrors: getMirrors(cfg),
 				AdditionalTags:    flags.AdditionalTags,
 				RunImage:          flags.RunImage,
-				Env:               env,
+				EnvVars:           env,
 				Image:             imageName,
 				Publish:           flags.Publish,
 				DockerHost:        flags.DockerHost,
@@ -146,8 +166,16 @@ func Build(logger logging.Logger, cfg config.Config, packClient PackClient) *cob
 				},
 				Buildpacks: buildpacks,
 				ContainerConfig: client.ContainerConfig{
-					Network: flags.Network,
-					Volumes: flags.Volumes,
+					Network:      flags.Network,
+					Volumes:      flags.Volumes,
+					CPUs:         flags.CPUs,
+					Memory:       memory,
+					Ulimits:      flags.Ulimits,
+					ExtraHosts:   flags.AddHosts,
+					DNS:          flags.DNS,
+					Tmpfs:        flags.Tmpfs,
+					SecurityOpts: flags.SecurityOpts,
+					ReadOnlyApp:  flags.ReadOnlyApp,
 				},
 				DefaultProcessType:       flags.DefaultProcessType,
 				ProjectDescriptorBaseDir: filepath.Dir(actualDescriptorPath),
@@ -159,6 +187,7 @@ func Build(logger logging.Logger, cfg config.Config, packClient PackClient) *cob
 				PreviousImage:            flags.PreviousImage,
 				Interactive:              flags.Interactive,
 				SBOMDestinationDir:       flags.SBOMDestinationDir,
+				Secrets:                  secrets,
 			}); err != nil {
 				return errors.Wrap(err, "failed to build")
 			}
@@ -173,30 +202,40 @@ func Build(logger logging.Logger, cfg config.Config, packClient PackClient) *cob
 
 func buildCommandFlags(cmd *cobra.Command, buildFlags *BuildFlags, cfg config.Config) {
 	cmd.Flags().StringVarP(&buildFlags.AppPath, "path", "p", "", "Path to app dir or zip-formatted file (defaults to current working directory)")
-	cmd.Flags().StringSliceVarP(&buildFlags.Buildpacks, "buildpack", "b", nil, "Buildpack to use. One of:\n  a buildpack by id and version in the form of '<buildpack>@<version>',\n  path to a buildpack directory (not supported on Windows),\n  path/URL to a buildpack .tar or .tgz file, or\n  a packaged buildpack image name in the form of '<hostname>/<repo>[:<tag>]'"+stringSliceHelp("buildpack"))
+	cmd.Flags().StringSliceVarP(&buildFlags.Buildpacks, "buildpack", "b", nil, "Buildpack to use. One of:\n  a buildpack by id and version in the form of '<buildpack>@<version>',\n  path to a buildpack directory (not supported on Windows),\n  path/URL to a buildpack .tar or .tgz file, including 's3://<bucket>/<key>' and 'oci://<hostname>/<repo>[:<tag>]' URLs, optionally pinned to its digest in the form of '<path/URL>#sha256:<hex>', or\n  a packaged buildpack image name in the form of '<hostname>/<repo>[:<tag>]'"+stringSliceHelp("buildpack"))
 	cmd.Flags().StringVarP(&buildFlags.Builder, "builder", "B", cfg.DefaultBuilder, "Builder image")
 	cmd.Flags().StringVar(&buildFlags.CacheImage, "cache-image", "", `Cache build layers in remote registry. Requires --publish`)
 	cmd.Flags().BoolVar(&buildFlags.ClearCache, "clear-cache", false, "Clear image's associated cache before building")
 	cmd.Flags().StringVarP(&buildFlags.DescriptorPath, "descriptor", "d", "", "Path to the project descriptor file")
 	cmd.Flags().StringVarP(&buildFlags.DefaultProcessType, "default-process", "D", "", `Set the default process type. (default "web")`)
-	cmd.Flags().StringArrayVarP(&buildFlags.Env, "env", "e", []string{}, "Build-time environment variable, in the form 'VAR=VALUE' or 'VAR'.\nWhen using latter value-less form, value will be taken from current\n  environment at the time this command is executed.\nThis flag may be specified multiple times and will override\n  individual values defined by --env-file."+stringArrayHelp("env")+"\nNOTE: These are NOT available at image runtime.")
-	cmd.Flags().StringArrayVar(&buildFlags.EnvFiles, "env-file", []string{}, "Build-time environment variables file\nOne variable per line, of the form 'VAR=VALUE' or 'VAR'\nWhen using latter value-less form, value will be taken from current\n  environment at the time this command is executed\nNOTE: These are NOT available at image runtime.\"")
+	cmd.Flags().StringArrayVarP(&buildFlags.Env, "env", "e", []string{}, "Build-time environment variable, in the form 'VAR=VALUE' or 'VAR'.\nWhen using latter value-less form, value will be taken from current\n  environment at the time this command is executed.\n"+envActionHelp+"\nThis flag may be specified multiple times and will override\n  individual values defined by --env-file."+stringArrayHelp("env")+"\nNOTE: These are NOT available at image runtime.")
+	cmd.Flags().StringArrayVar(&buildFlags.EnvFiles, "env-file", []string{}, "Build-time environment variables file in dotenv syntax\nOne variable per line, of the form 'VAR=VALUE' or 'VAR', values may be quoted and span lines\nWhen using latter value-less form, value will be taken from current\n  environment at the time this command is executed\nNOTE: These are NOT available at image runtime.")
+	cmd.Flags().StringArrayVar(&buildFlags.EnvPrefixes, "env-from-prefix", []string{}, "Pass the variables of the current environment whose names start with the prefix, e.g. 'BP_'.\nThey are overridden by --env-file and --env."+stringArrayHelp("env-from-prefix")+"\nNOTE: These are NOT available at image runtime.")
 	cmd.Flags().StringVar(&buildFlags.Network, "network", "", "Connect detect and build containers to network")
 	cmd.Flags().BoolVar(&buildFlags.Publish, "publish", false, "Publish to registry")
 	cmd.Flags().StringVar(&buildFlags.DockerHost, "docker-host", "",
 		`Address to docker daemon that will be exposed to the build container.
 If not set (or set to empty string) the standard socket location will be used.
-Special value 'inherit' may be used in which case DOCKER_HOST environment variable will be used.
+Special value 'inherit' may be used in which case the daemon pack uses will be used (DOCKER_HOST environment variable, or the host of the docker context).
 This option may set DOCKER_HOST environment variable for the build container if needed.
 `)
 	cmd.Flags().StringVar(&buildFlags.LifecycleImage, "lifecycle-image", cfg.LifecycleImage, `Custom lifecycle image to use for analysis, restore, and export when builder is untrusted.`)
-	cmd.Flags().StringVar(&buildFlags.Policy, "pull-policy", "", `Pull policy to use. Accepted values are always, never, and if-not-present. (default "always")`)
+	cmd.Flags().StringVar(&buildFlags.Policy, "pull-policy", "", `Pull policy to use. Accepted values are always, never, if-not-present, hourly, daily and weekly. (default "always")`)
 	cmd.Flags().StringVarP(&buildFlags.Registry, "buildpack-registry", "r", cfg.DefaultRegistryName, "Buildpack Registry by name")
 	cmd.Flags().StringVar(&buildFlags.RunImage, "run-image", "", "Run image (defaults to default stack's run image)")
 	cmd.Flags().StringSliceVarP(&buildFlags.AdditionalTags, "tag", "t", nil, "Additional tags to push the output image to.\nTags should be in the format 'image:tag' or 'repository/image:tag'."+stringSliceHelp("tag"))
 	cmd.Flags().BoolVar(&buildFlags.TrustBuilder, "trust-builder", false, "Trust the provided builder\nAll lifecycle phases will be run in a single container (if supported by the lifecycle).")
-	cmd.Flags().StringArrayVar(&buildFlags.Volumes, "volume", nil, "Mount host volume into the build container, in the form '<host path>:<target path>[:<options>]'.\n- 'host path': Name of the volume or absolute directory path to mount.\n- 'target path': The path where the file or directory is available in the container.\n- 'options' (default \"ro\"): An optional comma separated list of mount options.\n    - \"ro\", volume contents are read-only.\n    - \"rw\", volume contents are readable and writeable.\n    - \"volume-opt=<key>=<value>\", can be specified more than once, takes a key-value pair consisting of the option name and its value."+stringArrayHelp("volume"))
+	cmd.Flags().StringArrayVar(&buildFlags.Volumes, "volume", nil, "Mount host volume into the build container, in the form '<host path>:<target path>[:<options>]'.\n- 'host path': Name of the volume or absolute directory path to mount.\n- 'target path': The path where the file or directory is available in the container.\n- 'options' (default \"ro\"): An optional comma separated list of mount options.\n    - \"ro\", volume contents are read-only.\n    - \"rw\", volume contents are readable and writeable.\n    - \"volume-opt=<key>=<value>\", can be specified more than once, takes a key-value pair consisting of the option name and its value.\n    - \"z\" or \"Z\", relabel the host path for SELinux, shared by all containers or private to the build containers. Host paths aren't relabeled otherwise."+stringArrayHelp("volume"))
 	cmd.Flags().StringVar(&buildFlags.Workspace, "workspace", "", "Location at which to mount the app dir in the build image")
+	cmd.Flags().Float64Var(&buildFlags.CPUs, "cpus", 0, "Number of CPUs each build container can use, e.g. 1.5 (defaults to unlimited)")
+	cmd.Flags().StringVar(&buildFlags.Memory, "memory", "", "Memory limit of each build container, e.g. 512m or 4g (defaults to unlimited)")
+	cmd.Flags().StringArrayVar(&buildFlags.Ulimits, "ulimit", nil, "Ulimit of the build containers, in the form '<type>=<soft limit>[:<hard limit>]', e.g. 'nofile=1024:2048'."+stringArrayHelp("ulimit"))
+	cmd.Flags().StringArrayVar(&buildFlags.AddHosts, "add-host", nil, "Add a custom host-to-IP mapping to the build containers, in the form '<host>:<ip>'."+stringArrayHelp("add-host"))
+	cmd.Flags().StringArrayVar(&buildFlags.DNS, "dns", nil, "DNS server of the build containers."+stringArrayHelp("dns"))
+	cmd.Flags().StringArrayVar(&buildFlags.Tmpfs, "tmpfs", nil, "Mount a tmpfs directory in the build containers, in the form '<path>[:<options>]', e.g. '/tmp:size=64m'."+stringArrayHelp("tmpfs"))
+	cmd.Flags().StringArrayVar(&buildFlags.SecurityOpts, "security-opt", nil, "Security option of the build containers, e.g. 'seccomp=<profile file>', 'apparmor=<profile>' or 'label=<label>'."+stringArrayHelp("security-opt"))
+	cmd.Flags().BoolVar(&buildFlags.ReadOnlyApp, "read-only-app", false, "Mount the app dir read-only in the build containers.\nBuildpacks that write to the app dir (/workspace by default) will fail.")
+	cmd.Flags().StringArrayVar(&buildFlags.Secrets, "secret", nil, "Secret available to buildpacks during detect and build at '/platform/secrets/<id>', in the form 'id=<id>,src=<path>'.\nSecrets are not part of the cache or the app image, and their values are redacted from the build output.\nRequires a local docker daemon."+stringArrayHelp("secret"))
 	cmd.Flags().IntVar(&buildFlags.GID, "gid", 0, `Override GID of user's group in the stack's build and run images. The provided value must be a positive number`)
 	cmd.Flags().StringVar(&buildFlags.PreviousImage, "previous-image", "", "Set previous image to a particular tag reference, digest reference, or (when performing a daemon build) image ID")
 	cmd.Flags().StringVar(&buildFlags.SBOMDestinationDir, "sbom-output-dir", "", "Path to export SBoM contents.\nOmitting the flag will yield no SBoM content.")
@@ -226,49 +265,31 @@ func validateBuildFlags(flags *BuildFlags, cfg config.Config, packClient PackCli
 	return nil
 }
 
-func parseEnv(envFiles []string, envVars []string) (map[string]string, error) {
-	env := map[string]string{}
-
-	for _, envFile := range envFiles {
-		envFileVars, err := parseEnvFile(envFile)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to parse env file '%s'", envFile)
-		}
-
-		for k, v := range envFileVars {
-			env[k] = v
+// parseSecrets parses secrets in the form id=<id>,src=<path>, 'source' is accepted for 'src'
+func parseSecrets(values []string) ([]client.Secret, error) {
+	var secrets []client.Secret
+	for _, value := range values {
+		var secret client.Secret
+		for _, field := range strings.Split(value, ",") {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				return nil, errors.Errorf("parsing secret %s: fields must have the form <key>=<value>", style.Symbol(value))
+			}
+			switch strings.TrimSpace(kv[0]) {
+			case "id":
+				secret.ID = kv[1]
+			case "src", "source":
+				secret.Source = kv[1]
+			default:
+				return nil, errors.Errorf("parsing secret %s: unknown field %s", style.Symbol(value), style.Symbol(kv[0]))
+			}
 		}
-	}
-	for _, envVar := range envVars {
-		env = addEnvVar(env, envVar)
-	}
-	return env, nil
-}
-
-func parseEnvFile(filename string) (map[string]string, error) {
-	out := make(map[string]string)
-	f, err := ioutil.ReadFile(filepath.Clean(filename))
-	if err != nil {
-		return nil, errors.Wrapf(err, "open %s", filename)
-	}
-	for _, line := range strings.Split(string(f), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+		if secret.ID == "" || secret.Source == "" {
+			return nil, errors.Errorf("parsing secret %s: must have the form id=<id>,src=<path>", style.Symbol(value))
 		}
-		out = addEnvVar(out, line)
-	}
-	return out, nil
-}
-
-func addEnvVar(env map[string]string, item string) map[string]string {
-	arr := strings.SplitN(item, "=", 2)
-	if len(arr) > 1 {
-		env[arr[0]] = arr[1]
-	} else {
-		env[arr[0]] = os.Getenv(arr[0])
+		secrets = append(secrets, secret)
 	}
-	return env
+	return secrets, nil
 }
 
 func parseProjectToml(appPath, descriptorPath string) (projectTypes.Descriptor, string, error) {
@@ -138,6 +138,75 @@ func testBuildCommand(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("container limits and security options are given", func() {
+			it("forwards them onto the client", func() {
+				mockClient.EXPECT().
+					Build(gomock.Any(), EqBuildOptionsWithContainerConfig(client.ContainerConfig{
+						CPUs:         1.5,
+						Memory:       4 * 1024 * 1024 * 1024,
+						Ulimits:      []string{"nofile=1024:2048"},
+						ExtraHosts:   []string{"registry.local:10.0.0.1"},
+						DNS:          []string{"10.0.0.2"},
+						Tmpfs:        []string{"/tmp:size=64m"},
+						SecurityOpts: []string{"no-new-privileges", "seccomp=unconfined"},
+						ReadOnlyApp:  true,
+					})).
+					Return(nil)
+
+				command.SetArgs([]string{"image", "--builder", "my-builder",
+					"--cpus", "1.5",
+					"--memory", "4g",
+					"--ulimit", "nofile=1024:2048",
+					"--add-host", "registry.local:10.0.0.1",
+					"--dns", "10.0.0.2",
+					"--tmpfs", "/tmp:size=64m",
+					"--security-opt", "no-new-privileges",
+					"--security-opt", "seccomp=unconfined",
+					"--read-only-app",
+				})
+				h.AssertNil(t, command.Execute())
+			})
+
+			when("the memory is invalid", func() {
+				it("errors", func() {
+					command.SetArgs([]string{"image", "--builder", "my-builder", "--memory", "lots"})
+					err := command.Execute()
+					h.AssertError(t, err, "parsing memory lots")
+				})
+			})
+		})
+
+		when("--secret", func() {
+			it("forwards the secrets onto the client", func() {
+				mockClient.EXPECT().
+					Build(gomock.Any(), EqBuildOptionsWithSecrets([]client.Secret{
+						{ID: "npmrc", Source: "/some/.npmrc"},
+						{ID: "maven-settings", Source: "settings.xml"},
+					})).
+					Return(nil)
+
+				command.SetArgs([]string{"image", "--builder", "my-builder",
+					"--secret", "id=npmrc,src=/some/.npmrc",
+					"--secret", "id=maven-settings,source=settings.xml",
+				})
+				h.AssertNil(t, command.Execute())
+			})
+
+			when("the secret has no source", func() {
+				it("errors", func() {
+					command.SetArgs([]string{"image", "--builder", "my-builder", "--secret", "id=npmrc"})
+					h.AssertError(t, command.Execute(), "parsing secret 'id=npmrc': must have the form id=<id>,src=<path>")
+				})
+			})
+
+			when("the secret has an unknown field", func() {
+				it("errors", func() {
+					command.SetArgs([]string{"image", "--builder", "my-builder", "--secret", "id=npmrc,src=.npmrc,mode=0400"})
+					h.AssertError(t, command.Execute(), "unknown field 'mode'")
+				})
+			})
+		})
+
 		when("--pull-policy", func() {
 			it("sets pull-policy=never", func() {
 				mockClient.EXPECT().
@@ -325,6 +394,115 @@ func testBuildCommand(t *testing.T, when spec.G, it spec.S) {
 					h.AssertNil(t, command.Execute())
 				})
 			})
+
+			when("the env file uses dotenv syntax", func() {
+				var envPath string
+
+				it.Before(func() {
+					envfile, err := ioutil.TempFile("", "envfile")
+					h.AssertNil(t, err)
+					defer envfile.Close()
+
+					envfile.WriteString(`# build settings
+export BP_JVM_VERSION=17 # the LTS
+SINGLE='literal \n $HOME'
+DOUBLE="tab\tquote\" "
+MULTILINE="first
+second"
+JAVA_TOOL_OPTIONS.append=-Xmx1g
+JAVA_TOOL_OPTIONS.delim=" "
+`)
+					envPath = envfile.Name()
+				})
+
+				it.After(func() {
+					h.AssertNil(t, os.RemoveAll(envPath))
+				})
+
+				it("parses comments, quoting, multiline values and actions", func() {
+					source := "--env-file " + envPath
+					mockClient.EXPECT().
+						Build(gomock.Any(), EqBuildOptionsWithEnvVars([]client.EnvVar{
+							{Name: "BP_JVM_VERSION", Value: "17", Action: client.EnvOverride, Source: source},
+							{Name: "SINGLE", Value: `literal \n $HOME`, Action: client.EnvOverride, Source: source},
+							{Name: "DOUBLE", Value: "tab\tquote\" ", Action: client.EnvOverride, Source: source},
+							{Name: "MULTILINE", Value: "first\nsecond", Action: client.EnvOverride, Source: source},
+							{Name: "JAVA_TOOL_OPTIONS", Value: "-Xmx1g", Action: client.EnvAppend, Delim: " ", Source: source},
+						})).
+						Return(nil)
+
+					command.SetArgs([]string{"--builder", "my-builder", "image", "--env-file", envPath})
+					h.AssertNil(t, command.Execute())
+				})
+			})
+
+			when("a quoted value isn't closed", func() {
+				var envPath string
+
+				it.Before(func() {
+					envfile, err := ioutil.TempFile("", "envfile")
+					h.AssertNil(t, err)
+					defer envfile.Close()
+
+					envfile.WriteString("KEY1=VALUE1\nKEY2=\"VALUE2\n")
+					envPath = envfile.Name()
+				})
+
+				it.After(func() {
+					h.AssertNil(t, os.RemoveAll(envPath))
+				})
+
+				it("fails to run", func() {
+					command.SetArgs([]string{"--builder", "my-builder", "image", "--env-file", envPath})
+					h.AssertError(t, command.Execute(), "line 2: missing closing quote of the value of 'KEY2'")
+				})
+			})
+		})
+
+		when("env vars have actions", func() {
+			it("forwards the actions and delimiters onto the client", func() {
+				mockClient.EXPECT().
+					Build(gomock.Any(), EqBuildOptionsWithEnvVars([]client.EnvVar{
+						{Name: "PATH", Value: "/opt/bin", Action: client.EnvPrepend, Delim: ":", Source: "--env"},
+						{Name: "BP_JVM_VERSION", Value: "17", Action: client.EnvDefault, Source: "--env"},
+					})).
+					Return(nil)
+
+				command.SetArgs([]string{"--builder", "my-builder", "image",
+					"--env", "PATH.prepend=/opt/bin",
+					"--env", "PATH.delim=:",
+					"--env", "BP_JVM_VERSION.default=17",
+				})
+				h.AssertNil(t, command.Execute())
+			})
+		})
+
+		when("--env-from-prefix", func() {
+			it.Before(func() {
+				h.AssertNil(t, os.Setenv("PACK_TEST_PREFIX_B", "b-value"))
+				h.AssertNil(t, os.Setenv("PACK_TEST_PREFIX_A", "a-value"))
+			})
+
+			it.After(func() {
+				h.AssertNil(t, os.Unsetenv("PACK_TEST_PREFIX_B"))
+				h.AssertNil(t, os.Unsetenv("PACK_TEST_PREFIX_A"))
+			})
+
+			it("passes the host variables with the prefix, overridden by --env", func() {
+				mockClient.EXPECT().
+					Build(gomock.Any(), EqBuildOptionsWithEnvVars([]client.EnvVar{
+						{Name: "PACK_TEST_PREFIX_A", Value: "a-value", Action: client.EnvOverride, Source: "--env-from-prefix PACK_TEST_PREFIX_"},
+						{Name: "PACK_TEST_PREFIX_B", Value: "b-value", Action: client.EnvOverride, Source: "--env-from-prefix PACK_TEST_PREFIX_"},
+						{Name: "PACK_TEST_PREFIX_A", Value: "flag-value", Action: client.EnvOverride, Source: "--env"},
+					})).
+					Return(nil)
+
+				command.SetArgs([]string{"--builder", "my-builder", "image",
+					"--env-from-prefix", "PACK_TEST_PREFIX_",
+					"--env", "PACK_TEST_PREFIX_A=flag-value",
+				})
+				h.AssertNil(t, command.Execute())
+			})
 		})
 
 		when("a cache-image passed", func() {
@@ -840,6 +1018,24 @@ func EqBuildOptionsWithNetwork(network string) gomock.Matcher {
 	}
 }
 
+func EqBuildOptionsWithContainerConfig(config client.ContainerConfig) gomock.Matcher {
+	return buildOptionsMatcher{
+		description: fmt.Sprintf("ContainerConfig=%+v", config),
+		equals: func(o client.BuildOptions) bool {
+			return reflect.DeepEqual(o.ContainerConfig, config)
+		},
+	}
+}
+
+func EqBuildOptionsWithSecrets(secrets []client.Secret) gomock.Matcher {
+	return buildOptionsMatcher{
+		description: fmt.Sprintf("Secrets=%+v", secrets),
+		equals: func(o client.BuildOptions) bool {
+			return reflect.DeepEqual(o.Secrets, secrets)
+		},
+	}
+}
+
 func EqBuildOptionsWithBuilder(builder string) gomock.Matcher {
 	return buildOptionsMatcher{
 		description: fmt.Sprintf("Builder=%s", builder),
@@ -878,7 +1074,7 @@ func EqBuildOptionsWithAdditionalTags(additionalTags []string) gomock.Matcher {
 
 func EqBuildOptionsWithProjectDescriptor(descriptor projectTypes.Descriptor) gomock.Matcher {
 	return buildOptionsMatcher{
-		description: fmt.Sprintf("Descriptor=%s", descriptor),
+		description: fmt.Sprintf("Descriptor=%v", descriptor),
 		equals: func(o client.BuildOptions) bool {
 			return reflect.DeepEqual(o.ProjectDescriptor, descriptor)
 		},
@@ -889,17 +1085,24 @@ func EqBuildOptionsWithEnv(env map[string]string) gomock.Matcher {
 	return buildOptionsMatcher{
 		description: fmt.Sprintf("Env=%+v", env),
 		equals: func(o client.BuildOptions) bool {
+			// the variables are given by flags, which override each other
+			actual := map[string]string{}
 			for k, v := range o.Env {
-				if env[k] != v {
-					return false
-				}
+				actual[k] = v
 			}
-			for k, v := range env {
-				if o.Env[k] != v {
-					return false
-				}
+			for _, envVar := range o.EnvVars {
+				actual[envVar.Name] = envVar.Value
 			}
-			return true
+			return reflect.DeepEqual(actual, env)
+		},
+	}
+}
+
+func EqBuildOptionsWithEnvVars(envVars []client.EnvVar) gomock.Matcher {
+	return buildOptionsMatcher{
+		description: fmt.Sprintf("EnvVars=%+v", envVars),
+		equals: func(o client.BuildOptions) bool {
+			return reflect.DeepEqual(o.EnvVars, envVars)
 		},
 	}
 }
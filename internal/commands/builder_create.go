@@ -17,10 +17,13 @@ import (
 
 // BuilderCreateFlags define flags provided to the CreateBuilder command
 type BuilderCreateFlags struct {
-	BuilderTomlPath string
-	Publish         bool
-	Registry        string
-	Policy          string
+	BuilderTomlPath  string
+	Publish          bool
+	Registry         string
+	Policy           string
+	BaseBuilder      string
+	RunImage         string
+	CompressionLevel int
 }
 
 // CreateBuilder creates a builder image, based on a builder config
@@ -37,6 +40,18 @@ func BuilderCreate(logger logging.Logger, cfg config.Config, pack PackClient) *c
 	pack builders suggest
 
 Creating a custom builder allows you to control what buildpacks are used and what image apps are based on. For more on how to create a builder, see: https://buildpacks.io/docs/operator-guide/create-a-builder/.
+
+A builder can also be created on top of an existing builder, by setting base-builder in the builder config or using --base-builder. The new builder inherits the lifecycle, stack, buildpacks and order of the base builder, and the builder config only needs to list what changes: buildpacks to add or upgrade, buildpacks to remove with remove-buildpacks, a new order or a new run image.
+
+Environment variables can be provided to every build with [[build.env]] entries in the builder config. A variable with mode "default" (the default) can be replaced with --env when building, while a variable with mode "override" is always used.
+
+The layers of a published builder are compressed with gzip, at the level set with [compression] in the builder config or --compression-level.
+
+The lifecycle is set with one of version, uri or image under [lifecycle] in the builder config. Lifecycle releases downloaded by version are verified against their published checksums and cached in PACK_HOME, and can be downloaded from a mirror set with 'pack config lifecycle-mirror'. An image, such as buildpacksio/lifecycle, can be referenced by digest to pin the lifecycle that is used.
+
+Buildpacks and lifecycles downloaded from a file or URL can be pinned with a 'digest' of the form 'sha256:<hex>', and are verified against it before they are used. Verified downloads are cached in PACK_HOME by digest, so they can be used again without downloading them.
+
+The layers of buildpacks are cached in PACK_HOME by content, and buildpacks that are already on the base builder with the same contents are not added again, so that creating a builder again only writes the buildpacks that changed. The least recently used layers are removed from the cache once it grows over 2 GB.
 `,
 		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
 			if err := validateCreateFlags(&flags, cfg); err != nil {
@@ -60,6 +75,19 @@ Creating a custom builder allows you to control what buildpacks are used and wha
 				logger.Warnf("builder configuration: %s", w)
 			}
 
+			if flags.BaseBuilder != "" {
+				builderConfig.BaseBuilder = flags.BaseBuilder
+			}
+
+			if flags.RunImage != "" {
+				builderConfig.Stack.RunImage = flags.RunImage
+				builderConfig.Stack.RunImageMirrors = nil
+			}
+
+			if cmd.Flags().Changed("compression-level") {
+				builderConfig.Compression.Level = flags.CompressionLevel
+			}
+
 			relativeBaseDir, err := filepath.Abs(filepath.Dir(flags.BuilderTomlPath))
 			if err != nil {
 				return errors.Wrap(err, "getting absolute path for config")
@@ -88,7 +116,10 @@ Creating a custom builder allows you to control what buildpacks are used and wha
 	}
 	cmd.Flags().StringVarP(&flags.BuilderTomlPath, "config", "c", "", "Path to builder TOML file (required)")
 	cmd.Flags().BoolVar(&flags.Publish, "publish", false, "Publish to registry")
-	cmd.Flags().StringVar(&flags.Policy, "pull-policy", "", "Pull policy to use. Accepted values are always, never, and if-not-present. The default is always")
+	cmd.Flags().StringVar(&flags.BaseBuilder, "base-builder", "", "Builder image to create the builder from, instead of the base-builder of the builder config")
+	cmd.Flags().StringVar(&flags.RunImage, "run-image", "", "Run image to use instead of the stack run-image of the builder config or the base builder")
+	cmd.Flags().StringVar(&flags.Policy, "pull-policy", "", "Pull policy to use. Accepted values are always, never, if-not-present, hourly, daily and weekly. The default is always")
+	cmd.Flags().IntVar(&flags.CompressionLevel, "compression-level", 0, "Gzip compression level (1-9) of the layers of a published builder, instead of the level of the builder config")
 
 	AddHelpFlag(cmd, "create")
 	return cmd
@@ -2,6 +2,7 @@ package commands_test
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"path/filepath"
 	"testing"
@@ -15,6 +16,7 @@ import (
 	"github.com/buildpacks/pack/internal/commands"
 	"github.com/buildpacks/pack/internal/commands/testmocks"
 	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/pkg/client"
 	"github.com/buildpacks/pack/pkg/logging"
 	h "github.com/buildpacks/pack/testhelpers"
 )
@@ -161,5 +163,36 @@ func testCreateCommand(t *testing.T, when spec.G, it spec.S) {
 				h.AssertError(t, command.Execute(), "Please provide a builder config path")
 			})
 		})
+
+		when("--base-builder and --run-image are specified", func() {
+			it.Before(func() {
+				h.AssertNil(t, ioutil.WriteFile(builderConfigPath, []byte(`
+base-builder = "some/base-builder"
+
+[stack]
+  run-image = "some/run-image"
+  run-image-mirrors = ["some/run-image-mirror"]
+`), 0666))
+			})
+
+			it("overrides the builder config", func() {
+				mockClient.EXPECT().
+					CreateBuilder(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, opts client.CreateBuilderOptions) error {
+						h.AssertEq(t, opts.Config.BaseBuilder, "other/base-builder")
+						h.AssertEq(t, opts.Config.Stack.RunImage, "other/run-image")
+						h.AssertEq(t, len(opts.Config.Stack.RunImageMirrors), 0)
+						return nil
+					})
+
+				command.SetArgs([]string{
+					"some/builder",
+					"--config", builderConfigPath,
+					"--base-builder", "other/base-builder",
+					"--run-image", "other/run-image",
+				})
+				h.AssertNil(t, command.Execute())
+			})
+		})
 	})
 }
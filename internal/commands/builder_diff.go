@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/logging"
+)
+
+type BuilderDiffFlags struct {
+	OutputFormat string
+}
+
+// BuilderDiff compares two builders
+func BuilderDiff(logger logging.Logger, packClient PackClient) *cobra.Command {
+	var flags BuilderDiffFlags
+	cmd := &cobra.Command{
+		Use:     "diff <from-builder-image-name> <to-builder-image-name>",
+		Args:    cobra.ExactArgs(2),
+		Short:   "Compare two builders",
+		Example: "pack builder diff cnbs/sample-builder:bionic cnbs/sample-builder:jammy",
+		Long: "Show what changed from the first builder to the second: buildpacks that were added, removed, upgraded or " +
+			"downgraded, changes to the groups of the order, the lifecycle version and the Buildpack and Platform APIs it " +
+			"supports, the stack and its mixins, and the run image and its mirrors.\n\n" +
+			"Each builder is read from the daemon if present, and from its registry otherwise.",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			if flags.OutputFormat != "human-readable" && flags.OutputFormat != "json" {
+				return errors.Errorf("output format %s is not supported", style.Symbol(flags.OutputFormat))
+			}
+
+			diff, err := packClient.DiffBuilders(args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			if flags.OutputFormat == "json" {
+				out, err := json.MarshalIndent(diff, "", "  ")
+				if err != nil {
+					return errors.Wrap(err, "writing diff as json")
+				}
+				logger.Info(string(out))
+				return nil
+			}
+
+			logBuilderDiff(logger, diff)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&flags.OutputFormat, "output", "o", "human-readable", "Output format to display the differences (json, human-readable).\nOmission of this flag will display as human-readable.")
+	AddHelpFlag(cmd, "diff")
+	return cmd
+}
+
+func logBuilderDiff(logger logging.Logger, diff *client.BuilderDiff) {
+	logger.Infof("Comparing builder %s to %s", style.Symbol(diff.From), style.Symbol(diff.To))
+	logger.Info("")
+
+	if diff.IsEmpty() {
+		logger.Info("No differences")
+		return
+	}
+
+	if !diff.Buildpacks.IsEmpty() {
+		logger.Info("Buildpacks:")
+		for _, bp := range diff.Buildpacks.Added {
+			logger.Infof("  + %s", bp.FullName())
+		}
+		for _, bp := range diff.Buildpacks.Removed {
+			logger.Infof("  - %s", bp.FullName())
+		}
+		for _, change := range diff.Buildpacks.Upgraded {
+			logger.Infof("  ~ %s %s -> %s (upgraded)", change.ID, change.From, change.To)
+		}
+		for _, change := range diff.Buildpacks.Downgraded {
+			logger.Infof("  ~ %s %s -> %s (downgraded)", change.ID, change.From, change.To)
+		}
+		logger.Info("")
+	}
+
+	if len(diff.Order) > 0 {
+		logger.Info("Detection Order:")
+		for _, group := range diff.Order {
+			header := fmt.Sprintf("  Group #%d:", group.Group)
+			if group.Reordered {
+				header += " (reordered)"
+			}
+			logger.Info(header)
+			for _, ref := range group.Added {
+				logger.Infof("    + %s%s", ref.FullName(), stringFromOptional(ref.Optional))
+			}
+			for _, ref := range group.Removed {
+				logger.Infof("    - %s%s", ref.FullName(), stringFromOptional(ref.Optional))
+			}
+		}
+		logger.Info("")
+	}
+
+	if !diff.Lifecycle.IsEmpty() {
+		logger.Info("Lifecycle:")
+		if diff.Lifecycle.Version != nil {
+			logger.Infof("  Version: %s", valueChangeString(diff.Lifecycle.Version))
+		}
+		logAPIVersionsDiff(logger, "Buildpack APIs", diff.Lifecycle.BuildpackAPIs)
+		logAPIVersionsDiff(logger, "Platform APIs", diff.Lifecycle.PlatformAPIs)
+		logger.Info("")
+	}
+
+	if diff.Stack != nil || !diff.Mixins.IsEmpty() {
+		logger.Info("Stack:")
+		if diff.Stack != nil {
+			logger.Infof("  ID: %s", valueChangeString(diff.Stack))
+		}
+		logListDiff(logger, "  ", "Mixins", diff.Mixins)
+		logger.Info("")
+	}
+
+	if diff.RunImage != nil || !diff.RunImageMirrors.IsEmpty() {
+		logger.Info("Run Image:")
+		if diff.RunImage != nil {
+			logger.Infof("  Image: %s", valueChangeString(diff.RunImage))
+		}
+		logListDiff(logger, "  ", "Mirrors", diff.RunImageMirrors)
+		logger.Info("")
+	}
+}
+
+func logAPIVersionsDiff(logger logging.Logger, title string, diff client.APIVersionsDiff) {
+	if diff.IsEmpty() {
+		return
+	}
+
+	logger.Infof("  %s:", title)
+	logListDiff(logger, "    ", "Deprecated", diff.Deprecated)
+	logListDiff(logger, "    ", "Supported", diff.Supported)
+}
+
+func logListDiff(logger logging.Logger, indent, title string, diff client.ListDiff) {
+	if diff.IsEmpty() {
+		return
+	}
+
+	logger.Infof("%s%s:", indent, title)
+	for _, value := range diff.Added {
+		logger.Infof("%s  + %s", indent, value)
+	}
+	for _, value := range diff.Removed {
+		logger.Infof("%s  - %s", indent, value)
+	}
+}
+
+func valueChangeString(change *client.ValueChange) string {
+	from, to := change.From, change.To
+	if from == "" {
+		from = "(none)"
+	}
+	if to == "" {
+		to = "(none)"
+	}
+	return fmt.Sprintf("%s -> %s", from, to)
+}
+
+func stringFromOptional(optional bool) string {
+	if optional {
+		return " (optional)"
+	}
+	return ""
+}
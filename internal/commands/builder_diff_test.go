@@ -0,0 +1,153 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/pkg/errors"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/commands"
+	"github.com/buildpacks/pack/internal/commands/testmocks"
+	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/dist"
+	"github.com/buildpacks/pack/pkg/logging"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestBuilderDiffCommand(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "BuilderDiffCommand", testBuilderDiffCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBuilderDiffCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		logger         logging.Logger
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockClient     *testmocks.MockPackClient
+		diff           *client.BuilderDiff
+	)
+
+	it.Before(func() {
+		logger = logging.NewLogWithWriters(&outBuf, &outBuf)
+		mockController = gomock.NewController(t)
+		mockClient = testmocks.NewMockPackClient(mockController)
+		command = commands.BuilderDiff(logger, mockClient)
+
+		diff = &client.BuilderDiff{
+			From: "some/builder:1",
+			To:   "some/builder:2",
+			Buildpacks: client.BuildpacksDiff{
+				Added:    []dist.BuildpackInfo{{ID: "added/bp", Version: "3.0.0"}},
+				Removed:  []dist.BuildpackInfo{{ID: "removed/bp", Version: "0.1.0"}},
+				Upgraded: []client.VersionChange{{ID: "some/bp", From: "1.0.0", To: "1.1.0"}},
+			},
+			Order: []client.OrderGroupDiff{{
+				Group:     1,
+				Added:     []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "added/bp", Version: "3.0.0"}, Optional: true}},
+				Reordered: true,
+			}},
+			Lifecycle: client.LifecycleDiff{
+				Version:       &client.ValueChange{From: "0.13.0", To: "0.13.5"},
+				BuildpackAPIs: client.APIVersionsDiff{Supported: client.ListDiff{Added: []string{"0.8"}, Removed: []string{"0.2"}}},
+			},
+			Mixins:   client.ListDiff{Added: []string{"mixinC"}},
+			RunImage: &client.ValueChange{From: "some/run:1", To: "some/run:2"},
+		}
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#BuilderDiff", func() {
+		it("prints the differences in a human readable format", func() {
+			mockClient.EXPECT().DiffBuilders("some/builder:1", "some/builder:2").Return(diff, nil)
+
+			command.SetArgs([]string{"some/builder:1", "some/builder:2"})
+			h.AssertNil(t, command.Execute())
+
+			h.AssertEq(t, outBuf.String(), `Comparing builder 'some/builder:1' to 'some/builder:2'
+
+Buildpacks:
+  + added/bp@3.0.0
+  - removed/bp@0.1.0
+  ~ some/bp 1.0.0 -> 1.1.0 (upgraded)
+
+Detection Order:
+  Group #1: (reordered)
+    + added/bp@3.0.0 (optional)
+
+Lifecycle:
+  Version: 0.13.0 -> 0.13.5
+  Buildpack APIs:
+    Supported:
+      + 0.8
+      - 0.2
+
+Stack:
+  Mixins:
+    + mixinC
+
+Run Image:
+  Image: some/run:1 -> some/run:2
+
+`)
+		})
+
+		when("the builders are the same", func() {
+			it("prints that there are no differences", func() {
+				mockClient.EXPECT().DiffBuilders("some/builder:1", "some/builder:1").Return(&client.BuilderDiff{From: "some/builder:1", To: "some/builder:1"}, nil)
+
+				command.SetArgs([]string{"some/builder:1", "some/builder:1"})
+				h.AssertNil(t, command.Execute())
+
+				h.AssertContains(t, outBuf.String(), "No differences")
+			})
+		})
+
+		when("output is json", func() {
+			it("prints the differences as json", func() {
+				mockClient.EXPECT().DiffBuilders("some/builder:1", "some/builder:2").Return(diff, nil)
+
+				command.SetArgs([]string{"some/builder:1", "some/builder:2", "--output", "json"})
+				h.AssertNil(t, command.Execute())
+
+				h.NewAssertionManager(t).ContainsJSON(outBuf.String(), `{
+  "from": "some/builder:1",
+  "to": "some/builder:2",
+  "buildpacks": {
+    "added": [{"id": "added/bp", "version": "3.0.0"}],
+    "removed": [{"id": "removed/bp", "version": "0.1.0"}],
+    "upgraded": [{"id": "some/bp", "from": "1.0.0", "to": "1.1.0"}]
+  },
+  "order": [{"group": 1, "added": [{"id": "added/bp", "version": "3.0.0", "optional": true}], "reordered": true}],
+  "run_image": {"from": "some/run:1", "to": "some/run:2"}
+}`)
+			})
+		})
+
+		when("output format is not supported", func() {
+			it("returns an error", func() {
+				command.SetArgs([]string{"some/builder:1", "some/builder:2", "--output", "yaml"})
+				h.AssertError(t, command.Execute(), "output format 'yaml' is not supported")
+			})
+		})
+
+		when("a builder can't be inspected", func() {
+			it("returns the error", func() {
+				mockClient.EXPECT().DiffBuilders("some/builder:1", "some/builder:2").Return(nil, errors.New("unable to find builder 'some/builder:2' locally or remotely"))
+
+				command.SetArgs([]string{"some/builder:1", "some/builder:2"})
+				h.AssertError(t, command.Execute(), "unable to find builder 'some/builder:2' locally or remotely")
+			})
+		})
+	})
+}
@@ -1,22 +1,30 @@
 package commands
 
 import (
+	"context"
+
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/buildpacks/pack/builder"
 	"github.com/buildpacks/pack/internal/builder/writer"
 	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/style"
 	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/image"
 	"github.com/buildpacks/pack/pkg/logging"
 )
 
 type BuilderInspector interface {
 	InspectBuilder(name string, daemon bool, modifiers ...client.BuilderInspectionModifier) (*client.BuilderInfo, error)
+	Detect(ctx context.Context, opts client.DetectOptions) (*client.DetectResult, error)
 }
 
 type BuilderInspectFlags struct {
 	Depth        int
 	OutputFormat string
+	App          string
+	Policy       string
 }
 
 func BuilderInspect(logger logging.Logger,
@@ -31,7 +39,9 @@ func BuilderInspect(logger logging.Logger,
 		Aliases: []string{"inspect-builder"},
 		Short:   "Show information about a builder",
 		Example: "pack builder inspect cnbs/sample-builder:bionic",
-		Long:    "Show information about the builder provided. If no argument is provided, it will inspect the default builder, if one has been set.",
+		Long: "Show information about the builder provided. If no argument is provided, it will inspect the default builder, if one has been set.\n\n" +
+			"When an app is provided with `--app`, the detector of the builder is run against it and the detection order is " +
+			"annotated with the outcome of each buildpack, along with the group that would build the app.",
 		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
 			imageName := cfg.DefaultBuilder
 			if len(args) >= 1 {
@@ -43,17 +53,20 @@ func BuilderInspect(logger logging.Logger,
 				return client.NewSoftError()
 			}
 
-			return inspectBuilder(logger, imageName, flags, cfg, inspector, writerFactory)
+			return inspectBuilder(cmd.Context(), logger, imageName, flags, cfg, inspector, writerFactory)
 		}),
 	}
 
 	cmd.Flags().IntVarP(&flags.Depth, "depth", "d", builder.OrderDetectionMaxDepth, "Max depth to display for Detection Order.\nOmission of this flag or values < 0 will display the entire tree.")
 	cmd.Flags().StringVarP(&flags.OutputFormat, "output", "o", "human-readable", "Output format to display builder detail (json, yaml, toml, human-readable).\nOmission of this flag will display as human-readable.")
+	cmd.Flags().StringVar(&flags.App, "app", "", "Path to an app dir or zip-formatted file to run detection against.\nThe detection order will show the outcome of each buildpack for the app.")
+	cmd.Flags().StringVar(&flags.Policy, "pull-policy", "", `Pull policy to use when detecting an app. Accepted values are always, never, if-not-present, hourly, daily and weekly. (default "always")`)
 	AddHelpFlag(cmd, "inspect")
 	return cmd
 }
 
 func inspectBuilder(
+	ctx context.Context,
 	logger logging.Logger,
 	imageName string,
 	flags BuilderInspectFlags,
@@ -67,8 +80,33 @@ func inspectBuilder(
 		Trusted:   isTrustedBuilder(cfg, imageName),
 	}
 
-	localInfo, localErr := inspector.InspectBuilder(imageName, true, client.WithDetectionOrderDepth(flags.Depth))
-	remoteInfo, remoteErr := inspector.InspectBuilder(imageName, false, client.WithDetectionOrderDepth(flags.Depth))
+	modifiers := []client.BuilderInspectionModifier{client.WithDetectionOrderDepth(flags.Depth)}
+	if flags.App != "" {
+		stringPolicy := flags.Policy
+		if stringPolicy == "" {
+			stringPolicy = cfg.PullPolicy
+		}
+		pullPolicy, err := image.ParsePullPolicy(stringPolicy)
+		if err != nil {
+			return errors.Wrapf(err, "parsing pull policy %s", flags.Policy)
+		}
+
+		result, err := inspector.Detect(ctx, client.DetectOptions{
+			Builder:    imageName,
+			AppPath:    flags.App,
+			PullPolicy: pullPolicy,
+		})
+		if result == nil {
+			return errors.Wrapf(err, "detecting app %s", style.Symbol(flags.App))
+		}
+		if err != nil {
+			logger.Warnf("No group of %s passed detection for app %s", style.Symbol(imageName), style.Symbol(flags.App))
+		}
+		modifiers = append(modifiers, client.WithDetectResult(result))
+	}
+
+	localInfo, localErr := inspector.InspectBuilder(imageName, true, modifiers...)
+	remoteInfo, remoteErr := inspector.InspectBuilder(imageName, false, modifiers...)
 
 	writer, err := writerFactory.Writer(flags.OutputFormat)
 	if err != nil {
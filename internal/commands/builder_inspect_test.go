@@ -11,12 +11,14 @@ import (
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 
+	"github.com/buildpacks/pack/internal/build"
 	"github.com/buildpacks/pack/internal/builder"
 	"github.com/buildpacks/pack/internal/builder/writer"
 	"github.com/buildpacks/pack/internal/commands"
 	"github.com/buildpacks/pack/internal/commands/fakes"
 	"github.com/buildpacks/pack/internal/config"
 	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/image"
 	"github.com/buildpacks/pack/pkg/logging"
 	h "github.com/buildpacks/pack/testhelpers"
 )
@@ -131,6 +133,96 @@ func testBuilderInspectCommand(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("app flag is provided", func() {
+			it("detects the app and passes the result to the builder inspector", func() {
+				detectResult := &client.DetectResult{
+					Trials: []build.DetectTrial{{Buildpacks: []build.DetectedBuildpack{{ID: "some/buildpack", Version: "1.2.3", Status: "pass"}}}},
+				}
+				builderInspector := newDefaultBuilderInspector()
+				builderInspector.DetectResult = detectResult
+				command := commands.BuilderInspect(logger, cfg, builderInspector, newDefaultWriterFactory())
+				command.SetArgs([]string{"--app", "some/app"})
+
+				err := command.Execute()
+				assert.Nil(err)
+
+				assert.Equal(builderInspector.ReceivedDetectOptions.Builder, "default/builder")
+				assert.Equal(builderInspector.ReceivedDetectOptions.AppPath, "some/app")
+				assert.Equal(builderInspector.ReceivedDetectOptions.PullPolicy, image.PullAlways)
+				assert.Equal(builderInspector.CalculatedConfigForLocal.DetectResult, detectResult)
+				assert.Equal(builderInspector.CalculatedConfigForRemote.DetectResult, detectResult)
+			})
+
+			when("pull policy is provided", func() {
+				it("detects with that pull policy", func() {
+					builderInspector := newDefaultBuilderInspector()
+					builderInspector.DetectResult = &client.DetectResult{}
+					command := commands.BuilderInspect(logger, cfg, builderInspector, newDefaultWriterFactory())
+					command.SetArgs([]string{"--app", "some/app", "--pull-policy", "never"})
+
+					err := command.Execute()
+					assert.Nil(err)
+
+					assert.Equal(builderInspector.ReceivedDetectOptions.PullPolicy, image.PullNever)
+				})
+
+				when("it is invalid", func() {
+					it("returns an error", func() {
+						builderInspector := newDefaultBuilderInspector()
+						command := commands.BuilderInspect(logger, cfg, builderInspector, newDefaultWriterFactory())
+						command.SetArgs([]string{"--app", "some/app", "--pull-policy", "unknown-policy"})
+
+						err := command.Execute()
+						assert.ErrorWithMessage(err, "parsing pull policy unknown-policy: invalid pull policy unknown-policy")
+					})
+				})
+			})
+
+			when("pull policy is set in the config", func() {
+				it("detects with the configured pull policy", func() {
+					cfg.PullPolicy = "if-not-present"
+					builderInspector := newDefaultBuilderInspector()
+					builderInspector.DetectResult = &client.DetectResult{}
+					command := commands.BuilderInspect(logger, cfg, builderInspector, newDefaultWriterFactory())
+					command.SetArgs([]string{"--app", "some/app"})
+
+					err := command.Execute()
+					assert.Nil(err)
+
+					assert.Equal(builderInspector.ReceivedDetectOptions.PullPolicy, image.PullIfNotPresent)
+				})
+			})
+
+			when("no group passes detection", func() {
+				it("warns and still passes the result to the builder inspector", func() {
+					detectResult := &client.DetectResult{}
+					builderInspector := newDefaultBuilderInspector()
+					builderInspector.DetectResult = detectResult
+					builderInspector.DetectErr = errors.New("executing detector: failed with status code: 20")
+					command := commands.BuilderInspect(logger, cfg, builderInspector, newDefaultWriterFactory())
+					command.SetArgs([]string{"--app", "some/app"})
+
+					err := command.Execute()
+					assert.Nil(err)
+
+					assert.Contains(outBuf.String(), "Warning: No group of 'default/builder' passed detection for app 'some/app'")
+					assert.Equal(builderInspector.CalculatedConfigForLocal.DetectResult, detectResult)
+				})
+			})
+
+			when("the detector can't be run", func() {
+				it("returns the error", func() {
+					builderInspector := newDefaultBuilderInspector()
+					builderInspector.DetectErr = errors.New("invalid app path")
+					command := commands.BuilderInspect(logger, cfg, builderInspector, newDefaultWriterFactory())
+					command.SetArgs([]string{"--app", "some/app"})
+
+					err := command.Execute()
+					assert.ErrorWithMessage(err, "detecting app 'some/app': invalid app path")
+				})
+			})
+		})
+
 		when("output type is set to json", func() {
 			it("passes json to the writer factory", func() {
 				writerFactory := newDefaultWriterFactory()
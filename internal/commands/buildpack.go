@@ -19,6 +19,7 @@ func NewBuildpackCommand(logger logging.Logger, cfg config.Config, client PackCl
 	cmd.AddCommand(BuildpackPackage(logger, cfg, client, packageConfigReader))
 	cmd.AddCommand(BuildpackNew(logger, client))
 	cmd.AddCommand(BuildpackPull(logger, cfg, client))
+	cmd.AddCommand(BuildpackExtract(logger, cfg, client))
 	cmd.AddCommand(BuildpackRegister(logger, cfg, client))
 	cmd.AddCommand(BuildpackYank(logger, cfg, client))
 
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/image"
+	"github.com/buildpacks/pack/pkg/logging"
+)
+
+// BuildpackExtractFlags consist of flags applicable to the `buildpack extract` command
+type BuildpackExtractFlags struct {
+	// BuildpackRegistry is the name of the buildpack registry to use to search for
+	BuildpackRegistry string
+
+	// Policy is the pull policy for the package image
+	Policy string
+}
+
+// BuildpackExtract extracts the buildpacks of a buildpack package to a directory
+func BuildpackExtract(logger logging.Logger, cfg config.Config, pack PackClient) *cobra.Command {
+	var flags BuildpackExtractFlags
+
+	cmd := &cobra.Command{
+		Use:   "extract <package> <directory>",
+		Args:  cobra.ExactArgs(2),
+		Short: "Extract the buildpacks of a buildpack package to a directory",
+		Example: "pack buildpack extract docker://cnbs/sample-package:hello-universe ./hello-universe\n" +
+			"pack buildpack extract ./my-package.cnb ./my-package",
+		Long: "buildpack extract writes each buildpack of a package (an image, a '.cnb' file or a buildpack registry reference) " +
+			"to <directory>/<escaped id>/<version>, with its 'buildpack.toml' and 'bin/', so that its contents can be reviewed, " +
+			"or changed and packaged again. The directory must not exist, or be empty.",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			registry, err := config.GetRegistry(cfg, flags.BuildpackRegistry)
+			if err != nil {
+				return err
+			}
+
+			stringPolicy := flags.Policy
+			if stringPolicy == "" {
+				stringPolicy = cfg.PullPolicy
+			}
+			pullPolicy, err := image.ParsePullPolicy(stringPolicy)
+			if err != nil {
+				return errors.Wrap(err, "parsing pull policy")
+			}
+
+			if err := pack.ExtractBuildpack(cmd.Context(), client.ExtractBuildpackOptions{
+				URI:          args[0],
+				Dir:          args[1],
+				RegistryName: registry.Name,
+				PullPolicy:   pullPolicy,
+			}); err != nil {
+				return err
+			}
+
+			logger.Infof("Successfully extracted %s to %s", style.Symbol(args[0]), style.Symbol(args[1]))
+			return nil
+		}),
+	}
+	cmd.Flags().StringVarP(&flags.BuildpackRegistry, "buildpack-registry", "r", "", "Buildpack Registry name")
+	cmd.Flags().StringVar(&flags.Policy, "pull-policy", "", "Pull policy to use. Accepted values are always, never, if-not-present, hourly, daily and weekly. The default is always")
+	AddHelpFlag(cmd, "extract")
+	return cmd
+}
@@ -0,0 +1,91 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/commands"
+	"github.com/buildpacks/pack/internal/commands/testmocks"
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/image"
+	"github.com/buildpacks/pack/pkg/logging"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestBuildpackExtractCommand(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "BuildpackExtractCommand", testBuildpackExtractCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBuildpackExtractCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		logger         logging.Logger
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockClient     *testmocks.MockPackClient
+		cfg            config.Config
+	)
+
+	it.Before(func() {
+		logger = logging.NewLogWithWriters(&outBuf, &outBuf)
+		mockController = gomock.NewController(t)
+		mockClient = testmocks.NewMockPackClient(mockController)
+		cfg = config.Config{}
+
+		command = commands.BuildpackExtract(logger, cfg, mockClient)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#BuildpackExtract", func() {
+		when("no directory is provided", func() {
+			it("fails to run", func() {
+				command.SetArgs([]string{"some/package"})
+				err := command.Execute()
+				h.AssertError(t, err, "accepts 2 arg")
+			})
+		})
+
+		when("a package and directory are provided", func() {
+			it("extracts the package", func() {
+				mockClient.EXPECT().
+					ExtractBuildpack(gomock.Any(), client.ExtractBuildpackOptions{
+						URI:          "docker://some/package",
+						Dir:          "some-dir",
+						RegistryName: "official",
+						PullPolicy:   image.PullAlways,
+					}).
+					Return(nil)
+
+				command.SetArgs([]string{"docker://some/package", "some-dir"})
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), "Successfully extracted 'docker://some/package' to 'some-dir'")
+			})
+
+			it("uses the pull policy flag", func() {
+				mockClient.EXPECT().
+					ExtractBuildpack(gomock.Any(), client.ExtractBuildpackOptions{
+						URI:          "docker://some/package",
+						Dir:          "some-dir",
+						RegistryName: "official",
+						PullPolicy:   image.PullNever,
+					}).
+					Return(nil)
+
+				command.SetArgs([]string{"docker://some/package", "some-dir", "--pull-policy", "never"})
+				h.AssertNil(t, command.Execute())
+			})
+		})
+	})
+}
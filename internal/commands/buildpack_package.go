@@ -11,6 +11,7 @@ import (
 	"github.com/buildpacks/pack/internal/config"
 	"github.com/buildpacks/pack/internal/style"
 	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/dist"
 	"github.com/buildpacks/pack/pkg/image"
 	"github.com/buildpacks/pack/pkg/logging"
 )
@@ -23,6 +24,8 @@ type BuildpackPackageFlags struct {
 	Policy            string
 	BuildpackRegistry string
 	Path              string
+	Compression       string
+	CompressionLevel  int
 }
 
 // BuildpackPackager packages buildpacks
@@ -44,8 +47,13 @@ func BuildpackPackage(logger logging.Logger, cfg config.Config, packager Buildpa
 		Args:    cobra.ExactValidArgs(1),
 		Example: "pack buildpack package my-buildpack --config ./package.toml\npack buildpack package my-buildpack.cnb --config ./package.toml --f file",
 		Long: "buildpack package allows users to package (a) buildpack(s) into OCI format, which can then to be hosted in " +
-			"image repositories or persisted on disk as a '.cnb' file. You can also package a number of buildpacks " +
+			"image repositories or persisted on disk as a '.cnb' file, or as a directory with a 'buildpack.toml' and 'bin/' per buildpack. You can also package a number of buildpacks " +
 			"together, to enable easier distribution of a set of buildpacks. " +
+			"The buildpacks of a package, including those of nested packages, are checked for dependency cycles, " +
+			"conflicting copies of the same buildpack, unsupported Buildpack APIs and mixins no stack can provide, " +
+			"and the dependency tree of a meta-buildpack is printed. " +
+			"Buildpacks downloaded from a file or URL can be pinned with a 'digest' of the form 'sha256:<hex>' in the package config, " +
+			"and are verified against it before they are packaged. " +
 			"Packaged buildpacks can be used as inputs to `pack build` (using the `--buildpack` flag), " +
 			"and they can be included in the configs used in `pack builder create` and `pack buildpack package`. For more " +
 			"on how to package a buildpack, see: https://buildpacks.io/docs/buildpack-author-guide/package-a-buildpack/.",
@@ -82,6 +90,12 @@ func BuildpackPackage(logger logging.Logger, cfg config.Config, packager Buildpa
 					return errors.Wrap(err, "getting absolute path for config")
 				}
 			}
+			if flags.Compression != "" {
+				bpPackageCfg.Compression = dist.LayerCompression{Algorithm: flags.Compression}
+			}
+			if cmd.Flags().Changed("compression-level") {
+				bpPackageCfg.Compression.Level = flags.CompressionLevel
+			}
 			name := args[0]
 			if flags.Format == client.FormatFile {
 				switch ext := filepath.Ext(name); ext {
@@ -115,11 +129,13 @@ func BuildpackPackage(logger logging.Logger, cfg config.Config, packager Buildpa
 	}
 
 	cmd.Flags().StringVarP(&flags.PackageTomlPath, "config", "c", "", "Path to package TOML config")
-	cmd.Flags().StringVarP(&flags.Format, "format", "f", "", `Format to save package as ("image" or "file")`)
+	cmd.Flags().StringVarP(&flags.Format, "format", "f", "", `Format to save package as ("image", "file" or "dir")`)
 	cmd.Flags().BoolVar(&flags.Publish, "publish", false, `Publish to registry (applies to "--format=image" only)`)
-	cmd.Flags().StringVar(&flags.Policy, "pull-policy", "", "Pull policy to use. Accepted values are always, never, and if-not-present. The default is always")
+	cmd.Flags().StringVar(&flags.Policy, "pull-policy", "", "Pull policy to use. Accepted values are always, never, if-not-present, hourly, daily and weekly. The default is always")
 	cmd.Flags().StringVarP(&flags.Path, "path", "p", "", "Path to the Buildpack that needs to be packaged")
 	cmd.Flags().StringVarP(&flags.BuildpackRegistry, "buildpack-registry", "r", "", "Buildpack Registry name")
+	cmd.Flags().StringVar(&flags.Compression, "compression", "", `Compression of the layers of the package ("gzip", "zstd" or "estargz"), instead of the compression of the package config. Applies to packages saved as files or published`)
+	cmd.Flags().IntVar(&flags.CompressionLevel, "compression-level", 0, "Compression level of the layers of the package (1-9 for gzip and estargz, 1-22 for zstd)")
 
 	AddHelpFlag(cmd, "package")
 	return cmd
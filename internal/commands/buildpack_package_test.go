@@ -163,6 +163,17 @@ func testPackageCommand(t *testing.T, when spec.G, it spec.S) {
 					h.AssertEq(t, receivedOptions.PullPolicy, image.PullAlways)
 				})
 			})
+			when("compression flags", func() {
+				it("overrides the compression of the package config", func() {
+					cmd := packageCommand(withBuildpackPackager(fakeBuildpackPackager))
+					cmd.SetArgs([]string{"some-image-name", "--config", "/path/to/some/file", "--compression", "zstd", "--compression-level", "19"})
+					h.AssertNil(t, cmd.Execute())
+
+					receivedOptions := fakeBuildpackPackager.CreateCalledWithOptions
+					h.AssertEq(t, receivedOptions.Config.Compression, dist.LayerCompression{Algorithm: "zstd", Level: 19})
+				})
+			})
+
 			when("no --pull-policy", func() {
 				var pullPolicyArgs = []string{
 					"some-image-name",
@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/pkg/logging"
+)
+
+func NewBundleCommand(logger logging.Logger, cfg config.Config, client PackClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Move the images and buildpacks needed to build to a machine without network access",
+		RunE:  nil,
+	}
+
+	cmd.AddCommand(BundleCreate(logger, cfg, client))
+	cmd.AddCommand(BundleLoad(logger, client))
+
+	AddHelpFlag(cmd, "bundle")
+	return cmd
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/image"
+	"github.com/buildpacks/pack/pkg/logging"
+)
+
+// BundleCreateFlags consist of flags applicable to the `bundle create` command
+type BundleCreateFlags struct {
+	// Builder whose images are included in the bundle
+	Builder string
+
+	// AppPath is the path of the app, used to find its project descriptor
+	AppPath string
+
+	// DescriptorPath is the path of the project descriptor whose buildpacks are included
+	DescriptorPath string
+
+	// Buildpacks to include in addition to the ones of the project descriptor
+	Buildpacks []string
+
+	// RunImage to include instead of the run image of the builder
+	RunImage string
+
+	// LifecycleImage to include instead of the lifecycle image matching the builder
+	LifecycleImage string
+
+	// BuildpackRegistry is the name of the buildpack registry to use to search for
+	BuildpackRegistry string
+
+	// Policy is the pull policy for the images
+	Policy string
+}
+
+// BundleCreate writes the images and buildpacks needed to build an app to a bundle archive
+func BundleCreate(logger logging.Logger, cfg config.Config, pack PackClient) *cobra.Command {
+	var flags BundleCreateFlags
+
+	cmd := &cobra.Command{
+		Use:     "create <bundle-file>",
+		Args:    cobra.ExactArgs(1),
+		Short:   "Create a bundle of the images and buildpacks needed to build an app",
+		Example: "pack bundle create ./bundle.tar --builder cnbs/sample-builder:bionic --path ./my-app",
+		Long: "bundle create writes the builder, its run image and lifecycle image, and the buildpacks of the project " +
+			"descriptor and of '--buildpack' to <bundle-file>. Run 'pack bundle load <bundle-file>' on a machine without " +
+			"network access to make them available to 'pack build --offline'.",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			descriptor, actualDescriptorPath, err := parseProjectToml(flags.AppPath, flags.DescriptorPath)
+			if err != nil {
+				return err
+			}
+
+			builder := flags.Builder
+			if !cmd.Flags().Changed("builder") && descriptor.Build.Builder != "" {
+				builder = descriptor.Build.Builder
+			}
+
+			if builder == "" {
+				suggestSettingBuilder(logger, pack)
+				return client.NewSoftError()
+			}
+
+			registry, err := config.GetRegistry(cfg, flags.BuildpackRegistry)
+			if err != nil {
+				return err
+			}
+
+			stringPolicy := flags.Policy
+			if stringPolicy == "" {
+				stringPolicy = cfg.PullPolicy
+			}
+			pullPolicy, err := image.ParsePullPolicy(stringPolicy)
+			if err != nil {
+				return errors.Wrapf(err, "parsing pull policy %s", flags.Policy)
+			}
+
+			var lifecycleImage string
+			if flags.LifecycleImage != "" {
+				ref, err := name.ParseReference(flags.LifecycleImage)
+				if err != nil {
+					return errors.Wrapf(err, "parsing lifecycle image %s", flags.LifecycleImage)
+				}
+				lifecycleImage = ref.Name()
+			}
+
+			if err := pack.CreateBundle(cmd.Context(), client.CreateBundleOptions{
+				Path:                     args[0],
+				Builder:                  builder,
+				RunImage:                 flags.RunImage,
+				AdditionalMirrors:        getMirrors(cfg),
+				LifecycleImage:           lifecycleImage,
+				Buildpacks:               flags.Buildpacks,
+				ProjectDescriptor:        descriptor,
+				ProjectDescriptorBaseDir: filepath.Dir(actualDescriptorPath),
+				Registry:                 registry.Name,
+				PullPolicy:               pullPolicy,
+			}); err != nil {
+				return errors.Wrap(err, "failed to create bundle")
+			}
+
+			logger.Infof("Successfully created bundle %s", style.Symbol(args[0]))
+			return nil
+		}),
+	}
+	cmd.Flags().StringVarP(&flags.Builder, "builder", "B", cfg.DefaultBuilder, "Builder image")
+	cmd.Flags().StringVarP(&flags.AppPath, "path", "p", "", "Path to app dir, used to find its project descriptor (defaults to current working directory)")
+	cmd.Flags().StringVarP(&flags.DescriptorPath, "descriptor", "d", "", "Path to the project descriptor file")
+	cmd.Flags().StringSliceVarP(&flags.Buildpacks, "buildpack", "b", nil, "Buildpack to include, in any form accepted by 'pack build'"+stringSliceHelp("buildpack"))
+	cmd.Flags().StringVar(&flags.RunImage, "run-image", "", "Run image to include instead of the run image of the builder")
+	cmd.Flags().StringVar(&flags.LifecycleImage, "lifecycle-image", cfg.LifecycleImage, "Lifecycle image to include instead of the one matching the lifecycle of the builder")
+	cmd.Flags().StringVarP(&flags.BuildpackRegistry, "buildpack-registry", "r", "", "Buildpack Registry name")
+	cmd.Flags().StringVar(&flags.Policy, "pull-policy", "", "Pull policy to use. Accepted values are always, never, if-not-present, hourly, daily and weekly. The default is always")
+	AddHelpFlag(cmd, "create")
+	return cmd
+}
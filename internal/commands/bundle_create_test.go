@@ -0,0 +1,178 @@
+package commands_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/pkg/errors"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/commands"
+	"github.com/buildpacks/pack/internal/commands/testmocks"
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/image"
+	"github.com/buildpacks/pack/pkg/logging"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestBundleCreateCommand(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "BundleCreateCommand", testBundleCreateCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBundleCreateCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		logger         logging.Logger
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockClient     *testmocks.MockPackClient
+		cfg            config.Config
+	)
+
+	it.Before(func() {
+		logger = logging.NewLogWithWriters(&outBuf, &outBuf)
+		mockController = gomock.NewController(t)
+		mockClient = testmocks.NewMockPackClient(mockController)
+		cfg = config.Config{
+			DefaultBuilder: "default/builder",
+			RunImages: []config.RunImage{
+				{Image: "some/run", Mirrors: []string{"some-mirror/run"}},
+			},
+		}
+
+		command = commands.BundleCreate(logger, cfg, mockClient)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#BundleCreate", func() {
+		when("no bundle file is provided", func() {
+			it("fails to run", func() {
+				command.SetArgs([]string{})
+				err := command.Execute()
+				h.AssertError(t, err, "accepts 1 arg")
+			})
+		})
+
+		when("a bundle file is provided", func() {
+			it("creates the bundle with the default builder", func() {
+				mockClient.EXPECT().
+					CreateBundle(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, opts client.CreateBundleOptions) error {
+						h.AssertEq(t, opts.Path, "some-bundle.tar")
+						h.AssertEq(t, opts.Builder, "default/builder")
+						h.AssertEq(t, opts.Registry, "official")
+						h.AssertEq(t, opts.PullPolicy, image.PullAlways)
+						h.AssertEq(t, opts.AdditionalMirrors, map[string][]string{"some/run": {"some-mirror/run"}})
+						return nil
+					})
+
+				command.SetArgs([]string{"some-bundle.tar"})
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), "Successfully created bundle 'some-bundle.tar'")
+			})
+
+			it("passes the flags", func() {
+				mockClient.EXPECT().
+					CreateBundle(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, opts client.CreateBundleOptions) error {
+						h.AssertEq(t, opts.Builder, "some/builder")
+						h.AssertEq(t, opts.RunImage, "some/other-run")
+						h.AssertEq(t, opts.LifecycleImage, "index.docker.io/some/lifecycle:latest")
+						h.AssertEq(t, opts.Buildpacks, []string{"some/buildpack", "other/buildpack"})
+						h.AssertEq(t, opts.PullPolicy, image.PullIfNotPresent)
+						return nil
+					})
+
+				command.SetArgs([]string{
+					"some-bundle.tar",
+					"--builder", "some/builder",
+					"--run-image", "some/other-run",
+					"--lifecycle-image", "some/lifecycle",
+					"--buildpack", "some/buildpack",
+					"--buildpack", "other/buildpack",
+					"--pull-policy", "if-not-present",
+				})
+				h.AssertNil(t, command.Execute())
+			})
+
+			when("the project descriptor has a builder", func() {
+				var tmpDir string
+
+				it.Before(func() {
+					var err error
+					tmpDir, err = ioutil.TempDir("", "bundle-create-command")
+					h.AssertNil(t, err)
+					h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "project.toml"), []byte(`
+[build]
+builder = "descriptor/builder"
+
+[[build.buildpacks]]
+uri = "https://example.com/buildpack.tgz"
+`), 0600))
+				})
+
+				it.After(func() {
+					h.AssertNil(t, os.RemoveAll(tmpDir))
+				})
+
+				it("includes the builder and buildpacks of the descriptor", func() {
+					mockClient.EXPECT().
+						CreateBundle(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, opts client.CreateBundleOptions) error {
+							h.AssertEq(t, opts.Builder, "descriptor/builder")
+							h.AssertEq(t, opts.ProjectDescriptor.Build.Buildpacks[0].URI, "https://example.com/buildpack.tgz")
+							h.AssertEq(t, opts.ProjectDescriptorBaseDir, tmpDir)
+							return nil
+						})
+
+					command.SetArgs([]string{"some-bundle.tar", "--path", tmpDir})
+					h.AssertNil(t, command.Execute())
+				})
+
+				it("prefers the builder flag", func() {
+					mockClient.EXPECT().
+						CreateBundle(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, opts client.CreateBundleOptions) error {
+							h.AssertEq(t, opts.Builder, "some/builder")
+							return nil
+						})
+
+					command.SetArgs([]string{"some-bundle.tar", "--path", tmpDir, "--builder", "some/builder"})
+					h.AssertNil(t, command.Execute())
+				})
+			})
+
+			when("the bundle can't be created", func() {
+				it("returns an error", func() {
+					mockClient.EXPECT().
+						CreateBundle(gomock.Any(), gomock.Any()).
+						Return(errors.New("some-error"))
+
+					command.SetArgs([]string{"some-bundle.tar"})
+					h.AssertError(t, command.Execute(), "failed to create bundle: some-error")
+				})
+			})
+
+			when("the pull policy is invalid", func() {
+				it("returns an error", func() {
+					command.SetArgs([]string{"some-bundle.tar", "--pull-policy", "some-policy"})
+					h.AssertError(t, command.Execute(), "parsing pull policy some-policy")
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/logging"
+)
+
+// BundleLoad loads the images and buildpacks of a bundle archive
+func BundleLoad(logger logging.Logger, pack PackClient) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "load <bundle-file>",
+		Args:    cobra.ExactArgs(1),
+		Short:   "Load the images and buildpacks of a bundle",
+		Example: "pack bundle load ./bundle.tar",
+		Long: "bundle load loads the images of a bundle created with 'pack bundle create' into the daemon, and adds its " +
+			"buildpacks to the download cache and the buildpack registry caches, so that they can be used with '--offline'.",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			if err := pack.LoadBundle(cmd.Context(), client.LoadBundleOptions{Path: args[0]}); err != nil {
+				return errors.Wrap(err, "failed to load bundle")
+			}
+
+			logger.Infof("Successfully loaded bundle %s", style.Symbol(args[0]))
+			return nil
+		}),
+	}
+	AddHelpFlag(cmd, "load")
+	return cmd
+}
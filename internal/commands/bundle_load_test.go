@@ -0,0 +1,70 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/pkg/errors"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/commands"
+	"github.com/buildpacks/pack/internal/commands/testmocks"
+	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/logging"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestBundleLoadCommand(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "BundleLoadCommand", testBundleLoadCommand, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testBundleLoadCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		logger         logging.Logger
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockClient     *testmocks.MockPackClient
+	)
+
+	it.Before(func() {
+		logger = logging.NewLogWithWriters(&outBuf, &outBuf)
+		mockController = gomock.NewController(t)
+		mockClient = testmocks.NewMockPackClient(mockController)
+
+		command = commands.BundleLoad(logger, mockClient)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#BundleLoad", func() {
+		it("loads the bundle", func() {
+			mockClient.EXPECT().
+				LoadBundle(gomock.Any(), client.LoadBundleOptions{Path: "some-bundle.tar"}).
+				Return(nil)
+
+			command.SetArgs([]string{"some-bundle.tar"})
+			h.AssertNil(t, command.Execute())
+			h.AssertContains(t, outBuf.String(), "Successfully loaded bundle 'some-bundle.tar'")
+		})
+
+		when("the bundle can't be loaded", func() {
+			it("returns an error", func() {
+				mockClient.EXPECT().
+					LoadBundle(gomock.Any(), client.LoadBundleOptions{Path: "some-bundle.tar"}).
+					Return(errors.New("some-error"))
+
+				command.SetArgs([]string{"some-bundle.tar"})
+				h.AssertError(t, command.Execute(), "failed to load bundle: some-error")
+			})
+		})
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/engine"
 	"github.com/buildpacks/pack/internal/style"
 	"github.com/buildpacks/pack/pkg/client"
 	"github.com/buildpacks/pack/pkg/logging"
@@ -19,17 +20,24 @@ import (
 //go:generate mockgen -package testmocks -destination testmocks/mock_pack_client.go github.com/buildpacks/pack/internal/commands PackClient
 type PackClient interface {
 	InspectBuilder(string, bool, ...client.BuilderInspectionModifier) (*client.BuilderInfo, error)
+	DiffBuilders(string, string) (*client.BuilderDiff, error)
 	InspectImage(string, bool) (*client.ImageInfo, error)
 	Rebase(context.Context, client.RebaseOptions) error
+	CheckRebase(context.Context, client.RebaseOptions) (*client.RebaseReport, error)
 	CreateBuilder(context.Context, client.CreateBuilderOptions) error
 	NewBuildpack(context.Context, client.NewBuildpackOptions) error
 	PackageBuildpack(ctx context.Context, opts client.PackageBuildpackOptions) error
 	Build(context.Context, client.BuildOptions) error
+	Detect(context.Context, client.DetectOptions) (*client.DetectResult, error)
 	RegisterBuildpack(context.Context, client.RegisterBuildpackOptions) error
 	YankBuildpack(client.YankBuildpackOptions) error
 	InspectBuildpack(client.InspectBuildpackOptions) (*client.BuildpackInfo, error)
 	PullBuildpack(context.Context, client.PullBuildpackOptions) error
+	ExtractBuildpack(context.Context, client.ExtractBuildpackOptions) error
 	DownloadSBOM(name string, options client.DownloadSBOMOptions) error
+	CreateBundle(context.Context, client.CreateBundleOptions) error
+	LoadBundle(context.Context, client.LoadBundleOptions) error
+	ContainerEngine(context.Context) (engine.Engine, error)
 }
 
 func AddHelpFlag(cmd *cobra.Command, commandName string) {
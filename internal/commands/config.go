@@ -23,7 +23,13 @@ func NewConfigCommand(logger logging.Logger, cfg config.Config, cfgPath string,
 	cmd.AddCommand(ConfigRunImagesMirrors(logger, cfg, cfgPath))
 	cmd.AddCommand(ConfigTrustedBuilder(logger, cfg, cfgPath))
 	cmd.AddCommand(ConfigLifecycleImage(logger, cfg, cfgPath))
+	cmd.AddCommand(ConfigLifecycleMirror(logger, cfg, cfgPath))
 	cmd.AddCommand(ConfigRegistryMirrors(logger, cfg, cfgPath))
+	cmd.AddCommand(ConfigRegistryAuth(logger, cfg, cfgPath))
+	cmd.AddCommand(ConfigDownloadAuth(logger, cfg, cfgPath))
+	cmd.AddCommand(ConfigDownloadCACerts(logger, cfg, cfgPath))
+	cmd.AddCommand(ConfigOffline(logger, cfg, cfgPath))
+	cmd.AddCommand(ConfigFetchConcurrency(logger, cfg, cfgPath))
 
 	AddHelpFlag(cmd, "config")
 	return cmd
@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/logging"
+)
+
+var downloadCredential config.DownloadCredential
+
+func ConfigDownloadAuth(logger logging.Logger, cfg config.Config, cfgPath string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "download-auth",
+		Short: "List, add and remove credentials for hosts that buildpacks and lifecycles are downloaded from",
+		Long: "Configure how pack authenticates to the hosts that buildpacks and lifecycles are downloaded from over http(s).\n\n" +
+			"A bearer token or a username and password can be configured for each host. Hosts without credentials configured here " +
+			"use the credentials of the netrc file set with NETRC, or ~/.netrc, if any. Secrets are never written to the pack config, " +
+			"only the environment variable or file to read them from.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			listDownloadAuth(args, logger, cfg)
+			return nil
+		}),
+	}
+
+	listCmd := generateListCmd("download credentials", logger, cfg, listDownloadAuth)
+	listCmd.Long = "List all hosts with configured download credentials."
+	listCmd.Example = "pack config download-auth list"
+	cmd.AddCommand(listCmd)
+
+	addCmd := generateAdd("credentials for a download host", logger, cfg, cfgPath, addDownloadAuth)
+	addCmd.Use = "add <host>"
+	addCmd.Long = "Set credentials for a given host, with an optional port. Exactly one of a password or token must be provided."
+	addCmd.Example = "pack config download-auth add artifacts.example.com --token-env ARTIFACTS_TOKEN\n" +
+		"pack config download-auth add nexus.example.com:8443 --username my-user --password-file /run/secrets/nexus-password"
+	addCmd.Flags().StringVar(&downloadCredential.Username, "username", "", "Username to authenticate with")
+	addCmd.Flags().StringVar(&downloadCredential.PasswordEnv, "password-env", "", "Environment variable containing the password")
+	addCmd.Flags().StringVar(&downloadCredential.PasswordFile, "password-file", "", "File containing the password")
+	addCmd.Flags().StringVar(&downloadCredential.TokenEnv, "token-env", "", "Environment variable containing a bearer token")
+	addCmd.Flags().StringVar(&downloadCredential.TokenFile, "token-file", "", "File containing a bearer token")
+	cmd.AddCommand(addCmd)
+
+	rmCmd := generateRemove("credentials for a download host", logger, cfg, cfgPath, removeDownloadAuth)
+	rmCmd.Use = "remove <host>"
+	rmCmd.Long = "Remove credentials for a given host."
+	rmCmd.Example = "pack config download-auth remove artifacts.example.com"
+	cmd.AddCommand(rmCmd)
+
+	AddHelpFlag(cmd, "download-auth")
+	return cmd
+}
+
+func addDownloadAuth(args []string, logger logging.Logger, cfg config.Config, cfgPath string) error {
+	cred := downloadCredential
+	cred.Host = args[0]
+	if err := validateDownloadCredential(cred); err != nil {
+		return err
+	}
+
+	var credentials []config.DownloadCredential
+	for _, c := range cfg.DownloadCredentials {
+		if c.Host != cred.Host {
+			credentials = append(credentials, c)
+		}
+	}
+	cfg.DownloadCredentials = append(credentials, cred)
+
+	if err := config.Write(cfg, cfgPath); err != nil {
+		return errors.Wrapf(err, "failed to write to %s", cfgPath)
+	}
+
+	logger.Infof("Host %s configured with %s", style.Symbol(cred.Host), downloadCredentialSource(cred))
+	return nil
+}
+
+func removeDownloadAuth(args []string, logger logging.Logger, cfg config.Config, cfgPath string) error {
+	host := args[0]
+
+	var credentials []config.DownloadCredential
+	for _, c := range cfg.DownloadCredentials {
+		if c.Host != host {
+			credentials = append(credentials, c)
+		}
+	}
+
+	if len(credentials) == len(cfg.DownloadCredentials) {
+		logger.Infof("No credentials have been set for %s", style.Symbol(host))
+		return nil
+	}
+
+	cfg.DownloadCredentials = credentials
+	if err := config.Write(cfg, cfgPath); err != nil {
+		return errors.Wrapf(err, "failed to write to %s", cfgPath)
+	}
+
+	logger.Infof("Removed credentials for %s", style.Symbol(host))
+	return nil
+}
+
+func listDownloadAuth(args []string, logger logging.Logger, cfg config.Config) {
+	if len(cfg.DownloadCredentials) == 0 {
+		logger.Info("No download credentials have been set")
+		return
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString("Download Credentials:\n")
+	for _, cred := range cfg.DownloadCredentials {
+		buf.WriteString("  " + cred.Host + ": " + downloadCredentialSource(cred) + "\n")
+	}
+
+	logger.Info(buf.String())
+}
+
+func validateDownloadCredential(cred config.DownloadCredential) error {
+	if strings.Contains(cred.Host, "/") {
+		return errors.Errorf("host %s must not include a scheme or path", style.Symbol(cred.Host))
+	}
+
+	sources := 0
+	for _, pair := range [][2]string{
+		{cred.PasswordEnv, cred.PasswordFile},
+		{cred.TokenEnv, cred.TokenFile},
+	} {
+		if pair[0] != "" && pair[1] != "" {
+			return errors.New("a secret can be read from an environment variable or a file, not both")
+		}
+		if pair[0] != "" || pair[1] != "" {
+			sources++
+		}
+	}
+
+	if sources != 1 {
+		return errors.New("exactly one of a password or token must be provided")
+	}
+
+	if (cred.PasswordEnv != "" || cred.PasswordFile != "") && cred.Username == "" {
+		return errors.New("a username must be provided with a password")
+	}
+
+	return nil
+}
+
+func downloadCredentialSource(cred config.DownloadCredential) string {
+	switch {
+	case cred.TokenEnv != "":
+		return "token from environment variable " + style.Symbol(cred.TokenEnv)
+	case cred.TokenFile != "":
+		return "token from file " + style.Symbol(cred.TokenFile)
+	case cred.PasswordEnv != "":
+		return "user " + style.Symbol(cred.Username) + " with password from environment variable " + style.Symbol(cred.PasswordEnv)
+	case cred.PasswordFile != "":
+		return "user " + style.Symbol(cred.Username) + " with password from file " + style.Symbol(cred.PasswordFile)
+	}
+
+	return "no credentials"
+}
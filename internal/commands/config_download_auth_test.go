@@ -0,0 +1,177 @@
+package commands_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/commands"
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/pkg/logging"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestConfigDownloadAuth(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "ConfigDownloadAuthCommand", testConfigDownloadAuthCommand, spec.Random(), spec.Report(report.Terminal{}))
+}
+
+func testConfigDownloadAuthCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		cmd          *cobra.Command
+		logger       logging.Logger
+		outBuf       bytes.Buffer
+		tempPackHome string
+		configPath   string
+		testCfg      = config.Config{
+			DownloadCredentials: []config.DownloadCredential{
+				{Host: "artifacts.example.com", TokenEnv: "ARTIFACTS_TOKEN"},
+				{Host: "nexus.example.com:8443", Username: "some-user", PasswordFile: "/some/password"},
+			},
+		}
+	)
+
+	it.Before(func() {
+		var err error
+		logger = logging.NewLogWithWriters(&outBuf, &outBuf)
+		tempPackHome, err = os.MkdirTemp("", "pack-home")
+		h.AssertNil(t, err)
+		configPath = filepath.Join(tempPackHome, "config.toml")
+
+		cmd = commands.ConfigDownloadAuth(logger, testCfg, configPath)
+		cmd.SetOut(logging.GetWriterForLevel(logger, logging.InfoLevel))
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(tempPackHome))
+	})
+
+	when("-h", func() {
+		it("prints available commands", func() {
+			cmd.SetArgs([]string{"-h"})
+			h.AssertNil(t, cmd.Execute())
+			output := outBuf.String()
+			h.AssertContains(t, output, "Usage:")
+			for _, command := range []string{"add", "remove", "list"} {
+				h.AssertContains(t, output, command)
+			}
+		})
+	})
+
+	when("no arguments", func() {
+		it("lists download credentials without secrets", func() {
+			cmd.SetArgs([]string{})
+			h.AssertNil(t, cmd.Execute())
+			output := outBuf.String()
+			h.AssertContains(t, output, "Download Credentials:")
+			h.AssertContains(t, output, "artifacts.example.com: token from environment variable 'ARTIFACTS_TOKEN'")
+			h.AssertContains(t, output, "nexus.example.com:8443: user 'some-user' with password from file '/some/password'")
+		})
+	})
+
+	when("add", func() {
+		when("no host is specified", func() {
+			it("fails to run", func() {
+				cmd.SetArgs([]string{"add"})
+				h.AssertError(t, cmd.Execute(), "accepts 1 arg")
+			})
+		})
+
+		when("a token is provided", func() {
+			it("adds the credentials to the config", func() {
+				cmd.SetArgs([]string{"add", "downloads.example.com", "--token-file", "/some/token"})
+				h.AssertNil(t, cmd.Execute())
+
+				cfg, err := config.Read(configPath)
+				h.AssertNil(t, err)
+				h.AssertEq(t, cfg.DownloadCredentials, append(testCfg.DownloadCredentials,
+					config.DownloadCredential{Host: "downloads.example.com", TokenFile: "/some/token"},
+				))
+				h.AssertContains(t, outBuf.String(), "Host 'downloads.example.com' configured with token from file '/some/token'")
+			})
+		})
+
+		when("credentials already exist for the host", func() {
+			it("replaces them", func() {
+				cmd.SetArgs([]string{"add", "artifacts.example.com", "--username", "other-user", "--password-env", "ARTIFACTS_PASSWORD"})
+				h.AssertNil(t, cmd.Execute())
+
+				cfg, err := config.Read(configPath)
+				h.AssertNil(t, err)
+				h.AssertEq(t, cfg.DownloadCredentials, []config.DownloadCredential{
+					{Host: "nexus.example.com:8443", Username: "some-user", PasswordFile: "/some/password"},
+					{Host: "artifacts.example.com", Username: "other-user", PasswordEnv: "ARTIFACTS_PASSWORD"},
+				})
+			})
+		})
+
+		when("the host includes a scheme", func() {
+			it("fails to run", func() {
+				cmd.SetArgs([]string{"add", "https://downloads.example.com", "--token-env", "TOKEN"})
+				h.AssertError(t, cmd.Execute(), "host 'https://downloads.example.com' must not include a scheme or path")
+			})
+		})
+
+		when("no credentials are provided", func() {
+			it("fails to run", func() {
+				cmd.SetArgs([]string{"add", "downloads.example.com"})
+				h.AssertError(t, cmd.Execute(), "exactly one of a password or token must be provided")
+			})
+		})
+
+		when("a secret is read from both an environment variable and a file", func() {
+			it("fails to run", func() {
+				cmd.SetArgs([]string{"add", "downloads.example.com", "--token-env", "TOKEN", "--token-file", "/some/token"})
+				h.AssertError(t, cmd.Execute(), "a secret can be read from an environment variable or a file, not both")
+			})
+		})
+
+		when("a password is provided without a username", func() {
+			it("fails to run", func() {
+				cmd.SetArgs([]string{"add", "downloads.example.com", "--password-env", "PASSWORD"})
+				h.AssertError(t, cmd.Execute(), "a username must be provided with a password")
+			})
+		})
+	})
+
+	when("remove", func() {
+		when("host provided isn't present", func() {
+			it("prints a clear message", func() {
+				cmd.SetArgs([]string{"remove", "downloads.example.com"})
+				h.AssertNil(t, cmd.Execute())
+				h.AssertContains(t, outBuf.String(), "No credentials have been set for 'downloads.example.com'")
+			})
+		})
+
+		when("host is provided", func() {
+			it("removes the credentials for the host", func() {
+				cmd.SetArgs([]string{"remove", "artifacts.example.com"})
+				h.AssertNil(t, cmd.Execute())
+
+				cfg, err := config.Read(configPath)
+				h.AssertNil(t, err)
+				h.AssertEq(t, cfg.DownloadCredentials, []config.DownloadCredential{
+					{Host: "nexus.example.com:8443", Username: "some-user", PasswordFile: "/some/password"},
+				})
+			})
+		})
+	})
+
+	when("list", func() {
+		when("no credentials were set", func() {
+			it("prints a clear message", func() {
+				cmd = commands.ConfigDownloadAuth(logger, config.Config{}, configPath)
+				cmd.SetArgs([]string{"list"})
+				h.AssertNil(t, cmd.Execute())
+				h.AssertContains(t, outBuf.String(), "No download credentials have been set")
+			})
+		})
+	})
+}
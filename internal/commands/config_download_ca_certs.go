@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/logging"
+)
+
+func ConfigDownloadCACerts(logger logging.Logger, cfg config.Config, cfgPath string) *cobra.Command {
+	var unset bool
+
+	cmd := &cobra.Command{
+		Use:   "download-ca-certs <pem-file>",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Configure CA certificates to trust when downloading buildpacks and lifecycles",
+		Long: "You can use this command to set a file of PEM encoded CA certificates, which are trusted in addition to " +
+			"the certificates of the system when downloading buildpacks and lifecycles over https, e.g. from an internal artifact store. " +
+			"Downloads use the proxies set with the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			switch {
+			case unset:
+				if len(args) > 0 {
+					return errors.Errorf("CA certificates file and --unset cannot be specified simultaneously")
+				}
+
+				if cfg.DownloadCACerts == "" {
+					logger.Info("No CA certificates file was set.")
+				} else {
+					oldPath := cfg.DownloadCACerts
+					cfg.DownloadCACerts = ""
+					if err := config.Write(cfg, cfgPath); err != nil {
+						return errors.Wrapf(err, "failed to write to config at %s", cfgPath)
+					}
+					logger.Infof("Successfully unset CA certificates file %s", style.Symbol(oldPath))
+				}
+			case len(args) == 0:
+				if cfg.DownloadCACerts != "" {
+					logger.Infof("The current CA certificates file is %s", style.Symbol(cfg.DownloadCACerts))
+				} else {
+					logger.Info("No CA certificates file is set. Only the certificates of the system are trusted.")
+				}
+				return nil
+			default:
+				path, err := filepath.Abs(args[0])
+				if err != nil {
+					return err
+				}
+
+				pem, err := ioutil.ReadFile(filepath.Clean(path))
+				if err != nil {
+					return errors.Wrapf(err, "reading CA certificates file %s", style.Symbol(path))
+				}
+				if !x509.NewCertPool().AppendCertsFromPEM(pem) {
+					return errors.Errorf("no PEM encoded CA certificates found in %s", style.Symbol(path))
+				}
+
+				cfg.DownloadCACerts = path
+				if err := config.Write(cfg, cfgPath); err != nil {
+					return errors.Wrapf(err, "failed to write to config at %s", cfgPath)
+				}
+				logger.Infof("CA certificates in %s will now be trusted for downloads", style.Symbol(path))
+			}
+
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&unset, "unset", "u", false, "Unset CA certificates file, and only trust the certificates of the system")
+	AddHelpFlag(cmd, "download-ca-certs")
+	return cmd
+}
@@ -0,0 +1,125 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/commands"
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/pkg/logging"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestConfigDownloadCACerts(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "ConfigDownloadCACerts", testConfigDownloadCACertsCommand, spec.Random(), spec.Report(report.Terminal{}))
+}
+
+func testConfigDownloadCACertsCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command      *cobra.Command
+		logger       logging.Logger
+		outBuf       bytes.Buffer
+		tempPackHome string
+		configFile   string
+		pemFile      string
+	)
+
+	it.Before(func() {
+		var err error
+		logger = logging.NewLogWithWriters(&outBuf, &outBuf)
+		tempPackHome, err = os.MkdirTemp("", "pack-home")
+		h.AssertNil(t, err)
+		configFile = filepath.Join(tempPackHome, "config.toml")
+
+		server := httptest.NewTLSServer(nil)
+		defer server.Close()
+		pemFile = filepath.Join(tempPackHome, "ca.pem")
+		h.AssertNil(t, os.WriteFile(pemFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+
+		command = commands.ConfigDownloadCACerts(logger, config.Config{}, configFile)
+		command.SetOut(logging.GetWriterForLevel(logger, logging.InfoLevel))
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(tempPackHome))
+	})
+
+	when("no arguments", func() {
+		when("no CA certificates file is set", func() {
+			it("says only the certificates of the system are trusted", func() {
+				command.SetArgs([]string{})
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), "No CA certificates file is set. Only the certificates of the system are trusted.")
+			})
+		})
+
+		when("a CA certificates file is set", func() {
+			it("prints the file", func() {
+				command = commands.ConfigDownloadCACerts(logger, config.Config{DownloadCACerts: pemFile}, configFile)
+				command.SetArgs([]string{})
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), "The current CA certificates file is '"+pemFile+"'")
+			})
+		})
+	})
+
+	when("a file is provided", func() {
+		it("sets the CA certificates file in the config", func() {
+			command.SetArgs([]string{pemFile})
+			h.AssertNil(t, command.Execute())
+
+			cfg, err := config.Read(configFile)
+			h.AssertNil(t, err)
+			h.AssertEq(t, cfg.DownloadCACerts, pemFile)
+			h.AssertContains(t, outBuf.String(), "CA certificates in '"+pemFile+"' will now be trusted for downloads")
+		})
+
+		when("the file has no PEM encoded certificates", func() {
+			it("fails to run", func() {
+				invalidFile := filepath.Join(tempPackHome, "invalid.pem")
+				h.AssertNil(t, os.WriteFile(invalidFile, []byte("not a certificate"), 0600))
+
+				command.SetArgs([]string{invalidFile})
+				h.AssertError(t, command.Execute(), "no PEM encoded CA certificates found in")
+			})
+		})
+
+		when("the file doesn't exist", func() {
+			it("fails to run", func() {
+				command.SetArgs([]string{filepath.Join(tempPackHome, "missing.pem")})
+				h.AssertError(t, command.Execute(), "reading CA certificates file")
+			})
+		})
+
+		when("--unset is also provided", func() {
+			it("fails to run", func() {
+				command.SetArgs([]string{pemFile, "--unset"})
+				h.AssertError(t, command.Execute(), "CA certificates file and --unset cannot be specified simultaneously")
+			})
+		})
+	})
+
+	when("--unset", func() {
+		it("unsets the CA certificates file", func() {
+			command = commands.ConfigDownloadCACerts(logger, config.Config{DownloadCACerts: pemFile}, configFile)
+			command.SetArgs([]string{"--unset"})
+			h.AssertNil(t, command.Execute())
+
+			cfg, err := config.Read(configFile)
+			h.AssertNil(t, err)
+			h.AssertEq(t, cfg.DownloadCACerts, "")
+			h.AssertContains(t, outBuf.String(), "Successfully unset CA certificates file '"+pemFile+"'")
+		})
+	})
+}
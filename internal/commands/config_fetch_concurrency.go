@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/logging"
+)
+
+// the number of fetches at the same time when none is set, see client.WithFetchConcurrency
+const defaultFetchConcurrency = 4
+
+func ConfigFetchConcurrency(logger logging.Logger, cfg config.Config, cfgPath string) *cobra.Command {
+	var unset bool
+
+	cmd := &cobra.Command{
+		Use:   "fetch-concurrency <number>",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Configure the number of images and buildpacks fetched at the same time",
+		Long: "You can use this command to set the maximum number of images pulled and buildpacks downloaded at the same time " +
+			"when building, creating builders and packaging buildpacks. The default is " + strconv.Itoa(defaultFetchConcurrency) + ".",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			switch {
+			case unset:
+				if len(args) > 0 {
+					return errors.Errorf("fetch concurrency and --unset cannot be specified simultaneously")
+				}
+
+				if cfg.FetchConcurrency == 0 {
+					logger.Info("No fetch concurrency was set.")
+				} else {
+					cfg.FetchConcurrency = 0
+					if err := config.Write(cfg, cfgPath); err != nil {
+						return errors.Wrapf(err, "failed to write to config at %s", cfgPath)
+					}
+					logger.Infof("Successfully unset fetch concurrency, up to %d items will be fetched at the same time", defaultFetchConcurrency)
+				}
+			case len(args) == 0:
+				if cfg.FetchConcurrency != 0 {
+					logger.Infof("Up to %d items are fetched at the same time", cfg.FetchConcurrency)
+				} else {
+					logger.Infof("No fetch concurrency is set. Up to %d items are fetched at the same time.", defaultFetchConcurrency)
+				}
+				return nil
+			default:
+				concurrency, err := strconv.Atoi(args[0])
+				if err != nil || concurrency < 1 {
+					return errors.Errorf("Invalid fetch concurrency %s provided, must be a positive number", style.Symbol(args[0]))
+				}
+				if concurrency == cfg.FetchConcurrency {
+					logger.Infof("Fetch concurrency is already set to %d", concurrency)
+					return nil
+				}
+
+				cfg.FetchConcurrency = concurrency
+				if err := config.Write(cfg, cfgPath); err != nil {
+					return errors.Wrapf(err, "failed to write to config at %s", cfgPath)
+				}
+				logger.Infof("Up to %d items will now be fetched at the same time", concurrency)
+			}
+
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&unset, "unset", "u", false, "Unset fetch concurrency, and fetch up to "+strconv.Itoa(defaultFetchConcurrency)+" items at the same time")
+	AddHelpFlag(cmd, "fetch-concurrency")
+	return cmd
+}
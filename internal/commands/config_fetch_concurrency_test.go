@@ -0,0 +1,135 @@
+package commands_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/commands"
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/pkg/logging"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestConfigFetchConcurrency(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "ConfigFetchConcurrency", testConfigFetchConcurrencyCommand, spec.Random(), spec.Report(report.Terminal{}))
+}
+
+func testConfigFetchConcurrencyCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command      *cobra.Command
+		logger       logging.Logger
+		outBuf       bytes.Buffer
+		tempPackHome string
+		configFile   string
+		assert       = h.NewAssertionManager(t)
+		cfg          = config.Config{}
+	)
+
+	it.Before(func() {
+		var err error
+		logger = logging.NewLogWithWriters(&outBuf, &outBuf)
+		tempPackHome, err = ioutil.TempDir("", "pack-home")
+		h.AssertNil(t, err)
+		configFile = filepath.Join(tempPackHome, "config.toml")
+
+		command = commands.ConfigFetchConcurrency(logger, cfg, configFile)
+		command.SetOut(logging.GetWriterForLevel(logger, logging.InfoLevel))
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(tempPackHome))
+	})
+
+	when("#ConfigFetchConcurrency", func() {
+		when("list", func() {
+			when("no fetch concurrency was set", func() {
+				it("shows the default", func() {
+					command.SetArgs([]string{})
+
+					h.AssertNil(t, command.Execute())
+
+					assert.Contains(outBuf.String(), "No fetch concurrency is set. Up to 4 items are fetched at the same time.")
+				})
+			})
+
+			when("a fetch concurrency was set", func() {
+				it("lists it", func() {
+					cfg.FetchConcurrency = 8
+					command = commands.ConfigFetchConcurrency(logger, cfg, configFile)
+					command.SetArgs([]string{})
+
+					h.AssertNil(t, command.Execute())
+
+					assert.Contains(outBuf.String(), "Up to 8 items are fetched at the same time")
+				})
+			})
+		})
+
+		when("set", func() {
+			when("a positive number is specified", func() {
+				it("sets it in the config", func() {
+					command.SetArgs([]string{"8"})
+
+					h.AssertNil(t, command.Execute())
+
+					readCfg, err := config.Read(configFile)
+					h.AssertNil(t, err)
+					assert.Equal(readCfg.FetchConcurrency, 8)
+				})
+			})
+
+			when("an invalid number is specified", func() {
+				it("fails", func() {
+					for _, arg := range []string{"0", "many"} {
+						command.SetArgs([]string{arg})
+
+						h.AssertError(t, command.Execute(), "must be a positive number")
+					}
+				})
+			})
+
+			when("the same number is already set", func() {
+				it("says so", func() {
+					cfg.FetchConcurrency = 8
+					command = commands.ConfigFetchConcurrency(logger, cfg, configFile)
+					command.SetArgs([]string{"8"})
+
+					h.AssertNil(t, command.Execute())
+
+					assert.Contains(outBuf.String(), "Fetch concurrency is already set to 8")
+				})
+			})
+		})
+
+		when("unset", func() {
+			it("removes it from the config", func() {
+				cfg.FetchConcurrency = 8
+				h.AssertNil(t, config.Write(cfg, configFile))
+				command = commands.ConfigFetchConcurrency(logger, cfg, configFile)
+				command.SetArgs([]string{"--unset"})
+
+				h.AssertNil(t, command.Execute())
+
+				readCfg, err := config.Read(configFile)
+				h.AssertNil(t, err)
+				assert.Equal(readCfg.FetchConcurrency, 0)
+			})
+
+			it("fails when a number is also given", func() {
+				command.SetArgs([]string{"8", "--unset"})
+
+				h.AssertError(t, command.Execute(), "fetch concurrency and --unset cannot be specified simultaneously")
+			})
+		})
+	})
+}
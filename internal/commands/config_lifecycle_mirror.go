@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"net/url"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/logging"
+)
+
+func ConfigLifecycleMirror(logger logging.Logger, cfg config.Config, cfgPath string) *cobra.Command {
+	var unset bool
+
+	cmd := &cobra.Command{
+		Use:   "lifecycle-mirror <lifecycle-mirror-url>",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Configure a mirror to download lifecycle releases from",
+		Long: "You can use this command to set a URL to download lifecycle releases from when creating builders, " +
+			"instead of GitHub. The mirror must have the same layout as the GitHub releases, " +
+			"e.g. <lifecycle-mirror-url>/v0.13.3/lifecycle-v0.13.3+linux.x86-64.tgz. Each release is verified against " +
+			"the lifecycle.digest of the builder config if it's set, or else the .sha256 checksum published on GitHub, " +
+			"which must then be reachable the first time a version is downloaded.",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			switch {
+			case unset:
+				if len(args) > 0 {
+					return errors.Errorf("lifecycle mirror and --unset cannot be specified simultaneously")
+				}
+
+				if cfg.LifecycleMirror == "" {
+					logger.Info("No lifecycle mirror was set.")
+				} else {
+					oldMirror := cfg.LifecycleMirror
+					cfg.LifecycleMirror = ""
+					if err := config.Write(cfg, cfgPath); err != nil {
+						return errors.Wrapf(err, "failed to write to config at %s", cfgPath)
+					}
+					logger.Infof("Successfully unset lifecycle mirror %s", style.Symbol(oldMirror))
+				}
+			case len(args) == 0:
+				if cfg.LifecycleMirror != "" {
+					logger.Infof("The current lifecycle mirror is %s", style.Symbol(cfg.LifecycleMirror))
+				} else {
+					logger.Info("No lifecycle mirror is set. Lifecycle releases will be downloaded from GitHub.")
+				}
+				return nil
+			default:
+				mirror := args[0]
+				mirrorURL, err := url.Parse(mirror)
+				if err != nil || (mirrorURL.Scheme != "http" && mirrorURL.Scheme != "https" && mirrorURL.Scheme != "file") {
+					return errors.Errorf("Invalid lifecycle mirror %s provided, must be an http(s) or file URL", style.Symbol(mirror))
+				}
+				if mirror == cfg.LifecycleMirror {
+					logger.Infof("Lifecycle mirror is already set to %s", style.Symbol(mirror))
+					return nil
+				}
+
+				cfg.LifecycleMirror = mirror
+				if err := config.Write(cfg, cfgPath); err != nil {
+					return errors.Wrapf(err, "failed to write to config at %s", cfgPath)
+				}
+				logger.Infof("Lifecycle releases will now be downloaded from %s", style.Symbol(mirror))
+			}
+
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&unset, "unset", "u", false, "Unset lifecycle mirror, and download lifecycle releases from GitHub")
+	AddHelpFlag(cmd, "lifecycle-mirror")
+	return cmd
+}
@@ -0,0 +1,141 @@
+package commands_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/commands"
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/pkg/logging"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestConfigLifecycleMirror(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "ConfigLifecycleMirror", testConfigLifecycleMirrorCommand, spec.Random(), spec.Report(report.Terminal{}))
+}
+
+func testConfigLifecycleMirrorCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command      *cobra.Command
+		logger       logging.Logger
+		outBuf       bytes.Buffer
+		tempPackHome string
+		configFile   string
+		assert       = h.NewAssertionManager(t)
+		cfg          = config.Config{}
+	)
+
+	it.Before(func() {
+		var err error
+		logger = logging.NewLogWithWriters(&outBuf, &outBuf)
+		tempPackHome, err = ioutil.TempDir("", "pack-home")
+		h.AssertNil(t, err)
+		configFile = filepath.Join(tempPackHome, "config.toml")
+
+		command = commands.ConfigLifecycleMirror(logger, cfg, configFile)
+		command.SetOut(logging.GetWriterForLevel(logger, logging.InfoLevel))
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(tempPackHome))
+	})
+
+	when("#ConfigLifecycleMirror", func() {
+		when("list", func() {
+			when("no lifecycle mirror was set", func() {
+				it("says releases are downloaded from GitHub", func() {
+					command.SetArgs([]string{})
+
+					h.AssertNil(t, command.Execute())
+
+					assert.Contains(outBuf.String(), "Lifecycle releases will be downloaded from GitHub")
+				})
+			})
+
+			when("a lifecycle mirror was set", func() {
+				it("lists the mirror", func() {
+					cfg.LifecycleMirror = "https://mirror.example.com/lifecycle"
+					command = commands.ConfigLifecycleMirror(logger, cfg, configFile)
+					command.SetArgs([]string{})
+
+					h.AssertNil(t, command.Execute())
+
+					assert.Contains(outBuf.String(), "https://mirror.example.com/lifecycle")
+				})
+			})
+		})
+
+		when("set", func() {
+			when("a valid lifecycle mirror is specified", func() {
+				it("sets the lifecycle mirror in config", func() {
+					command.SetArgs([]string{"https://mirror.example.com/lifecycle"})
+					assert.Succeeds(command.Execute())
+
+					readCfg, err := config.Read(configFile)
+					assert.Nil(err)
+					assert.Equal(readCfg.LifecycleMirror, "https://mirror.example.com/lifecycle")
+				})
+			})
+
+			when("the lifecycle mirror is already set", func() {
+				it("provides a helpful message", func() {
+					cfg.LifecycleMirror = "https://mirror.example.com/lifecycle"
+					command = commands.ConfigLifecycleMirror(logger, cfg, configFile)
+					command.SetArgs([]string{"https://mirror.example.com/lifecycle"})
+
+					h.AssertNil(t, command.Execute())
+
+					h.AssertEq(t, strings.TrimSpace(outBuf.String()), `Lifecycle mirror is already set to 'https://mirror.example.com/lifecycle'`)
+				})
+			})
+
+			when("an invalid lifecycle mirror is specified", func() {
+				it("returns an error", func() {
+					command.SetArgs([]string{"mirror.example.com/lifecycle"})
+					h.AssertError(t, command.Execute(), "Invalid lifecycle mirror 'mirror.example.com/lifecycle' provided")
+				})
+			})
+		})
+
+		when("unset", func() {
+			when("the lifecycle mirror is set", func() {
+				it("removes the lifecycle mirror", func() {
+					command = commands.ConfigLifecycleMirror(logger, config.Config{LifecycleMirror: "https://mirror.example.com/lifecycle"}, configFile)
+					command.SetArgs([]string{"--unset"})
+					assert.Succeeds(command.Execute())
+
+					readCfg, err := config.Read(configFile)
+					assert.Nil(err)
+					assert.Equal(readCfg.LifecycleMirror, "")
+				})
+			})
+
+			when("the lifecycle mirror is not set", func() {
+				it("returns clear message that no lifecycle mirror is set", func() {
+					command.SetArgs([]string{"--unset"})
+					assert.Succeeds(command.Execute())
+
+					h.AssertEq(t, strings.TrimSpace(outBuf.String()), `No lifecycle mirror was set.`)
+				})
+			})
+		})
+
+		when("--unset and lifecycle mirror to set is provided", func() {
+			it("errors", func() {
+				command.SetArgs([]string{"https://mirror.example.com/lifecycle", "--unset"})
+				h.AssertError(t, command.Execute(), "lifecycle mirror and --unset cannot be specified simultaneously")
+			})
+		})
+	})
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/logging"
+)
+
+func ConfigOffline(logger logging.Logger, cfg config.Config, cfgPath string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "offline [<true | false>]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "List and set the current 'offline' value from the config",
+		Long: "In offline mode pack never uses the network: images are only taken from the daemon, buildpacks from the download and registry caches, and images can't be published. " +
+			"Use `pack bundle create` and `pack bundle load` to make the images and buildpacks needed to build available without network access.\n\n" +
+			"* Running `pack config offline` prints whether offline mode is currently enabled.\n" +
+			"* Running `pack config offline <true | false>` enables or disables offline mode for every command. Use the `--offline` flag to enable it for a single command.",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			switch {
+			case len(args) == 0:
+				if cfg.Offline {
+					logger.Infof("Offline mode is enabled. To turn it off, run `pack config offline false`")
+				} else {
+					logger.Info("Offline mode isn't currently enabled. To enable it, run `pack config offline true`")
+				}
+			default:
+				val, err := strconv.ParseBool(args[0])
+				if err != nil {
+					return errors.Wrapf(err, "invalid value %s provided", style.Symbol(args[0]))
+				}
+				cfg.Offline = val
+
+				if err = config.Write(cfg, cfgPath); err != nil {
+					return errors.Wrap(err, "writing to config")
+				}
+
+				if cfg.Offline {
+					logger.Info("Offline mode enabled")
+				} else {
+					logger.Info("Offline mode disabled")
+				}
+			}
+
+			return nil
+		}),
+	}
+
+	AddHelpFlag(cmd, "offline")
+	return cmd
+}
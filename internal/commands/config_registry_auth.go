@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/logging"
+)
+
+var registryCredential config.RegistryCredential
+
+func ConfigRegistryAuth(logger logging.Logger, cfg config.Config, cfgPath string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "registry-auth",
+		Short: "List, add and remove credentials for OCI registries",
+		Long: "Configure how pack authenticates to OCI registries.\n\n" +
+			"Credentials configured here take precedence over the Docker config, and are used when fetching and publishing images " +
+			"as well as by the lifecycle. Secrets are never written to the pack config, only the environment variable or file " +
+			"to read them from, or the name of a Docker credential helper.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			listRegistryAuth(args, logger, cfg)
+			return nil
+		}),
+	}
+
+	listCmd := generateListCmd("registry credentials", logger, cfg, listRegistryAuth)
+	listCmd.Long = "List all registries with configured credentials."
+	listCmd.Example = "pack config registry-auth list"
+	cmd.AddCommand(listCmd)
+
+	addCmd := generateAdd("credentials for a registry", logger, cfg, cfgPath, addRegistryAuth)
+	addCmd.Use = "add <registry>"
+	addCmd.Long = "Set credentials for a given registry. Exactly one of a password, token, identity token or credential helper must be provided."
+	addCmd.Example = "pack config registry-auth add ghcr.io --username my-user --password-env GHCR_TOKEN\n" +
+		"pack config registry-auth add registry.example.com --token-file /run/secrets/registry-token\n" +
+		"pack config registry-auth add 123456789.dkr.ecr.us-east-1.amazonaws.com --helper ecr-login"
+	addCmd.Flags().StringVar(&registryCredential.Username, "username", "", "Username to authenticate with")
+	addCmd.Flags().StringVar(&registryCredential.PasswordEnv, "password-env", "", "Environment variable containing the password")
+	addCmd.Flags().StringVar(&registryCredential.PasswordFile, "password-file", "", "File containing the password")
+	addCmd.Flags().StringVar(&registryCredential.TokenEnv, "token-env", "", "Environment variable containing a registry bearer token")
+	addCmd.Flags().StringVar(&registryCredential.TokenFile, "token-file", "", "File containing a registry bearer token")
+	addCmd.Flags().StringVar(&registryCredential.IdentityTokenEnv, "identity-token-env", "", "Environment variable containing an identity token, exchanged for a registry token")
+	addCmd.Flags().StringVar(&registryCredential.IdentityTokenFile, "identity-token-file", "", "File containing an identity token, exchanged for a registry token")
+	addCmd.Flags().StringVar(&registryCredential.Helper, "helper", "", "Docker credential helper to use, e.g. 'ecr-login' for 'docker-credential-ecr-login'")
+	cmd.AddCommand(addCmd)
+
+	rmCmd := generateRemove("credentials for a registry", logger, cfg, cfgPath, removeRegistryAuth)
+	rmCmd.Use = "remove <registry>"
+	rmCmd.Long = "Remove credentials for a given registry."
+	rmCmd.Example = "pack config registry-auth remove ghcr.io"
+	cmd.AddCommand(rmCmd)
+
+	AddHelpFlag(cmd, "registry-auth")
+	return cmd
+}
+
+func addRegistryAuth(args []string, logger logging.Logger, cfg config.Config, cfgPath string) error {
+	cred := registryCredential
+	cred.Registry = args[0]
+	if err := validateRegistryCredential(cred); err != nil {
+		return err
+	}
+
+	var credentials []config.RegistryCredential
+	for _, c := range cfg.RegistryCredentials {
+		if c.Registry != cred.Registry {
+			credentials = append(credentials, c)
+		}
+	}
+	cfg.RegistryCredentials = append(credentials, cred)
+
+	if err := config.Write(cfg, cfgPath); err != nil {
+		return errors.Wrapf(err, "failed to write to %s", cfgPath)
+	}
+
+	logger.Infof("Registry %s configured with %s", style.Symbol(cred.Registry), registryCredentialSource(cred))
+	return nil
+}
+
+func removeRegistryAuth(args []string, logger logging.Logger, cfg config.Config, cfgPath string) error {
+	registry := args[0]
+
+	var credentials []config.RegistryCredential
+	for _, c := range cfg.RegistryCredentials {
+		if c.Registry != registry {
+			credentials = append(credentials, c)
+		}
+	}
+
+	if len(credentials) == len(cfg.RegistryCredentials) {
+		logger.Infof("No credentials have been set for %s", style.Symbol(registry))
+		return nil
+	}
+
+	cfg.RegistryCredentials = credentials
+	if err := config.Write(cfg, cfgPath); err != nil {
+		return errors.Wrapf(err, "failed to write to %s", cfgPath)
+	}
+
+	logger.Infof("Removed credentials for %s", style.Symbol(registry))
+	return nil
+}
+
+func listRegistryAuth(args []string, logger logging.Logger, cfg config.Config) {
+	if len(cfg.RegistryCredentials) == 0 {
+		logger.Info("No registry credentials have been set")
+		return
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString("Registry Credentials:\n")
+	for _, cred := range cfg.RegistryCredentials {
+		buf.WriteString("  " + cred.Registry + ": " + registryCredentialSource(cred) + "\n")
+	}
+
+	logger.Info(buf.String())
+}
+
+func validateRegistryCredential(cred config.RegistryCredential) error {
+	sources := 0
+	for _, pair := range [][2]string{
+		{cred.PasswordEnv, cred.PasswordFile},
+		{cred.TokenEnv, cred.TokenFile},
+		{cred.IdentityTokenEnv, cred.IdentityTokenFile},
+	} {
+		if pair[0] != "" && pair[1] != "" {
+			return errors.New("a secret can be read from an environment variable or a file, not both")
+		}
+		if pair[0] != "" || pair[1] != "" {
+			sources++
+		}
+	}
+	if cred.Helper != "" {
+		sources++
+	}
+
+	if sources != 1 {
+		return errors.New("exactly one of a password, token, identity token or credential helper must be provided")
+	}
+
+	if (cred.PasswordEnv != "" || cred.PasswordFile != "") && cred.Username == "" {
+		return errors.New("a username must be provided with a password")
+	}
+
+	return nil
+}
+
+func registryCredentialSource(cred config.RegistryCredential) string {
+	switch {
+	case cred.Helper != "":
+		return "credential helper " + style.Symbol(cred.Helper)
+	case cred.TokenEnv != "":
+		return "token from environment variable " + style.Symbol(cred.TokenEnv)
+	case cred.TokenFile != "":
+		return "token from file " + style.Symbol(cred.TokenFile)
+	case cred.IdentityTokenEnv != "":
+		return "identity token from environment variable " + style.Symbol(cred.IdentityTokenEnv)
+	case cred.IdentityTokenFile != "":
+		return "identity token from file " + style.Symbol(cred.IdentityTokenFile)
+	case cred.PasswordEnv != "":
+		return "user " + style.Symbol(cred.Username) + " with password from environment variable " + style.Symbol(cred.PasswordEnv)
+	case cred.PasswordFile != "":
+		return "user " + style.Symbol(cred.Username) + " with password from file " + style.Symbol(cred.PasswordFile)
+	}
+
+	return "no credentials"
+}
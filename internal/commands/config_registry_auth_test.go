@@ -0,0 +1,177 @@
+package commands_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/commands"
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/pkg/logging"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestConfigRegistryAuth(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "ConfigRegistryAuthCommand", testConfigRegistryAuthCommand, spec.Random(), spec.Report(report.Terminal{}))
+}
+
+func testConfigRegistryAuthCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		cmd          *cobra.Command
+		logger       logging.Logger
+		outBuf       bytes.Buffer
+		tempPackHome string
+		configPath   string
+		testCfg      = config.Config{
+			RegistryCredentials: []config.RegistryCredential{
+				{Registry: "ghcr.io", Username: "some-user", PasswordEnv: "GHCR_TOKEN"},
+				{Registry: "registry.example.com", Helper: "some-helper"},
+			},
+		}
+	)
+
+	it.Before(func() {
+		var err error
+		logger = logging.NewLogWithWriters(&outBuf, &outBuf)
+		tempPackHome, err = os.MkdirTemp("", "pack-home")
+		h.AssertNil(t, err)
+		configPath = filepath.Join(tempPackHome, "config.toml")
+
+		cmd = commands.ConfigRegistryAuth(logger, testCfg, configPath)
+		cmd.SetOut(logging.GetWriterForLevel(logger, logging.InfoLevel))
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(tempPackHome))
+	})
+
+	when("-h", func() {
+		it("prints available commands", func() {
+			cmd.SetArgs([]string{"-h"})
+			h.AssertNil(t, cmd.Execute())
+			output := outBuf.String()
+			h.AssertContains(t, output, "Usage:")
+			for _, command := range []string{"add", "remove", "list"} {
+				h.AssertContains(t, output, command)
+			}
+		})
+	})
+
+	when("no arguments", func() {
+		it("lists registry credentials without secrets", func() {
+			cmd.SetArgs([]string{})
+			h.AssertNil(t, cmd.Execute())
+			output := outBuf.String()
+			h.AssertContains(t, output, "Registry Credentials:")
+			h.AssertContains(t, output, "ghcr.io: user 'some-user' with password from environment variable 'GHCR_TOKEN'")
+			h.AssertContains(t, output, "registry.example.com: credential helper 'some-helper'")
+		})
+	})
+
+	when("add", func() {
+		when("no registry is specified", func() {
+			it("fails to run", func() {
+				cmd.SetArgs([]string{"add"})
+				h.AssertError(t, cmd.Execute(), "accepts 1 arg")
+			})
+		})
+
+		when("a token is provided", func() {
+			it("adds the credentials to the config", func() {
+				cmd.SetArgs([]string{"add", "quay.io", "--token-env", "QUAY_TOKEN"})
+				h.AssertNil(t, cmd.Execute())
+
+				cfg, err := config.Read(configPath)
+				h.AssertNil(t, err)
+				h.AssertEq(t, cfg.RegistryCredentials, append(testCfg.RegistryCredentials,
+					config.RegistryCredential{Registry: "quay.io", TokenEnv: "QUAY_TOKEN"},
+				))
+				h.AssertContains(t, outBuf.String(), "Registry 'quay.io' configured with token from environment variable 'QUAY_TOKEN'")
+			})
+		})
+
+		when("credentials already exist for the registry", func() {
+			it("replaces them", func() {
+				cmd.SetArgs([]string{"add", "ghcr.io", "--identity-token-file", "/some/identity-token"})
+				h.AssertNil(t, cmd.Execute())
+
+				cfg, err := config.Read(configPath)
+				h.AssertNil(t, err)
+				h.AssertEq(t, cfg.RegistryCredentials, []config.RegistryCredential{
+					{Registry: "registry.example.com", Helper: "some-helper"},
+					{Registry: "ghcr.io", IdentityTokenFile: "/some/identity-token"},
+				})
+			})
+		})
+
+		when("no credentials are provided", func() {
+			it("fails to run", func() {
+				cmd.SetArgs([]string{"add", "quay.io"})
+				h.AssertError(t, cmd.Execute(), "exactly one of a password, token, identity token or credential helper must be provided")
+			})
+		})
+
+		when("more than one credential source is provided", func() {
+			it("fails to run", func() {
+				cmd.SetArgs([]string{"add", "quay.io", "--helper", "some-helper", "--token-env", "QUAY_TOKEN"})
+				h.AssertError(t, cmd.Execute(), "exactly one of a password, token, identity token or credential helper must be provided")
+			})
+		})
+
+		when("a secret is read from both an environment variable and a file", func() {
+			it("fails to run", func() {
+				cmd.SetArgs([]string{"add", "quay.io", "--token-env", "QUAY_TOKEN", "--token-file", "/some/token"})
+				h.AssertError(t, cmd.Execute(), "a secret can be read from an environment variable or a file, not both")
+			})
+		})
+
+		when("a password is provided without a username", func() {
+			it("fails to run", func() {
+				cmd.SetArgs([]string{"add", "quay.io", "--password-file", "/some/password"})
+				h.AssertError(t, cmd.Execute(), "a username must be provided with a password")
+			})
+		})
+	})
+
+	when("remove", func() {
+		when("registry provided isn't present", func() {
+			it("prints a clear message", func() {
+				cmd.SetArgs([]string{"remove", "quay.io"})
+				h.AssertNil(t, cmd.Execute())
+				h.AssertContains(t, outBuf.String(), "No credentials have been set for 'quay.io'")
+			})
+		})
+
+		when("registry is provided", func() {
+			it("removes the credentials for the registry", func() {
+				cmd.SetArgs([]string{"remove", "ghcr.io"})
+				h.AssertNil(t, cmd.Execute())
+
+				cfg, err := config.Read(configPath)
+				h.AssertNil(t, err)
+				h.AssertEq(t, cfg.RegistryCredentials, []config.RegistryCredential{
+					{Registry: "registry.example.com", Helper: "some-helper"},
+				})
+			})
+		})
+	})
+
+	when("list", func() {
+		when("no credentials were set", func() {
+			it("prints a clear message", func() {
+				cmd = commands.ConfigRegistryAuth(logger, config.Config{}, configPath)
+				cmd.SetArgs([]string{"list"})
+				h.AssertNil(t, cmd.Execute())
+				h.AssertContains(t, outBuf.String(), "No registry credentials have been set")
+			})
+		})
+	})
+}
@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/image"
+	"github.com/buildpacks/pack/pkg/logging"
+)
+
+type DetectFlags struct {
+	AppPath        string
+	Builder        string
+	Registry       string
+	Policy         string
+	Network        string
+	DescriptorPath string
+	Workspace      string
+	Env            []string
+	EnvFiles       []string
+	EnvPrefixes    []string
+	Buildpacks     []string
+	Volumes        []string
+}
+
+// Detect runs only the detection phase for an app
+func Detect(logger logging.Logger, cfg config.Config, packClient PackClient) *cobra.Command {
+	var flags DetectFlags
+
+	cmd := &cobra.Command{
+		Use:     "detect",
+		Args:    cobra.NoArgs,
+		Short:   "Show which buildpacks would build an app",
+		Example: "pack detect --path apps/test-app --builder cnbs/sample-builder:bionic",
+		Long: "Pack Detect runs only the detection phase of the lifecycle against the source code of an app. It prints the " +
+			"buildpack group that would build the app, the resolved build plan, and whether each buildpack that was tried " +
+			"passed, failed or was skipped, along with anything its detect printed.\n\nDetect uses the order of the builder, " +
+			"or only the buildpacks provided with `--buildpack`.",
+		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
+			if flags.Registry != "" && !cfg.Experimental {
+				return client.NewExperimentError("Support for buildpack registries is currently experimental.")
+			}
+
+			descriptor, actualDescriptorPath, err := parseProjectToml(flags.AppPath, flags.DescriptorPath)
+			if err != nil {
+				return err
+			}
+
+			if actualDescriptorPath != "" {
+				logger.Debugf("Using project descriptor located at %s", style.Symbol(actualDescriptorPath))
+			}
+
+			builder := flags.Builder
+			if !cmd.Flags().Changed("builder") && descriptor.Build.Builder != "" {
+				builder = descriptor.Build.Builder
+			}
+
+			if builder == "" {
+				suggestSettingBuilder(logger, packClient)
+				return client.NewSoftError()
+			}
+
+			env, err := parseEnv(flags.EnvFiles, flags.Env, flags.EnvPrefixes)
+			if err != nil {
+				return err
+			}
+
+			stringPolicy := flags.Policy
+			if stringPolicy == "" {
+				stringPolicy = cfg.PullPolicy
+			}
+			pullPolicy, err := image.ParsePullPolicy(stringPolicy)
+			if err != nil {
+				return errors.Wrapf(err, "parsing pull policy %s", flags.Policy)
+			}
+
+			result, err := packClient.Detect(cmd.Context(), client.DetectOptions{
+				AppPath:    flags.AppPath,
+				Builder:    builder,
+				Registry:   flags.Registry,
+				EnvVars:    env,
+				Buildpacks: flags.Buildpacks,
+				PullPolicy: pullPolicy,
+				ContainerConfig: client.ContainerConfig{
+					Network: flags.Network,
+					Volumes: flags.Volumes,
+				},
+				ProjectDescriptorBaseDir: filepath.Dir(actualDescriptorPath),
+				ProjectDescriptor:        descriptor,
+				Workspace:                flags.Workspace,
+			})
+			if result != nil {
+				logDetectResult(logger, result)
+			}
+			if err != nil {
+				return errors.Wrap(err, "failed to detect")
+			}
+
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&flags.AppPath, "path", "p", "", "Path to app dir or zip-formatted file (defaults to current working directory)")
+	cmd.Flags().StringSliceVarP(&flags.Buildpacks, "buildpack", "b", nil, "Buildpack to detect with instead of the builder's order. One of:\n  a buildpack by id and version in the form of '<buildpack>@<version>',\n  path to a buildpack directory (not supported on Windows),\n  path/URL to a buildpack .tar or .tgz file, or\n  a packaged buildpack image name in the form of '<hostname>/<repo>[:<tag>]'"+stringSliceHelp("buildpack"))
+	cmd.Flags().StringVarP(&flags.Builder, "builder", "B", cfg.DefaultBuilder, "Builder image")
+	cmd.Flags().StringVarP(&flags.DescriptorPath, "descriptor", "d", "", "Path to the project descriptor file")
+	cmd.Flags().StringArrayVarP(&flags.Env, "env", "e", []string{}, "Build-time environment variable, in the form 'VAR=VALUE' or 'VAR'.\nWhen using latter value-less form, value will be taken from current\n  environment at the time this command is executed.\n"+envActionHelp+"\nThis flag may be specified multiple times and will override\n  individual values defined by --env-file."+stringArrayHelp("env"))
+	cmd.Flags().StringArrayVar(&flags.EnvFiles, "env-file", []string{}, "Build-time environment variables file in dotenv syntax\nOne variable per line, of the form 'VAR=VALUE' or 'VAR', values may be quoted and span lines\nWhen using latter value-less form, value will be taken from current\n  environment at the time this command is executed")
+	cmd.Flags().StringArrayVar(&flags.EnvPrefixes, "env-from-prefix", []string{}, "Pass the variables of the current environment whose names start with the prefix, e.g. 'BP_'.\nThey are overridden by --env-file and --env."+stringArrayHelp("env-from-prefix"))
+	cmd.Flags().StringVar(&flags.Network, "network", "", "Connect the detect container to network")
+	cmd.Flags().StringVar(&flags.Policy, "pull-policy", "", `Pull policy to use. Accepted values are always, never, if-not-present, hourly, daily and weekly. (default "always")`)
+	cmd.Flags().StringVarP(&flags.Registry, "buildpack-registry", "r", cfg.DefaultRegistryName, "Buildpack Registry by name")
+	cmd.Flags().StringArrayVar(&flags.Volumes, "volume", nil, "Mount host volume into the detect container, in the form '<host path>:<target path>[:<options>]'."+stringArrayHelp("volume"))
+	cmd.Flags().StringVar(&flags.Workspace, "workspace", "", "Location at which to mount the app dir in the build image")
+	AddHelpFlag(cmd, "detect")
+	return cmd
+}
+
+func logDetectResult(logger logging.Logger, result *client.DetectResult) {
+	if len(result.Trials) > 0 {
+		logger.Info("Detection results:")
+		for i, trial := range result.Trials {
+			logger.Infof("  Group %d:", i+1)
+			for _, bp := range trial.Buildpacks {
+				status := fmt.Sprintf("    %s: %s@%s", bp.Status, bp.ID, bp.Version)
+				if bp.Reason != "" {
+					status += fmt.Sprintf(" (%s)", bp.Reason)
+				}
+				logger.Info(status)
+				for _, line := range strings.Split(bp.Output, "\n") {
+					if line != "" {
+						logger.Infof("      %s", line)
+					}
+				}
+			}
+		}
+		logger.Info("")
+	}
+
+	if len(result.Group) == 0 {
+		return
+	}
+
+	logger.Info("Detected buildpacks:")
+	tw := tabwriter.NewWriter(logging.GetWriterForLevel(logger, logging.InfoLevel), writerMinWidth, writerTabWidth, defaultTabWidth, writerPadChar, writerFlags)
+	for _, bp := range result.Group {
+		fmt.Fprintf(tw, "  %s\t%s\n", bp.ID, bp.Version)
+	}
+	tw.Flush()
+
+	logger.Info("")
+	logger.Info("Build plan:")
+	if len(result.Plan) == 0 {
+		logger.Info("  (none)")
+		return
+	}
+
+	for _, entry := range result.Plan {
+		var providers []string
+		for _, provider := range entry.Providers {
+			providers = append(providers, provider.String())
+		}
+
+		for _, require := range entry.Requires {
+			logger.Infof("  %s", require.Name)
+			logger.Infof("    Provided by: %s", strings.Join(providers, ", "))
+			if require.Version != "" {
+				logger.Infof("    Version: %s", require.Version)
+			}
+			if len(require.Metadata) > 0 {
+				var keys []string
+				for key := range require.Metadata {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+
+				logger.Info("    Metadata:")
+				for _, key := range keys {
+					logger.Infof("      %s: %v", key, require.Metadata[key])
+				}
+			}
+		}
+	}
+}
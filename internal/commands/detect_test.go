@@ -0,0 +1,135 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/buildpacks/lifecycle/buildpack"
+	"github.com/buildpacks/lifecycle/platform"
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/pkg/errors"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+	"github.com/spf13/cobra"
+
+	"github.com/buildpacks/pack/internal/build"
+	"github.com/buildpacks/pack/internal/commands"
+	"github.com/buildpacks/pack/internal/commands/testmocks"
+	"github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/image"
+	"github.com/buildpacks/pack/pkg/logging"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestDetectCommand(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+
+	spec.Run(t, "Commands", testDetectCommand, spec.Random(), spec.Report(report.Terminal{}))
+}
+
+func testDetectCommand(t *testing.T, when spec.G, it spec.S) {
+	var (
+		command        *cobra.Command
+		logger         logging.Logger
+		outBuf         bytes.Buffer
+		mockController *gomock.Controller
+		mockClient     *testmocks.MockPackClient
+		cfg            config.Config
+	)
+
+	it.Before(func() {
+		logger = logging.NewLogWithWriters(&outBuf, &outBuf)
+		cfg = config.Config{}
+		mockController = gomock.NewController(t)
+		mockClient = testmocks.NewMockPackClient(mockController)
+
+		command = commands.Detect(logger, cfg, mockClient)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#DetectCommand", func() {
+		when("no builder is specified", func() {
+			it("suggests a builder and returns a soft error", func() {
+				mockClient.EXPECT().InspectBuilder(gomock.Any(), false).Return(&client.BuilderInfo{}, nil).AnyTimes()
+
+				command.SetArgs([]string{})
+				err := command.Execute()
+				h.AssertError(t, err, "")
+				h.AssertContains(t, outBuf.String(), "Please select a default builder with:")
+			})
+		})
+
+		when("a group passes detection", func() {
+			it("prints the group, the plan and the outcome of each buildpack", func() {
+				group := []buildpack.GroupBuildpack{{ID: "some/buildpack", Version: "1.2.3"}}
+				mockClient.EXPECT().
+					Detect(gomock.Any(), client.DetectOptions{
+						Builder:                  "my-builder",
+						Buildpacks:               []string{"some/buildpack@1.2.3"},
+						EnvVars:                  []client.EnvVar{{Name: "KEY", Value: "VALUE", Action: client.EnvOverride, Source: "--env"}},
+						PullPolicy:               image.PullNever,
+						ProjectDescriptorBaseDir: ".",
+					}).
+					Return(&client.DetectResult{
+						Group: group,
+						Plan: []platform.BuildPlanEntry{{
+							Providers: group,
+							Requires:  []buildpack.Require{{Name: "some-dependency", Metadata: map[string]interface{}{"launch": true}}},
+						}},
+						Trials: []build.DetectTrial{{Buildpacks: []build.DetectedBuildpack{
+							{ID: "some/buildpack", Version: "1.2.3", Status: "pass", Output: "found an app"},
+							{ID: "other/buildpack", Version: "0.1.0", Status: "skip"},
+						}}},
+					}, nil)
+
+				command.SetArgs([]string{"--builder", "my-builder", "--buildpack", "some/buildpack@1.2.3", "--env", "KEY=VALUE", "--pull-policy", "never"})
+				h.AssertNil(t, command.Execute())
+
+				output := outBuf.String()
+				h.AssertContains(t, output, `Detection results:
+  Group 1:
+    pass: some/buildpack@1.2.3
+      found an app
+    skip: other/buildpack@0.1.0
+`)
+				h.AssertContains(t, output, `Detected buildpacks:
+  some/buildpack    1.2.3
+`)
+				h.AssertContains(t, output, `Build plan:
+  some-dependency
+    Provided by: some/buildpack@1.2.3
+    Metadata:
+      launch: true
+`)
+			})
+		})
+
+		when("no group passes detection", func() {
+			it("prints the outcome of each buildpack and fails", func() {
+				mockClient.EXPECT().
+					Detect(gomock.Any(), gomock.Any()).
+					Return(&client.DetectResult{
+						Trials: []build.DetectTrial{{Buildpacks: []build.DetectedBuildpack{
+							{ID: "some/buildpack", Version: "1.2.3", Status: "fail", Output: "no go.mod found"},
+						}}},
+					}, errors.New("executing detector: failed with status code: 20"))
+
+				command.SetArgs([]string{"--builder", "my-builder"})
+				err := command.Execute()
+				h.AssertError(t, err, "failed to detect: executing detector: failed with status code: 20")
+
+				output := outBuf.String()
+				h.AssertContains(t, output, `    fail: some/buildpack@1.2.3
+      no go.mod found
+`)
+				h.AssertNotContains(t, output, "Detected buildpacks:")
+			})
+		})
+	})
+}
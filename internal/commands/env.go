@@ -0,0 +1,217 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/client"
+)
+
+const envActionHelp = "Append '.append', '.prepend' or '.default' to VAR to combine VALUE with the value from\n  project.toml or an earlier flag instead of overriding it, and set the delimiter with 'VAR.delim=DELIM'."
+
+// the suffix of a variable name setting the delimiter of its appended and prepended values, e.g. PATH.delim=:
+const envDelimSuffix = "delim"
+
+// parseEnv reads the build environment from its sources, lowest precedence first:
+// host variables with the given prefixes, env files in order, then env flags in order.
+func parseEnv(envFiles []string, envVars []string, prefixes []string) ([]client.EnvVar, error) {
+	var (
+		env    []client.EnvVar
+		delims = map[string]string{}
+	)
+	add := func(item, value, source string) error {
+		name, action := splitEnvAction(item)
+		if action == envDelimSuffix {
+			delims[name] = value
+			return nil
+		}
+		envAction, err := client.ParseEnvAction(action)
+		if err != nil {
+			return errors.Wrapf(err, "parsing environment variable %s", style.Symbol(item))
+		}
+		env = append(env, client.EnvVar{Name: name, Value: value, Action: envAction, Source: source})
+		return nil
+	}
+
+	for _, prefix := range prefixes {
+		var names []string
+		for _, kv := range os.Environ() {
+			if name := strings.SplitN(kv, "=", 2)[0]; strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			env = append(env, client.EnvVar{Name: name, Value: os.Getenv(name), Action: client.EnvOverride, Source: "--env-from-prefix " + prefix})
+		}
+	}
+
+	for _, envFile := range envFiles {
+		entries, err := parseEnvFile(envFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse env file '%s'", envFile)
+		}
+		for _, entry := range entries {
+			if err := add(entry.key, entry.value, "--env-file "+envFile); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse env file '%s'", envFile)
+			}
+		}
+	}
+
+	for _, envVar := range envVars {
+		key, value := splitEnvVar(envVar)
+		if err := add(key, value, "--env"); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range env {
+		if env[i].Action == client.EnvAppend || env[i].Action == client.EnvPrepend {
+			env[i].Delim = delims[env[i].Name]
+		}
+	}
+	return env, nil
+}
+
+// splitEnvVar splits an item of the form VAR=VALUE, the value of an item of the form VAR is taken from the environment
+func splitEnvVar(item string) (string, string) {
+	arr := strings.SplitN(item, "=", 2)
+	if len(arr) > 1 {
+		return arr[0], arr[1]
+	}
+	name, _ := splitEnvAction(arr[0])
+	return arr[0], os.Getenv(name)
+}
+
+// splitEnvAction splits a variable name of the form NAME.<action>, e.g. JAVA_TOOL_OPTIONS.append
+func splitEnvAction(key string) (string, string) {
+	if i := strings.LastIndex(key, "."); i > 0 {
+		switch action := key[i+1:]; action {
+		case string(client.EnvOverride), string(client.EnvDefault), string(client.EnvAppend), string(client.EnvPrepend), envDelimSuffix:
+			return key[:i], action
+		}
+	}
+	return key, ""
+}
+
+type envFileEntry struct {
+	key   string
+	value string
+}
+
+// parseEnvFile reads variables in dotenv syntax: one VAR=VALUE per line, optionally preceded by 'export'.
+// Lines starting with # are comments, as is the rest of a line after ' #' following an unquoted value.
+// Single quoted values are taken literally, double quoted values support the escapes \n, \t, \r, \", \\ and \$.
+// Quoted values may span lines. The value of a line of the form VAR is taken from the environment.
+func parseEnvFile(filename string) ([]envFileEntry, error) {
+	f, err := ioutil.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return nil, errors.Wrapf(err, "open %s", filename)
+	}
+
+	var entries []envFileEntry
+	lines := strings.Split(strings.ReplaceAll(string(f), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		// only leading whitespace is trimmed, trailing whitespace may be part of a quoted value
+		line := strings.TrimLeft(lines[i], " \t")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+			line = strings.TrimLeft(line[len("export"):], " \t")
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			line = strings.TrimSpace(line)
+			if strings.ContainsAny(line, " \t") {
+				return nil, errors.Errorf("line %d: expected VAR=VALUE or VAR", lineNumber)
+			}
+			key, value := splitEnvVar(line)
+			entries = append(entries, envFileEntry{key: key, value: value})
+			continue
+		}
+
+		key := strings.TrimSpace(line[:eq])
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, errors.Errorf("line %d: invalid variable name %s", lineNumber, style.Symbol(key))
+		}
+
+		rest := strings.TrimLeft(line[eq+1:], " \t")
+		if rest == "" || (rest[0] != '"' && rest[0] != '\'') {
+			if comment := strings.Index(rest, " #"); comment >= 0 {
+				rest = rest[:comment]
+			}
+			entries = append(entries, envFileEntry{key: key, value: strings.TrimSpace(rest)})
+			continue
+		}
+
+		// the value is quoted, it ends at the closing quote, possibly on a later line
+		quote := rest[0]
+		body := rest[1:]
+		end := closingQuote(body, quote)
+		for end < 0 {
+			i++
+			if i >= len(lines) {
+				return nil, errors.Errorf("line %d: missing closing quote of the value of %s", lineNumber, style.Symbol(key))
+			}
+			body += "\n" + lines[i]
+			end = closingQuote(body, quote)
+		}
+		if after := strings.TrimSpace(body[end+1:]); after != "" && !strings.HasPrefix(after, "#") {
+			return nil, errors.Errorf("line %d: unexpected %s after the quoted value of %s", lineNumber, style.Symbol(after), style.Symbol(key))
+		}
+
+		value := body[:end]
+		if quote == '"' {
+			value = unescapeDoubleQuoted(value)
+		}
+		entries = append(entries, envFileEntry{key: key, value: value})
+	}
+	return entries, nil
+}
+
+// closingQuote returns the index of the quote closing s, or -1. Double quotes may be escaped with a backslash.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeDoubleQuoted(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case '"', '\\', '$':
+			sb.WriteByte(s[i])
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
@@ -1,6 +1,8 @@
 package fakes
 
 import (
+	"context"
+
 	"github.com/buildpacks/pack/pkg/client"
 )
 
@@ -14,6 +16,10 @@ type FakeBuilderInspector struct {
 	ReceivedForRemoteName     string
 	CalculatedConfigForLocal  client.BuilderInspectionConfig
 	CalculatedConfigForRemote client.BuilderInspectionConfig
+
+	DetectResult          *client.DetectResult
+	DetectErr             error
+	ReceivedDetectOptions client.DetectOptions
 }
 
 func (i *FakeBuilderInspector) InspectBuilder(
@@ -37,3 +43,8 @@ func (i *FakeBuilderInspector) InspectBuilder(
 	i.ReceivedForRemoteName = name
 	return i.InfoForRemote, i.ErrorForRemote
 }
+
+func (i *FakeBuilderInspector) Detect(ctx context.Context, opts client.DetectOptions) (*client.DetectResult, error) {
+	i.ReceivedDetectOptions = opts
+	return i.DetectResult, i.DetectErr
+}
@@ -35,7 +35,7 @@ func InspectBuilder(
 				return client.NewSoftError()
 			}
 
-			return inspectBuilder(logger, imageName, flags, cfg, inspector, writerFactory)
+			return inspectBuilder(cmd.Context(), logger, imageName, flags, cfg, inspector, writerFactory)
 		}),
 	}
 	cmd.Flags().IntVarP(&flags.Depth, "depth", "d", builder.OrderDetectionMaxDepth, "Max depth to display for Detection Order.\nOmission of this flag or values < 0 will display the entire tree.")
@@ -23,7 +23,13 @@ func Rebase(logger logging.Logger, cfg config.Config, pack PackClient) *cobra.Co
 		Short:   "Rebase app image with latest run image",
 		Example: "pack rebase buildpacksio/pack",
 		Long: "Rebase allows you to quickly swap out the underlying OS layers (run image) of an app image generated by `pack build` " +
-			"with a newer version of the run image, without re-building the application.",
+			"with a newer version of the run image, without re-building the application.\n\n" +
+			"With --dry-run, the app image is left untouched and pack reports whether a rebase would change it, " +
+			"listing the run image layers that would be removed and added. " +
+			"The command then exits with status 0 if the image is up to date and status 2 if a rebase is needed.\n\n" +
+			"The new run image must match the stack, OS, architecture and mixins of the app image. " +
+			"Rebasing onto a run image that is older than the current one fails unless --force is set. " +
+			"When the current run image is neither in the daemon nor in its registry, its age can't be compared and a warning is shown instead.",
 		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
 			opts.RepoName = args[0]
 			opts.AdditionalMirrors = getMirrors(cfg)
@@ -38,6 +44,18 @@ func Rebase(logger logging.Logger, cfg config.Config, pack PackClient) *cobra.Co
 				return errors.Wrapf(err, "parsing pull policy %s", stringPolicy)
 			}
 
+			if opts.DryRun {
+				report, err := pack.CheckRebase(cmd.Context(), opts)
+				if err != nil {
+					return err
+				}
+
+				if report.RebaseNeeded() {
+					return client.NewSoftError()
+				}
+				return nil
+			}
+
 			if err := pack.Rebase(cmd.Context(), opts); err != nil {
 				return err
 			}
@@ -48,7 +66,9 @@ func Rebase(logger logging.Logger, cfg config.Config, pack PackClient) *cobra.Co
 
 	cmd.Flags().BoolVar(&opts.Publish, "publish", false, "Publish to registry")
 	cmd.Flags().StringVar(&opts.RunImage, "run-image", "", "Run image to use for rebasing")
-	cmd.Flags().StringVar(&policy, "pull-policy", "", "Pull policy to use. Accepted values are always, never, and if-not-present. The default is always")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Report whether a rebase is needed without modifying the image")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Rebase even if the new run image is older than the current run image")
+	cmd.Flags().StringVar(&policy, "pull-policy", "", "Pull policy to use. Accepted values are always, never, if-not-present, hourly, daily and weekly. The default is always")
 
 	AddHelpFlag(cmd, "rebase")
 	return cmd
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/buildpacks/lifecycle/platform"
 	"github.com/heroku/color"
 
 	"github.com/buildpacks/pack/pkg/client"
@@ -116,6 +117,57 @@ func testRebaseCommand(t *testing.T, when spec.G, it spec.S) {
 				})
 			})
 
+			when("--force", func() {
+				it("works", func() {
+					opts.Force = true
+					mockClient.EXPECT().
+						Rebase(gomock.Any(), opts).
+						Return(nil)
+
+					command.SetArgs([]string{repoName, "--force"})
+					h.AssertNil(t, command.Execute())
+				})
+			})
+
+			when("--dry-run", func() {
+				it.Before(func() {
+					opts.DryRun = true
+				})
+
+				when("a rebase is needed", func() {
+					it("reports the change and returns a soft error", func() {
+						mockClient.EXPECT().
+							CheckRebase(gomock.Any(), opts).
+							Return(&client.RebaseReport{
+								RunImage: "test/image",
+								Current:  platform.RunImageMetadata{TopLayer: "old-top-layer", Reference: "old-digest"},
+								Next:     platform.RunImageMetadata{TopLayer: "new-top-layer", Reference: "new-digest"},
+							}, nil)
+
+						command.SetArgs([]string{repoName, "--dry-run"})
+						err := command.Execute()
+						h.AssertError(t, err, "")
+						_, isSoftError := err.(client.SoftError)
+						h.AssertTrue(t, isSoftError)
+					})
+				})
+
+				when("the image is up to date", func() {
+					it("succeeds", func() {
+						mockClient.EXPECT().
+							CheckRebase(gomock.Any(), opts).
+							Return(&client.RebaseReport{
+								RunImage: "test/image",
+								Current:  platform.RunImageMetadata{TopLayer: "top-layer", Reference: "old-digest"},
+								Next:     platform.RunImageMetadata{TopLayer: "top-layer", Reference: "new-digest"},
+							}, nil)
+
+						command.SetArgs([]string{repoName, "--dry-run"})
+						h.AssertNil(t, command.Execute())
+					})
+				})
+			})
+
 			when("--pull-policy unknown-policy", func() {
 				it("fails to run", func() {
 					command.SetArgs([]string{repoName, "--pull-policy", "unknown-policy"})
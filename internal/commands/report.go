@@ -18,7 +18,7 @@ import (
 	"github.com/buildpacks/pack/pkg/logging"
 )
 
-func Report(logger logging.Logger, version, cfgPath string) *cobra.Command {
+func Report(logger logging.Logger, version, cfgPath string, packClient PackClient) *cobra.Command {
 	var explicit bool
 
 	cmd := &cobra.Command{
@@ -28,7 +28,14 @@ func Report(logger logging.Logger, version, cfgPath string) *cobra.Command {
 		Example: "pack report",
 		RunE: logError(logger, func(cmd *cobra.Command, args []string) error {
 			var buf bytes.Buffer
-			err := generateOutput(&buf, version, cfgPath, explicit)
+			containerEngine := "(unable to detect)"
+			if detected, err := packClient.ContainerEngine(cmd.Context()); err != nil {
+				logger.Debugf("Unable to detect the container engine: %s", err)
+			} else {
+				containerEngine = detected.String()
+			}
+
+			err := generateOutput(&buf, version, cfgPath, containerEngine, explicit)
 			if err != nil {
 				return err
 			}
@@ -44,7 +51,7 @@ func Report(logger logging.Logger, version, cfgPath string) *cobra.Command {
 	return cmd
 }
 
-func generateOutput(writer io.Writer, version, cfgPath string, explicit bool) error {
+func generateOutput(writer io.Writer, version, cfgPath, containerEngine string, explicit bool) error {
 	tpl := template.Must(template.New("").Parse(`Pack:
   Version:  {{ .Version }}
   OS/Arch:  {{ .OS }}/{{ .Arch }}
@@ -53,6 +60,8 @@ Default Lifecycle Version:  {{ .DefaultLifecycleVersion }}
 
 Supported Platform APIs:  {{ .SupportedPlatformAPIs }}
 
+Container Engine:  {{ .ContainerEngine }}
+
 Config:
 {{ .Config -}}`))
 
@@ -79,6 +88,7 @@ Config:
 		"Arch":                    runtime.GOARCH,
 		"DefaultLifecycleVersion": builder.DefaultLifecycleVersion,
 		"SupportedPlatformAPIs":   platformAPIs,
+		"ContainerEngine":         containerEngine,
 		"Config":                  configData,
 	})
 }
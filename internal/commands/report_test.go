@@ -8,11 +8,15 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 	"github.com/spf13/cobra"
 
 	"github.com/buildpacks/pack/internal/commands"
+	"github.com/buildpacks/pack/internal/commands/testmocks"
+	"github.com/buildpacks/pack/internal/engine"
 	"github.com/buildpacks/pack/pkg/logging"
 	h "github.com/buildpacks/pack/testhelpers"
 )
@@ -29,18 +33,23 @@ func testReportCommand(t *testing.T, when spec.G, it spec.S) {
 		tempPackHome      string
 		packConfigPath    string
 		tempPackEmptyHome string
+		mockController    *gomock.Controller
+		mockClient        *testmocks.MockPackClient
 		testVersion       = "1.2.3"
 	)
 
 	it.Before(func() {
 		var err error
 		logger = logging.NewLogWithWriters(&outBuf, &outBuf)
+		mockController = gomock.NewController(t)
+		mockClient = testmocks.NewMockPackClient(mockController)
+		mockClient.EXPECT().ContainerEngine(gomock.Any()).Return(engine.Engine{Kind: engine.Podman, Version: "4.2.0", Rootless: true, UserNamespace: true}, nil).AnyTimes()
 
 		tempPackHome, err = ioutil.TempDir("", "pack-home")
 		h.AssertNil(t, err)
 
 		packConfigPath = filepath.Join(tempPackHome, "config.toml")
-		command = commands.Report(logger, testVersion, packConfigPath)
+		command = commands.Report(logger, testVersion, packConfigPath, mockClient)
 		command.SetArgs([]string{})
 		h.AssertNil(t, ioutil.WriteFile(packConfigPath, []byte(`
 default-builder-image = "some/image"
@@ -64,6 +73,7 @@ experimental = true
 	})
 
 	it.After(func() {
+		mockController.Finish()
 		h.AssertNil(t, os.RemoveAll(tempPackHome))
 		h.AssertNil(t, os.RemoveAll(tempPackEmptyHome))
 	})
@@ -110,9 +120,27 @@ experimental = true
 			})
 		})
 
+		it("presents the container engine", func() {
+			h.AssertNil(t, command.Execute())
+			h.AssertContains(t, outBuf.String(), `Container Engine:  Podman 4.2.0 (rootless)`)
+		})
+
+		when("the container engine can't be detected", func() {
+			it("presents output", func() {
+				unreachableClient := testmocks.NewMockPackClient(mockController)
+				unreachableClient.EXPECT().ContainerEngine(gomock.Any()).Return(engine.Engine{}, errors.New("connection refused"))
+
+				command = commands.Report(logger, testVersion, packConfigPath, unreachableClient)
+				command.SetArgs([]string{})
+				h.AssertNil(t, command.Execute())
+				h.AssertContains(t, outBuf.String(), `Container Engine:  (unable to detect)`)
+				h.AssertContains(t, outBuf.String(), `Version:  `+testVersion)
+			})
+		})
+
 		when("config.toml is not present", func() {
 			it("logs a message", func() {
-				command = commands.Report(logger, testVersion, filepath.Join(tempPackEmptyHome, "/config.toml"))
+				command = commands.Report(logger, testVersion, filepath.Join(tempPackEmptyHome, "/config.toml"), mockClient)
 				command.SetArgs([]string{})
 				h.AssertNil(t, command.Execute())
 				h.AssertContains(t, outBuf.String(), fmt.Sprintf("(no config file found at %s)", filepath.Join(tempPackEmptyHome, "config.toml")))
@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 
+	engine "github.com/buildpacks/pack/internal/engine"
 	client "github.com/buildpacks/pack/pkg/client"
 )
 
@@ -50,6 +51,36 @@ func (mr *MockPackClientMockRecorder) Build(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Build", reflect.TypeOf((*MockPackClient)(nil).Build), arg0, arg1)
 }
 
+// CheckRebase mocks base method.
+func (m *MockPackClient) CheckRebase(arg0 context.Context, arg1 client.RebaseOptions) (*client.RebaseReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRebase", arg0, arg1)
+	ret0, _ := ret[0].(*client.RebaseReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckRebase indicates an expected call of CheckRebase.
+func (mr *MockPackClientMockRecorder) CheckRebase(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRebase", reflect.TypeOf((*MockPackClient)(nil).CheckRebase), arg0, arg1)
+}
+
+// ContainerEngine mocks base method.
+func (m *MockPackClient) ContainerEngine(arg0 context.Context) (engine.Engine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContainerEngine", arg0)
+	ret0, _ := ret[0].(engine.Engine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContainerEngine indicates an expected call of ContainerEngine.
+func (mr *MockPackClientMockRecorder) ContainerEngine(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerEngine", reflect.TypeOf((*MockPackClient)(nil).ContainerEngine), arg0)
+}
+
 // CreateBuilder mocks base method.
 func (m *MockPackClient) CreateBuilder(arg0 context.Context, arg1 client.CreateBuilderOptions) error {
 	m.ctrl.T.Helper()
@@ -64,6 +95,50 @@ func (mr *MockPackClientMockRecorder) CreateBuilder(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBuilder", reflect.TypeOf((*MockPackClient)(nil).CreateBuilder), arg0, arg1)
 }
 
+// CreateBundle mocks base method.
+func (m *MockPackClient) CreateBundle(arg0 context.Context, arg1 client.CreateBundleOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBundle", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBundle indicates an expected call of CreateBundle.
+func (mr *MockPackClientMockRecorder) CreateBundle(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBundle", reflect.TypeOf((*MockPackClient)(nil).CreateBundle), arg0, arg1)
+}
+
+// Detect mocks base method.
+func (m *MockPackClient) Detect(arg0 context.Context, arg1 client.DetectOptions) (*client.DetectResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Detect", arg0, arg1)
+	ret0, _ := ret[0].(*client.DetectResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Detect indicates an expected call of Detect.
+func (mr *MockPackClientMockRecorder) Detect(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Detect", reflect.TypeOf((*MockPackClient)(nil).Detect), arg0, arg1)
+}
+
+// DiffBuilders mocks base method.
+func (m *MockPackClient) DiffBuilders(arg0, arg1 string) (*client.BuilderDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffBuilders", arg0, arg1)
+	ret0, _ := ret[0].(*client.BuilderDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffBuilders indicates an expected call of DiffBuilders.
+func (mr *MockPackClientMockRecorder) DiffBuilders(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffBuilders", reflect.TypeOf((*MockPackClient)(nil).DiffBuilders), arg0, arg1)
+}
+
 // DownloadSBOM mocks base method.
 func (m *MockPackClient) DownloadSBOM(arg0 string, arg1 client.DownloadSBOMOptions) error {
 	m.ctrl.T.Helper()
@@ -78,6 +153,20 @@ func (mr *MockPackClientMockRecorder) DownloadSBOM(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadSBOM", reflect.TypeOf((*MockPackClient)(nil).DownloadSBOM), arg0, arg1)
 }
 
+// ExtractBuildpack mocks base method.
+func (m *MockPackClient) ExtractBuildpack(arg0 context.Context, arg1 client.ExtractBuildpackOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtractBuildpack", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExtractBuildpack indicates an expected call of ExtractBuildpack.
+func (mr *MockPackClientMockRecorder) ExtractBuildpack(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractBuildpack", reflect.TypeOf((*MockPackClient)(nil).ExtractBuildpack), arg0, arg1)
+}
+
 // InspectBuilder mocks base method.
 func (m *MockPackClient) InspectBuilder(arg0 string, arg1 bool, arg2 ...client.BuilderInspectionModifier) (*client.BuilderInfo, error) {
 	m.ctrl.T.Helper()
@@ -128,6 +217,20 @@ func (mr *MockPackClientMockRecorder) InspectImage(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectImage", reflect.TypeOf((*MockPackClient)(nil).InspectImage), arg0, arg1)
 }
 
+// LoadBundle mocks base method.
+func (m *MockPackClient) LoadBundle(arg0 context.Context, arg1 client.LoadBundleOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadBundle", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LoadBundle indicates an expected call of LoadBundle.
+func (mr *MockPackClientMockRecorder) LoadBundle(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadBundle", reflect.TypeOf((*MockPackClient)(nil).LoadBundle), arg0, arg1)
+}
+
 // NewBuildpack mocks base method.
 func (m *MockPackClient) NewBuildpack(arg0 context.Context, arg1 client.NewBuildpackOptions) error {
 	m.ctrl.T.Helper()
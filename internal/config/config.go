@@ -12,16 +12,22 @@ import (
 
 type Config struct {
 	// Deprecated: Use DefaultRegistryName instead. See https://github.com/buildpacks/pack/issues/747.
-	DefaultRegistry     string            `toml:"default-registry-url,omitempty"`
-	DefaultRegistryName string            `toml:"default-registry,omitempty"`
-	DefaultBuilder      string            `toml:"default-builder-image,omitempty"`
-	PullPolicy          string            `toml:"pull-policy,omitempty"`
-	Experimental        bool              `toml:"experimental,omitempty"`
-	RunImages           []RunImage        `toml:"run-images"`
-	TrustedBuilders     []TrustedBuilder  `toml:"trusted-builders,omitempty"`
-	Registries          []Registry        `toml:"registries,omitempty"`
-	LifecycleImage      string            `toml:"lifecycle-image,omitempty"`
-	RegistryMirrors     map[string]string `toml:"registry-mirrors,omitempty"`
+	DefaultRegistry     string               `toml:"default-registry-url,omitempty"`
+	DefaultRegistryName string               `toml:"default-registry,omitempty"`
+	DefaultBuilder      string               `toml:"default-builder-image,omitempty"`
+	PullPolicy          string               `toml:"pull-policy,omitempty"`
+	Experimental        bool                 `toml:"experimental,omitempty"`
+	RunImages           []RunImage           `toml:"run-images"`
+	TrustedBuilders     []TrustedBuilder     `toml:"trusted-builders,omitempty"`
+	Registries          []Registry           `toml:"registries,omitempty"`
+	LifecycleImage      string               `toml:"lifecycle-image,omitempty"`
+	LifecycleMirror     string               `toml:"lifecycle-mirror,omitempty"`
+	RegistryMirrors     map[string]string    `toml:"registry-mirrors,omitempty"`
+	RegistryCredentials []RegistryCredential `toml:"registry-credentials,omitempty"`
+	DownloadCredentials []DownloadCredential `toml:"download-credentials,omitempty"`
+	DownloadCACerts     string               `toml:"download-ca-certs,omitempty"`
+	Offline             bool                 `toml:"offline,omitempty"`
+	FetchConcurrency    int                  `toml:"fetch-concurrency,omitempty"`
 }
 
 type Registry struct {
@@ -30,6 +36,31 @@ type Registry struct {
 	URL  string `toml:"url"`
 }
 
+// RegistryCredential configures how pack authenticates to a registry.
+// Secrets are never stored in the config, only the environment variable or file they are read from.
+type RegistryCredential struct {
+	Registry          string `toml:"registry"`
+	Username          string `toml:"username,omitempty"`
+	PasswordEnv       string `toml:"password-env,omitempty"`
+	PasswordFile      string `toml:"password-file,omitempty"`
+	TokenEnv          string `toml:"token-env,omitempty"`
+	TokenFile         string `toml:"token-file,omitempty"`
+	IdentityTokenEnv  string `toml:"identity-token-env,omitempty"`
+	IdentityTokenFile string `toml:"identity-token-file,omitempty"`
+	Helper            string `toml:"helper,omitempty"`
+}
+
+// DownloadCredential configures how pack authenticates to a host that buildpacks and lifecycles are downloaded from.
+// Secrets are never stored in the config, only the environment variable or file they are read from.
+type DownloadCredential struct {
+	Host         string `toml:"host"`
+	Username     string `toml:"username,omitempty"`
+	PasswordEnv  string `toml:"password-env,omitempty"`
+	PasswordFile string `toml:"password-file,omitempty"`
+	TokenEnv     string `toml:"token-env,omitempty"`
+	TokenFile    string `toml:"token-file,omitempty"`
+}
+
 type RunImage struct {
 	Image   string   `toml:"image"`
 	Mirrors []string `toml:"mirrors"`
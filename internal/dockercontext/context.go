@@ -0,0 +1,149 @@
+// Package dockercontext reads the contexts of the Docker CLI, which select the docker daemon to use.
+package dockercontext
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/pkg/homedir"
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/style"
+)
+
+// DefaultContextName is the name of the context that uses the DOCKER_HOST environment variable, or the default socket
+const DefaultContextName = "default"
+
+const dockerEndpointName = "docker"
+
+// Endpoint is the docker endpoint of a context
+type Endpoint struct {
+	// Context is the name of the context of the endpoint
+	Context string
+
+	// Host is the address of the docker daemon, e.g. unix:///var/run/docker.sock, tcp://example.com:2376 or ssh://user@example.com
+	Host string
+
+	// SkipTLSVerify disables the verification of the certificate of the docker daemon
+	SkipTLSVerify bool
+
+	// CACert, Cert and Key are the paths of the TLS material of the endpoint, they're empty if the context has none
+	CACert string
+	Cert   string
+	Key    string
+}
+
+// Resolve returns the endpoint of the context to use, see Current.
+// ok is false if it's the default context, whose daemon is selected by the DOCKER_HOST environment variable.
+func Resolve(name string) (endpoint Endpoint, ok bool, err error) {
+	name, err = Current(name)
+	if err != nil {
+		return Endpoint{}, false, err
+	}
+	if name == DefaultContextName {
+		return Endpoint{}, false, nil
+	}
+
+	endpoint, err = Load(name)
+	if err != nil {
+		return Endpoint{}, false, err
+	}
+	return endpoint, true, nil
+}
+
+// Current returns the name of the context to use, in order of precedence: name if it isn't empty, the default
+// context if DOCKER_HOST is set, DOCKER_CONTEXT, and the current context of the Docker CLI config. As with the
+// Docker CLI, DOCKER_HOST takes precedence over DOCKER_CONTEXT.
+func Current(name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	if os.Getenv("DOCKER_HOST") != "" {
+		return DefaultContextName, nil
+	}
+	if name := os.Getenv("DOCKER_CONTEXT"); name != "" {
+		return name, nil
+	}
+
+	contents, err := os.ReadFile(filepath.Join(configDir(), "config.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultContextName, nil
+		}
+		return "", errors.Wrap(err, "reading docker config")
+	}
+
+	var config struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if err := json.Unmarshal(contents, &config); err != nil {
+		return "", errors.Wrap(err, "parsing docker config")
+	}
+	if config.CurrentContext == "" {
+		return DefaultContextName, nil
+	}
+	return config.CurrentContext, nil
+}
+
+// Load returns the docker endpoint of the context name
+func Load(name string) (Endpoint, error) {
+	id := contextID(name)
+	contents, err := os.ReadFile(filepath.Join(configDir(), "contexts", "meta", id, "meta.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Endpoint{}, errors.Errorf("docker context %s doesn't exist", style.Symbol(name))
+		}
+		return Endpoint{}, errors.Wrapf(err, "reading docker context %s", style.Symbol(name))
+	}
+
+	var meta struct {
+		Endpoints map[string]struct {
+			Host          string
+			SkipTLSVerify bool
+		}
+	}
+	if err := json.Unmarshal(contents, &meta); err != nil {
+		return Endpoint{}, errors.Wrapf(err, "parsing docker context %s", style.Symbol(name))
+	}
+
+	dockerEndpoint, ok := meta.Endpoints[dockerEndpointName]
+	if !ok || dockerEndpoint.Host == "" {
+		return Endpoint{}, errors.Errorf("docker context %s has no docker endpoint", style.Symbol(name))
+	}
+
+	endpoint := Endpoint{
+		Context:       name,
+		Host:          dockerEndpoint.Host,
+		SkipTLSVerify: dockerEndpoint.SkipTLSVerify,
+	}
+
+	tlsDir := filepath.Join(configDir(), "contexts", "tls", id, dockerEndpointName)
+	for file, path := range map[string]*string{"ca.pem": &endpoint.CACert, "cert.pem": &endpoint.Cert, "key.pem": &endpoint.Key} {
+		if _, err := os.Stat(filepath.Join(tlsDir, file)); err == nil {
+			*path = filepath.Join(tlsDir, file)
+		}
+	}
+	return endpoint, nil
+}
+
+// HasTLS reports whether the endpoint has TLS material or skips TLS verification
+func (e Endpoint) HasTLS() bool {
+	return e.CACert != "" || e.Cert != "" || e.Key != "" || e.SkipTLSVerify
+}
+
+// the directory of the Docker CLI config, DOCKER_CONFIG or ~/.docker
+func configDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	return filepath.Join(homedir.Get(), ".docker")
+}
+
+// the Docker CLI stores contexts in directories named after the digest of their name
+func contextID(name string) string {
+	digest := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,190 @@
+package dockercontext_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpacks/pack/internal/dockercontext"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestDockerContext(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "DockerContext", testDockerContext, spec.Sequential(), spec.Report(report.Terminal{}))
+}
+
+// this test cannot be parallelized as it uses process wide environment variables
+func testDockerContext(t *testing.T, when spec.G, it spec.S) {
+	var (
+		configDir string
+		env       = map[string]string{}
+	)
+
+	setEnv := func(key, val string) {
+		if _, ok := env[key]; !ok {
+			env[key] = os.Getenv(key)
+		}
+		h.AssertNil(t, os.Setenv(key, val))
+	}
+
+	writeFile := func(path, contents string) {
+		h.AssertNil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		h.AssertNil(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	}
+
+	contextDir := func(kind, name string) string {
+		digest := sha256.Sum256([]byte(name))
+		return filepath.Join(configDir, "contexts", kind, hex.EncodeToString(digest[:]))
+	}
+
+	it.Before(func() {
+		var err error
+		configDir, err = ioutil.TempDir("", "docker-config")
+		h.AssertNil(t, err)
+
+		setEnv("DOCKER_CONFIG", configDir)
+		setEnv("DOCKER_CONTEXT", "")
+		setEnv("DOCKER_HOST", "")
+
+		writeFile(filepath.Join(contextDir("meta", "some-context"), "meta.json"),
+			`{"Name":"some-context","Metadata":{},"Endpoints":{"docker":{"Host":"unix:///some/docker.sock","SkipTLSVerify":false}}}`)
+		writeFile(filepath.Join(contextDir("meta", "tls-context"), "meta.json"),
+			`{"Name":"tls-context","Metadata":{},"Endpoints":{"docker":{"Host":"tcp://example.com:2376","SkipTLSVerify":true}}}`)
+		for _, file := range []string{"ca.pem", "cert.pem", "key.pem"} {
+			writeFile(filepath.Join(contextDir("tls", "tls-context"), "docker", file), "some-pem")
+		}
+	})
+
+	it.After(func() {
+		for key, val := range env {
+			h.AssertNil(t, os.Setenv(key, val))
+		}
+		h.AssertNil(t, os.RemoveAll(configDir))
+	})
+
+	when("#Current", func() {
+		it("prefers the name given", func() {
+			setEnv("DOCKER_CONTEXT", "env-context")
+			name, err := dockercontext.Current("some-context")
+			h.AssertNil(t, err)
+			h.AssertEq(t, name, "some-context")
+		})
+
+		it("uses DOCKER_CONTEXT", func() {
+			writeFile(filepath.Join(configDir, "config.json"), `{"currentContext":"some-context"}`)
+			setEnv("DOCKER_CONTEXT", "env-context")
+			name, err := dockercontext.Current("")
+			h.AssertNil(t, err)
+			h.AssertEq(t, name, "env-context")
+		})
+
+		it("prefers DOCKER_HOST to DOCKER_CONTEXT", func() {
+			setEnv("DOCKER_CONTEXT", "env-context")
+			setEnv("DOCKER_HOST", "tcp://example.com:2375")
+			name, err := dockercontext.Current("")
+			h.AssertNil(t, err)
+			h.AssertEq(t, name, dockercontext.DefaultContextName)
+		})
+
+		it("uses the default context when DOCKER_HOST is set", func() {
+			writeFile(filepath.Join(configDir, "config.json"), `{"currentContext":"some-context"}`)
+			setEnv("DOCKER_HOST", "tcp://example.com:2375")
+			name, err := dockercontext.Current("")
+			h.AssertNil(t, err)
+			h.AssertEq(t, name, dockercontext.DefaultContextName)
+		})
+
+		it("uses the current context of the docker config", func() {
+			writeFile(filepath.Join(configDir, "config.json"), `{"currentContext":"some-context"}`)
+			name, err := dockercontext.Current("")
+			h.AssertNil(t, err)
+			h.AssertEq(t, name, "some-context")
+		})
+
+		it("uses the default context when there's no docker config", func() {
+			name, err := dockercontext.Current("")
+			h.AssertNil(t, err)
+			h.AssertEq(t, name, dockercontext.DefaultContextName)
+		})
+
+		when("the docker config is invalid", func() {
+			it("returns an error", func() {
+				writeFile(filepath.Join(configDir, "config.json"), `{`)
+				_, err := dockercontext.Current("")
+				h.AssertError(t, err, "parsing docker config")
+			})
+		})
+	})
+
+	when("#Load", func() {
+		it("returns the docker endpoint of the context", func() {
+			endpoint, err := dockercontext.Load("some-context")
+			h.AssertNil(t, err)
+			h.AssertEq(t, endpoint, dockercontext.Endpoint{Context: "some-context", Host: "unix:///some/docker.sock"})
+			h.AssertEq(t, endpoint.HasTLS(), false)
+		})
+
+		it("returns the TLS material of the context", func() {
+			endpoint, err := dockercontext.Load("tls-context")
+			h.AssertNil(t, err)
+			tlsDir := filepath.Join(contextDir("tls", "tls-context"), "docker")
+			h.AssertEq(t, endpoint, dockercontext.Endpoint{
+				Context:       "tls-context",
+				Host:          "tcp://example.com:2376",
+				SkipTLSVerify: true,
+				CACert:        filepath.Join(tlsDir, "ca.pem"),
+				Cert:          filepath.Join(tlsDir, "cert.pem"),
+				Key:           filepath.Join(tlsDir, "key.pem"),
+			})
+			h.AssertEq(t, endpoint.HasTLS(), true)
+		})
+
+		when("the context doesn't exist", func() {
+			it("returns an error", func() {
+				_, err := dockercontext.Load("missing-context")
+				h.AssertError(t, err, "docker context 'missing-context' doesn't exist")
+			})
+		})
+
+		when("the context has no docker endpoint", func() {
+			it("returns an error", func() {
+				writeFile(filepath.Join(contextDir("meta", "k8s-context"), "meta.json"),
+					`{"Name":"k8s-context","Metadata":{},"Endpoints":{"kubernetes":{"Host":"https://example.com"}}}`)
+				_, err := dockercontext.Load("k8s-context")
+				h.AssertError(t, err, "docker context 'k8s-context' has no docker endpoint")
+			})
+		})
+	})
+
+	when("#Resolve", func() {
+		it("returns the endpoint of the current context", func() {
+			writeFile(filepath.Join(configDir, "config.json"), `{"currentContext":"some-context"}`)
+			endpoint, ok, err := dockercontext.Resolve("")
+			h.AssertNil(t, err)
+			h.AssertEq(t, ok, true)
+			h.AssertEq(t, endpoint.Host, "unix:///some/docker.sock")
+		})
+
+		it("returns no endpoint for the default context", func() {
+			_, ok, err := dockercontext.Resolve(dockercontext.DefaultContextName)
+			h.AssertNil(t, err)
+			h.AssertEq(t, ok, false)
+		})
+
+		when("the context doesn't exist", func() {
+			it("returns an error", func() {
+				_, _, err := dockercontext.Resolve("missing-context")
+				h.AssertError(t, err, "docker context 'missing-context' doesn't exist")
+			})
+		})
+	})
+}
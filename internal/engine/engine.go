@@ -0,0 +1,138 @@
+// Package engine detects the container engine behind the docker API, e.g. Docker or Podman, and how it's run.
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+// Kind is the product of a container engine
+type Kind string
+
+const (
+	// Docker is the Docker Engine, or an engine that doesn't identify itself
+	Docker Kind = "Docker"
+
+	// Podman is Podman through its docker compatible API
+	Podman Kind = "Podman"
+)
+
+const podmanComponentName = "Podman Engine"
+
+// Client is the part of the docker client used to detect the engine
+type Client interface {
+	Info(ctx context.Context) (types.Info, error)
+	ServerVersion(ctx context.Context) (types.Version, error)
+	DaemonHost() string
+}
+
+// Engine describes the container engine pack is connected to
+type Engine struct {
+	// Kind is the product of the engine, it's empty if the engine hasn't been detected
+	Kind Kind
+
+	// Version is the version of the engine
+	Version string
+
+	// Rootless is true if the engine runs as an unprivileged user
+	Rootless bool
+
+	// UserNamespace is true if containers run in a user namespace, i.e. the engine is rootless or remaps users
+	UserNamespace bool
+
+	// SELinux is true if the engine labels containers for SELinux
+	SELinux bool
+
+	// Socket is the path of the unix socket the engine is reached at on its host, it's empty for other transports
+	Socket string
+}
+
+// Detect asks the engine behind c what it is and how it's run
+func Detect(ctx context.Context, c Client) (Engine, error) {
+	version, err := c.ServerVersion(ctx)
+	if err != nil {
+		return Engine{}, errors.Wrap(err, "getting engine version")
+	}
+
+	info, err := c.Info(ctx)
+	if err != nil {
+		return Engine{}, errors.Wrap(err, "getting engine info")
+	}
+
+	engine := Engine{
+		Kind:    Docker,
+		Version: version.Version,
+	}
+	for _, component := range version.Components {
+		if component.Name == podmanComponentName {
+			engine.Kind = Podman
+			engine.Version = component.Version
+		}
+	}
+
+	for _, opt := range info.SecurityOptions {
+		switch securityOptionName(opt) {
+		case "rootless":
+			engine.Rootless = true
+			engine.UserNamespace = true
+		case "userns":
+			engine.UserNamespace = true
+		case "selinux":
+			engine.SELinux = true
+		}
+	}
+
+	if host := c.DaemonHost(); strings.HasPrefix(host, "unix://") {
+		engine.Socket = strings.TrimPrefix(host, "unix://")
+	}
+
+	return engine, nil
+}
+
+// Detected reports whether the engine has been detected
+func (e Engine) Detected() bool {
+	return e.Kind != ""
+}
+
+// IsPodman reports whether the engine is Podman
+func (e Engine) IsPodman() bool {
+	return e.Kind == Podman
+}
+
+// String describes the engine, e.g. "Podman 4.2.0 (rootless, selinux)"
+func (e Engine) String() string {
+	if !e.Detected() {
+		return "unknown"
+	}
+
+	description := strings.TrimSpace(fmt.Sprintf("%s %s", e.Kind, e.Version))
+
+	var traits []string
+	switch {
+	case e.Rootless:
+		traits = append(traits, "rootless")
+	case e.UserNamespace:
+		traits = append(traits, "userns")
+	default:
+		traits = append(traits, "rootful")
+	}
+	if e.SELinux {
+		traits = append(traits, "selinux")
+	}
+
+	return fmt.Sprintf("%s (%s)", description, strings.Join(traits, ", "))
+}
+
+// security options are reported as comma separated key=value pairs, e.g. "name=seccomp,profile=default"
+func securityOptionName(opt string) string {
+	for _, field := range strings.Split(opt, ",") {
+		if kv := strings.SplitN(field, "=", 2); len(kv) == 2 && kv[0] == "name" {
+			return kv[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpacks/pack/internal/engine"
+	"github.com/buildpacks/pack/pkg/testmocks"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestEngine(t *testing.T) {
+	spec.Run(t, "Engine", testEngine, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testEngine(t *testing.T, when spec.G, it spec.S) {
+	var (
+		mockController *gomock.Controller
+		mockDocker     *testmocks.MockCommonAPIClient
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockDocker = testmocks.NewMockCommonAPIClient(mockController)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#Detect", func() {
+		it("detects a rootful docker engine", func() {
+			mockDocker.EXPECT().ServerVersion(gomock.Any()).Return(types.Version{
+				Version:    "20.10.14",
+				Components: []types.ComponentVersion{{Name: "Engine", Version: "20.10.14"}},
+			}, nil)
+			mockDocker.EXPECT().Info(gomock.Any()).Return(types.Info{
+				SecurityOptions: []string{"name=apparmor", "name=seccomp,profile=default"},
+			}, nil)
+			mockDocker.EXPECT().DaemonHost().Return("unix:///var/run/docker.sock")
+
+			detected, err := engine.Detect(context.TODO(), mockDocker)
+			h.AssertNil(t, err)
+			h.AssertEq(t, detected, engine.Engine{
+				Kind:    engine.Docker,
+				Version: "20.10.14",
+				Socket:  "/var/run/docker.sock",
+			})
+			h.AssertEq(t, detected.String(), "Docker 20.10.14 (rootful)")
+		})
+
+		it("detects a rootless podman engine with selinux", func() {
+			mockDocker.EXPECT().ServerVersion(gomock.Any()).Return(types.Version{
+				Version:    "4.2.0",
+				Components: []types.ComponentVersion{{Name: "Podman Engine", Version: "4.2.0"}},
+			}, nil)
+			mockDocker.EXPECT().Info(gomock.Any()).Return(types.Info{
+				SecurityOptions: []string{"name=seccomp,profile=default", "name=rootless", "name=selinux"},
+			}, nil)
+			mockDocker.EXPECT().DaemonHost().Return("unix:///run/user/1000/podman/podman.sock")
+
+			detected, err := engine.Detect(context.TODO(), mockDocker)
+			h.AssertNil(t, err)
+			h.AssertEq(t, detected, engine.Engine{
+				Kind:          engine.Podman,
+				Version:       "4.2.0",
+				Rootless:      true,
+				UserNamespace: true,
+				SELinux:       true,
+				Socket:        "/run/user/1000/podman/podman.sock",
+			})
+			h.AssertEq(t, detected.IsPodman(), true)
+			h.AssertEq(t, detected.String(), "Podman 4.2.0 (rootless, selinux)")
+		})
+
+		it("detects a docker engine remapping users", func() {
+			mockDocker.EXPECT().ServerVersion(gomock.Any()).Return(types.Version{Version: "20.10.14"}, nil)
+			mockDocker.EXPECT().Info(gomock.Any()).Return(types.Info{
+				SecurityOptions: []string{"name=userns"},
+			}, nil)
+			mockDocker.EXPECT().DaemonHost().Return("tcp://example.com:2376")
+
+			detected, err := engine.Detect(context.TODO(), mockDocker)
+			h.AssertNil(t, err)
+			h.AssertEq(t, detected.UserNamespace, true)
+			h.AssertEq(t, detected.Rootless, false)
+			h.AssertEq(t, detected.Socket, "")
+			h.AssertEq(t, detected.String(), "Docker 20.10.14 (userns)")
+		})
+
+		when("the engine can't be reached", func() {
+			it("returns an error", func() {
+				mockDocker.EXPECT().ServerVersion(gomock.Any()).Return(types.Version{}, errors.New("connection refused"))
+
+				_, err := engine.Detect(context.TODO(), mockDocker)
+				h.AssertError(t, err, "getting engine version: connection refused")
+			})
+		})
+	})
+
+	when("#String", func() {
+		it("describes an undetected engine", func() {
+			h.AssertEq(t, engine.Engine{}.String(), "unknown")
+		})
+	})
+}
@@ -2,6 +2,7 @@ package fakes
 
 import (
 	"context"
+	"sync"
 
 	"github.com/buildpacks/imgutil"
 	"github.com/pkg/errors"
@@ -19,6 +20,8 @@ type FakeImageFetcher struct {
 	LocalImages  map[string]imgutil.Image
 	RemoteImages map[string]imgutil.Image
 	FetchCalls   map[string]*FetchArgs
+
+	mu sync.Mutex
 }
 
 func NewFakeImageFetcher() *FakeImageFetcher {
@@ -30,6 +33,9 @@ func NewFakeImageFetcher() *FakeImageFetcher {
 }
 
 func (f *FakeImageFetcher) Fetch(ctx context.Context, name string, options image.FetchOptions) (imgutil.Image, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
 	f.FetchCalls[name] = &FetchArgs{Daemon: options.Daemon, PullPolicy: options.PullPolicy, Platform: options.Platform}
 
 	ri, remoteFound := f.RemoteImages[name]
@@ -55,7 +61,7 @@ func (f *FakeImageFetcher) Fetch(ctx context.Context, name string, options image
 }
 
 func shouldPull(localFound, remoteFound bool, policy image.PullPolicy) bool {
-	if remoteFound && !localFound && policy == image.PullIfNotPresent {
+	if remoteFound && !localFound && (policy == image.PullIfNotPresent || policy.Interval() > 0) {
 		return true
 	}
 
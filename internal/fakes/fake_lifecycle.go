@@ -8,9 +8,19 @@ import (
 
 type FakeLifecycle struct {
 	Opts build.LifecycleOptions
+
+	DetectCalled       bool
+	ReturnForDetect    build.DetectResult
+	ReturnForDetectErr error
 }
 
 func (f *FakeLifecycle) Execute(ctx context.Context, opts build.LifecycleOptions) error {
 	f.Opts = opts
 	return nil
 }
+
+func (f *FakeLifecycle) Detect(ctx context.Context, opts build.LifecycleOptions) (build.DetectResult, error) {
+	f.Opts = opts
+	f.DetectCalled = true
+	return f.ReturnForDetect, f.ReturnForDetectErr
+}
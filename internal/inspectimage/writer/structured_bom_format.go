@@ -39,8 +39,11 @@ func (w *StructuredBOMFormat) Print(
 		return err
 	}
 
-	_, err = logger.Writer().Write(out)
-	return err
+	writer := logger.Writer()
+	if _, err = writer.Write(out); err != nil {
+		return err
+	}
+	return logging.FlushWriter(writer)
 }
 
 func errorString(err error) string {
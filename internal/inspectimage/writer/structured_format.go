@@ -44,6 +44,9 @@ func (w *StructuredFormat) Print(
 		panic(err)
 	}
 
-	_, err = logger.Writer().Write(out)
-	return err
+	writer := logger.Writer()
+	if _, err = writer.Write(out); err != nil {
+		return err
+	}
+	return logging.FlushWriter(writer)
 }
@@ -33,6 +33,8 @@ type Cache struct {
 	url         *url.URL
 	Root        string
 	RegistryDir string
+	// Offline makes the cache locate buildpacks as of its last refresh, without accessing the network
+	Offline bool
 }
 
 const GithubIssueTitleTemplate = "{{ if .Yanked }}YANK{{ else }}ADD{{ end }} {{.Namespace}}/{{.Name}}@{{.Version}}"
@@ -77,8 +79,11 @@ func NewRegistryCache(logger logging.Logger, home, registryURL string) (Cache, e
 
 // LocateBuildpack stored in registry
 func (r *Cache) LocateBuildpack(bp string) (Buildpack, error) {
-	err := r.Refresh()
-	if err != nil {
+	if r.Offline {
+		if _, err := git.PlainOpen(r.Root); err != nil {
+			return Buildpack{}, errors.Wrapf(err, "registry cache for %s isn't available and can't be created in offline mode", style.Symbol(r.url.String()))
+		}
+	} else if err := r.Refresh(); err != nil {
 		return Buildpack{}, errors.Wrap(err, "refreshing cache")
 	}
 
@@ -144,6 +144,39 @@ func testRegistryCache(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 
+	when("#LocateBuildpack offline", func() {
+		var (
+			registryCache Cache
+		)
+
+		it.Before(func() {
+			registryCache, err = NewRegistryCache(logger, tmpDir, registryFixture)
+			h.AssertNil(t, err)
+			registryCache.Offline = true
+		})
+
+		when("the cache was refreshed before", func() {
+			it("locates a buildpack without refreshing the cache", func() {
+				h.AssertNil(t, registryCache.Refresh())
+				h.AssertNil(t, os.RemoveAll(registryFixture))
+
+				bp, err := registryCache.LocateBuildpack("example/foo@1.1.0")
+				h.AssertNil(t, err)
+				h.AssertEq(t, bp.Version, "1.1.0")
+			})
+		})
+
+		when("there is no cache", func() {
+			it("returns an error", func() {
+				_, err := registryCache.LocateBuildpack("example/foo")
+				h.AssertError(t, err, "isn't available and can't be created in offline mode")
+
+				_, err = os.Stat(registryCache.Root)
+				h.AssertEq(t, os.IsNotExist(err), true)
+			})
+		})
+	})
+
 	when("#Refresh", func() {
 		var (
 			registryCache Cache
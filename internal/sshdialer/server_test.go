@@ -32,6 +32,9 @@ type SSHServer struct {
 	portIPv6       int
 	hasDialStdio   bool
 	isWin          bool
+	conns          []*ssh.ServerConn
+	connCount      int
+	jumpCount      int
 }
 
 func (s *SSHServer) SetIsWindows(v bool) {
@@ -70,6 +73,37 @@ func (s *SSHServer) SetHasDialStdio(v bool) {
 	s.hasDialStdio = v
 }
 
+// ConnectionCount returns the number of ssh connections made to the server
+func (s *SSHServer) ConnectionCount() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.connCount
+}
+
+// JumpCount returns the number of connections made through the server to the server itself
+func (s *SSHServer) JumpCount() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.jumpCount
+}
+
+// DropConnections closes the ssh connections made to the server, as if the network was lost
+func (s *SSHServer) DropConnections() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func (s *SSHServer) addConnection(conn *ssh.ServerConn) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.conns = append(s.conns, conn)
+	s.connCount++
+}
+
 const dockerUnixSocket = "/home/testuser/test.sock"
 const dockerTCPSocket = "localhost:1234"
 
@@ -286,6 +320,7 @@ func (s *SSHServer) handleConnection(ctx context.Context, conn net.Conn) error {
 	if err != nil {
 		return err
 	}
+	s.addConnection(sshConn)
 
 	go func() {
 		<-ctx.Done()
@@ -300,7 +335,12 @@ func (s *SSHServer) handleConnection(ctx context.Context, conn net.Conn) error {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		ssh.DiscardRequests(reqs)
+		for req := range reqs {
+			// replies to keepalive requests like sshd does
+			if req.WantReply {
+				_ = req.Reply(req.Type == "keepalive@openssh.com", nil)
+			}
+		}
 	}()
 
 	for newChannel := range newChannels {
@@ -499,6 +539,10 @@ func (s *SSHServer) handleTunnel(newChannel ssh.NewChannel) {
 		}
 
 		hostPort := fmt.Sprintf("%s:%d", tcpExtraData.HostLocal, tcpExtraData.PortLocal)
+		if hostPort == net.JoinHostPort(s.hostIPv4, strconv.Itoa(s.portIPv4)) {
+			s.handleJump(newChannel, hostPort)
+			return
+		}
 		if hostPort != dockerTCPSocket {
 			err = newChannel.Reject(ssh.ConnectionFailed, fmt.Sprintf("bad socket: '%s:%d'", tcpExtraData.HostLocal, tcpExtraData.PortLocal))
 			if err != nil {
@@ -526,6 +570,33 @@ func (s *SSHServer) handleTunnel(newChannel ssh.NewChannel) {
 	<-conn.closed
 }
 
+// handleJump connects the channel to the server itself, like a jump host does (ssh -J)
+func (s *SSHServer) handleJump(newChannel ssh.NewChannel, hostPort string) {
+	conn, err := net.Dial("tcp", hostPort)
+	if err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	defer conn.Close()
+
+	ch, _, err := newChannel.Accept()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "err: %v\n", err)
+		return
+	}
+	defer ch.Close()
+
+	s.lock.Lock()
+	s.jumpCount++
+	s.lock.Unlock()
+
+	go func() {
+		_, _ = io.Copy(conn, ch)
+		conn.Close()
+	}()
+	_, _ = io.Copy(ch, conn)
+}
+
 type listener struct {
 	conns  chan net.Conn
 	closed chan struct{}
@@ -0,0 +1,194 @@
+package sshdialer
+
+import (
+	"fmt"
+	"net"
+	urlPkg "net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/pkg/homedir"
+	"github.com/kevinburke/ssh_config"
+)
+
+// hostConfig is the configuration used to connect to a host, resolved from the URL and the ssh_config file
+type hostConfig struct {
+	alias                 string
+	hostName              string
+	port                  string
+	user                  string
+	password              string
+	identityFiles         []string
+	knownHostsFiles       []string
+	strictHostKeyChecking string
+	// jumps are the hosts connected through, in order, to reach the host (ProxyJump)
+	jumps []hostConfig
+}
+
+func (h hostConfig) addr() string {
+	return net.JoinHostPort(h.hostName, h.port)
+}
+
+// reads the ssh_config file at path, or ~/.ssh/config if path is empty
+// a missing ~/.ssh/config is treated as an empty config
+func readSSHConfig(path string) (*ssh_config.Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = filepath.Join(homedir.Get(), ".ssh", "config")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return &ssh_config.Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read ssh config: %w", err)
+	}
+	defer f.Close()
+
+	cfg, err := ssh_config.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ssh config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// resolves the configuration of the host in url, including the hosts to jump through to reach it
+func resolveHostConfig(sshConfig *ssh_config.Config, url *urlPkg.URL) (hostConfig, error) {
+	host, err := resolveHost(sshConfig, url.Hostname(), url.User.Username(), url.Port())
+	if err != nil {
+		return hostConfig{}, err
+	}
+	host.password, _ = url.User.Password()
+
+	proxyJump, err := sshConfigValue(sshConfig, host.alias, "ProxyJump")
+	if err != nil {
+		return hostConfig{}, err
+	}
+	if proxyJump == "" || strings.EqualFold(proxyJump, "none") {
+		return host, nil
+	}
+
+	// hops are reached in order, the ProxyJump of a hop itself isn't followed
+	for _, hop := range strings.Split(proxyJump, ",") {
+		hopURL, err := urlPkg.Parse("ssh://" + strings.TrimPrefix(strings.TrimSpace(hop), "ssh://"))
+		if err != nil {
+			return hostConfig{}, fmt.Errorf("invalid ProxyJump %q: %w", hop, err)
+		}
+		jump, err := resolveHost(sshConfig, hopURL.Hostname(), hopURL.User.Username(), hopURL.Port())
+		if err != nil {
+			return hostConfig{}, err
+		}
+		host.jumps = append(host.jumps, jump)
+	}
+	return host, nil
+}
+
+// resolves the configuration of a single host, the user and port given take precedence over the ssh_config file
+func resolveHost(sshConfig *ssh_config.Config, alias, username, port string) (hostConfig, error) {
+	host := hostConfig{alias: alias, user: username, port: port}
+
+	var err error
+	values := map[string]*string{
+		"HostName":              &host.hostName,
+		"Port":                  &host.port,
+		"User":                  &host.user,
+		"StrictHostKeyChecking": &host.strictHostKeyChecking,
+	}
+	for key, val := range values {
+		if *val != "" {
+			continue
+		}
+		if *val, err = sshConfigValue(sshConfig, alias, key); err != nil {
+			return hostConfig{}, err
+		}
+	}
+
+	if host.hostName == "" {
+		host.hostName = alias
+	}
+	host.hostName = strings.ReplaceAll(host.hostName, "%h", alias)
+	if host.port == "" {
+		host.port = defaultSSHPort
+	}
+	if host.user == "" {
+		host.user = localUsername()
+	}
+	host.strictHostKeyChecking = strings.ToLower(host.strictHostKeyChecking)
+
+	for _, identityFile := range sshConfigValues(sshConfig, alias, "IdentityFile") {
+		host.identityFiles = append(host.identityFiles, expandTokens(identityFile, host))
+	}
+
+	knownHostsFiles, err := sshConfigValue(sshConfig, alias, "UserKnownHostsFile")
+	if err != nil {
+		return hostConfig{}, err
+	}
+	if knownHostsFiles == "" {
+		host.knownHostsFiles = []string{filepath.Join(homedir.Get(), ".ssh", "known_hosts")}
+	} else if !strings.EqualFold(knownHostsFiles, "none") {
+		for _, knownHostsFile := range strings.Fields(knownHostsFiles) {
+			host.knownHostsFiles = append(host.knownHostsFiles, expandTokens(knownHostsFile, host))
+		}
+	}
+
+	return host, nil
+}
+
+// returns the first value of key for the host alias, like ssh does
+func sshConfigValue(sshConfig *ssh_config.Config, alias, key string) (string, error) {
+	val, err := sshConfig.Get(alias, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from ssh config: %w", key, err)
+	}
+	return val, nil
+}
+
+// returns all the values of key for the host alias, for keys that accumulate, e.g. IdentityFile
+func sshConfigValues(sshConfig *ssh_config.Config, alias, key string) []string {
+	var vals []string
+	for _, host := range sshConfig.Hosts {
+		if !host.Matches(alias) {
+			continue
+		}
+		for _, node := range host.Nodes {
+			switch n := node.(type) {
+			case *ssh_config.KV:
+				if strings.EqualFold(n.Key, key) {
+					vals = append(vals, n.Value)
+				}
+			case *ssh_config.Include:
+				if val := n.Get(alias, key); val != "" {
+					vals = append(vals, val)
+				}
+			}
+		}
+	}
+	return vals
+}
+
+// expands '~' and the %d, %h, %p, %r and %u tokens of path
+func expandTokens(path string, host hostConfig) string {
+	home := homedir.Get()
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		path = home + path[1:]
+	}
+	return strings.NewReplacer(
+		"%%", "%",
+		"%d", home,
+		"%h", host.hostName,
+		"%p", host.port,
+		"%r", host.user,
+		"%u", localUsername(),
+	).Replace(path)
+}
+
+func localUsername() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	urlPkg "net/url"
@@ -15,10 +16,9 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/docker/cli/cli/connhelper"
-	"github.com/docker/docker/pkg/homedir"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
 	"golang.org/x/crypto/ssh/knownhosts"
@@ -33,25 +33,34 @@ type Config struct {
 	PasswordCallback   SecretCallback
 	PassPhraseCallback SecretCallback
 	HostKeyCallback    HostKeyCallback
+	// SSHConfigPath is the ssh_config file hosts are configured in, ~/.ssh/config is used if it's empty
+	SSHConfigPath string
 }
 
 const defaultSSHPort = "22"
 
+// NewDialContext returns a dial function for the docker daemon of the host in url.
+// The host is configured by the ssh_config file, including the hosts to jump through to reach it.
+// All the connections to the daemon are multiplexed on a single ssh connection,
+// which is established again if it's lost.
 func NewDialContext(url *urlPkg.URL, config Config) (func(ctx context.Context, network, addr string) (net.Conn, error), error) {
-	sshConfig, err := NewSSHClientConfig(url, config)
+	sshConfig, err := readSSHConfig(config.SSHConfigPath)
 	if err != nil {
 		return nil, err
 	}
 
-	port := url.Port()
-	if port == "" {
-		port = defaultSSHPort
+	host, err := resolveHostConfig(sshConfig, url)
+	if err != nil {
+		return nil, err
 	}
-	host := url.Hostname()
 
-	sshClient, err := ssh.Dial("tcp", net.JoinHostPort(host, port), sshConfig)
+	connect := func() (*ssh.Client, error) {
+		return dialHost(host, config)
+	}
+
+	sshClient, err := connect()
 	if err != nil {
-		return nil, fmt.Errorf("failed to dial ssh: %w", err)
+		return nil, err
 	}
 	defer func() {
 		if sshClient != nil {
@@ -59,30 +68,27 @@ func NewDialContext(url *urlPkg.URL, config Config) (func(ctx context.Context, n
 		}
 	}()
 
-	dialContext, err := tryGetStdioDialContext(url, sshClient, config.Identity)
+	d := dialer{sshClient: sshClient, connect: connect}
+
+	d.stdio, err = hasDialStdio(sshClient)
 	if err != nil {
 		return nil, err
 	}
-	if dialContext != nil {
-		return dialContext, nil
-	}
 
-	var addr string
-	var network string
-
-	if url.Path != "" {
-		addr = url.Path
-		network = "unix"
-	} else {
-		network, addr, err = networkAndAddressFromRemoteDockerHost(sshClient)
-		if err != nil {
-			return nil, err
+	if !d.stdio {
+		if url.Path != "" {
+			d.addr = url.Path
+			d.network = "unix"
+		} else {
+			d.network, d.addr, err = networkAndAddressFromRemoteDockerHost(sshClient)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	d := dialer{sshClient: sshClient, addr: addr, network: network}
 	sshClient = nil
-	dialContext = d.DialContext
+	dialContext := d.DialContext
 
 	runtime.SetFinalizer(&d, func(d *dialer) {
 		d.Close()
@@ -91,8 +97,62 @@ func NewDialContext(url *urlPkg.URL, config Config) (func(ctx context.Context, n
 	return dialContext, nil
 }
 
+// dials the host through its jumps
+func dialHost(host hostConfig, config Config) (*ssh.Client, error) {
+	var sshClient *ssh.Client
+	for _, h := range append(host.jumps, host) {
+		clientConfig, err := newSSHClientConfig(h, config)
+		if err != nil {
+			if sshClient != nil {
+				sshClient.Close()
+			}
+			return nil, err
+		}
+
+		if sshClient == nil {
+			sshClient, err = ssh.Dial("tcp", h.addr(), clientConfig)
+			if err != nil {
+				return nil, fmt.Errorf("failed to dial ssh: %w", err)
+			}
+			continue
+		}
+
+		jumpClient := sshClient
+		sshClient, err = dialThrough(jumpClient, h.addr(), clientConfig)
+		if err != nil {
+			jumpClient.Close()
+			return nil, fmt.Errorf("failed to dial ssh through %s: %w", jumpClient.RemoteAddr(), err)
+		}
+	}
+	return sshClient, nil
+}
+
+// dials addr from the jump host, the jump connection is closed with the returned client
+func dialThrough(jumpClient *ssh.Client, addr string, clientConfig *ssh.ClientConfig) (*ssh.Client, error) {
+	conn, err := jumpClient.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, clientConfig)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	sshClient := ssh.NewClient(c, chans, reqs)
+	go func() {
+		_ = sshClient.Wait()
+		jumpClient.Close()
+	}()
+	return sshClient, nil
+}
+
 type dialer struct {
+	mu        sync.Mutex
 	sshClient *ssh.Client
+	connect   func() (*ssh.Client, error)
+	stdio     bool
 	network   string
 	addr      string
 }
@@ -112,11 +172,57 @@ func (d *dialer) DialContext(ctx context.Context, n, a string) (net.Conn, error)
 }
 
 func (d *dialer) Dial(n, a string) (net.Conn, error) {
-	return d.sshClient.Dial(d.network, d.addr)
+	sshClient := d.client()
+	conn, err := d.dial(sshClient)
+	if err == nil || isAlive(sshClient) {
+		return conn, err
+	}
+
+	sshClient, err = d.reconnect(sshClient)
+	if err != nil {
+		return nil, err
+	}
+	return d.dial(sshClient)
+}
+
+func (d *dialer) dial(sshClient *ssh.Client) (net.Conn, error) {
+	if d.stdio {
+		return dialStdio(sshClient)
+	}
+	return sshClient.Dial(d.network, d.addr)
+}
+
+func (d *dialer) client() *ssh.Client {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.sshClient
+}
+
+// replaces the lost connection, unless it was already replaced by another dial
+func (d *dialer) reconnect(lost *ssh.Client) (*ssh.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.sshClient != lost {
+		return d.sshClient, nil
+	}
+
+	sshClient, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+	lost.Close()
+	d.sshClient = sshClient
+	return sshClient, nil
 }
 
 func (d *dialer) Close() error {
-	return d.sshClient.Close()
+	return d.client().Close()
+}
+
+// checks the connection is still usable, the same way ssh's ServerAliveInterval does
+func isAlive(sshClient *ssh.Client) bool {
+	_, _, err := sshClient.SendRequest("keepalive@openssh.com", true, nil)
+	return err == nil
 }
 
 func isWindowsMachine(sshClient *ssh.Client) (bool, error) {
@@ -184,42 +290,108 @@ func networkAndAddressFromRemoteDockerHost(sshClient *ssh.Client) (network strin
 	return network, addr, err
 }
 
-func tryGetStdioDialContext(url *urlPkg.URL, sshClient *ssh.Client, identity string) (func(ctx context.Context, network, addr string) (net.Conn, error), error) {
+// checks whether `docker system dial-stdio` can be used to connect to the daemon
+func hasDialStdio(sshClient *ssh.Client) (bool, error) {
 	session, err := sshClient.NewSession()
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 	defer session.Close()
 	session.Stdin = nil
 	session.Stdout = nil
 	session.Stderr = nil
-	err = session.Run("docker system dial-stdio")
-	if err == nil {
-		var opts []string
+	return session.Run("docker system dial-stdio") == nil, nil
+}
 
-		if identity != "" {
-			opts = append(opts, "-i", identity)
-		}
+// runs `docker system dial-stdio` in a new session, its stdin and stdout are the connection to the daemon
+func dialStdio(sshClient *ssh.Client) (net.Conn, error) {
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return nil, err
+	}
 
-		connHelper, err := connhelper.GetConnectionHelperWithSSHOpts(url.String(), opts)
-		if err != nil {
-			return nil, err
-		}
-		if connHelper != nil {
-			return connHelper.Dialer, nil
-		}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
 	}
-	return nil, nil
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	if err = session.Start("docker system dial-stdio"); err != nil {
+		session.Close()
+		return nil, err
+	}
+	return &stdioConn{session: session, stdin: stdin, stdout: stdout}, nil
+}
+
+type stdioConn struct {
+	session *ssh.Session
+	stdin   io.WriteCloser
+	stdout  io.Reader
+}
+
+func (c *stdioConn) Read(b []byte) (int, error) {
+	return c.stdout.Read(b)
 }
 
+func (c *stdioConn) Write(b []byte) (int, error) {
+	return c.stdin.Write(b)
+}
+
+func (c *stdioConn) CloseWrite() error {
+	return c.stdin.Close()
+}
+
+func (c *stdioConn) Close() error {
+	c.stdin.Close()
+	return c.session.Close()
+}
+
+func (c *stdioConn) LocalAddr() net.Addr {
+	return stdioAddr{}
+}
+
+func (c *stdioConn) RemoteAddr() net.Addr {
+	return stdioAddr{}
+}
+
+func (c *stdioConn) SetDeadline(t time.Time) error { return nil }
+
+func (c *stdioConn) SetReadDeadline(t time.Time) error { return nil }
+
+func (c *stdioConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type stdioAddr struct{}
+
+func (stdioAddr) Network() string { return "stdio" }
+
+func (stdioAddr) String() string { return "docker system dial-stdio" }
+
 func NewSSHClientConfig(url *urlPkg.URL, config Config) (*ssh.ClientConfig, error) {
+	sshConfig, err := readSSHConfig(config.SSHConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	host, err := resolveHostConfig(sshConfig, url)
+	if err != nil {
+		return nil, err
+	}
+	return newSSHClientConfig(host, config)
+}
+
+func newSSHClientConfig(host hostConfig, config Config) (*ssh.ClientConfig, error) {
 	var (
 		authMethods []ssh.AuthMethod
 		signers     []ssh.Signer
 	)
 
-	if pw, found := url.User.Password(); found {
-		authMethods = append(authMethods, ssh.Password(pw))
+	if host.password != "" {
+		authMethods = append(authMethods, ssh.Password(host.password))
 	}
 
 	// add signer from explicit identity parameter
@@ -231,6 +403,18 @@ func NewSSHClientConfig(url *urlPkg.URL, config Config) (*ssh.ClientConfig, erro
 		signers = append(signers, signer)
 	}
 
+	// add signers from the IdentityFile entries of the ssh config, missing files are skipped like ssh does
+	for _, identityFile := range host.identityFiles {
+		if _, err := os.Stat(identityFile); os.IsNotExist(err) {
+			continue
+		}
+		signer, err := loadSignerFromFile(identityFile, []byte(config.PassPhrase), config.PassPhraseCallback)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse identity file %s: %w", identityFile, err)
+		}
+		signers = append(signers, signer)
+	}
+
 	// pulls signers (keys) from ssh-agent
 	signersFromAgent, err := getSignersFromAgent()
 	if err != nil {
@@ -259,9 +443,9 @@ func NewSSHClientConfig(url *urlPkg.URL, config Config) (*ssh.ClientConfig, erro
 
 	const sshTimeout = 5
 	clientConfig := &ssh.ClientConfig{
-		User:            url.User.Username(),
+		User:            host.user,
 		Auth:            authMethods,
-		HostKeyCallback: createHostKeyCallback(config.HostKeyCallback),
+		HostKeyCallback: createHostKeyCallback(host.knownHostsFiles, host.strictHostKeyChecking, config.HostKeyCallback),
 		HostKeyAlgorithms: []string{
 			ssh.KeyAlgoECDSA256,
 			ssh.KeyAlgoECDSA384,
@@ -373,20 +557,43 @@ func loadSignerFromFile(path string, passphrase []byte, passPhraseCallback Secre
 	return signer, nil
 }
 
-func createHostKeyCallback(userCallback HostKeyCallback) ssh.HostKeyCallback {
+// createHostKeyCallback verifies host keys against the known_hosts files with the semantics of StrictHostKeyChecking:
+// "yes" rejects unknown keys, "accept-new" and "no" add them to the first known_hosts file,
+// and "ask", the default, leaves the decision to userCallback. A key that doesn't match the known one is always rejected.
+func createHostKeyCallback(knownHostsFiles []string, strictHostKeyChecking string, userCallback HostKeyCallback) ssh.HostKeyCallback {
 	return func(hostPort string, remote net.Addr, pubKey ssh.PublicKey) error {
-		knownHosts := filepath.Join(homedir.Get(), ".ssh", "known_hosts")
+		var existing []string
+		for _, knownHosts := range knownHostsFiles {
+			if _, err := os.Stat(knownHosts); err == nil {
+				existing = append(existing, knownHosts)
+			}
+		}
 
-		fileCallback, err := knownhosts.New(knownHosts)
-		if err != nil {
-			if os.IsNotExist(err) {
-				err = errKeyUnknown
+		err := errKeyUnknown
+		if len(existing) > 0 {
+			var fileCallback ssh.HostKeyCallback
+			fileCallback, err = knownhosts.New(existing...)
+			if err != nil {
+				return err
 			}
-		} else {
 			err = fileCallback(hostPort, remote, pubKey)
 			if err == nil {
 				return nil
 			}
+			var keyErr *knownhosts.KeyError
+			if !errors.As(err, &keyErr) || len(keyErr.Want) > 0 {
+				return err
+			}
+		}
+
+		switch strictHostKeyChecking {
+		case "yes":
+			return err
+		case "accept-new", "no", "off":
+			if len(knownHostsFiles) == 0 {
+				return nil
+			}
+			return addKnownHost(knownHostsFiles[0], hostPort, pubKey)
 		}
 
 		if userCallback != nil {
@@ -400,6 +607,21 @@ func createHostKeyCallback(userCallback HostKeyCallback) ssh.HostKeyCallback {
 	}
 }
 
+func addKnownHost(knownHosts, hostPort string, pubKey ssh.PublicKey) error {
+	if err := os.MkdirAll(filepath.Dir(knownHosts), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(knownHosts, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostPort)}, pubKey))
+	return err
+}
+
 var ErrKeyMismatchMsg = "key mismatch"
 var ErrKeyUnknownMsg = "key is unknown"
 
@@ -11,7 +11,7 @@ func (s *Termui) Debugf(fmt string, v ...interface{}) {
 }
 
 func (s *Termui) Info(msg string) {
-	s.textChan <- msg
+	s.textChan <- s.redact(msg)
 }
 
 func (s *Termui) Infof(fmt string, v ...interface{}) {
@@ -17,6 +17,7 @@ import (
 	"github.com/buildpacks/pack/internal/builder"
 	"github.com/buildpacks/pack/internal/container"
 	"github.com/buildpacks/pack/pkg/dist"
+	"github.com/buildpacks/pack/pkg/logging"
 )
 
 var (
@@ -54,13 +55,16 @@ type Termui struct {
 	textChan      chan string
 	buildpackChan chan dist.BuildpackInfo
 	nodes         map[string]*tview.TreeNode
+	redactor      *logging.Redactor
 }
 
-func NewTermui(appName string, bldr *builder.Builder, runImageName string) *Termui {
+// NewTermui creates a Termui for building appName, the text it displays is redacted by redactor.
+func NewTermui(appName string, bldr *builder.Builder, runImageName string, redactor *logging.Redactor) *Termui {
 	return &Termui{
 		appName:       appName,
 		bldr:          bldr,
 		runImageName:  runImageName,
+		redactor:      redactor,
 		app:           tview.NewApplication(),
 		buildpackChan: make(chan dist.BuildpackInfo, 50),
 		textChan:      make(chan string, 50),
@@ -138,7 +142,7 @@ func (s *Termui) Handler() container.Handler {
 				return nil
 			default:
 				if scanner.Scan() {
-					s.textChan <- scanner.Text()
+					s.textChan <- s.redact(scanner.Text())
 					continue
 				}
 
@@ -209,3 +213,18 @@ func collect(buildpackChan chan dist.BuildpackInfo) []dist.BuildpackInfo {
 
 	return result
 }
+
+// Redactor returns the Redactor applied to the text displayed, see logging.AddRedactions
+func (s *Termui) Redactor() *logging.Redactor {
+	if s.redactor == nil {
+		s.redactor = logging.NewRedactor()
+	}
+	return s.redactor
+}
+
+func (s *Termui) redact(txt string) string {
+	if s.redactor == nil {
+		return txt
+	}
+	return s.redactor.Redact(txt)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/buildpacks/pack/internal/builder"
 	"github.com/buildpacks/pack/internal/termui/fakes"
 	"github.com/buildpacks/pack/pkg/dist"
+	"github.com/buildpacks/pack/pkg/logging"
 	h "github.com/buildpacks/pack/testhelpers"
 )
 
@@ -235,6 +236,30 @@ func testTermui(t *testing.T, when spec.G, it spec.S) {
 		}, eventuallyInterval, eventuallyDuration)
 	})
 
+	it("redacts the text it displays", func() {
+		var (
+			fakeBodyChan        = make(chan dcontainer.ContainerWaitOKBody, 1)
+			r, w                = io.Pipe()
+			fakeDockerStdWriter = fakes.NewDockerStdWriter(w)
+			s                   = &Termui{
+				textChan: make(chan string, 10),
+				redactor: logging.NewRedactor("some-secret-value"),
+			}
+		)
+
+		defer func() {
+			fakeBodyChan <- dcontainer.ContainerWaitOKBody{StatusCode: 0}
+			w.Close()
+		}()
+		go s.Handler()(fakeBodyChan, nil, r)
+
+		s.Info("using some-secret-value")
+		assert.Equal(<-s.textChan, "using [REDACTED]")
+
+		fakeDockerStdWriter.WriteStdoutln(`NPM_TOKEN=abc123 and some-secret-value`)
+		assert.Equal(<-s.textChan, "NPM_TOKEN=[REDACTED] and [REDACTED]")
+	})
+
 	// TODO: change to show errors on-screen
 	// See: https://github.com/buildpacks/pack/issues/1262
 	it("returns errors from error channel", func() {
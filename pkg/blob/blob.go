@@ -62,6 +62,25 @@ func (b blob) Open() (r io.ReadCloser, err error) {
 	return rc, nil
 }
 
+// OpenRaw returns an io.ReadCloser of the contents of a blob as stored, without decompressing them.
+// Only blobs backed by a single file, such as downloaded archives, can be opened this way.
+func OpenRaw(b Blob) (io.ReadCloser, error) {
+	fileBlob, ok := b.(*blob)
+	if !ok {
+		return nil, errors.New("blob is not backed by a file")
+	}
+
+	fi, err := os.Stat(fileBlob.path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read blob at path '%s'", fileBlob.path)
+	}
+	if fi.IsDir() {
+		return nil, errors.Errorf("blob at path '%s' is a directory", fileBlob.path)
+	}
+
+	return os.Open(fileBlob.path)
+}
+
 func isGZip(file io.ReadSeeker) (bool, error) {
 	b := make([]byte, 3)
 	if _, err := file.Seek(0, 0); err != nil {
@@ -1,6 +1,7 @@
 package blob_test
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -59,5 +60,29 @@ func testBlob(t *testing.T, when spec.G, it spec.S) {
 				})
 			})
 		})
+
+		when("#OpenRaw", func() {
+			var blobDir = filepath.Join("testdata", "blob")
+
+			it("returns the contents of a tgz without decompressing them", func() {
+				blobPath := h.CreateTGZ(t, blobDir, ".", -1)
+				defer os.Remove(blobPath)
+
+				rc, err := blob.OpenRaw(blob.NewBlob(blobPath))
+				h.AssertNil(t, err)
+				defer rc.Close()
+
+				contents, err := ioutil.ReadAll(rc)
+				h.AssertNil(t, err)
+				expected, err := ioutil.ReadFile(blobPath)
+				h.AssertNil(t, err)
+				h.AssertEq(t, contents, expected)
+			})
+
+			it("returns an error for a dir", func() {
+				_, err := blob.OpenRaw(blob.NewBlob(blobDir))
+				h.AssertError(t, err, "is a directory")
+			})
+		})
 	})
 }
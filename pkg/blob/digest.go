@@ -0,0 +1,83 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/style"
+)
+
+const (
+	digestSeparator = "#"
+	sha256Prefix    = "sha256:"
+)
+
+var digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// ValidateDigest returns an error if digest isn't a sha256 digest, in the format 'sha256:<hex>'
+func ValidateDigest(digest string) error {
+	if !digestPattern.MatchString(digest) {
+		return errors.Errorf("invalid digest %s, must be in the format %s", style.Symbol(digest), style.Symbol("sha256:<hex>"))
+	}
+	return nil
+}
+
+// WithDigest returns a path or URI pinned to a digest, e.g. 'https://example.com/bp.tgz#sha256:<hex>', which is
+// verified when it is downloaded. The path or URI is returned as is if digest is empty.
+func WithDigest(pathOrURI, digest string) string {
+	if digest == "" {
+		return pathOrURI
+	}
+	return pathOrURI + digestSeparator + digest
+}
+
+// SplitDigest splits a path or URI pinned with WithDigest into the path or URI and its digest. The digest is empty
+// if the path or URI isn't pinned.
+func SplitDigest(pathOrURI string) (string, string, error) {
+	i := strings.LastIndex(pathOrURI, digestSeparator+sha256Prefix)
+	if i < 0 {
+		return pathOrURI, "", nil
+	}
+
+	digest := pathOrURI[i+len(digestSeparator):]
+	if err := ValidateDigest(digest); err != nil {
+		return "", "", errors.Wrapf(err, "parsing %s", style.Symbol(pathOrURI))
+	}
+
+	return pathOrURI[:i], digest, nil
+}
+
+// Digest returns the sha256 digest of the contents of a blob as stored, in the format 'sha256:<hex>'
+func Digest(b Blob) (string, error) {
+	rc, err := OpenRaw(b)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, rc); err != nil {
+		return "", err
+	}
+
+	return sha256Prefix + hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// VerifyDigest returns an error if the contents of a blob as stored don't match digest
+func VerifyDigest(b Blob, digest string) error {
+	actual, err := Digest(b)
+	if err != nil {
+		return err
+	}
+
+	if actual != digest {
+		return errors.Errorf("checksum mismatch: expected %s, got %s", style.Symbol(digest), style.Symbol(actual))
+	}
+
+	return nil
+}
@@ -3,6 +3,9 @@ package blob
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -10,7 +13,11 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/mitchellh/ioprogress"
 	"github.com/pkg/errors"
 
@@ -21,6 +28,9 @@ import (
 const (
 	cacheDirPrefix = "c"
 	cacheVersion   = "2"
+
+	defaultAttempts = 4
+	defaultBackoff  = time.Second
 )
 
 type Logger interface {
@@ -33,19 +43,138 @@ type Downloader interface {
 	Download(ctx context.Context, pathOrURI string) (Blob, error)
 }
 
+// Seeder stores the contents of a remote blob in the download cache, e.g. to make it available in offline mode
+type Seeder interface {
+	Seed(uri string, contents io.Reader) error
+}
+
+// Authenticator adds credentials for the host of a request to it, if any are configured
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// DownloaderOption configures a downloader
+type DownloaderOption func(d *downloader)
+
+// WithAuthenticator sets the credentials used for http and https downloads
+func WithAuthenticator(authenticator Authenticator) DownloaderOption {
+	return func(d *downloader) {
+		d.authenticator = authenticator
+	}
+}
+
+// WithCACertFile sets a file of PEM encoded CA certificates that are trusted for https downloads, in addition to
+// the certificates of the system
+func WithCACertFile(path string) DownloaderOption {
+	return func(d *downloader) {
+		d.caCertFile = path
+	}
+}
+
+// WithKeychain sets the credentials used to download artifacts from OCI registries
+func WithKeychain(keychain authn.Keychain) DownloaderOption {
+	return func(d *downloader) {
+		d.keychain = keychain
+	}
+}
+
+// WithRetries sets how many times a download is attempted when it fails with a transient error, and the time to
+// wait before the first retry, which is doubled for each retry after it
+func WithRetries(attempts int, backoff time.Duration) DownloaderOption {
+	return func(d *downloader) {
+		d.attempts = attempts
+		d.backoff = backoff
+	}
+}
+
+// WithOffline sets whether remote blobs are only read from the download cache, without accessing the network
+func WithOffline(offline bool) DownloaderOption {
+	return func(d *downloader) {
+		d.offline = offline
+	}
+}
+
 type downloader struct {
-	logger       Logger
-	baseCacheDir string
+	logger        Logger
+	baseCacheDir  string
+	authenticator Authenticator
+	caCertFile    string
+	keychain      authn.Keychain
+	attempts      int
+	backoff       time.Duration
+	offline       bool
+
+	transportOnce sync.Once
+	transport     http.RoundTripper
+	transportErr  error
+
+	// cacheLocks holds a *sync.Mutex per cache path, see lockCachePath
+	cacheLocks sync.Map
 }
 
-func NewDownloader(logger Logger, baseCacheDir string) Downloader {
-	return &downloader{
+func NewDownloader(logger Logger, baseCacheDir string, opts ...DownloaderOption) Downloader {
+	d := &downloader{
 		logger:       logger,
 		baseCacheDir: baseCacheDir,
+		keychain:     authn.DefaultKeychain,
+		attempts:     defaultAttempts,
+		backoff:      defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(d)
 	}
+	return d
 }
 
+// Download returns a blob from a local path or a file, http, https, s3 or oci URI. A path or URI pinned to a digest,
+// e.g. 'https://example.com/bp.tgz#sha256:<hex>', is verified against it, and downloads that are verified are cached
+// by digest, so that they can be used again without downloading them.
 func (d *downloader) Download(ctx context.Context, pathOrURI string) (Blob, error) {
+	pathOrURI, digest, err := SplitDigest(pathOrURI)
+	if err != nil {
+		return nil, err
+	}
+
+	if digest == "" {
+		return d.download(ctx, pathOrURI)
+	}
+
+	return d.downloadVerified(ctx, pathOrURI, digest)
+}
+
+func (d *downloader) downloadVerified(ctx context.Context, pathOrURI, digest string) (Blob, error) {
+	cachePath := d.digestCachePath(digest)
+	if exists, err := fileExists(cachePath); err != nil {
+		return nil, err
+	} else if exists {
+		if err := VerifyDigest(&blob{path: cachePath}, digest); err == nil {
+			d.logger.Debugf("Using cached version of %s", style.Symbol(WithDigest(pathOrURI, digest)))
+			return &blob{path: cachePath}, nil
+		}
+	}
+
+	downloaded, err := d.download(ctx, pathOrURI)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := VerifyDigest(downloaded, digest); err != nil {
+		return nil, errors.Wrapf(err, "verifying %s", style.Symbol(pathOrURI))
+	}
+
+	// local files are used where they are, as they may change after they're verified either way
+	if !isRemote(pathOrURI) {
+		return downloaded, nil
+	}
+
+	if err := copyToCache(downloaded.(*blob).path, cachePath); err != nil {
+		return nil, errors.Wrap(err, "caching verified download")
+	}
+
+	return &blob{path: cachePath}, nil
+}
+
+func (d *downloader) download(ctx context.Context, pathOrURI string) (Blob, error) {
 	if paths.IsURI(pathOrURI) {
 		parsedURL, err := url.Parse(pathOrURI)
 		if err != nil {
@@ -58,6 +187,10 @@ func (d *downloader) Download(ctx context.Context, pathOrURI string) (Blob, erro
 			path, err = paths.URIToFilePath(pathOrURI)
 		case "http", "https":
 			path, err = d.handleHTTP(ctx, pathOrURI)
+		case "s3":
+			path, err = d.handleS3(ctx, parsedURL)
+		case "oci":
+			path, err = d.handleOCI(ctx, pathOrURI)
 		default:
 			err = fmt.Errorf("unsupported protocol %s in URI %s", style.Symbol(parsedURL.Scheme), style.Symbol(pathOrURI))
 		}
@@ -83,84 +216,235 @@ func (d *downloader) handleFile(path string) string {
 }
 
 func (d *downloader) handleHTTP(ctx context.Context, uri string) (string, error) {
-	cacheDir := d.versionedCacheDir()
+	return d.downloadToCache(ctx, uri, uri, func(req *http.Request) error {
+		if d.authenticator == nil {
+			return nil
+		}
+		return d.authenticator.Authenticate(req)
+	})
+}
 
-	if err := os.MkdirAll(cacheDir, 0750); err != nil {
-		return "", err
-	}
+// downloadToCache downloads uri to the cache, retrying transient failures. The cached download is revalidated with its
+// ETag, and an interrupted download is resumed where it stopped if the server supports range requests. prepare is
+// called with each request before it is sent, e.g. to authenticate it. Downloads are cached by displayURI, the URI
+// they were requested with.
+func (d *downloader) downloadToCache(ctx context.Context, uri, displayURI string, prepare func(req *http.Request) error) (string, error) {
+	cachePath := d.uriCachePath(displayURI)
 
-	cachePath := filepath.Join(cacheDir, fmt.Sprintf("%x", sha256.Sum256([]byte(uri))))
+	if d.offline {
+		if exists, err := fileExists(cachePath); err != nil {
+			return "", err
+		} else if !exists {
+			return "", errOffline(displayURI)
+		}
+		d.logger.Debugf("Using cached version of %s", style.Symbol(displayURI))
+		return cachePath, nil
+	}
 
-	etagFile := cachePath + ".etag"
-	etagExists, err := fileExists(etagFile)
-	if err != nil {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0750); err != nil {
 		return "", err
 	}
 
-	etag := ""
-	if etagExists {
-		bytes, err := ioutil.ReadFile(filepath.Clean(etagFile))
-		if err != nil {
+	unlock := d.lockCachePath(cachePath)
+	defer unlock()
+
+	for attempt := 1; ; attempt++ {
+		err := d.tryDownload(ctx, uri, displayURI, cachePath, prepare)
+		if err == nil {
+			return cachePath, nil
+		}
+
+		var transient *transientError
+		if !errors.As(err, &transient) || attempt >= d.attempts {
 			return "", err
 		}
-		etag = string(bytes)
+
+		wait := d.backoff * time.Duration(1<<(attempt-1))
+		d.logger.Debugf("Retrying download from %s in %s: %s", style.Symbol(displayURI), wait, err)
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(wait):
+		}
 	}
+}
+
+// lockCachePath waits for other downloads to cachePath to finish, e.g. of a buildpack referenced twice, so that they
+// don't write the same partial download, and returns a function that releases cachePath
+func (d *downloader) lockCachePath(cachePath string) func() {
+	lock, _ := d.cacheLocks.LoadOrStore(cachePath, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	return lock.(*sync.Mutex).Unlock
+}
 
-	reader, etag, err := d.downloadAsStream(ctx, uri, etag)
+func (d *downloader) tryDownload(ctx context.Context, uri, displayURI, cachePath string, prepare func(req *http.Request) error) error {
+	etagFile := cachePath + ".etag"
+	partialPath := cachePath + ".partial"
+	partialETagFile := partialPath + ".etag"
+
+	etag, err := readFileIfExists(etagFile)
 	if err != nil {
-		return "", err
-	} else if reader == nil {
-		return cachePath, nil
+		return err
 	}
-	defer reader.Close()
-
-	fh, err := os.Create(cachePath)
+	partialETag, err := readFileIfExists(partialETagFile)
 	if err != nil {
-		return "", errors.Wrapf(err, "create cache path %s", style.Symbol(cachePath))
+		return err
+	}
+	partialSize := int64(0)
+	if info, err := os.Stat(partialPath); err == nil && partialETag != "" {
+		partialSize = info.Size()
 	}
-	defer fh.Close()
 
-	_, err = io.Copy(fh, reader)
+	req, err := http.NewRequest("GET", uri, nil)
 	if err != nil {
-		return "", errors.Wrap(err, "writing cache")
+		return err
 	}
+	req = req.WithContext(ctx)
 
-	if err = ioutil.WriteFile(etagFile, []byte(etag), 0744); err != nil {
-		return "", errors.Wrap(err, "writing etag")
+	if partialSize > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", partialSize))
+		req.Header.Set("If-Range", partialETag)
+	} else if etag != "" {
+		req.Header.Set("If-None-Match", etag)
 	}
 
-	return cachePath, nil
-}
+	if prepare != nil {
+		if err := prepare(req); err != nil {
+			return errors.Wrapf(err, "preparing request to %s", style.Symbol(displayURI))
+		}
+	}
 
-func (d *downloader) downloadAsStream(ctx context.Context, uri string, etag string) (io.ReadCloser, string, error) {
-	req, err := http.NewRequest("GET", uri, nil)
+	transport, err := d.httpTransport()
 	if err != nil {
-		return nil, "", err
+		return err
 	}
-	req = req.WithContext(ctx)
 
-	if etag != "" {
-		req.Header.Set("If-None-Match", etag)
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return &transientError{err}
+	}
+	defer resp.Body.Close()
+
+	var flags int
+	switch {
+	case resp.StatusCode == http.StatusNotModified && partialSize == 0:
+		d.logger.Debugf("Using cached version of %s", style.Symbol(displayURI))
+		return nil
+	case resp.StatusCode == http.StatusPartialContent && partialSize > 0:
+		d.logger.Infof("Resuming download from %s", style.Symbol(displayURI))
+		flags = os.O_WRONLY | os.O_APPEND
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		d.logger.Infof("Downloading from %s", style.Symbol(displayURI))
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		partialETag = resp.Header.Get("Etag")
+		if err := ioutil.WriteFile(partialETagFile, []byte(partialETag), 0600); err != nil {
+			return errors.Wrap(err, "writing etag")
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && partialSize > 0:
+		// the partial download can't be resumed, so it is downloaded again from the start
+		if err := os.Remove(partialETagFile); err != nil {
+			return err
+		}
+		return &transientError{httpStatusError(displayURI, resp.StatusCode)}
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return &transientError{httpStatusError(displayURI, resp.StatusCode)}
+	default:
+		return httpStatusError(displayURI, resp.StatusCode)
 	}
 
-	resp, err := (&http.Client{}).Do(req) //nolint:bodyclose
+	fh, err := os.OpenFile(partialPath, flags, 0600)
 	if err != nil {
-		return nil, "", err
+		return errors.Wrapf(err, "create cache path %s", style.Symbol(cachePath))
+	}
+	defer fh.Close()
+
+	length := resp.ContentLength
+	if length >= 0 {
+		length += partialSize
 	}
+	if _, err := io.Copy(fh, withProgress(d.logger.Writer(), resp.Body, length)); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return &transientError{errors.Wrap(err, "writing cache")}
+	}
+	if err := fh.Close(); err != nil {
+		return errors.Wrap(err, "writing cache")
+	}
+
+	if err := os.Rename(partialPath, cachePath); err != nil {
+		return errors.Wrap(err, "writing cache")
+	}
+	if err := os.Remove(partialETagFile); err != nil {
+		return err
+	}
+
+	if err = ioutil.WriteFile(etagFile, []byte(partialETag), 0744); err != nil {
+		return errors.Wrap(err, "writing etag")
+	}
+
+	return nil
+}
+
+// httpTransport returns the transport used for downloads, which uses the proxies set with the HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY environment variables, and trusts the configured CA certificates
+func (d *downloader) httpTransport() (http.RoundTripper, error) {
+	d.transportOnce.Do(func() {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyFromEnvironment
+
+		if d.caCertFile != "" {
+			pool, err := caCertPool(d.caCertFile)
+			if err != nil {
+				d.transportErr = err
+				return
+			}
+			transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		}
+
+		d.transport = transport
+	})
+
+	return d.transport, d.transportErr
+}
 
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		d.logger.Infof("Downloading from %s", style.Symbol(uri))
-		return withProgress(d.logger.Writer(), resp.Body, resp.ContentLength), resp.Header.Get("Etag"), nil
+func caCertPool(caCertFile string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
 	}
 
-	if resp.StatusCode == 304 {
-		d.logger.Debugf("Using cached version of %s", style.Symbol(uri))
-		return nil, etag, nil
+	pem, err := ioutil.ReadFile(filepath.Clean(caCertFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading CA certificates")
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no PEM encoded CA certificates found in %s", style.Symbol(caCertFile))
 	}
 
-	return nil, "", fmt.Errorf(
+	return pool, nil
+}
+
+// transientError is an error downloading that may not happen again, so the download is retried
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+func httpStatusError(uri string, statusCode int) error {
+	return fmt.Errorf(
 		"could not download from %s, code http status %s",
-		style.Symbol(uri), style.SymbolF("%d", resp.StatusCode),
+		style.Symbol(uri), style.SymbolF("%d", statusCode),
 	)
 }
 
@@ -180,10 +464,110 @@ type progressReader struct {
 	io.Closer
 }
 
+// Seed stores the contents of a blob in the cache as if it was downloaded from uri, which must be an http, https, s3
+// or oci URI. The cached contents are used in offline mode, or until they change on the server.
+func (d *downloader) Seed(uri string, contents io.Reader) error {
+	parsedURL, err := url.Parse(uri)
+	if err != nil {
+		return errors.Wrapf(err, "parsing uri %s", style.Symbol(uri))
+	}
+
+	switch parsedURL.Scheme {
+	case "http", "https", "s3":
+		return writeToCache(contents, d.uriCachePath(uri))
+	case "oci":
+		tmpFile, err := ioutil.TempFile("", "seed-")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmpFile.Name())
+		defer tmpFile.Close()
+
+		hasher := sha256.New()
+		if _, err := io.Copy(io.MultiWriter(tmpFile, hasher), contents); err != nil {
+			return err
+		}
+		if err := tmpFile.Close(); err != nil {
+			return err
+		}
+
+		digest := sha256Prefix + hex.EncodeToString(hasher.Sum(nil))
+		if err := copyToCache(tmpFile.Name(), d.digestCachePath(digest)); err != nil {
+			return err
+		}
+		return d.writeOCIDigest(uri, digest)
+	default:
+		return errors.Errorf("unsupported protocol %s in URI %s", style.Symbol(parsedURL.Scheme), style.Symbol(uri))
+	}
+}
+
+func errOffline(uri string) error {
+	return errors.Errorf("%s isn't in the download cache and can't be downloaded in offline mode", style.Symbol(uri))
+}
+
 func (d *downloader) versionedCacheDir() string {
 	return filepath.Join(d.baseCacheDir, cacheDirPrefix+cacheVersion)
 }
 
+func (d *downloader) uriCachePath(uri string) string {
+	return filepath.Join(d.versionedCacheDir(), fmt.Sprintf("%x", sha256.Sum256([]byte(uri))))
+}
+
+func (d *downloader) digestCachePath(digest string) string {
+	return filepath.Join(d.baseCacheDir, "sha256", strings.TrimPrefix(digest, sha256Prefix))
+}
+
+func isRemote(pathOrURI string) bool {
+	if !paths.IsURI(pathOrURI) {
+		return false
+	}
+
+	parsedURL, err := url.Parse(pathOrURI)
+	return err == nil && parsedURL.Scheme != "file"
+}
+
+// copyToCache copies a file to cachePath, through a temporary file so that a partial copy is never cached
+func copyToCache(path, cachePath string) error {
+	src, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return writeToCache(src, cachePath)
+}
+
+// writeToCache writes the contents of src to cachePath, through a temporary file so that partial contents are never cached
+func writeToCache(src io.Reader, cachePath string) error {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0750); err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(cachePath), "download-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	if _, err := io.Copy(tmpFile, src); err != nil {
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), cachePath)
+}
+
+func readFileIfExists(path string) (string, error) {
+	contents, err := ioutil.ReadFile(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return string(contents), err
+}
+
 func fileExists(file string) (bool, error) {
 	_, err := os.Stat(file)
 	if err != nil {
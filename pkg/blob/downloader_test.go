@@ -2,14 +2,26 @@ package blob_test
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
-
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/heroku/color"
 	"github.com/onsi/gomega/ghttp"
 	"github.com/sclevine/spec"
@@ -86,6 +98,34 @@ func testDownloader(t *testing.T, when spec.G, it spec.S) {
 					assertBlob(t, b)
 				})
 			})
+
+			when("path is pinned to a digest", func() {
+				var tgz string
+
+				it.Before(func() {
+					tgz = h.CreateTGZ(t, relPath, "./", 0777)
+				})
+
+				it.After(func() {
+					os.Remove(tgz)
+				})
+
+				it("verifies the file", func() {
+					b, err := subject.Download(context.TODO(), blob.WithDigest(tgz, fileDigest(t, tgz)))
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+				})
+
+				it("returns an error if the file doesn't match the digest", func() {
+					_, err := subject.Download(context.TODO(), blob.WithDigest(tgz, "sha256:"+strings.Repeat("0", 64)))
+					h.AssertError(t, err, "checksum mismatch")
+				})
+
+				it("returns an error if the digest is invalid", func() {
+					_, err := subject.Download(context.TODO(), tgz+"#sha256:abc")
+					h.AssertError(t, err, "invalid digest 'sha256:abc'")
+				})
+			})
 		})
 
 		when("is uri", func() {
@@ -125,6 +165,28 @@ func testDownloader(t *testing.T, when spec.G, it spec.S) {
 					assertBlob(t, b)
 				})
 
+				it("downloads a URI requested at the same time once", func() {
+					var (
+						wg    sync.WaitGroup
+						blobs = make([]blob.Blob, 2)
+						errs  = make([]error, 2)
+					)
+					for i := range blobs {
+						wg.Add(1)
+						go func(i int) {
+							defer wg.Done()
+							blobs[i], errs[i] = subject.Download(context.TODO(), uri)
+						}(i)
+					}
+					wg.Wait()
+
+					for i := range blobs {
+						h.AssertNil(t, errs[i])
+						assertBlob(t, blobs[i])
+					}
+					h.AssertEq(t, server.ReceivedRequests()[1].Header.Get("If-None-Match"), "A")
+				})
+
 				it("uses cache from a 'http(s)://' URI tgz", func() {
 					b, err := subject.Download(context.TODO(), uri)
 					h.AssertNil(t, err)
@@ -136,6 +198,165 @@ func testDownloader(t *testing.T, when spec.G, it spec.S) {
 				})
 			})
 
+			when("uri is pinned to a digest", func() {
+				it.Before(func() {
+					server.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Add("ETag", "A")
+						http.ServeFile(w, r, tgz)
+					})
+				})
+
+				it("verifies the download", func() {
+					b, err := subject.Download(context.TODO(), blob.WithDigest(uri, fileDigest(t, tgz)))
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+				})
+
+				it("uses the verified download without downloading it again", func() {
+					_, err := subject.Download(context.TODO(), blob.WithDigest(uri, fileDigest(t, tgz)))
+					h.AssertNil(t, err)
+
+					server.Close()
+
+					b, err := subject.Download(context.TODO(), blob.WithDigest(uri, fileDigest(t, tgz)))
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+				})
+
+				it("returns an error if the download doesn't match the digest", func() {
+					_, err := subject.Download(context.TODO(), blob.WithDigest(uri, "sha256:"+strings.Repeat("0", 64)))
+					h.AssertError(t, err, "checksum mismatch")
+
+					_, err = os.Stat(filepath.Join(cacheDir, "sha256", strings.Repeat("0", 64)))
+					h.AssertEq(t, os.IsNotExist(err), true)
+				})
+			})
+
+			when("an authenticator is set", func() {
+				it.Before(func() {
+					subject = blob.NewDownloader(&logger{ioutil.Discard}, cacheDir, blob.WithAuthenticator(authenticatorFunc(func(req *http.Request) error {
+						req.Header.Set("Authorization", "Bearer some-token")
+						return nil
+					})))
+
+					server.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
+						http.ServeFile(w, r, tgz)
+					})
+				})
+
+				it("authenticates the request", func() {
+					b, err := subject.Download(context.TODO(), uri)
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+					h.AssertEq(t, server.ReceivedRequests()[0].Header.Get("Authorization"), "Bearer some-token")
+				})
+			})
+
+			when("the server fails transiently", func() {
+				it.Before(func() {
+					subject = blob.NewDownloader(&logger{ioutil.Discard}, cacheDir, blob.WithRetries(3, time.Millisecond))
+
+					server.AppendHandlers(
+						ghttp.RespondWith(http.StatusServiceUnavailable, nil),
+						ghttp.RespondWith(http.StatusTooManyRequests, nil),
+						func(w http.ResponseWriter, r *http.Request) {
+							http.ServeFile(w, r, tgz)
+						},
+					)
+				})
+
+				it("retries the download", func() {
+					b, err := subject.Download(context.TODO(), uri)
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+					h.AssertEq(t, len(server.ReceivedRequests()), 3)
+				})
+
+				it("gives up after the last attempt", func() {
+					subject = blob.NewDownloader(&logger{ioutil.Discard}, cacheDir, blob.WithRetries(2, time.Millisecond))
+
+					_, err := subject.Download(context.TODO(), uri)
+					h.AssertError(t, err, "http status '429'")
+					h.AssertEq(t, len(server.ReceivedRequests()), 2)
+				})
+			})
+
+			when("a previous download was interrupted", func() {
+				var contents []byte
+
+				it.Before(func() {
+					contents, err = ioutil.ReadFile(tgz)
+					h.AssertNil(t, err)
+
+					cachePath := filepath.Join(cacheDir, "c2", fmt.Sprintf("%x", sha256.Sum256([]byte(uri))))
+					h.AssertNil(t, os.MkdirAll(filepath.Dir(cachePath), 0750))
+					h.AssertNil(t, ioutil.WriteFile(cachePath+".partial", contents[:10], 0600))
+					h.AssertNil(t, ioutil.WriteFile(cachePath+".partial.etag", []byte("A"), 0600))
+				})
+
+				it("resumes the download", func() {
+					server.AppendHandlers(ghttp.RespondWith(http.StatusPartialContent, contents[10:], http.Header{"ETag": []string{"A"}}))
+
+					b, err := subject.Download(context.TODO(), uri)
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+
+					req := server.ReceivedRequests()[0]
+					h.AssertEq(t, req.Header.Get("Range"), "bytes=10-")
+					h.AssertEq(t, req.Header.Get("If-Range"), "A")
+				})
+
+				it("downloads again from the start if the file changed", func() {
+					server.AppendHandlers(ghttp.RespondWith(http.StatusOK, contents, http.Header{"ETag": []string{"B"}}))
+
+					b, err := subject.Download(context.TODO(), uri)
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+				})
+			})
+
+			when("offline", func() {
+				var offline blob.Downloader
+
+				it.Before(func() {
+					offline = blob.NewDownloader(&logger{ioutil.Discard}, cacheDir, blob.WithOffline(true))
+				})
+
+				it("uses a previous download without accessing the server", func() {
+					server.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Add("ETag", "A")
+						http.ServeFile(w, r, tgz)
+					})
+
+					_, err := subject.Download(context.TODO(), uri)
+					h.AssertNil(t, err)
+
+					b, err := offline.Download(context.TODO(), uri)
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+					h.AssertEq(t, len(server.ReceivedRequests()), 1)
+				})
+
+				it("uses a seeded blob", func() {
+					fh, err := os.Open(tgz)
+					h.AssertNil(t, err)
+					defer fh.Close()
+
+					h.AssertNil(t, offline.(blob.Seeder).Seed(uri, fh))
+
+					b, err := offline.Download(context.TODO(), uri)
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+					h.AssertEq(t, len(server.ReceivedRequests()), 0)
+				})
+
+				it("returns an error if the blob wasn't downloaded before", func() {
+					_, err := offline.Download(context.TODO(), uri)
+					h.AssertError(t, err, fmt.Sprintf("'%s' isn't in the download cache and can't be downloaded in offline mode", uri))
+					h.AssertEq(t, len(server.ReceivedRequests()), 0)
+				})
+			})
+
 			when("uri is invalid", func() {
 				when("uri file is not found", func() {
 					it.Before(func() {
@@ -159,6 +380,145 @@ func testDownloader(t *testing.T, when spec.G, it spec.S) {
 				})
 			})
 		})
+
+		when("is s3 uri", func() {
+			var (
+				server *ghttp.Server
+				tgz    string
+			)
+
+			it.Before(func() {
+				server = ghttp.NewServer()
+				tgz = h.CreateTGZ(t, filepath.Join("testdata", "blob"), "./", 0777)
+
+				for key, value := range map[string]string{
+					"AWS_ENDPOINT_URL_S3":   server.URL(),
+					"AWS_ACCESS_KEY_ID":     "some-access-key",
+					"AWS_SECRET_ACCESS_KEY": "some-secret-key",
+					"AWS_REGION":            "eu-west-1",
+				} {
+					h.AssertNil(t, os.Setenv(key, value))
+				}
+			})
+
+			it.After(func() {
+				for _, key := range []string{"AWS_ENDPOINT_URL_S3", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_REGION"} {
+					os.Unsetenv(key)
+				}
+				os.Remove(tgz)
+				server.Close()
+			})
+
+			it("downloads the object with a signed request", func() {
+				server.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
+					http.ServeFile(w, r, tgz)
+				})
+
+				b, err := subject.Download(context.TODO(), "s3://some-bucket/buildpacks/some%20buildpack.tgz")
+				h.AssertNil(t, err)
+				assertBlob(t, b)
+
+				req := server.ReceivedRequests()[0]
+				h.AssertEq(t, req.URL.EscapedPath(), "/some-bucket/buildpacks/some%20buildpack.tgz")
+				h.AssertContains(t, req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=some-access-key/")
+				h.AssertContains(t, req.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request")
+			})
+
+			it("returns an error if the key is missing", func() {
+				_, err := subject.Download(context.TODO(), "s3://some-bucket")
+				h.AssertError(t, err, "invalid S3 URI 's3://some-bucket'")
+			})
+		})
+
+		when("is oci uri", func() {
+			var (
+				server *httptest.Server
+				tgz    string
+				ref    string
+			)
+
+			it.Before(func() {
+				server = httptest.NewServer(registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))))
+				tgz = h.CreateTGZ(t, filepath.Join("testdata", "blob"), "./", 0777)
+
+				layer, err := tarball.LayerFromFile(tgz)
+				h.AssertNil(t, err)
+				artifact, err := mutate.AppendLayers(empty.Image, layer)
+				h.AssertNil(t, err)
+
+				ref = strings.TrimPrefix(server.URL, "http://") + "/buildpacks/some-buildpack:1.0.0"
+				parsed, err := name.ParseReference(ref)
+				h.AssertNil(t, err)
+				h.AssertNil(t, remote.Write(parsed, artifact))
+			})
+
+			it.After(func() {
+				os.Remove(tgz)
+				server.Close()
+			})
+
+			it("downloads the layer of the artifact", func() {
+				b, err := subject.Download(context.TODO(), "oci://"+ref)
+				h.AssertNil(t, err)
+				assertBlob(t, b)
+			})
+
+			it("uses the cached layer", func() {
+				_, err := subject.Download(context.TODO(), "oci://"+ref)
+				h.AssertNil(t, err)
+
+				_, err = os.Stat(filepath.Join(cacheDir, "sha256", strings.TrimPrefix(fileDigest(t, tgz), "sha256:")))
+				h.AssertNil(t, err)
+			})
+
+			when("offline", func() {
+				it("uses the layer of a previous download", func() {
+					_, err := subject.Download(context.TODO(), "oci://"+ref)
+					h.AssertNil(t, err)
+					server.Close()
+
+					b, err := blob.NewDownloader(&logger{ioutil.Discard}, cacheDir, blob.WithOffline(true)).Download(context.TODO(), "oci://"+ref)
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+				})
+
+				it("uses a seeded layer", func() {
+					server.Close()
+					offline := blob.NewDownloader(&logger{ioutil.Discard}, cacheDir, blob.WithOffline(true))
+
+					fh, err := os.Open(tgz)
+					h.AssertNil(t, err)
+					defer fh.Close()
+					h.AssertNil(t, offline.(blob.Seeder).Seed("oci://"+ref, fh))
+
+					b, err := offline.Download(context.TODO(), "oci://"+ref)
+					h.AssertNil(t, err)
+					assertBlob(t, b)
+				})
+			})
+
+			it("doesn't record the digest of a layer that couldn't be cached", func() {
+				h.AssertNil(t, os.MkdirAll(cacheDir, 0750))
+				h.AssertNil(t, ioutil.WriteFile(filepath.Join(cacheDir, "sha256"), nil, 0600))
+
+				_, err := subject.Download(context.TODO(), "oci://"+ref)
+				h.AssertNotNil(t, err)
+
+				var digests []string
+				h.AssertNil(t, filepath.Walk(cacheDir, func(path string, info os.FileInfo, err error) error {
+					if strings.HasSuffix(path, ".digest") {
+						digests = append(digests, path)
+					}
+					return err
+				}))
+				h.AssertEq(t, len(digests), 0)
+			})
+
+			it("returns an error if the artifact doesn't exist", func() {
+				_, err := subject.Download(context.TODO(), "oci://"+strings.TrimPrefix(server.URL, "http://")+"/buildpacks/missing:1.0.0")
+				h.AssertError(t, err, "fetching OCI artifact")
+			})
+		})
 	})
 }
 
@@ -174,6 +534,19 @@ func assertBlob(t *testing.T, b blob.Blob) {
 	h.AssertEq(t, string(bytes), "contents")
 }
 
+func fileDigest(t *testing.T, path string) string {
+	t.Helper()
+	contents, err := ioutil.ReadFile(path)
+	h.AssertNil(t, err)
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(contents))
+}
+
+type authenticatorFunc func(req *http.Request) error
+
+func (f authenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
 type logger struct {
 	writer io.Writer
 }
@@ -0,0 +1,130 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/style"
+)
+
+// handleOCI downloads an artifact with a single layer, such as a buildpack archive, from an OCI registry with a URI
+// in the form oci://<registry>/<repository>[:<tag>|@<digest>]. The layer is cached by its digest.
+func (d *downloader) handleOCI(ctx context.Context, uri string) (string, error) {
+	ref, err := name.ParseReference(strings.TrimPrefix(uri, "oci://"))
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing OCI reference %s", style.Symbol(uri))
+	}
+
+	if d.offline {
+		return d.cachedOCILayer(uri)
+	}
+
+	transport, err := d.httpTransport()
+	if err != nil {
+		return "", err
+	}
+
+	artifact, err := remote.Image(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(d.keychain), remote.WithTransport(transport))
+	if err != nil {
+		return "", errors.Wrapf(err, "fetching OCI artifact %s", style.Symbol(uri))
+	}
+
+	layers, err := artifact.Layers()
+	if err != nil {
+		return "", errors.Wrapf(err, "reading layers of OCI artifact %s", style.Symbol(uri))
+	}
+	if len(layers) != 1 {
+		return "", errors.Errorf("OCI artifact %s must have exactly one layer, found %d", style.Symbol(uri), len(layers))
+	}
+
+	digest, err := layers[0].Digest()
+	if err != nil {
+		return "", err
+	}
+
+	cachePath := d.digestCachePath(digest.String())
+	unlock := d.lockCachePath(cachePath)
+	defer unlock()
+
+	if exists, err := fileExists(cachePath); err != nil {
+		return "", err
+	} else if exists {
+		d.logger.Debugf("Using cached version of %s", style.Symbol(uri))
+		return cachePath, d.writeOCIDigest(uri, digest.String())
+	}
+
+	d.logger.Infof("Downloading from %s", style.Symbol(uri))
+	rc, err := layers[0].Compressed()
+	if err != nil {
+		return "", errors.Wrapf(err, "downloading layer of OCI artifact %s", style.Symbol(uri))
+	}
+	defer rc.Close()
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0750); err != nil {
+		return "", err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(cachePath), "download-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmpFile, hasher), rc); err != nil {
+		return "", errors.Wrapf(err, "downloading layer of OCI artifact %s", style.Symbol(uri))
+	}
+	if actual := hex.EncodeToString(hasher.Sum(nil)); actual != digest.Hex {
+		return "", errors.Errorf("checksum mismatch: expected %s, got %s", style.Symbol(digest.String()), style.Symbol(sha256Prefix+actual))
+	}
+	if err := tmpFile.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpFile.Name(), cachePath); err != nil {
+		return "", err
+	}
+
+	// the digest is only recorded once the layer is cached, so that it's never found offline without it
+	return cachePath, d.writeOCIDigest(uri, digest.String())
+}
+
+// cachedOCILayer returns the cached layer of the artifact uri was last resolved to
+func (d *downloader) cachedOCILayer(uri string) (string, error) {
+	digest, err := readFileIfExists(d.uriCachePath(uri) + ".digest")
+	if err != nil {
+		return "", err
+	}
+	if digest == "" {
+		return "", errOffline(uri)
+	}
+
+	cachePath := d.digestCachePath(digest)
+	if exists, err := fileExists(cachePath); err != nil {
+		return "", err
+	} else if !exists {
+		return "", errOffline(uri)
+	}
+
+	d.logger.Debugf("Using cached version of %s", style.Symbol(uri))
+	return cachePath, nil
+}
+
+// writeOCIDigest records the digest of the layer of the artifact uri resolved to, so that it can be found offline
+func (d *downloader) writeOCIDigest(uri, digest string) error {
+	digestFile := d.uriCachePath(uri) + ".digest"
+	if err := os.MkdirAll(filepath.Dir(digestFile), 0750); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(digestFile, []byte(digest), 0600)
+}
@@ -3,17 +3,26 @@ package buildpack
 import (
 	"archive/tar"
 	"compress/gzip"
+	"io"
 	"io/ioutil"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/buildpacks/imgutil/layer"
 
 	"github.com/buildpacks/imgutil"
+	"github.com/buildpacks/imgutil/remote"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	v1remote "github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/pkg/errors"
 
 	"github.com/buildpacks/pack/internal/stack"
@@ -33,6 +42,7 @@ type WorkableImage interface {
 
 type layoutImage struct {
 	v1.Image
+	compression dist.LayerCompression
 }
 
 func (i *layoutImage) SetLabel(key string, val string) error {
@@ -50,7 +60,7 @@ func (i *layoutImage) SetLabel(key string, val string) error {
 }
 
 func (i *layoutImage) AddLayerWithDiffID(path, _ string) error {
-	tarLayer, err := tarball.LayerFromFile(path, tarball.WithCompressionLevel(gzip.DefaultCompression))
+	tarLayer, err := CompressedLayer(path, i.compression)
 	if err != nil {
 		return err
 	}
@@ -65,6 +75,27 @@ type PackageBuilder struct {
 	buildpack    Buildpack
 	dependencies []Buildpack
 	imageFactory ImageFactory
+	compression  dist.LayerCompression
+	keychain     authn.Keychain
+
+	// validated is true once the buildpacks have been validated, until they change
+	validated bool
+}
+
+// gzipLayersImage is an image whose layers are compressed before they are added, for images that
+// would otherwise compress them with the default gzip level
+type gzipLayersImage struct {
+	WorkableImage
+	compression dist.LayerCompression
+	tmpDir      string
+}
+
+func (i *gzipLayersImage) AddLayerWithDiffID(path, diffID string) error {
+	compressedPath, err := GzipLayerTar(path, i.tmpDir, i.compression)
+	if err != nil {
+		return err
+	}
+	return i.WorkableImage.AddLayerWithDiffID(compressedPath, diffID)
 }
 
 // TODO: Rename to PackageBuilder
@@ -76,10 +107,24 @@ func NewBuilder(imageFactory ImageFactory) *PackageBuilder {
 
 func (b *PackageBuilder) SetBuildpack(buildpack Buildpack) {
 	b.buildpack = buildpack
+	b.validated = false
 }
 
 func (b *PackageBuilder) AddDependency(buildpack Buildpack) {
 	b.dependencies = append(b.dependencies, buildpack)
+	b.validated = false
+}
+
+// SetLayerCompression sets how the layers of the package are compressed. Images saved to the daemon are
+// left to the daemon to compress.
+func (b *PackageBuilder) SetLayerCompression(compression dist.LayerCompression) {
+	b.compression = compression
+}
+
+// SetKeychain sets the credentials to publish images with, when their layers are compressed with an
+// algorithm other than gzip and they are pushed to the registry directly
+func (b *PackageBuilder) SetKeychain(keychain authn.Keychain) {
+	b.keychain = keychain
 }
 
 func (b *PackageBuilder) finalizeImage(image WorkableImage, tmpDir string) error {
@@ -91,7 +136,16 @@ func (b *PackageBuilder) finalizeImage(image WorkableImage, tmpDir string) error
 	}
 
 	bpLayers := dist.BuildpackLayers{}
+	addedBuildpacks := map[string]bool{}
 	for _, bp := range append(b.dependencies, b.buildpack) {
+		// the same buildpack may be provided by more than one dependency, e.g. by two packages, validate
+		// ensures the copies have the same contents
+		fullName := bp.Descriptor().Info.FullName()
+		if addedBuildpacks[fullName] {
+			continue
+		}
+		addedBuildpacks[fullName] = true
+
 		bpLayerTar, err := ToLayerTar(tmpDir, bp)
 		if err != nil {
 			return err
@@ -120,6 +174,10 @@ func (b *PackageBuilder) finalizeImage(image WorkableImage, tmpDir string) error
 }
 
 func (b *PackageBuilder) validate() error {
+	if b.validated {
+		return nil
+	}
+
 	if b.buildpack == nil {
 		return errors.New("buildpack must be set")
 	}
@@ -128,10 +186,32 @@ func (b *PackageBuilder) validate() error {
 		return err
 	}
 
+	if err := validateNoCycles(b.buildpack, b.buildpacksByName()); err != nil {
+		return err
+	}
+
+	bps := append([]Buildpack{b.buildpack}, b.dependencies...)
+	if err := validateBuildpackAPIs(bps); err != nil {
+		return err
+	}
+
+	if err := validateMixins(bps); err != nil {
+		return err
+	}
+
+	if err := validateOptionalConsistency(b.buildpack, b.buildpacksByName()); err != nil {
+		return err
+	}
+
+	if err := validateNoConflictingCopies(bps); err != nil {
+		return err
+	}
+
 	if len(b.resolvedStacks()) == 0 {
 		return errors.Errorf("no compatible stacks among provided buildpacks")
 	}
 
+	b.validated = true
 	return nil
 }
 
@@ -155,7 +235,11 @@ func (b *PackageBuilder) SaveAsFile(path, imageOS string) error {
 		return err
 	}
 
-	layoutImage, err := newLayoutImage(imageOS)
+	if err := b.compression.Validate(); err != nil {
+		return err
+	}
+
+	layoutImage, err := newLayoutImage(imageOS, b.compression)
 	if err != nil {
 		return errors.Wrap(err, "creating layout image")
 	}
@@ -196,7 +280,152 @@ func (b *PackageBuilder) SaveAsFile(path, imageOS string) error {
 	return archive.WriteDirToTar(tw, layoutDir, "/", 0, 0, 0755, true, false, nil)
 }
 
-func newLayoutImage(imageOS string) (*layoutImage, error) {
+// SaveAsDir writes the buildpacks of the package to a directory, in the layout they have on a builder,
+// i.e. <escaped id>/<version>/ per buildpack
+func (b *PackageBuilder) SaveAsDir(dir string) error {
+	if err := b.validate(); err != nil {
+		return err
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "reading output directory")
+	}
+	if len(entries) > 0 {
+		return errors.Errorf("output directory %s is not empty", style.Symbol(dir))
+	}
+
+	extracted := map[string]bool{}
+	for _, bp := range append([]Buildpack{b.buildpack}, b.dependencies...) {
+		fullName := bp.Descriptor().Info.FullName()
+		if extracted[fullName] {
+			continue
+		}
+		extracted[fullName] = true
+
+		if err := extractBuildpack(bp, dir); err != nil {
+			return errors.Wrapf(err, "extracting buildpack %s", style.Symbol(fullName))
+		}
+	}
+
+	return nil
+}
+
+// extractBuildpack writes the contents of the layer of a buildpack, found under /cnb/buildpacks, to dest
+func extractBuildpack(bp Buildpack, dest string) error {
+	rc, err := bp.Open()
+	if err != nil {
+		return errors.Wrap(err, "opening buildpack")
+	}
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "reading buildpack")
+		}
+
+		// windows layers keep their contents under Files/
+		name := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(header.Name)), "/Files")
+		if !strings.HasPrefix(name, "/cnb/buildpacks/") {
+			continue
+		}
+		name = strings.TrimPrefix(name, "/cnb/buildpacks/")
+
+		// symlinks written by this or an earlier buildpack must not be followed, neither to write
+		// under them nor to write through them
+		if err := checkNoSymlinks(dest, name); err != nil {
+			return errors.Wrapf(err, "entry %s", style.Symbol(header.Name))
+		}
+
+		target := filepath.Join(dest, filepath.FromSlash(name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(tr, target, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			linkname, err := symlinkTarget(name, header.Linkname)
+			if err != nil {
+				return errors.Wrapf(err, "entry %s", style.Symbol(header.Name))
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(filepath.FromSlash(linkname), target); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// checkNoSymlinks errors if name, or any of its parent directories, is a symlink under dest
+func checkNoSymlinks(dest, name string) error {
+	for p := name; p != "."; p = path.Dir(p) {
+		fi, err := os.Lstat(filepath.Join(dest, filepath.FromSlash(p)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return errors.Errorf("would be written through symlink %s", style.Symbol(p))
+		}
+	}
+	return nil
+}
+
+// symlinkTarget returns the target of the symlink name relative to its directory. Absolute targets are
+// only allowed under /cnb/buildpacks, targets outside of the buildpacks directory are rejected.
+func symlinkTarget(name, linkname string) (string, error) {
+	var resolved string
+	if path.IsAbs(linkname) {
+		if !strings.HasPrefix(path.Clean(linkname), "/cnb/buildpacks/") {
+			return "", errors.Errorf("symlink target %s is outside of the buildpacks directory", style.Symbol(linkname))
+		}
+		resolved = strings.TrimPrefix(path.Clean(linkname), "/cnb/buildpacks/")
+	} else {
+		resolved = path.Join(path.Dir(name), linkname)
+	}
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return "", errors.Errorf("symlink target %s is outside of the buildpacks directory", style.Symbol(linkname))
+	}
+
+	// the link is relative to its directory, so it resolves within the output directory wherever that is
+	var up []string
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		up = append(up, "..")
+	}
+	return path.Join(append(up, resolved)...), nil
+}
+
+func writeFile(r io.Reader, target string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	fh, err := os.OpenFile(filepath.Clean(target), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	_, err = io.Copy(fh, r)
+	return err
+}
+
+// newLayoutImage creates an empty image whose layers are compressed as configured. Images with zstd or
+// estargz layers use the OCI media types, as Docker manifests only allow gzip layers.
+func newLayoutImage(imageOS string, compression dist.LayerCompression) (*layoutImage, error) {
 	i := empty.Image
 
 	configFile, err := i.ConfigFile()
@@ -227,7 +456,12 @@ func newLayoutImage(imageOS string) (*layoutImage, error) {
 		}
 	}
 
-	return &layoutImage{Image: i}, nil
+	if !compression.IsGzip() {
+		i = mutate.MediaType(i, types.OCIManifestSchema1)
+		i = mutate.ConfigMediaType(i, types.OCIConfigJSON)
+	}
+
+	return &layoutImage{Image: i, compression: compression}, nil
 }
 
 func (b *PackageBuilder) SaveAsImage(repoName string, publish bool, imageOS string) (imgutil.Image, error) {
@@ -235,6 +469,13 @@ func (b *PackageBuilder) SaveAsImage(repoName string, publish bool, imageOS stri
 		return nil, err
 	}
 
+	if err := b.compression.Validate(); err != nil {
+		return nil, err
+	}
+	if publish && !b.compression.IsGzip() {
+		return b.publishLayoutImage(repoName, imageOS)
+	}
+
 	image, err := b.imageFactory.NewImage(repoName, !publish, imageOS)
 	if err != nil {
 		return nil, errors.Wrapf(err, "creating image")
@@ -246,7 +487,12 @@ func (b *PackageBuilder) SaveAsImage(repoName string, publish bool, imageOS stri
 	}
 	defer os.RemoveAll(tmpDir)
 
-	if err := b.finalizeImage(image, tmpDir); err != nil {
+	var workableImage WorkableImage = image
+	if publish && !b.compression.IsDefault() {
+		workableImage = &gzipLayersImage{WorkableImage: image, compression: b.compression, tmpDir: tmpDir}
+	}
+
+	if err := b.finalizeImage(workableImage, tmpDir); err != nil {
 		return nil, err
 	}
 
@@ -257,6 +503,41 @@ func (b *PackageBuilder) SaveAsImage(repoName string, publish bool, imageOS stri
 	return image, nil
 }
 
+// publishLayoutImage pushes the package to the registry directly, as images created by the image factory
+// only take gzip compressed layers
+func (b *PackageBuilder) publishLayoutImage(repoName, imageOS string) (imgutil.Image, error) {
+	layoutImage, err := newLayoutImage(imageOS, b.compression)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating layout image")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "package-buildpack")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := b.finalizeImage(layoutImage, tmpDir); err != nil {
+		return nil, err
+	}
+
+	ref, err := name.ParseReference(repoName, name.WeakValidation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing image name %s", style.Symbol(repoName))
+	}
+
+	keychain := b.keychain
+	if keychain == nil {
+		keychain = authn.DefaultKeychain
+	}
+
+	if err := v1remote.Write(ref, layoutImage.Image, v1remote.WithAuthFromKeychain(keychain)); err != nil {
+		return nil, errors.Wrapf(err, "publishing image %s", style.Symbol(repoName))
+	}
+
+	return remote.NewImage(repoName, keychain, remote.FromBaseImage(repoName))
+}
+
 func validateBuildpacks(mainBP Buildpack, depBPs []Buildpack) error {
 	depsWithRefs := map[string][]dist.BuildpackInfo{}
 
@@ -7,22 +7,31 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/buildpacks/imgutil/fakes"
 	"github.com/buildpacks/imgutil/layer"
 	"github.com/buildpacks/lifecycle/api"
 	"github.com/golang/mock/gomock"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/stream"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/heroku/color"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 
 	ifakes "github.com/buildpacks/pack/internal/fakes"
+	"github.com/buildpacks/pack/pkg/archive"
+	"github.com/buildpacks/pack/pkg/blob"
 	"github.com/buildpacks/pack/pkg/buildpack"
 	"github.com/buildpacks/pack/pkg/dist"
 	"github.com/buildpacks/pack/pkg/testmocks"
@@ -203,6 +212,163 @@ func testPackageBuilder(t *testing.T, when spec.G, it spec.S) {
 						})
 					})
 
+					when("validate dependency graph", func() {
+						when("dependencies form a cycle", func() {
+							it("should error", func() {
+								mainBP, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+									API:  api.MustParse("0.2"),
+									Info: dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"},
+									Order: dist.Order{{
+										Group: []dist.BuildpackRef{
+											{BuildpackInfo: dist.BuildpackInfo{ID: "bp.nested.id", Version: "bp.nested.version"}},
+										},
+									}},
+								}, 0644)
+								h.AssertNil(t, err)
+
+								builder := buildpack.NewBuilder(mockImageFactory(expectedImageOS))
+								builder.SetBuildpack(mainBP)
+
+								nestedBP, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+									API:  api.MustParse("0.2"),
+									Info: dist.BuildpackInfo{ID: "bp.nested.id", Version: "bp.nested.version"},
+									Order: dist.Order{{
+										Group: []dist.BuildpackRef{
+											{BuildpackInfo: dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"}},
+										},
+									}},
+								}, 0644)
+								h.AssertNil(t, err)
+								builder.AddDependency(nestedBP)
+								builder.AddDependency(mainBP)
+
+								err = testFn(builder)
+								h.AssertError(t, err, "buildpack 'bp.1.id@bp.1.version' has a dependency cycle: bp.1.id@bp.1.version -> bp.nested.id@bp.nested.version -> bp.1.id@bp.1.version")
+							})
+						})
+
+						when("a buildpack uses a Buildpack API no lifecycle supports", func() {
+							it("should error", func() {
+								bp, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+									API:    api.MustParse("99.0"),
+									Info:   dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"},
+									Stacks: []dist.Stack{{ID: "some.stack"}},
+								}, 0644)
+								h.AssertNil(t, err)
+
+								builder := buildpack.NewBuilder(mockImageFactory(expectedImageOS))
+								builder.SetBuildpack(bp)
+
+								err = testFn(builder)
+								h.AssertError(t, err, "buildpack 'bp.1.id@bp.1.version' uses Buildpack API '99.0', which is not supported by any released lifecycle")
+							})
+						})
+
+						when("a buildpack requires a mixin for an unknown stage", func() {
+							it("should error", func() {
+								bp, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+									API:    api.MustParse("0.2"),
+									Info:   dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"},
+									Stacks: []dist.Stack{{ID: "some.stack", Mixins: []string{"launch:mixinA"}}},
+								}, 0644)
+								h.AssertNil(t, err)
+
+								builder := buildpack.NewBuilder(mockImageFactory(expectedImageOS))
+								builder.SetBuildpack(bp)
+
+								err = testFn(builder)
+								h.AssertError(t, err, "buildpack 'bp.1.id@bp.1.version' requires mixin 'launch:mixinA' for unknown stage 'launch' on stack 'some.stack'")
+							})
+						})
+
+						when("a buildpack requires a mixin without a name", func() {
+							it("should error", func() {
+								bp, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+									API:    api.MustParse("0.2"),
+									Info:   dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"},
+									Stacks: []dist.Stack{{ID: "some.stack", Mixins: []string{"build:"}}},
+								}, 0644)
+								h.AssertNil(t, err)
+
+								builder := buildpack.NewBuilder(mockImageFactory(expectedImageOS))
+								builder.SetBuildpack(bp)
+
+								err = testFn(builder)
+								h.AssertError(t, err, "buildpack 'bp.1.id@bp.1.version' requires a mixin without a name on stack 'some.stack'")
+							})
+						})
+
+						when("a buildpack is both optional and required in a group of a nested order", func() {
+							it("should error", func() {
+								mainBP, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+									API:  api.MustParse("0.2"),
+									Info: dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"},
+									Order: dist.Order{{
+										Group: []dist.BuildpackRef{
+											{BuildpackInfo: dist.BuildpackInfo{ID: "bp.2.id", Version: "bp.2.version"}},
+											{BuildpackInfo: dist.BuildpackInfo{ID: "bp.nested.id", Version: "bp.nested.version"}, Optional: true},
+										},
+									}},
+								}, 0644)
+								h.AssertNil(t, err)
+
+								nestedBP, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+									API:  api.MustParse("0.2"),
+									Info: dist.BuildpackInfo{ID: "bp.nested.id", Version: "bp.nested.version"},
+									Order: dist.Order{{
+										Group: []dist.BuildpackRef{
+											{BuildpackInfo: dist.BuildpackInfo{ID: "bp.2.id", Version: "bp.2.version"}},
+										},
+									}},
+								}, 0644)
+								h.AssertNil(t, err)
+
+								bp2, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+									API:    api.MustParse("0.2"),
+									Info:   dist.BuildpackInfo{ID: "bp.2.id", Version: "bp.2.version"},
+									Stacks: []dist.Stack{{ID: "some.stack"}},
+								}, 0644)
+								h.AssertNil(t, err)
+
+								builder := buildpack.NewBuilder(mockImageFactory(expectedImageOS))
+								builder.SetBuildpack(mainBP)
+								builder.AddDependency(nestedBP)
+								builder.AddDependency(bp2)
+
+								err = testFn(builder)
+								h.AssertError(t, err, "buildpack 'bp.2.id@bp.2.version' is both optional and required in a group of buildpack 'bp.1.id@bp.1.version'")
+							})
+						})
+
+						when("a buildpack is provided more than once with different contents", func() {
+							it("should error", func() {
+								mainBP, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+									API:  api.MustParse("0.2"),
+									Info: dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"},
+									Order: dist.Order{{
+										Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "bp.2.id", Version: "bp.2.version"}}},
+									}},
+								}, 0644)
+								h.AssertNil(t, err)
+
+								builder := buildpack.NewBuilder(mockImageFactory(expectedImageOS))
+								builder.SetBuildpack(mainBP)
+								for _, options := range [][]ifakes.FakeBuildpackOption{nil, {ifakes.WithExtraBuildpackContents("some-file", "some-contents")}} {
+									bp2, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+										API:    api.MustParse("0.2"),
+										Info:   dist.BuildpackInfo{ID: "bp.2.id", Version: "bp.2.version"},
+										Stacks: []dist.Stack{{ID: "some.stack"}},
+									}, 0644, options...)
+									h.AssertNil(t, err)
+									builder.AddDependency(bp2)
+								}
+
+								err = testFn(builder)
+								h.AssertError(t, err, "buildpack 'bp.2.id@bp.2.version' is provided more than once with different contents")
+							})
+						})
+					})
+
 					when("validate stacks", func() {
 						when("buildpack is meta-buildpack", func() {
 							it("should succeed", func() {
@@ -496,7 +662,217 @@ func testPackageBuilder(t *testing.T, when spec.G, it spec.S) {
 		}
 	})
 
+	when("#DependencyTree", func() {
+		it("follows the order of each meta-buildpack", func() {
+			mainBP, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+				API:  api.MustParse("0.2"),
+				Info: dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"},
+				Order: dist.Order{
+					{Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "bp.nested.id", Version: "bp.nested.version"}}}},
+					{Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "bp.2.id", Version: "bp.2.version"}, Optional: true}}},
+				},
+			}, 0644)
+			h.AssertNil(t, err)
+
+			nestedBP, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+				API:  api.MustParse("0.2"),
+				Info: dist.BuildpackInfo{ID: "bp.nested.id", Version: "bp.nested.version"},
+				Order: dist.Order{
+					{Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "bp.2.id", Version: "bp.2.version"}}}},
+				},
+			}, 0644)
+			h.AssertNil(t, err)
+
+			bp2, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+				API:    api.MustParse("0.3"),
+				Info:   dist.BuildpackInfo{ID: "bp.2.id", Version: "bp.2.version"},
+				Stacks: []dist.Stack{{ID: "some.stack"}},
+			}, 0644)
+			h.AssertNil(t, err)
+
+			builder := buildpack.NewBuilder(mockImageFactory(""))
+			builder.SetBuildpack(mainBP)
+			builder.AddDependency(nestedBP)
+			builder.AddDependency(bp2)
+
+			tree, err := builder.DependencyTree()
+			h.AssertNil(t, err)
+
+			leaf := buildpack.DependencyNode{Info: dist.BuildpackInfo{ID: "bp.2.id", Version: "bp.2.version"}, API: api.MustParse("0.3")}
+			optionalLeaf := leaf
+			optionalLeaf.Optional = true
+			h.AssertEq(t, tree, buildpack.DependencyNode{
+				Info: dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"},
+				API:  api.MustParse("0.2"),
+				Groups: [][]buildpack.DependencyNode{
+					{{
+						Info:   dist.BuildpackInfo{ID: "bp.nested.id", Version: "bp.nested.version"},
+						API:    api.MustParse("0.2"),
+						Groups: [][]buildpack.DependencyNode{{leaf}},
+					}},
+					{optionalLeaf},
+				},
+			})
+		})
+
+		it("validates the buildpacks again once they change", func() {
+			bp1, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+				API:    api.MustParse("0.2"),
+				Info:   dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"},
+				Stacks: []dist.Stack{{ID: "some.stack"}},
+			}, 0644)
+			h.AssertNil(t, err)
+
+			unusedBP, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+				API:    api.MustParse("0.2"),
+				Info:   dist.BuildpackInfo{ID: "bp.unused.id", Version: "bp.unused.version"},
+				Stacks: []dist.Stack{{ID: "some.stack"}},
+			}, 0644)
+			h.AssertNil(t, err)
+
+			builder := buildpack.NewBuilder(mockImageFactory(""))
+			builder.SetBuildpack(bp1)
+
+			_, err = builder.DependencyTree()
+			h.AssertNil(t, err)
+
+			builder.AddDependency(unusedBP)
+			_, err = builder.SaveAsImage("some/package", false, "linux")
+			h.AssertError(t, err, "buildpack 'bp.unused.id@bp.unused.version' is not used by buildpack 'bp.1.id@bp.1.version'")
+		})
+	})
+
 	when("#SaveAsImage", func() {
+		when("layer compression is set", func() {
+			var buildpack1 buildpack.Buildpack
+
+			it.Before(func() {
+				var err error
+				buildpack1, err = ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+					API:    api.MustParse("0.2"),
+					Info:   dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"},
+					Stacks: []dist.Stack{{ID: "stack.id.1"}},
+				}, 0644)
+				h.AssertNil(t, err)
+			})
+
+			it("adds gzip compressed layers to published images", func() {
+				imageFactory := testmocks.NewMockImageFactory(mockController)
+				fakePackageImage := fakes.NewImage("some/package", "", nil)
+				imageFactory.EXPECT().NewImage("some/package", false, "linux").Return(fakePackageImage, nil)
+
+				builder := buildpack.NewBuilder(imageFactory)
+				builder.SetBuildpack(buildpack1)
+				builder.SetLayerCompression(dist.LayerCompression{Algorithm: dist.CompressionGzip, Level: 9})
+
+				_, err := builder.SaveAsImage("some/package", true, "linux")
+				h.AssertNil(t, err)
+				h.AssertEq(t, fakePackageImage.NumberOfAddedLayers(), 1)
+			})
+
+			it("publishes images with zstd compressed layers to the registry", func() {
+				server := httptest.NewServer(registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))))
+				defer server.Close()
+				repoName := strings.TrimPrefix(server.URL, "http://") + "/some/package"
+
+				builder := buildpack.NewBuilder(mockImageFactory(""))
+				builder.SetBuildpack(buildpack1)
+				builder.SetLayerCompression(dist.LayerCompression{Algorithm: dist.CompressionZstd})
+
+				_, err := builder.SaveAsImage(repoName, true, "linux")
+				h.AssertNil(t, err)
+
+				ref, err := name.ParseReference(repoName, name.WeakValidation)
+				h.AssertNil(t, err)
+				img, err := remote.Image(ref)
+				h.AssertNil(t, err)
+				mediaType, err := img.MediaType()
+				h.AssertNil(t, err)
+				h.AssertEq(t, mediaType, types.OCIManifestSchema1)
+				manifest, err := img.Manifest()
+				h.AssertNil(t, err)
+				h.AssertEq(t, len(manifest.Layers), 1)
+				h.AssertEq(t, manifest.Layers[0].MediaType, buildpack.MediaTypeZstdLayer)
+
+				metadata, err := img.ConfigFile()
+				h.AssertNil(t, err)
+				h.AssertContains(t, metadata.Config.Labels["io.buildpacks.buildpackage.metadata"], `"id":"bp.1.id"`)
+			})
+
+			it("reads back the buildpacks of images published with zstd compressed layers", func() {
+				server := httptest.NewServer(registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))))
+				defer server.Close()
+				repoName := strings.TrimPrefix(server.URL, "http://") + "/some/package"
+
+				builder := buildpack.NewBuilder(mockImageFactory(""))
+				builder.SetBuildpack(buildpack1)
+				builder.SetLayerCompression(dist.LayerCompression{Algorithm: dist.CompressionZstd})
+
+				packageImage, err := builder.SaveAsImage(repoName, true, "linux")
+				h.AssertNil(t, err)
+
+				mainBP, _, err := buildpack.ExtractBuildpacks(packageImage)
+				h.AssertNil(t, err)
+
+				rc, err := mainBP.Open()
+				h.AssertNil(t, err)
+				defer rc.Close()
+
+				_, contents, err := archive.ReadTarEntry(rc, "/cnb/buildpacks/bp.1.id/bp.1.version/bin/build")
+				h.AssertNil(t, err)
+				h.AssertEq(t, string(contents), "build-contents")
+			})
+		})
+
+		when("a dependency is provided more than once", func() {
+			var mainBP buildpack.Buildpack
+
+			it.Before(func() {
+				var err error
+				mainBP, err = ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+					API:  api.MustParse("0.2"),
+					Info: dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"},
+					Order: dist.Order{{
+						Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "bp.2.id", Version: "bp.2.version"}}},
+					}},
+				}, 0644)
+				h.AssertNil(t, err)
+			})
+
+			newDependency := func(options ...ifakes.FakeBuildpackOption) buildpack.Buildpack {
+				bp, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+					API:    api.MustParse("0.2"),
+					Info:   dist.BuildpackInfo{ID: "bp.2.id", Version: "bp.2.version"},
+					Stacks: []dist.Stack{{ID: "some.stack"}},
+				}, 0644, options...)
+				h.AssertNil(t, err)
+				return bp
+			}
+
+			it("adds identical copies once", func() {
+				builder := buildpack.NewBuilder(mockImageFactory("linux"))
+				builder.SetBuildpack(mainBP)
+				builder.AddDependency(newDependency())
+				builder.AddDependency(newDependency())
+
+				packageImage, err := builder.SaveAsImage("some/package", false, "linux")
+				h.AssertNil(t, err)
+
+				fakePackageImage := packageImage.(*fakes.Image)
+				h.AssertEq(t, fakePackageImage.NumberOfAddedLayers(), 2)
+			})
+
+			it("errors if the copies have different contents", func() {
+				builder := buildpack.NewBuilder(mockImageFactory("linux"))
+				builder.SetBuildpack(mainBP)
+				builder.AddDependency(newDependency())
+				builder.AddDependency(newDependency(ifakes.WithExtraBuildpackContents("some-file", "some-contents")))
+
+				_, err := builder.SaveAsImage("some/package", false, "linux")
+				h.AssertError(t, err, "buildpack 'bp.2.id@bp.2.version' is provided more than once with different contents")
+			})
+		})
+
 		it("sets metadata", func() {
 			buildpack1, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
 				API: api.MustParse("0.2"),
@@ -638,6 +1014,130 @@ func testPackageBuilder(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 
+	when("#SaveAsDir", func() {
+		var builder *buildpack.PackageBuilder
+
+		it.Before(func() {
+			mainBP, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+				API:  api.MustParse("0.2"),
+				Info: dist.BuildpackInfo{ID: "bp/meta", Version: "1.0.0"},
+				Order: dist.Order{{
+					Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "bp/nested", Version: "2.0.0"}}},
+				}},
+			}, 0644)
+			h.AssertNil(t, err)
+
+			nestedBP, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+				API:    api.MustParse("0.2"),
+				Info:   dist.BuildpackInfo{ID: "bp/nested", Version: "2.0.0"},
+				Stacks: []dist.Stack{{ID: "some.stack"}},
+			}, 0755)
+			h.AssertNil(t, err)
+
+			builder = buildpack.NewBuilder(mockImageFactory(""))
+			builder.SetBuildpack(mainBP)
+			builder.AddDependency(nestedBP)
+		})
+
+		it("writes each buildpack in the layout of a builder", func() {
+			outputDir := filepath.Join(tmpDir, "package")
+			h.AssertNil(t, builder.SaveAsDir(outputDir))
+
+			for _, descriptorPath := range []string{"bp_meta/1.0.0/buildpack.toml", "bp_nested/2.0.0/buildpack.toml"} {
+				_, err := os.Stat(filepath.Join(outputDir, descriptorPath))
+				h.AssertNil(t, err)
+			}
+
+			contents, err := ioutil.ReadFile(filepath.Join(outputDir, "bp_nested", "2.0.0", "bin", "build"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, string(contents), "build-contents")
+		})
+
+		it("errors if the directory is not empty", func() {
+			h.AssertNil(t, ioutil.WriteFile(filepath.Join(tmpDir, "some-file"), []byte("some-contents"), 0600))
+
+			err := builder.SaveAsDir(tmpDir)
+			h.AssertError(t, err, "is not empty")
+		})
+
+		when("the buildpack contains symlinks", func() {
+			var (
+				outsideFile string
+				outputDir   string
+			)
+
+			it.Before(func() {
+				outsideFile = filepath.Join(tmpDir, "outside")
+				h.AssertNil(t, ioutil.WriteFile(outsideFile, []byte("outside-contents"), 0600))
+				outputDir = filepath.Join(tmpDir, "package")
+			})
+
+			symlinkBuildpack := func(entries ...*tar.Header) buildpack.Buildpack {
+				tarPath := filepath.Join(tmpDir, "bp.tar")
+				f, err := os.Create(tarPath)
+				h.AssertNil(t, err)
+				tw := tar.NewWriter(f)
+				for _, header := range entries {
+					h.AssertNil(t, tw.WriteHeader(header))
+					if header.Typeflag == tar.TypeReg {
+						_, err := tw.Write([]byte("malicious-contents"))
+						h.AssertNil(t, err)
+					}
+				}
+				h.AssertNil(t, tw.Close())
+				h.AssertNil(t, f.Close())
+
+				return buildpack.FromBlob(dist.BuildpackDescriptor{
+					API:    api.MustParse("0.2"),
+					Info:   dist.BuildpackInfo{ID: "bp/links", Version: "1.0.0"},
+					Stacks: []dist.Stack{{ID: "some.stack"}},
+				}, blob.NewBlob(tarPath))
+			}
+
+			it("refuses to write a file through a symlink", func() {
+				builder := buildpack.NewBuilder(mockImageFactory(""))
+				builder.SetBuildpack(symlinkBuildpack(
+					&tar.Header{Name: "/cnb/buildpacks/bp_links/1.0.0/x", Typeflag: tar.TypeSymlink, Linkname: "y", Mode: 0777},
+					&tar.Header{Name: "/cnb/buildpacks/bp_links/1.0.0/x", Typeflag: tar.TypeReg, Size: int64(len("malicious-contents")), Mode: 0644},
+				))
+
+				err := builder.SaveAsDir(outputDir)
+				h.AssertError(t, err, "would be written through symlink 'bp_links/1.0.0/x'")
+			})
+
+			it("rejects symlinks resolving outside of the output directory", func() {
+				for _, linkname := range []string{outsideFile, "../../../outside"} {
+					builder := buildpack.NewBuilder(mockImageFactory(""))
+					builder.SetBuildpack(symlinkBuildpack(
+						&tar.Header{Name: "/cnb/buildpacks/bp_links/1.0.0/x", Typeflag: tar.TypeSymlink, Linkname: linkname, Mode: 0777},
+						&tar.Header{Name: "/cnb/buildpacks/bp_links/1.0.0/x", Typeflag: tar.TypeReg, Size: int64(len("malicious-contents")), Mode: 0644},
+					))
+
+					h.AssertNil(t, os.RemoveAll(outputDir))
+					err := builder.SaveAsDir(outputDir)
+					h.AssertError(t, err, "is outside of the buildpacks directory")
+				}
+
+				contents, err := ioutil.ReadFile(outsideFile)
+				h.AssertNil(t, err)
+				h.AssertEq(t, string(contents), "outside-contents")
+			})
+
+			it("keeps symlinks within the buildpacks directory", func() {
+				builder := buildpack.NewBuilder(mockImageFactory(""))
+				builder.SetBuildpack(symlinkBuildpack(
+					&tar.Header{Name: "/cnb/buildpacks/bp_links/1.0.0/bin/build", Typeflag: tar.TypeReg, Size: int64(len("malicious-contents")), Mode: 0755},
+					&tar.Header{Name: "/cnb/buildpacks/bp_links/1.0.0/bin/detect", Typeflag: tar.TypeSymlink, Linkname: "/cnb/buildpacks/bp_links/1.0.0/bin/build", Mode: 0777},
+				))
+
+				h.AssertNil(t, builder.SaveAsDir(outputDir))
+				linkname, err := os.Readlink(filepath.Join(outputDir, "bp_links", "1.0.0", "bin", "detect"))
+				h.AssertNil(t, err)
+				h.AssertEq(t, linkname, filepath.Join("..", "..", "..", "bp_links", "1.0.0", "bin", "build"))
+			})
+		})
+	})
+
 	when("#SaveAsFile", func() {
 		it("sets metadata", func() {
 			buildpack1, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
@@ -746,6 +1246,56 @@ func testPackageBuilder(t *testing.T, when spec.G, it spec.S) {
 					h.HasFileMode(0644)))
 		})
 
+		for _, compression := range []dist.LayerCompression{
+			{Algorithm: dist.CompressionGzip, Level: 1},
+			{Algorithm: dist.CompressionZstd, Level: 19},
+		} {
+			compression := compression
+
+			it(fmt.Sprintf("adds buildpack layers that can be read back with %s compression", compression.Algorithm), func() {
+				buildpack1, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+					API:    api.MustParse("0.2"),
+					Info:   dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"},
+					Stacks: []dist.Stack{{ID: "stack.id.1"}},
+				}, 0644)
+				h.AssertNil(t, err)
+
+				builder := buildpack.NewBuilder(mockImageFactory(""))
+				builder.SetBuildpack(buildpack1)
+				builder.SetLayerCompression(compression)
+
+				outputFile := filepath.Join(tmpDir, fmt.Sprintf("package-%s.cnb", h.RandString(10)))
+				h.AssertNil(t, builder.SaveAsFile(outputFile, "linux"))
+
+				mainBP, _, err := buildpack.BuildpacksFromOCILayoutBlob(blob.NewBlob(outputFile))
+				h.AssertNil(t, err)
+
+				bpReader, err := mainBP.Open()
+				h.AssertNil(t, err)
+				defer bpReader.Close()
+
+				_, contents, err := archive.ReadTarEntry(bpReader, "/cnb/buildpacks/bp.1.id/bp.1.version/bin/build")
+				h.AssertNil(t, err)
+				h.AssertEq(t, string(contents), "build-contents")
+			})
+		}
+
+		it("errors for an unknown compression algorithm", func() {
+			buildpack1, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
+				API:    api.MustParse("0.2"),
+				Info:   dist.BuildpackInfo{ID: "bp.1.id", Version: "bp.1.version"},
+				Stacks: []dist.Stack{{ID: "stack.id.1"}},
+			}, 0644)
+			h.AssertNil(t, err)
+
+			builder := buildpack.NewBuilder(mockImageFactory(""))
+			builder.SetBuildpack(buildpack1)
+			builder.SetLayerCompression(dist.LayerCompression{Algorithm: "lz4"})
+
+			err = builder.SaveAsFile(filepath.Join(tmpDir, "package.cnb"), "linux")
+			h.AssertError(t, err, "compression algorithm 'lz4' must be one of 'gzip', 'zstd' or 'estargz'")
+		})
+
 		it("adds baselayer + buildpack layers for windows", func() {
 			buildpack1, err := ifakes.NewFakeBuildpack(dist.BuildpackDescriptor{
 				API:    api.MustParse("0.2"),
@@ -0,0 +1,189 @@
+package buildpack
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/pkg/ioutils"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/dist"
+)
+
+// MediaTypeZstdLayer is the media type of zstd compressed layers
+const MediaTypeZstdLayer types.MediaType = "application/vnd.oci.image.layer.v1.tar+zstd"
+
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// CompressedLayer returns a layer from an uncompressed layer tar, compressed as configured
+func CompressedLayer(layerTarPath string, compression dist.LayerCompression) (v1.Layer, error) {
+	switch compression.Algorithm {
+	case "", dist.CompressionGzip:
+		return tarball.LayerFromFile(layerTarPath, tarball.WithCompressionLevel(gzipLevel(compression)))
+	case dist.CompressionEstargz:
+		return tarball.LayerFromFile(layerTarPath, tarball.WithCompressionLevel(gzipLevel(compression)), tarball.WithEstargz)
+	case dist.CompressionZstd:
+		return newZstdLayer(layerTarPath, compression.Level)
+	default:
+		return nil, compression.Validate()
+	}
+}
+
+// GzipLayerTar writes a gzip compressed copy of a layer tar into dir, for images that take layers
+// which are already compressed, and returns its path
+func GzipLayerTar(layerTarPath, dir string, compression dist.LayerCompression) (string, error) {
+	if !compression.IsGzip() {
+		return "", errors.Errorf("%s compression is not supported for images that take gzip compressed layers", style.Symbol(compression.Algorithm))
+	}
+
+	src, err := os.Open(filepath.Clean(layerTarPath))
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := ioutil.TempFile(dir, "layer-*.tar.gz")
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+	compressedPath := dst.Name()
+
+	zw, err := gzip.NewWriterLevel(dst, gzipLevel(compression))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(zw, src); err != nil {
+		return "", errors.Wrapf(err, "compressing %s", style.Symbol(layerTarPath))
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+
+	return compressedPath, dst.Close()
+}
+
+func gzipLevel(compression dist.LayerCompression) int {
+	if compression.Level == 0 {
+		return gzip.DefaultCompression
+	}
+	return compression.Level
+}
+
+// decompressZstdLayer returns the uncompressed contents of a layer read from an image. The image library only
+// decompresses gzip layers, so the layers of packages published with zstd compression are decompressed here.
+func decompressZstdLayer(rc io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(rc)
+	magic, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		rc.Close()
+		return nil, err
+	}
+
+	if !bytes.Equal(magic, zstdMagic) {
+		return ioutils.NewReadCloserWrapper(br, rc.Close), nil
+	}
+
+	decoder, err := zstd.NewReader(br)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+
+	return ioutils.NewReadCloserWrapper(decoder, func() error {
+		decoder.Close()
+		return rc.Close()
+	}), nil
+}
+
+// zstdLayer is a layer compressed with zstd, which the image library can't create
+type zstdLayer struct {
+	path           string
+	compressedPath string
+	digest         v1.Hash
+	diffID         v1.Hash
+	size           int64
+}
+
+func newZstdLayer(layerTarPath string, level int) (*zstdLayer, error) {
+	src, err := os.Open(filepath.Clean(layerTarPath))
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	compressedPath := layerTarPath + ".zst"
+	dst, err := os.Create(compressedPath)
+	if err != nil {
+		return nil, err
+	}
+	defer dst.Close()
+
+	var opts []zstd.EOption
+	if level != 0 {
+		opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+
+	compressedHasher := sha256.New()
+	zw, err := zstd.NewWriter(io.MultiWriter(dst, compressedHasher), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	uncompressedHasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(zw, uncompressedHasher), src); err != nil {
+		return nil, errors.Wrapf(err, "compressing %s", style.Symbol(layerTarPath))
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	info, err := dst.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return &zstdLayer{
+		path:           layerTarPath,
+		compressedPath: compressedPath,
+		digest:         v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(compressedHasher.Sum(nil))},
+		diffID:         v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(uncompressedHasher.Sum(nil))},
+		size:           info.Size(),
+	}, nil
+}
+
+func (l *zstdLayer) Digest() (v1.Hash, error) {
+	return l.digest, nil
+}
+
+func (l *zstdLayer) DiffID() (v1.Hash, error) {
+	return l.diffID, nil
+}
+
+func (l *zstdLayer) Compressed() (io.ReadCloser, error) {
+	return os.Open(l.compressedPath)
+}
+
+func (l *zstdLayer) Uncompressed() (io.ReadCloser, error) {
+	return os.Open(l.path)
+}
+
+func (l *zstdLayer) Size() (int64, error) {
+	return l.size, nil
+}
+
+func (l *zstdLayer) MediaType() (types.MediaType, error) {
+	return MediaTypeZstdLayer, nil
+}
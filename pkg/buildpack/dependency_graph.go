@@ -0,0 +1,240 @@
+package buildpack
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/buildpacks/lifecycle/api"
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/dist"
+)
+
+// DependencyNode is a buildpack of a package, along with the groups of buildpacks in its order if it is a meta-buildpack
+type DependencyNode struct {
+	Info     dist.BuildpackInfo
+	API      *api.Version
+	Optional bool
+	Groups   [][]DependencyNode
+}
+
+// DependencyTree returns the tree of buildpacks of the package, starting from the main buildpack and following the
+// order of each meta-buildpack. The buildpacks are validated once, saving the package afterwards doesn't validate
+// them again.
+func (b *PackageBuilder) DependencyTree() (DependencyNode, error) {
+	if err := b.validate(); err != nil {
+		return DependencyNode{}, err
+	}
+
+	return dependencyNode(b.buildpack.Descriptor(), false, b.buildpacksByName()), nil
+}
+
+func dependencyNode(bpd dist.BuildpackDescriptor, optional bool, buildpacks map[string]Buildpack) DependencyNode {
+	node := DependencyNode{Info: bpd.Info, API: bpd.API, Optional: optional}
+	for _, orderEntry := range bpd.Order {
+		var group []DependencyNode
+		for _, groupEntry := range orderEntry.Group {
+			dep := buildpacks[groupEntry.FullName()].Descriptor()
+			group = append(group, dependencyNode(dep, groupEntry.Optional, buildpacks))
+		}
+		node.Groups = append(node.Groups, group)
+	}
+	return node
+}
+
+func (b *PackageBuilder) buildpacksByName() map[string]Buildpack {
+	buildpacks := map[string]Buildpack{}
+	for _, bp := range append([]Buildpack{b.buildpack}, b.dependencies...) {
+		buildpacks[bp.Descriptor().Info.FullName()] = bp
+	}
+	return buildpacks
+}
+
+// validateNoCycles returns an error if a meta-buildpack refers back to itself, directly or through its dependencies
+func validateNoCycles(mainBP Buildpack, buildpacks map[string]Buildpack) error {
+	visited := map[string]bool{}
+
+	var visit func(bpd dist.BuildpackDescriptor, path []string) error
+	visit = func(bpd dist.BuildpackDescriptor, path []string) error {
+		name := bpd.Info.FullName()
+		for i, seen := range path {
+			if seen == name {
+				return errors.Errorf("buildpack %s has a dependency cycle: %s", style.Symbol(name), strings.Join(append(path[i:], name), " -> "))
+			}
+		}
+		if visited[name] {
+			return nil
+		}
+
+		path = append(path, name)
+		for _, orderEntry := range bpd.Order {
+			for _, groupEntry := range orderEntry.Group {
+				dep, ok := buildpacks[groupEntry.FullName()]
+				if !ok {
+					continue
+				}
+				if err := visit(dep.Descriptor(), path); err != nil {
+					return err
+				}
+			}
+		}
+		visited[name] = true
+		return nil
+	}
+
+	return visit(mainBP.Descriptor(), nil)
+}
+
+// validateBuildpackAPIs returns an error if a buildpack targets a Buildpack API newer than any released lifecycle supports
+func validateBuildpackAPIs(bps []Buildpack) error {
+	latest := api.Buildpack.Latest()
+	for _, bp := range bps {
+		bpd := bp.Descriptor()
+		if bpd.API != nil && bpd.API.Compare(latest) > 0 {
+			return errors.Errorf(
+				"buildpack %s uses Buildpack API %s, which is not supported by any released lifecycle (latest supported is %s)",
+				style.Symbol(bpd.Info.FullName()),
+				style.Symbol(bpd.API.String()),
+				style.Symbol(latest.String()),
+			)
+		}
+	}
+	return nil
+}
+
+// validateMixins returns an error if a buildpack requires a mixin that no stack can provide, i.e. a mixin for a stage
+// other than build or run, or a mixin without a name
+func validateMixins(bps []Buildpack) error {
+	for _, bp := range bps {
+		bpd := bp.Descriptor()
+		for _, s := range bpd.Stacks {
+			for _, mixin := range s.Mixins {
+				name := mixin
+				if i := strings.Index(mixin, ":"); i >= 0 {
+					if stage := mixin[:i]; stage != "build" && stage != "run" {
+						return errors.Errorf(
+							"buildpack %s requires mixin %s for unknown stage %s on stack %s",
+							style.Symbol(bpd.Info.FullName()),
+							style.Symbol(mixin),
+							style.Symbol(stage),
+							style.Symbol(s.ID),
+						)
+					}
+					name = mixin[i+1:]
+				}
+
+				if strings.TrimSpace(name) == "" {
+					return errors.Errorf(
+						"buildpack %s requires a mixin without a name on stack %s",
+						style.Symbol(bpd.Info.FullName()),
+						style.Symbol(s.ID),
+					)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateOptionalConsistency returns an error if a buildpack is both optional and required in a group, once the
+// groups of meta-buildpacks are expanded into the groups that include them. A buildpack in the group of an optional
+// meta-buildpack is optional too.
+func validateOptionalConsistency(mainBP Buildpack, buildpacks map[string]Buildpack) error {
+	type groupEntry struct {
+		name     string
+		optional bool
+	}
+
+	// expand returns the groups a buildpack expands to, the buildpack itself unless it's a meta-buildpack
+	var expand func(bpd dist.BuildpackDescriptor, optional bool) [][]groupEntry
+	expand = func(bpd dist.BuildpackDescriptor, optional bool) [][]groupEntry {
+		if len(bpd.Order) == 0 {
+			return [][]groupEntry{{{name: bpd.Info.FullName(), optional: optional}}}
+		}
+
+		var groups [][]groupEntry
+		for _, orderEntry := range bpd.Order {
+			expanded := [][]groupEntry{nil}
+			for _, ref := range orderEntry.Group {
+				dep, ok := buildpacks[ref.FullName()]
+				if !ok {
+					continue
+				}
+
+				var next [][]groupEntry
+				for _, prefix := range expanded {
+					for _, depGroup := range expand(dep.Descriptor(), optional || ref.Optional) {
+						next = append(next, append(append([]groupEntry{}, prefix...), depGroup...))
+					}
+				}
+				expanded = next
+			}
+			groups = append(groups, expanded...)
+		}
+		return groups
+	}
+
+	for _, group := range expand(mainBP.Descriptor(), false) {
+		seen := map[string]bool{}
+		for _, entry := range group {
+			if optional, ok := seen[entry.name]; ok && optional != entry.optional {
+				return errors.Errorf("buildpack %s is both optional and required in a group of buildpack %s", style.Symbol(entry.name), style.Symbol(mainBP.Descriptor().Info.FullName()))
+			}
+			seen[entry.name] = entry.optional
+		}
+	}
+	return nil
+}
+
+// validateNoConflictingCopies returns an error if a buildpack is provided more than once with different contents,
+// e.g. by two packages
+func validateNoConflictingCopies(bps []Buildpack) (err error) {
+	var tmpDir string
+	defer func() {
+		if tmpDir != "" {
+			os.RemoveAll(tmpDir)
+		}
+	}()
+
+	diffIDs := map[string]string{}
+	for _, bp := range bps {
+		fullName := bp.Descriptor().Info.FullName()
+
+		var copies int
+		for _, other := range bps {
+			if other.Descriptor().Info.FullName() == fullName {
+				copies++
+			}
+		}
+		if copies == 1 {
+			continue
+		}
+
+		if tmpDir == "" {
+			if tmpDir, err = ioutil.TempDir("", "package-buildpack-copies"); err != nil {
+				return err
+			}
+		}
+		bpTmpDir, err := ioutil.TempDir(tmpDir, "")
+		if err != nil {
+			return err
+		}
+
+		bpLayerTar, err := ToLayerTar(bpTmpDir, bp)
+		if err != nil {
+			return err
+		}
+		diffID, err := dist.LayerDiffID(bpLayerTar)
+		if err != nil {
+			return errors.Wrapf(err, "getting content hashes for buildpack %s", style.Symbol(fullName))
+		}
+
+		if added, ok := diffIDs[fullName]; ok && added != diffID.String() {
+			return errors.Errorf("buildpack %s is provided more than once with different contents", style.Symbol(fullName))
+		}
+		diffIDs[fullName] = diffID.String()
+	}
+	return nil
+}
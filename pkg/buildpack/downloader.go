@@ -111,10 +111,17 @@ func (c *buildpackDownloader) Download(ctx context.Context, buildpackURI string,
 			return nil, nil, errors.Wrapf(err, "extracting from registry %s", style.Symbol(buildpackURI))
 		}
 	case URILocator:
+		var digest string
+		buildpackURI, digest, err = blob.SplitDigest(buildpackURI)
+		if err != nil {
+			return nil, nil, err
+		}
+
 		buildpackURI, err = paths.FilePathToURI(buildpackURI, opts.RelativeBaseDir)
 		if err != nil {
 			return nil, nil, errors.Wrapf(err, "making absolute: %s", style.Symbol(buildpackURI))
 		}
+		buildpackURI = blob.WithDigest(buildpackURI, digest)
 
 		c.logger.Debugf("Downloading buildpack from URI: %s", style.Symbol(buildpackURI))
 
@@ -11,6 +11,7 @@ import (
 
 	"github.com/buildpacks/pack/internal/paths"
 	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/blob"
 	"github.com/buildpacks/pack/pkg/dist"
 )
 
@@ -54,6 +55,17 @@ func (l LocatorType) String() string {
 // If a type cannot be determined, `INVALID_LOCATOR` will be returned. If an error
 // is encountered, it will be returned.
 func GetLocatorType(locator string, relativeBaseDir string, buildpacksFromBuilder []dist.BuildpackInfo) (LocatorType, error) {
+	locator, digest, err := blob.SplitDigest(locator)
+	if err != nil {
+		return InvalidLocator, err
+	}
+	if digest != "" {
+		if (paths.IsURI(locator) && !HasDockerLocator(locator)) || isLocalFile(locator, relativeBaseDir) {
+			return URILocator, nil
+		}
+		return InvalidLocator, fmt.Errorf("%s can't be pinned to a digest, as only files and URLs are verified against digests", style.Symbol(locator))
+	}
+
 	if locator == deprecatedFromBuilderPrefix {
 		return FromBuilderLocator, nil
 	}
@@ -79,6 +79,14 @@ func testGetLocatorType(t *testing.T, when spec.G, it spec.S) {
 			locator:      "https://example.com/buildpack.tgz",
 			expectedType: buildpack.URILocator,
 		},
+		{
+			locator:      "s3://some-bucket/buildpack.tgz",
+			expectedType: buildpack.URILocator,
+		},
+		{
+			locator:      "oci://registry.example.com/buildpacks/some-bp:1.2.3",
+			expectedType: buildpack.URILocator,
+		},
 		{
 			locator:      "localhost:1234/example/package-cnb",
 			expectedType: buildpack.PackageLocator,
@@ -163,6 +171,24 @@ func testGetLocatorType(t *testing.T, when spec.G, it spec.S) {
 			locator:      "dev.local/http-go-fn:latest",
 			expectedType: buildpack.PackageLocator,
 		},
+		{
+			locator:      "https://example.com/buildpack.tgz#sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			expectedType: buildpack.URILocator,
+		},
+		{
+			locator:      localPath("buildpack") + "#sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			expectedType: buildpack.URILocator,
+		},
+		{
+			locator:      "https://example.com/buildpack.tgz#sha256:0123",
+			expectedType: buildpack.InvalidLocator,
+			expectedErr:  "invalid digest 'sha256:0123'",
+		},
+		{
+			locator:      "example/foo@1.0.0#sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			expectedType: buildpack.InvalidLocator,
+			expectedErr:  "'example/foo@1.0.0' can't be pinned to a digest",
+		},
 	} {
 		tc := tc
 
@@ -5,10 +5,12 @@ import (
 	"compress/gzip"
 	"encoding/json"
 	"io"
+	"io/ioutil"
 	"path"
 	"strings"
 
 	"github.com/docker/docker/pkg/ioutils"
+	"github.com/klauspost/compress/zstd"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 
@@ -71,7 +73,8 @@ func newOCILayoutPackage(blob Blob) (*ociLayoutPackage, error) {
 
 	var manifestDescriptor *v1.Descriptor
 	for _, m := range index.Manifests {
-		if m.MediaType == "application/vnd.docker.distribution.manifest.v2+json" {
+		// packages with zstd or estargz layers have OCI manifests
+		if m.MediaType == "application/vnd.docker.distribution.manifest.v2+json" || m.MediaType == v1.MediaTypeImageManifest {
 			manifestDescriptor = &m // nolint:scopelint
 			break
 		}
@@ -143,13 +146,20 @@ func (o *ociLayoutPackage) GetLayer(diffID string) (io.ReadCloser, error) {
 		}
 
 		if path.Clean(header.Name) == path.Clean(layerPath) {
-			finalReader := blobReader
+			finalReader := ioutil.NopCloser(tr)
 
-			if strings.HasSuffix(layerDescriptor.MediaType, ".gzip") {
+			switch {
+			case strings.HasSuffix(layerDescriptor.MediaType, "gzip"):
 				finalReader, err = gzip.NewReader(tr)
 				if err != nil {
 					return nil, err
 				}
+			case strings.HasSuffix(layerDescriptor.MediaType, "zstd"):
+				decoder, err := zstd.NewReader(tr)
+				if err != nil {
+					return nil, err
+				}
+				finalReader = decoder.IOReadCloser()
 			}
 
 			return ioutils.NewReadCloserWrapper(finalReader, func() error {
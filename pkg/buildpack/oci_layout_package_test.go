@@ -2,6 +2,8 @@ package buildpack_test
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -55,6 +57,26 @@ func testOCILayoutPackage(t *testing.T, when spec.G, it spec.S) {
 				h.AssertContains(t, string(contents), bp.Descriptor().Info.Version)
 			}
 		})
+
+		it("knows the layer diff IDs of the buildpacks", func() {
+			mainBP, depBPs, err := buildpack.BuildpacksFromOCILayoutBlob(blob.NewBlob(filepath.Join("testdata", "hello-universe.cnb")))
+			h.AssertNil(t, err)
+
+			tmpDir, err := ioutil.TempDir("", "oci-layout-package-test")
+			h.AssertNil(t, err)
+			defer os.RemoveAll(tmpDir)
+
+			for _, bp := range append([]buildpack.Buildpack{mainBP}, depBPs...) {
+				knownDiffID, ok := buildpack.KnownLayerDiffID(bp)
+				h.AssertEq(t, ok, true)
+
+				layerTar, err := buildpack.ToLayerTar(tmpDir, bp)
+				h.AssertNil(t, err)
+				diffID, err := dist.LayerDiffID(layerTar)
+				h.AssertNil(t, err)
+				h.AssertEq(t, knownDiffID, diffID.String())
+			}
+		})
 	})
 
 	when("#IsOCILayoutBlob", func() {
@@ -56,6 +56,9 @@ func ExtractBuildpacks(pkg Package) (mainBP Buildpack, depBPs []Buildpack, err e
 			b := &openerBlob{
 				opener: func() (io.ReadCloser, error) {
 					rc, err := pkg.GetLayer(diffID)
+					if err == nil {
+						rc, err = decompressZstdLayer(rc)
+					}
 					if err != nil {
 						return nil, errors.Wrapf(err,
 							"extracting buildpack %s layer (diffID %s)",
@@ -67,10 +70,11 @@ func ExtractBuildpacks(pkg Package) (mainBP Buildpack, depBPs []Buildpack, err e
 				},
 			}
 
+			bp := &packagedBuildpack{Buildpack: FromBlob(desc, b), diffID: diffID}
 			if desc.Info.Match(md.BuildpackInfo) { // This is the order buildpack of the package
-				mainBP = FromBlob(desc, b)
+				mainBP = bp
 			} else {
-				depBPs = append(depBPs, FromBlob(desc, b))
+				depBPs = append(depBPs, bp)
 			}
 		}
 	}
@@ -85,3 +89,18 @@ type openerBlob struct {
 func (b *openerBlob) Open() (io.ReadCloser, error) {
 	return b.opener()
 }
+
+// packagedBuildpack is a buildpack from a package, whose layer diff ID is known from the package metadata
+type packagedBuildpack struct {
+	Buildpack
+	diffID string
+}
+
+// KnownLayerDiffID returns the diff ID of the layer of a buildpack, if it is known without reading the layer,
+// as it is for buildpacks from packages
+func KnownLayerDiffID(bp Buildpack) (string, bool) {
+	if packaged, ok := bp.(*packagedBuildpack); ok && packaged.diffID != "" {
+		return packaged.diffID, true
+	}
+	return "", false
+}
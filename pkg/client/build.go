@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
 	"sort"
@@ -17,6 +18,7 @@ import (
 	"github.com/buildpacks/lifecycle/platform"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/volume/mounts"
+	"github.com/docker/go-units"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/pkg/errors"
 	ignore "github.com/sabhiram/go-gitignore"
@@ -30,6 +32,7 @@ import (
 	"github.com/buildpacks/pack/internal/style"
 	"github.com/buildpacks/pack/internal/termui"
 	"github.com/buildpacks/pack/pkg/archive"
+	"github.com/buildpacks/pack/pkg/blob"
 	"github.com/buildpacks/pack/pkg/buildpack"
 	"github.com/buildpacks/pack/pkg/dist"
 	"github.com/buildpacks/pack/pkg/image"
@@ -61,6 +64,9 @@ type LifecycleExecutor interface {
 	// Execute is responsible for invoking each of these binaries
 	// with the desired configuration.
 	Execute(ctx context.Context, opts build.LifecycleOptions) error
+
+	// Detect invokes only the detector and reports its outcome.
+	Detect(ctx context.Context, opts build.LifecycleOptions) (build.DetectResult, error)
 }
 
 type IsTrustedBuilder func(string) bool
@@ -90,6 +96,7 @@ type BuildOptions struct {
 
 	// Address of docker daemon exposed to build container
 	// e.g. tcp://example.com:1234, unix:///run/user/1000/podman/podman.sock
+	// 'inherit' exposes the daemon pack uses, DOCKER_HOST or the one given with WithDockerHost.
 	DockerHost string
 
 	// Used to determine a run-image mirror if Run Image is empty.
@@ -105,6 +112,11 @@ type BuildOptions struct {
 	// Buildpacks may both read and overwrite these values.
 	Env map[string]string
 
+	// EnvVars are applied in order after Env, each combining with the value
+	// the variable has so far as its Action says. In verbose mode the effective
+	// environment is logged along with the source of each variable.
+	EnvVars []EnvVar
+
 	// Option only valid if Publish is true
 	// Create an additional image that contains cache=true layers and push it to the registry.
 	CacheImage string
@@ -170,6 +182,10 @@ type BuildOptions struct {
 
 	// Directory to output any SBOM artifacts
 	SBOMDestinationDir string
+
+	// Secrets are mounted read-only to /platform/secrets/<id> during detect and build.
+	// They override secrets of the ProjectDescriptor with the same id.
+	Secrets []Secret
 }
 
 // ProxyConfig specifies proxy setting to be set as environment variables in a container.
@@ -199,6 +215,33 @@ type ContainerConfig struct {
 	// - /layers
 	// - anything below /cnb/**
 	Volumes []string
+
+	// CPUs limits the number of CPUs each build container can use, e.g. 1.5. Zero is unlimited.
+	CPUs float64
+
+	// Memory limits the memory of each build container, in bytes. Zero is unlimited.
+	Memory int64
+
+	// Ulimits of the build containers, in the form <type>=<soft limit>[:<hard limit>], e.g. nofile=1024:2048.
+	Ulimits []string
+
+	// ExtraHosts are added to /etc/hosts of the build containers, in the form <host>:<ip>.
+	ExtraHosts []string
+
+	// DNS servers of the build containers.
+	DNS []string
+
+	// Tmpfs mounts of the build containers, in the form <path>[:<options>], e.g. /tmp:size=64m.
+	Tmpfs []string
+
+	// SecurityOpts of the build containers, e.g. seccomp=<profile file>, apparmor=<profile> or label=<label>.
+	// For valid values see:
+	// https://docs.docker.com/engine/reference/run/#security-configuration
+	SecurityOpts []string
+
+	// ReadOnlyApp mounts the app directory read-only in the build containers.
+	// Buildpacks that write to the app directory will fail.
+	ReadOnlyApp bool
 }
 
 var IsSuggestedBuilderFunc = func(b string) bool {
@@ -215,6 +258,10 @@ var IsSuggestedBuilderFunc = func(b string) bool {
 // If any configuration is deemed invalid, or if any lifecycle phases fail,
 // an error will be returned and no image produced.
 func (c *Client) Build(ctx context.Context, opts BuildOptions) error {
+	if err := c.validatePublish(opts.Publish); err != nil {
+		return err
+	}
+
 	imageRef, err := c.parseTagReference(opts.Image)
 	if err != nil {
 		return errors.Wrapf(err, "invalid image name '%s'", opts.Image)
@@ -242,19 +289,55 @@ func (c *Client) Build(ctx context.Context, opts BuildOptions) error {
 		return errors.Wrapf(err, "invalid builder %s", style.Symbol(opts.Builder))
 	}
 
-	runImageName := c.resolveRunImage(opts.RunImage, imageRef.Context().RegistryStr(), builderRef.Context().RegistryStr(), bldr.Stack(), opts.AdditionalMirrors, opts.Publish)
-	runImage, err := c.validateRunImage(ctx, runImageName, opts.PullPolicy, opts.Publish, bldr.StackID)
+	imgOS, err := rawBuilderImage.OS()
 	if err != nil {
+		return errors.Wrapf(err, "getting builder OS")
+	}
+
+	// Default mode: if the TrustBuilder option is not set, trust the suggested builders.
+	if opts.TrustBuilder == nil {
+		opts.TrustBuilder = IsSuggestedBuilderFunc
+	}
+
+	var (
+		runImage       imgutil.Image
+		fetchedBPs     []buildpack.Buildpack
+		order          dist.Order
+		lifecycleImage imgutil.Image
+	)
+
+	runImageName := c.resolveRunImage(opts.RunImage, imageRef.Context().RegistryStr(), builderRef.Context().RegistryStr(), bldr.Stack(), opts.AdditionalMirrors, opts.Publish)
+	lifecycleVersion := bldr.LifecycleDescriptor().Info.Version
+
+	fetches := c.newFetchGroup()
+	fetches.Add("run image", func(ctx context.Context) error {
+		var err error
+		runImage, err = c.validateRunImage(ctx, runImageName, opts.PullPolicy, opts.Publish, bldr.StackID)
 		return errors.Wrapf(err, "invalid run-image '%s'", runImageName)
+	})
+	fetches.Add("buildpacks", func(ctx context.Context) error {
+		var err error
+		fetchedBPs, order, err = c.processBuildpacks(ctx, bldr.Image(), bldr.Buildpacks(), bldr.Order(), bldr.StackID, opts)
+		return err
+	})
+	if !opts.TrustBuilder(opts.Builder) {
+		if !lifecycleImageSupported(imgOS, lifecycleVersion) {
+			return errors.Errorf("Lifecycle %s does not have an associated lifecycle image. Builder must be trusted.", lifecycleVersion.String())
+		}
+
+		fetches.Add("lifecycle image", func(ctx context.Context) error {
+			var err error
+			lifecycleImage, err = c.fetchLifecycleImage(ctx, rawBuilderImage, imgOS, lifecycleVersion, opts)
+			return err
+		})
 	}
 
-	var runMixins []string
-	if _, err := dist.GetLabel(runImage, stack.MixinsLabel, &runMixins); err != nil {
+	if err := fetches.Wait(ctx); err != nil {
 		return err
 	}
 
-	fetchedBPs, order, err := c.processBuildpacks(ctx, bldr.Image(), bldr.Buildpacks(), bldr.Order(), bldr.StackID, opts)
-	if err != nil {
+	var runMixins []string
+	if _, err := dist.GetLabel(runImage, stack.MixinsLabel, &runMixins); err != nil {
 		return err
 	}
 
@@ -262,13 +345,9 @@ func (c *Client) Build(ctx context.Context, opts BuildOptions) error {
 		return errors.Wrap(err, "validating stack mixins")
 	}
 
-	buildEnvs := map[string]string{}
-	for _, envVar := range opts.ProjectDescriptor.Build.Env {
-		buildEnvs[envVar.Name] = envVar.Value
-	}
-
-	for k, v := range opts.Env {
-		buildEnvs[k] = v
+	buildEnvs, err := c.resolveEnv(bldr.BuildEnv(), opts.ProjectDescriptor.Build.Env, opts.Env, opts.EnvVars)
+	if err != nil {
+		return err
 	}
 
 	ephemeralBuilder, err := c.createEphemeralBuilder(rawBuilderImage, buildEnvs, order, fetchedBPs)
@@ -287,11 +366,6 @@ func (c *Client) Build(ctx context.Context, opts BuildOptions) error {
 		return errors.Errorf("Builder %s is incompatible with this version of pack", style.Symbol(opts.Builder))
 	}
 
-	imgOS, err := rawBuilderImage.OS()
-	if err != nil {
-		return errors.Wrapf(err, "getting builder OS")
-	}
-
 	processedVolumes, warnings, err := processVolumes(imgOS, opts.ContainerConfig.Volumes)
 	if err != nil {
 		return err
@@ -306,6 +380,16 @@ func (c *Client) Build(ctx context.Context, opts BuildOptions) error {
 		return err
 	}
 
+	containerSettings, err := processContainerConfig(imgOS, opts.ContainerConfig, opts.ProjectDescriptor.Build.Container)
+	if err != nil {
+		return err
+	}
+
+	secrets, err := c.processSecrets(opts.Secrets, opts.ProjectDescriptor.Build.Secrets, opts.ProjectDescriptorBaseDir)
+	if err != nil {
+		return err
+	}
+
 	runImageName, err = pname.TranslateRegistry(runImageName, c.registryMirrors, c.logger)
 	if err != nil {
 		return err
@@ -324,11 +408,6 @@ func (c *Client) Build(ctx context.Context, opts BuildOptions) error {
 		}
 	}
 
-	// Default mode: if the TrustBuilder option is not set, trust the suggested builders.
-	if opts.TrustBuilder == nil {
-		opts.TrustBuilder = IsSuggestedBuilderFunc
-	}
-
 	lifecycleOpts := build.LifecycleOptions{
 		AppPath:            appPath,
 		Image:              imageRef,
@@ -340,7 +419,7 @@ func (c *Client) Build(ctx context.Context, opts BuildOptions) error {
 		Publish:            opts.Publish,
 		TrustBuilder:       opts.TrustBuilder(opts.Builder),
 		UseCreator:         false,
-		DockerHost:         opts.DockerHost,
+		DockerHost:         c.daemonAccessHost(opts.DockerHost),
 		CacheImage:         opts.CacheImage,
 		HTTPProxy:          proxyConfig.HTTPProxy,
 		HTTPSProxy:         proxyConfig.HTTPSProxy,
@@ -354,11 +433,14 @@ func (c *Client) Build(ctx context.Context, opts BuildOptions) error {
 		GID:                opts.GroupID,
 		PreviousImage:      opts.PreviousImage,
 		Interactive:        opts.Interactive,
-		Termui:             termui.NewTermui(imageRef.Name(), ephemeralBuilder, runImageName),
+		Termui:             termui.NewTermui(imageRef.Name(), ephemeralBuilder, runImageName, logging.GetRedactor(c.logger)),
 		SBOMDestinationDir: opts.SBOMDestinationDir,
+		Keychain:           c.keychain,
+		Engine:             c.detectEngine(ctx),
+		Container:          containerSettings,
+		Secrets:            secrets,
 	}
 
-	lifecycleVersion := ephemeralBuilder.LifecycleDescriptor().Info.Version
 	// Technically the creator is supported as of platform API version 0.3 (lifecycle version 0.7.0+) but earlier versions
 	// have bugs that make using the creator problematic.
 	lifecycleSupportsCreator := !lifecycleVersion.LessThan(semver.MustParse(minLifecycleVersionSupportingCreator))
@@ -373,31 +455,8 @@ func (c *Client) Build(ctx context.Context, opts BuildOptions) error {
 		return c.logImageNameAndSha(ctx, opts.Publish, imageRef)
 	}
 
-	if !opts.TrustBuilder(opts.Builder) {
-		if lifecycleImageSupported(imgOS, lifecycleVersion) {
-			lifecycleImageName := opts.LifecycleImage
-			if lifecycleImageName == "" {
-				lifecycleImageName = fmt.Sprintf("%s:%s", internalConfig.DefaultLifecycleImageRepo, lifecycleVersion.String())
-			}
-
-			imgArch, err := rawBuilderImage.Architecture()
-			if err != nil {
-				return errors.Wrapf(err, "getting builder architecture")
-			}
-
-			lifecycleImage, err := c.imageFetcher.Fetch(
-				ctx,
-				lifecycleImageName,
-				image.FetchOptions{Daemon: true, PullPolicy: opts.PullPolicy, Platform: fmt.Sprintf("%s/%s", imgOS, imgArch)},
-			)
-			if err != nil {
-				return errors.Wrap(err, "fetching lifecycle image")
-			}
-
-			lifecycleOpts.LifecycleImage = lifecycleImage.Name()
-		} else {
-			return errors.Errorf("Lifecycle %s does not have an associated lifecycle image. Builder must be trusted.", lifecycleVersion.String())
-		}
+	if lifecycleImage != nil {
+		lifecycleOpts.LifecycleImage = lifecycleImage.Name()
 	}
 
 	if err := c.lifecycleExecutor.Execute(ctx, lifecycleOpts); err != nil {
@@ -407,6 +466,29 @@ func (c *Client) Build(ctx context.Context, opts BuildOptions) error {
 	return c.logImageNameAndSha(ctx, opts.Publish, imageRef)
 }
 
+func (c *Client) fetchLifecycleImage(ctx context.Context, builderImage imgutil.Image, imgOS string, lifecycleVersion *builder.Version, opts BuildOptions) (imgutil.Image, error) {
+	lifecycleImageName := opts.LifecycleImage
+	if lifecycleImageName == "" {
+		lifecycleImageName = fmt.Sprintf("%s:%s", internalConfig.DefaultLifecycleImageRepo, lifecycleVersion.String())
+	}
+
+	imgArch, err := builderImage.Architecture()
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting builder architecture")
+	}
+
+	lifecycleImage, err := c.imageFetcher.Fetch(
+		ctx,
+		lifecycleImageName,
+		image.FetchOptions{Daemon: true, PullPolicy: opts.PullPolicy, Platform: fmt.Sprintf("%s/%s", imgOS, imgArch)},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching lifecycle image")
+	}
+
+	return lifecycleImage, nil
+}
+
 func getFileFilter(descriptor projectTypes.Descriptor) (func(string) bool, error) {
 	if len(descriptor.Build.Exclude) > 0 {
 		excludes := ignore.CompileIgnoreLines(descriptor.Build.Exclude...)
@@ -611,6 +693,14 @@ func (c *Client) processAppPath(appPath string) (string, error) {
 	return resolvedAppPath, nil
 }
 
+// daemonAccessHost resolves 'inherit' to the docker host of the client, when it isn't DOCKER_HOST
+func (c *Client) daemonAccessHost(dockerHost string) string {
+	if dockerHost == "inherit" && c.dockerHost != "" {
+		return c.dockerHost
+	}
+	return dockerHost
+}
+
 func (c *Client) processProxyConfig(config *ProxyConfig) ProxyConfig {
 	var (
 		httpProxy, httpsProxy, noProxy string
@@ -703,7 +793,7 @@ func (c *Client) processBuildpacks(ctx context.Context, builderImage imgutil.Ima
 				}
 				declaredBPs = append(declaredBPs, pathToInlineBuildpack)
 			case bp.URI != "":
-				declaredBPs = append(declaredBPs, bp.URI)
+				declaredBPs = append(declaredBPs, blob.WithDigest(bp.URI, bp.Digest))
 			case bp.ID != "" && bp.Version != "":
 				declaredBPs = append(declaredBPs, fmt.Sprintf("%s@%s", bp.ID, bp.Version))
 			default:
@@ -712,14 +802,49 @@ func (c *Client) processBuildpacks(ctx context.Context, builderImage imgutil.Ima
 		}
 	}
 
-	order = dist.Order{{Group: []dist.BuildpackRef{}}}
-	for _, bp := range declaredBPs {
+	locatorTypes := make([]buildpack.LocatorType, len(declaredBPs))
+	downloads := make([][]buildpack.Buildpack, len(declaredBPs))
+	fetches := c.newFetchGroup()
+	for i, bp := range declaredBPs {
 		locatorType, err := buildpack.GetLocatorType(bp, relativeBaseDir, builderBPs)
 		if err != nil {
 			return nil, nil, err
 		}
+		locatorTypes[i] = locatorType
+
+		if locatorType == buildpack.FromBuilderLocator || locatorType == buildpack.IDLocator {
+			continue
+		}
 
-		switch locatorType {
+		imageOS, err := builderImage.OS()
+		if err != nil {
+			return fetchedBPs, order, errors.Wrapf(err, "getting OS from %s", style.Symbol(builderImage.Name()))
+		}
+
+		i, bp := i, bp
+		fetches.Add(bp, func(ctx context.Context) error {
+			mainBP, depBPs, err := c.buildpackDownloader.Download(ctx, bp, buildpack.DownloadOptions{
+				RegistryName:    registry,
+				ImageOS:         imageOS,
+				RelativeBaseDir: relativeBaseDir,
+				Daemon:          !publish,
+				PullPolicy:      pullPolicy,
+			})
+			if err != nil {
+				return errors.Wrap(err, "downloading buildpack")
+			}
+			downloads[i] = append([]buildpack.Buildpack{mainBP}, depBPs...)
+			return nil
+		})
+	}
+
+	if err := fetches.Wait(ctx); err != nil {
+		return fetchedBPs, order, err
+	}
+
+	order = dist.Order{{Group: []dist.BuildpackRef{}}}
+	for i, bp := range declaredBPs {
+		switch locatorTypes[i] {
 		case buildpack.FromBuilderLocator:
 			switch {
 			case len(order) == 0 || len(order[0].Group) == 0:
@@ -744,22 +869,8 @@ func (c *Client) processBuildpacks(ctx context.Context, builderImage imgutil.Ima
 				Version: version,
 			})
 		default:
-			imageOS, err := builderImage.OS()
-			if err != nil {
-				return fetchedBPs, order, errors.Wrapf(err, "getting OS from %s", style.Symbol(builderImage.Name()))
-			}
-			mainBP, depBPs, err := c.buildpackDownloader.Download(ctx, bp, buildpack.DownloadOptions{
-				RegistryName:    registry,
-				ImageOS:         imageOS,
-				RelativeBaseDir: relativeBaseDir,
-				Daemon:          !publish,
-				PullPolicy:      pullPolicy,
-			})
-			if err != nil {
-				return fetchedBPs, order, errors.Wrap(err, "downloading buildpack")
-			}
-			fetchedBPs = append(append(fetchedBPs, mainBP), depBPs...)
-			order = appendBuildpackToOrder(order, mainBP.Descriptor().Info)
+			fetchedBPs = append(fetchedBPs, downloads[i]...)
+			order = appendBuildpackToOrder(order, downloads[i][0].Descriptor().Info)
 		}
 	}
 
@@ -843,6 +954,94 @@ func processVolumes(imgOS string, volumes []string) (processed []string, warning
 	return processed, warnings, nil
 }
 
+// processContainerConfig validates config and converts it to the settings of the build containers.
+// The settings of the project descriptor apply unless config overrides them.
+func processContainerConfig(imgOS string, config ContainerConfig, descriptor projectTypes.Container) (build.ContainerSettings, error) {
+	settings := build.ContainerSettings{
+		ReadOnlyApp: config.ReadOnlyApp || descriptor.ReadOnlyApp,
+	}
+
+	cpus := config.CPUs
+	if cpus == 0 {
+		cpus = descriptor.CPUs
+	}
+	if cpus < 0 {
+		return build.ContainerSettings{}, errors.New("cpus must not be negative")
+	}
+	settings.NanoCPUs = int64(cpus * 1e9)
+
+	settings.Memory = config.Memory
+	if settings.Memory == 0 && descriptor.Memory != "" {
+		memory, err := units.RAMInBytes(descriptor.Memory)
+		if err != nil {
+			return build.ContainerSettings{}, errors.Wrapf(err, "parsing memory %s", style.Symbol(descriptor.Memory))
+		}
+		settings.Memory = memory
+	}
+	if settings.Memory < 0 {
+		return build.ContainerSettings{}, errors.New("memory must not be negative")
+	}
+
+	ulimits := append(append([]string{}, descriptor.Ulimits...), config.Ulimits...)
+	tmpfs := append(append([]string{}, descriptor.Tmpfs...), config.Tmpfs...)
+	if imgOS == "windows" && (len(ulimits) > 0 || len(tmpfs) > 0) {
+		return build.ContainerSettings{}, errors.New("ulimits and tmpfs mounts are not supported on Windows")
+	}
+
+	for _, u := range ulimits {
+		ulimit, err := units.ParseUlimit(u)
+		if err != nil {
+			return build.ContainerSettings{}, errors.Wrapf(err, "parsing ulimit %s", style.Symbol(u))
+		}
+
+		// a ulimit given later overrides an earlier one of the same type
+		replaced := false
+		for i, existing := range settings.Ulimits {
+			if existing.Name == ulimit.Name {
+				settings.Ulimits[i] = ulimit
+				replaced = true
+			}
+		}
+		if !replaced {
+			settings.Ulimits = append(settings.Ulimits, ulimit)
+		}
+	}
+
+	for _, t := range tmpfs {
+		parts := strings.SplitN(t, ":", 2)
+		if !strings.HasPrefix(parts[0], "/") {
+			return build.ContainerSettings{}, errors.Errorf("tmpfs mount %s must have an absolute path", style.Symbol(t))
+		}
+		if settings.Tmpfs == nil {
+			settings.Tmpfs = map[string]string{}
+		}
+		settings.Tmpfs[parts[0]] = ""
+		if len(parts) == 2 {
+			settings.Tmpfs[parts[0]] = parts[1]
+		}
+	}
+
+	for _, host := range append(append([]string{}, descriptor.AddHosts...), config.ExtraHosts...) {
+		parts := strings.SplitN(host, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || (parts[1] != "host-gateway" && net.ParseIP(parts[1]) == nil) {
+			return build.ContainerSettings{}, errors.Errorf("extra host %s must have the form <host>:<ip>", style.Symbol(host))
+		}
+		settings.ExtraHosts = append(settings.ExtraHosts, host)
+	}
+
+	for _, dns := range append(append([]string{}, descriptor.DNS...), config.DNS...) {
+		if net.ParseIP(dns) == nil {
+			return build.ContainerSettings{}, errors.Errorf("DNS server %s must be an IP address", style.Symbol(dns))
+		}
+		settings.DNS = append(settings.DNS, dns)
+	}
+
+	settings.SecurityOpts = append(settings.SecurityOpts, descriptor.SecurityOpts...)
+	settings.SecurityOpts = append(settings.SecurityOpts, config.SecurityOpts...)
+
+	return settings, nil
+}
+
 func processMode(mode string) string {
 	if mode == "" {
 		return "ro"
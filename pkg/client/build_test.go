@@ -25,6 +25,7 @@ import (
 	"github.com/buildpacks/lifecycle/api"
 	"github.com/buildpacks/lifecycle/platform"
 	dockerclient "github.com/docker/docker/client"
+	"github.com/docker/go-units"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/heroku/color"
 	"github.com/onsi/gomega/ghttp"
@@ -1632,6 +1633,90 @@ func testBuild(t *testing.T, when spec.G, it spec.S) {
 				h.AssertTarFileContents(t, layerTar, "/platform/env/key1", `value1`)
 				h.AssertTarFileContents(t, layerTar, "/platform/env/key2", `value2`)
 			})
+
+			it("should redact the values of variables named like secrets from the logs", func() {
+				h.AssertNil(t, subject.Build(context.TODO(), BuildOptions{
+					Image:   "some/app",
+					Builder: defaultBuilderName,
+					Env: map[string]string{
+						"NPM_TOKEN": "some-npm-token",
+						"key1":      "value1",
+					},
+				}))
+
+				logger.Infof("printed some-npm-token and value1")
+				h.AssertContains(t, outBuf.String(), "printed [REDACTED] and value1")
+			})
+		})
+
+		when("EnvVars option", func() {
+			var descriptor projectTypes.Descriptor
+
+			it.Before(func() {
+				descriptor = projectTypes.Descriptor{
+					Build: projectTypes.Build{
+						Env: []projectTypes.EnvVar{
+							{Name: "BP_JVM_VERSION", Value: "11"},
+							{Name: "JAVA_TOOL_OPTIONS", Value: "-Xss1m"},
+							{Name: "BP_LOG_LEVEL", Value: "INFO"},
+						},
+					},
+				}
+			})
+
+			it("combines the variables with the project descriptor as their actions say", func() {
+				h.AssertNil(t, subject.Build(context.TODO(), BuildOptions{
+					Image:             "some/app",
+					Builder:           defaultBuilderName,
+					ProjectDescriptor: descriptor,
+					EnvVars: []EnvVar{
+						{Name: "BP_JVM_VERSION", Value: "17", Action: EnvDefault, Source: "--env"},
+						{Name: "JAVA_TOOL_OPTIONS", Value: "-Xmx1g", Action: EnvAppend, Delim: " ", Source: "--env"},
+						{Name: "BP_LOG_LEVEL", Value: "DEBUG", Source: "--env"},
+						{Name: "PATH", Value: "/opt/bin", Action: EnvPrepend, Delim: ":", Source: "--env"},
+					},
+				}))
+				layerTar, err := defaultBuilderImage.FindLayerWithPath("/platform/env/BP_JVM_VERSION")
+				h.AssertNil(t, err)
+				h.AssertTarFileContents(t, layerTar, "/platform/env/BP_JVM_VERSION", `11`)
+				h.AssertTarFileContents(t, layerTar, "/platform/env/JAVA_TOOL_OPTIONS", `-Xss1m -Xmx1g`)
+				h.AssertTarFileContents(t, layerTar, "/platform/env/BP_LOG_LEVEL", `DEBUG`)
+				h.AssertTarFileContents(t, layerTar, "/platform/env/PATH", `/opt/bin`)
+			})
+
+			it("logs the effective environment with its sources in verbose mode", func() {
+				logger = logging.NewLogWithWriters(&outBuf, &outBuf, logging.WithVerbose())
+				subject.logger = logger
+
+				h.AssertNil(t, subject.Build(context.TODO(), BuildOptions{
+					Image:             "some/app",
+					Builder:           defaultBuilderName,
+					ProjectDescriptor: descriptor,
+					EnvVars: []EnvVar{
+						{Name: "JAVA_TOOL_OPTIONS", Value: "-Xmx1g", Action: EnvAppend, Delim: " ", Source: "--env"},
+						{Name: "BP_LOG_LEVEL", Value: "DEBUG", Source: "--env-file .env"},
+						{Name: "NPM_TOKEN", Value: "some-npm-token", Source: "--env"},
+					},
+				}))
+
+				h.AssertContains(t, outBuf.String(), "Build environment:\n")
+				h.AssertContains(t, outBuf.String(), "BP_JVM_VERSION=11")
+				h.AssertContainsMatch(t, outBuf.String(), `BP_LOG_LEVEL=DEBUG +--env-file .env, overriding project.toml\n`)
+				h.AssertContainsMatch(t, outBuf.String(), `JAVA_TOOL_OPTIONS=-Xss1m -Xmx1g +project.toml, --env \(append\)\n`)
+				h.AssertContainsMatch(t, outBuf.String(), `NPM_TOKEN=\[REDACTED\] +--env\n`)
+				h.AssertNotContains(t, outBuf.String(), "some-npm-token")
+			})
+
+			when("an action is invalid", func() {
+				it("errors", func() {
+					err := subject.Build(context.TODO(), BuildOptions{
+						Image:   "some/app",
+						Builder: defaultBuilderName,
+						EnvVars: []EnvVar{{Name: "PATH", Value: "/opt/bin", Action: "insert", Source: "--env"}},
+					})
+					h.AssertError(t, err, "environment variable 'PATH' from --env: invalid action 'insert'")
+				})
+			})
 		})
 
 		when("Publish option", func() {
@@ -1662,6 +1747,20 @@ func testBuild(t *testing.T, when spec.G, it spec.S) {
 			})
 
 			when("true", func() {
+				when("the client is offline", func() {
+					it("errors without fetching images", func() {
+						subject.offline = true
+
+						err := subject.Build(context.TODO(), BuildOptions{
+							Image:   "some/app",
+							Builder: defaultBuilderName,
+							Publish: true,
+						})
+						h.AssertError(t, err, "images can't be published in offline mode")
+						h.AssertEq(t, len(fakeImageFetcher.FetchCalls), 0)
+					})
+				})
+
 				it("uses a remote run image", func() {
 					h.AssertNil(t, subject.Build(context.TODO(), BuildOptions{
 						Image:   "some/app",
@@ -1957,6 +2056,207 @@ func testBuild(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("ContainerConfig limits and security options", func() {
+			it("passes them through", func() {
+				h.AssertNil(t, subject.Build(context.TODO(), BuildOptions{
+					Image:   "some/app",
+					Builder: defaultBuilderName,
+					ContainerConfig: ContainerConfig{
+						CPUs:         1.5,
+						Memory:       1024 * 1024 * 1024,
+						Ulimits:      []string{"nofile=1024:2048"},
+						ExtraHosts:   []string{"registry.local:10.0.0.1"},
+						DNS:          []string{"10.0.0.2"},
+						Tmpfs:        []string{"/tmp:size=64m", "/run"},
+						SecurityOpts: []string{"no-new-privileges"},
+						ReadOnlyApp:  true,
+					},
+				}))
+				h.AssertEq(t, fakeLifecycle.Opts.Container, build.ContainerSettings{
+					NanoCPUs:     1500000000,
+					Memory:       1024 * 1024 * 1024,
+					Ulimits:      []*units.Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}},
+					ExtraHosts:   []string{"registry.local:10.0.0.1"},
+					DNS:          []string{"10.0.0.2"},
+					Tmpfs:        map[string]string{"/tmp": "size=64m", "/run": ""},
+					SecurityOpts: []string{"no-new-privileges"},
+					ReadOnlyApp:  true,
+				})
+			})
+
+			when("the project descriptor has container settings", func() {
+				it("uses them unless the options override them", func() {
+					h.AssertNil(t, subject.Build(context.TODO(), BuildOptions{
+						Image:   "some/app",
+						Builder: defaultBuilderName,
+						ContainerConfig: ContainerConfig{
+							CPUs:    2,
+							Ulimits: []string{"nofile=4096"},
+							DNS:     []string{"10.0.0.3"},
+						},
+						ProjectDescriptor: projectTypes.Descriptor{
+							Build: projectTypes.Build{
+								Container: projectTypes.Container{
+									CPUs:        1,
+									Memory:      "512m",
+									Ulimits:     []string{"nofile=1024:2048", "nproc=512"},
+									DNS:         []string{"10.0.0.2"},
+									ReadOnlyApp: true,
+								},
+							},
+						},
+					}))
+					h.AssertEq(t, fakeLifecycle.Opts.Container, build.ContainerSettings{
+						NanoCPUs:    2000000000,
+						Memory:      512 * 1024 * 1024,
+						Ulimits:     []*units.Ulimit{{Name: "nofile", Soft: 4096, Hard: 4096}, {Name: "nproc", Soft: 512, Hard: 512}},
+						DNS:         []string{"10.0.0.2", "10.0.0.3"},
+						ReadOnlyApp: true,
+					})
+				})
+			})
+
+			when("an option is invalid", func() {
+				for _, tc := range []struct {
+					name   string
+					config ContainerConfig
+					err    string
+				}{
+					{"negative cpus", ContainerConfig{CPUs: -1}, "cpus must not be negative"},
+					{"invalid ulimit", ContainerConfig{Ulimits: []string{"nofile"}}, "parsing ulimit 'nofile'"},
+					{"relative tmpfs path", ContainerConfig{Tmpfs: []string{"tmp"}}, "tmpfs mount 'tmp' must have an absolute path"},
+					{"extra host without ip", ContainerConfig{ExtraHosts: []string{"registry.local"}}, "extra host 'registry.local' must have the form <host>:<ip>"},
+					{"dns server name", ContainerConfig{DNS: []string{"dns.local"}}, "DNS server 'dns.local' must be an IP address"},
+				} {
+					tc := tc
+					it("errors for "+tc.name, func() {
+						err := subject.Build(context.TODO(), BuildOptions{
+							Image:           "some/app",
+							Builder:         defaultBuilderName,
+							ContainerConfig: tc.config,
+						})
+						h.AssertError(t, err, tc.err)
+					})
+				}
+			})
+		})
+
+		when("Secrets option", func() {
+			var secretsDir string
+
+			it.Before(func() {
+				secretsDir = filepath.Join(tmpDir, "secrets")
+				h.AssertNil(t, os.MkdirAll(secretsDir, 0755))
+				h.AssertNil(t, ioutil.WriteFile(filepath.Join(secretsDir, "npmrc"), []byte("some-npmrc"), 0600))
+				h.AssertNil(t, ioutil.WriteFile(filepath.Join(secretsDir, "settings.xml"), []byte("some-settings"), 0600))
+				h.AssertNil(t, ioutil.WriteFile(filepath.Join(secretsDir, "other-npmrc"), []byte("other-npmrc"), 0600))
+			})
+
+			it("reads the secrets and passes them to the lifecycle", func() {
+				h.AssertNil(t, subject.Build(context.TODO(), BuildOptions{
+					Image:   "some/app",
+					Builder: defaultBuilderName,
+					Secrets: []Secret{{ID: "npmrc", Source: filepath.Join(secretsDir, "npmrc")}},
+				}))
+				h.AssertEq(t, fakeLifecycle.Opts.Secrets, map[string][]byte{"npmrc": []byte("some-npmrc")})
+			})
+
+			when("the project descriptor has secrets", func() {
+				it("resolves them against the base dir unless the options override them", func() {
+					h.AssertNil(t, subject.Build(context.TODO(), BuildOptions{
+						Image:                    "some/app",
+						Builder:                  defaultBuilderName,
+						Secrets:                  []Secret{{ID: "npmrc", Source: filepath.Join(secretsDir, "other-npmrc")}},
+						ProjectDescriptorBaseDir: secretsDir,
+						ProjectDescriptor: projectTypes.Descriptor{
+							Build: projectTypes.Build{
+								Secrets: []projectTypes.Secret{
+									{ID: "npmrc", Source: "npmrc"},
+									{ID: "maven-settings", Source: "settings.xml"},
+								},
+							},
+						},
+					}))
+					h.AssertEq(t, fakeLifecycle.Opts.Secrets, map[string][]byte{
+						"npmrc":          []byte("other-npmrc"),
+						"maven-settings": []byte("some-settings"),
+					})
+				})
+			})
+
+			when("the id of a secret is invalid", func() {
+				it("errors", func() {
+					err := subject.Build(context.TODO(), BuildOptions{
+						Image:   "some/app",
+						Builder: defaultBuilderName,
+						Secrets: []Secret{{ID: "../npmrc", Source: filepath.Join(secretsDir, "npmrc")}},
+					})
+					h.AssertError(t, err, "invalid secret id '../npmrc'")
+				})
+			})
+
+			when("the source of a secret doesn't exist", func() {
+				it("errors", func() {
+					err := subject.Build(context.TODO(), BuildOptions{
+						Image:   "some/app",
+						Builder: defaultBuilderName,
+						Secrets: []Secret{{ID: "npmrc", Source: filepath.Join(secretsDir, "missing")}},
+					})
+					h.AssertError(t, err, "reading secret 'npmrc'")
+				})
+			})
+
+			when("the docker daemon is remote", func() {
+				it("errors", func() {
+					remoteDocker, err := dockerclient.NewClientWithOpts(dockerclient.WithHost("tcp://docker.example.com:2376"), dockerclient.WithVersion("1.38"))
+					h.AssertNil(t, err)
+					subject.docker = remoteDocker
+
+					err = subject.Build(context.TODO(), BuildOptions{
+						Image:   "some/app",
+						Builder: defaultBuilderName,
+						Secrets: []Secret{{ID: "npmrc", Source: filepath.Join(secretsDir, "npmrc")}},
+					})
+					h.AssertError(t, err, "secrets require a local docker daemon, the daemon at 'tcp://docker.example.com:2376' is remote")
+				})
+			})
+		})
+
+		when("DockerHost option", func() {
+			it("passes the value through", func() {
+				h.AssertNil(t, subject.Build(context.TODO(), BuildOptions{
+					Image:      "some/app",
+					Builder:    defaultBuilderName,
+					DockerHost: "tcp://example.com:2375",
+				}))
+				h.AssertEq(t, fakeLifecycle.Opts.DockerHost, "tcp://example.com:2375")
+			})
+
+			when("it's 'inherit'", func() {
+				it("passes 'inherit' through, for DOCKER_HOST to be used", func() {
+					h.AssertNil(t, subject.Build(context.TODO(), BuildOptions{
+						Image:      "some/app",
+						Builder:    defaultBuilderName,
+						DockerHost: "inherit",
+					}))
+					h.AssertEq(t, fakeLifecycle.Opts.DockerHost, "inherit")
+				})
+
+				when("the client has a docker host", func() {
+					it("uses the docker host of the client", func() {
+						WithDockerHost("unix:///some/docker.sock")(subject)
+
+						h.AssertNil(t, subject.Build(context.TODO(), BuildOptions{
+							Image:      "some/app",
+							Builder:    defaultBuilderName,
+							DockerHost: "inherit",
+						}))
+						h.AssertEq(t, fakeLifecycle.Opts.DockerHost, "unix:///some/docker.sock")
+					})
+				})
+			})
+		})
+
 		when("Lifecycle option", func() {
 			when("Platform API", func() {
 				for _, supportedPlatformAPI := range []string{"0.3", "0.4"} {
@@ -2623,3 +2923,8 @@ func (f *executeFailsLifecycle) Execute(_ context.Context, opts build.LifecycleO
 	f.Opts = opts
 	return errors.New("")
 }
+
+func (f *executeFailsLifecycle) Detect(_ context.Context, opts build.LifecycleOptions) (build.DetectResult, error) {
+	f.Opts = opts
+	return build.DetectResult{}, errors.New("")
+}
@@ -0,0 +1,491 @@
+package client
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/builder"
+	internalConfig "github.com/buildpacks/pack/internal/config"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/archive"
+	"github.com/buildpacks/pack/pkg/blob"
+	"github.com/buildpacks/pack/pkg/buildpack"
+	"github.com/buildpacks/pack/pkg/image"
+	"github.com/buildpacks/pack/pkg/logging"
+	projectTypes "github.com/buildpacks/pack/pkg/project/types"
+)
+
+const (
+	bundleManifestPath = "bundle.toml"
+	bundleImagesPath   = "images.tar"
+	bundleBlobsDir     = "blobs"
+	bundleRegistryDir  = "registries"
+)
+
+// the name of the cache of a buildpack registry in pack home, see registry.NewRegistryCache
+var registryCacheDirRegexp = regexp.MustCompile(`^registry-[0-9a-f]{64}$`)
+
+// CreateBundleOptions are options available for CreateBundle
+type CreateBundleOptions struct {
+	// Path of the bundle archive to create.
+	Path string
+
+	// Builder image to include, along with its run image and lifecycle image.
+	Builder string
+
+	// RunImage to include instead of the run image of the builder.
+	RunImage string
+
+	// AdditionalMirrors of run images, used to select the run image like Build does.
+	AdditionalMirrors map[string][]string
+
+	// LifecycleImage to include instead of the lifecycle image matching the lifecycle of the builder.
+	LifecycleImage string
+
+	// Buildpacks to include, in any form Build accepts.
+	Buildpacks []string
+
+	// RelativeBaseDir to resolve relative Buildpacks from.
+	RelativeBaseDir string
+
+	// ProjectDescriptor whose buildpacks are included, in addition to Buildpacks.
+	ProjectDescriptor projectTypes.Descriptor
+
+	// ProjectDescriptorBaseDir to resolve relative buildpacks of the ProjectDescriptor from.
+	ProjectDescriptorBaseDir string
+
+	// Registry to locate registry buildpacks in.
+	Registry string
+
+	// Strategy for updating images before they're included.
+	PullPolicy image.PullPolicy
+}
+
+// LoadBundleOptions are options available for LoadBundle
+type LoadBundleOptions struct {
+	// Path of the bundle archive to load.
+	Path string
+}
+
+type bundleManifest struct {
+	Images     []string     `toml:"images"`
+	Blobs      []bundleBlob `toml:"blobs"`
+	Registries []string     `toml:"registries"`
+}
+
+type bundleBlob struct {
+	URI  string `toml:"uri"`
+	Path string `toml:"path"`
+}
+
+// CreateBundle exports the images and downloads needed to build with a builder and a project to a single archive,
+// which LoadBundle loads on another machine, so that it can build without network access in offline mode.
+// Buildpacks that are local files are not included, as they're expected to be provided with the project.
+func (c *Client) CreateBundle(ctx context.Context, opts CreateBundleOptions) error {
+	if opts.Path == "" {
+		return errors.New("bundle path must be provided")
+	}
+
+	builderRef, err := c.processBuilderName(opts.Builder)
+	if err != nil {
+		return errors.Wrapf(err, "invalid builder '%s'", opts.Builder)
+	}
+
+	rawBuilderImage, err := c.imageFetcher.Fetch(ctx, builderRef.Name(), image.FetchOptions{Daemon: true, PullPolicy: opts.PullPolicy})
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch builder image '%s'", builderRef.Name())
+	}
+
+	bldr, err := c.getBuilder(rawBuilderImage)
+	if err != nil {
+		return errors.Wrapf(err, "invalid builder %s", style.Symbol(opts.Builder))
+	}
+
+	manifest := bundleManifest{}
+	addImage := func(name string) {
+		for _, img := range manifest.Images {
+			if img == name {
+				return
+			}
+		}
+		manifest.Images = append(manifest.Images, name)
+	}
+	addImage(builderRef.Name())
+
+	runImageName := c.resolveRunImage(opts.RunImage, "", builderRef.Context().RegistryStr(), bldr.Stack(), opts.AdditionalMirrors, false)
+	if _, err := c.imageFetcher.Fetch(ctx, runImageName, image.FetchOptions{Daemon: true, PullPolicy: opts.PullPolicy}); err != nil {
+		return errors.Wrapf(err, "failed to fetch run image '%s'", runImageName)
+	}
+	addImage(runImageName)
+
+	lifecycleImageName, err := c.fetchBundleLifecycleImage(ctx, bldr, opts)
+	if err != nil {
+		return err
+	}
+	if lifecycleImageName != "" {
+		addImage(lifecycleImageName)
+	}
+
+	blobs := map[string]blob.Blob{}
+	registries := map[string]bool{}
+	for _, bp := range bundleBuildpacks(opts) {
+		locatorType, err := buildpack.GetLocatorType(bp.locator, bp.baseDir, bldr.Buildpacks())
+		if err != nil {
+			return err
+		}
+
+		switch locatorType {
+		case buildpack.PackageLocator:
+			imageName := buildpack.ParsePackageLocator(bp.locator)
+			if _, err := c.imageFetcher.Fetch(ctx, imageName, image.FetchOptions{Daemon: true, PullPolicy: opts.PullPolicy}); err != nil {
+				return errors.Wrapf(err, "fetching buildpack image %s", style.Symbol(imageName))
+			}
+			addImage(imageName)
+		case buildpack.RegistryLocator:
+			registryCache, err := getRegistry(c.logger, opts.Registry, c.offline)
+			if err != nil {
+				return errors.Wrapf(err, "invalid registry %s", style.Symbol(opts.Registry))
+			}
+
+			registryBp, err := registryCache.LocateBuildpack(bp.locator)
+			if err != nil {
+				return errors.Wrapf(err, "locating in registry %s", style.Symbol(bp.locator))
+			}
+
+			if _, err := c.imageFetcher.Fetch(ctx, registryBp.Address, image.FetchOptions{Daemon: true, PullPolicy: opts.PullPolicy}); err != nil {
+				return errors.Wrapf(err, "fetching buildpack image %s", style.Symbol(registryBp.Address))
+			}
+			addImage(registryBp.Address)
+			registries[registryCache.Root] = true
+		case buildpack.URILocator:
+			uri, _, err := blob.SplitDigest(bp.locator)
+			if err != nil {
+				return err
+			}
+			if !isRemoteURI(uri) {
+				c.logger.Debugf("Skipping local buildpack %s", style.Symbol(uri))
+				continue
+			}
+
+			if _, ok := blobs[uri]; ok {
+				continue
+			}
+
+			downloaded, err := c.downloader.Download(ctx, bp.locator)
+			if err != nil {
+				return errors.Wrapf(err, "downloading buildpack %s", style.Symbol(uri))
+			}
+			blobs[uri] = downloaded
+			manifest.Blobs = append(manifest.Blobs, bundleBlob{
+				URI:  uri,
+				Path: path.Join(bundleBlobsDir, fmt.Sprintf("%x", sha256.Sum256([]byte(uri)))),
+			})
+		}
+	}
+
+	var registryRoots []string
+	for root := range registries {
+		registryRoots = append(registryRoots, root)
+	}
+	sort.Strings(registryRoots)
+	for _, root := range registryRoots {
+		manifest.Registries = append(manifest.Registries, path.Join(bundleRegistryDir, filepath.Base(root)))
+	}
+
+	if err := c.writeBundle(ctx, opts.Path, manifest, blobs, registryRoots); err != nil {
+		os.Remove(opts.Path)
+		return errors.Wrapf(err, "writing bundle %s", style.Symbol(opts.Path))
+	}
+
+	c.logger.Infof("Created bundle %s with %d images, %d buildpack downloads and %d buildpack registries",
+		style.Symbol(opts.Path), len(manifest.Images), len(manifest.Blobs), len(manifest.Registries))
+	return nil
+}
+
+// LoadBundle loads the images of a bundle created with CreateBundle to the daemon, and its buildpack downloads and
+// buildpack registries to the caches of pack, so that they're used in offline mode.
+func (c *Client) LoadBundle(ctx context.Context, opts LoadBundleOptions) error {
+	fh, err := os.Open(opts.Path)
+	if err != nil {
+		return errors.Wrapf(err, "opening bundle %s", style.Symbol(opts.Path))
+	}
+	defer fh.Close()
+
+	tr := tar.NewReader(fh)
+	header, err := tr.Next()
+	if err != nil || header.Name != bundleManifestPath {
+		return errors.Errorf("%s is not a bundle created with 'pack bundle create'", style.Symbol(opts.Path))
+	}
+
+	var manifest bundleManifest
+	if _, err := toml.NewDecoder(tr).Decode(&manifest); err != nil {
+		return errors.Wrapf(err, "reading %s of bundle %s", bundleManifestPath, style.Symbol(opts.Path))
+	}
+
+	blobURIs := map[string]string{}
+	for _, b := range manifest.Blobs {
+		blobURIs[b.Path] = b.URI
+	}
+
+	seeder, ok := c.downloader.(blob.Seeder)
+	if !ok && len(blobURIs) > 0 {
+		return errors.New("buildpack downloads can't be loaded, as the downloader of the client doesn't support it")
+	}
+
+	packHome, err := internalConfig.PackHome()
+	if err != nil {
+		return errors.Wrap(err, "getting pack home")
+	}
+
+	// only the registry caches listed in the manifest are loaded, each replaces the cache of the same registry
+	registries := map[string]bool{}
+	for _, registry := range manifest.Registries {
+		name := strings.TrimPrefix(registry, bundleRegistryDir+"/")
+		if !registryCacheDirRegexp.MatchString(name) {
+			return errors.Errorf("invalid buildpack registry %s in %s of bundle %s", style.Symbol(registry), bundleManifestPath, style.Symbol(opts.Path))
+		}
+		registries[name] = true
+	}
+
+	loadedRegistries := map[string]bool{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "reading bundle %s", style.Symbol(opts.Path))
+		}
+
+		switch {
+		case header.Name == bundleImagesPath:
+			if err := c.loadBundleImages(ctx, tr); err != nil {
+				return errors.Wrap(err, "loading images")
+			}
+		case blobURIs[header.Name] != "":
+			uri := blobURIs[header.Name]
+			c.logger.Debugf("Loading buildpack download %s", style.Symbol(uri))
+			if err := seeder.Seed(uri, tr); err != nil {
+				return errors.Wrapf(err, "loading buildpack download %s", style.Symbol(uri))
+			}
+		case strings.HasPrefix(header.Name, bundleRegistryDir+"/"):
+			if err := extractBundleRegistryEntry(packHome, header, tr, registries, loadedRegistries); err != nil {
+				return errors.Wrapf(err, "loading buildpack registry entry %s", style.Symbol(header.Name))
+			}
+		}
+	}
+
+	c.logger.Infof("Loaded %d images, %d buildpack downloads and %d buildpack registries from %s",
+		len(manifest.Images), len(manifest.Blobs), len(manifest.Registries), style.Symbol(opts.Path))
+	return nil
+}
+
+func (c *Client) fetchBundleLifecycleImage(ctx context.Context, bldr *builder.Builder, opts CreateBundleOptions) (string, error) {
+	imgOS, err := bldr.Image().OS()
+	if err != nil {
+		return "", errors.Wrap(err, "getting builder OS")
+	}
+
+	lifecycleVersion := bldr.LifecycleDescriptor().Info.Version
+	if opts.LifecycleImage == "" && !lifecycleImageSupported(imgOS, lifecycleVersion) {
+		c.logger.Debugf("Lifecycle %s does not have an associated lifecycle image", lifecycleVersion.String())
+		return "", nil
+	}
+
+	lifecycleImage, err := c.fetchLifecycleImage(ctx, bldr.Image(), imgOS, lifecycleVersion, BuildOptions{
+		LifecycleImage: opts.LifecycleImage,
+		PullPolicy:     opts.PullPolicy,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return lifecycleImage.Name(), nil
+}
+
+type bundleBuildpack struct {
+	locator string
+	baseDir string
+}
+
+// bundleBuildpacks returns the buildpacks to include in a bundle, along with the directory relative buildpacks are
+// resolved from. Inline buildpacks of the project descriptor are skipped, as they're part of the project.
+func bundleBuildpacks(opts CreateBundleOptions) []bundleBuildpack {
+	var bps []bundleBuildpack
+	for _, bp := range opts.Buildpacks {
+		bps = append(bps, bundleBuildpack{locator: bp, baseDir: opts.RelativeBaseDir})
+	}
+
+	for _, bp := range opts.ProjectDescriptor.Build.Buildpacks {
+		switch {
+		case bp.URI != "":
+			bps = append(bps, bundleBuildpack{locator: blob.WithDigest(bp.URI, bp.Digest), baseDir: opts.ProjectDescriptorBaseDir})
+		case bp.ID != "" && bp.Version != "" && bp.Script.Inline == "":
+			bps = append(bps, bundleBuildpack{locator: fmt.Sprintf("%s@%s", bp.ID, bp.Version), baseDir: opts.ProjectDescriptorBaseDir})
+		}
+	}
+
+	return bps
+}
+
+func (c *Client) writeBundle(ctx context.Context, bundlePath string, manifest bundleManifest, blobs map[string]blob.Blob, registryRoots []string) error {
+	fh, err := os.Create(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	tw := tar.NewWriter(fh)
+
+	var manifestContents strings.Builder
+	if err := toml.NewEncoder(&manifestContents).Encode(manifest); err != nil {
+		return err
+	}
+	if err := writeBundleEntry(tw, bundleManifestPath, strings.NewReader(manifestContents.String())); err != nil {
+		return err
+	}
+
+	c.logger.Debugf("Saving images %s", strings.Join(manifest.Images, ", "))
+	images, err := c.docker.ImageSave(ctx, manifest.Images)
+	if err != nil {
+		return errors.Wrap(err, "saving images")
+	}
+	defer images.Close()
+	if err := writeBundleEntry(tw, bundleImagesPath, images); err != nil {
+		return errors.Wrap(err, "saving images")
+	}
+
+	for _, b := range manifest.Blobs {
+		rc, err := blob.OpenRaw(blobs[b.URI])
+		if err != nil {
+			return errors.Wrapf(err, "reading buildpack download %s", style.Symbol(b.URI))
+		}
+		err = writeBundleEntry(tw, b.Path, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, root := range registryRoots {
+		if err := archive.WriteDirToTar(tw, root, path.Join(bundleRegistryDir, filepath.Base(root)), 0, 0, -1, false, false, nil); err != nil {
+			return errors.Wrapf(err, "adding buildpack registry cache %s", style.Symbol(root))
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return fh.Close()
+}
+
+// writeBundleEntry writes a file to a bundle, through a temporary file as its size has to be known up front
+func writeBundleEntry(tw *tar.Writer, name string, r io.Reader) error {
+	tmpFile, err := ioutil.TempFile("", "bundle-entry-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	size, err := io.Copy(tmpFile, r)
+	if err != nil {
+		return err
+	}
+	if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  archive.NormalizedDateTime,
+	}); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, tmpFile)
+	return err
+}
+
+func (c *Client) loadBundleImages(ctx context.Context, r io.Reader) error {
+	resp, err := c.docker.ImageLoad(ctx, r, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	writer := logging.GetWriterForLevel(c.logger, logging.DebugLevel)
+	return jsonmessage.DisplayJSONMessagesStream(resp.Body, writer, 0, false, nil)
+}
+
+// extractBundleRegistryEntry extracts an entry of a buildpack registry cache to pack home, replacing an existing
+// cache of the same registry. Only entries of the given registries are extracted.
+func extractBundleRegistryEntry(packHome string, header *tar.Header, r io.Reader, registries, loaded map[string]bool) error {
+	relPath := path.Clean(strings.TrimPrefix(header.Name, bundleRegistryDir+"/"))
+	registryName := strings.SplitN(relPath, "/", 2)[0]
+	if !registries[registryName] {
+		return errors.New("entry isn't part of a buildpack registry listed in the bundle manifest")
+	}
+
+	target := filepath.Join(packHome, filepath.FromSlash(relPath))
+	registryRoot := filepath.Join(packHome, registryName)
+	if target != registryRoot && !strings.HasPrefix(target, registryRoot+string(filepath.Separator)) {
+		return errors.New("entry is outside of the registry caches")
+	}
+
+	if !loaded[registryRoot] {
+		if err := os.RemoveAll(registryRoot); err != nil {
+			return err
+		}
+		loaded[registryRoot] = true
+	}
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(target, 0750)
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return err
+		}
+		fh, err := os.OpenFile(filepath.Clean(target), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, header.FileInfo().Mode())
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		_, err = io.Copy(fh, r)
+		return err
+	}
+
+	return nil
+}
+
+func isRemoteURI(uri string) bool {
+	parsedURL, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+
+	switch parsedURL.Scheme {
+	case "http", "https", "s3", "oci":
+		return true
+	}
+	return false
+}
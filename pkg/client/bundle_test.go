@@ -0,0 +1,292 @@
+package client
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buildpacks/imgutil/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpacks/pack/internal/builder"
+	cfg "github.com/buildpacks/pack/internal/config"
+	ifakes "github.com/buildpacks/pack/internal/fakes"
+	"github.com/buildpacks/pack/pkg/archive"
+	"github.com/buildpacks/pack/pkg/blob"
+	"github.com/buildpacks/pack/pkg/image"
+	"github.com/buildpacks/pack/pkg/logging"
+	projectTypes "github.com/buildpacks/pack/pkg/project/types"
+	"github.com/buildpacks/pack/pkg/testmocks"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestBundle(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "Bundle", testBundle, spec.Sequential(), spec.Report(report.Terminal{}))
+}
+
+func testBundle(t *testing.T, when spec.G, it spec.S) {
+	var (
+		subject            *Client
+		fakeImageFetcher   *ifakes.FakeImageFetcher
+		mockDockerClient   *testmocks.MockCommonAPIClient
+		mockController     *gomock.Controller
+		tmpDir             string
+		bundlePath         string
+		downloadCacheDir   string
+		server             *ghttp.Server
+		buildpackURI       string
+		buildpackTGZ       string
+		builderName        = "example.com/some/builder:tag"
+		runImageName       = "some/run"
+		lifecycleImageName = fmt.Sprintf("%s:%s", cfg.DefaultLifecycleImageRepo, builder.DefaultLifecycleVersion)
+		packageImageName   = "example.com/some/package:1.0.0"
+		outBuf             bytes.Buffer
+	)
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "bundle-test")
+		h.AssertNil(t, err)
+		h.AssertNil(t, os.Setenv("PACK_HOME", filepath.Join(tmpDir, "pack-home")))
+		bundlePath = filepath.Join(tmpDir, "bundle.tar")
+		downloadCacheDir = filepath.Join(tmpDir, "download-cache")
+
+		fakeImageFetcher = ifakes.NewFakeImageFetcher()
+		fakeImageFetcher.LocalImages[builderName] = newFakeBuilderImage(t, tmpDir, builderName, "some.stack.id", runImageName, builder.DefaultLifecycleVersion, newLinuxImage)
+		for _, name := range []string{runImageName, lifecycleImageName, packageImageName} {
+			fakeImageFetcher.LocalImages[name] = fakes.NewImage(name, "", nil)
+		}
+
+		buildpackTGZ = h.CreateTGZ(t, filepath.Join("testdata", "buildpack"), "./", 0755)
+		server = ghttp.NewServer()
+		server.AllowUnhandledRequests = true
+		server.RouteToHandler(http.MethodGet, "/buildpack.tgz", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, buildpackTGZ)
+		})
+		buildpackURI = server.URL() + "/buildpack.tgz"
+
+		mockController = gomock.NewController(t)
+		mockDockerClient = testmocks.NewMockCommonAPIClient(mockController)
+
+		subject, err = NewClient(
+			WithLogger(logging.NewLogWithWriters(&outBuf, &outBuf)),
+			WithFetcher(fakeImageFetcher),
+			WithDockerClient(mockDockerClient),
+			WithDownloader(blob.NewDownloader(logging.NewSimpleLogger(&outBuf), downloadCacheDir)),
+		)
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+		server.Close()
+		os.Unsetenv("PACK_HOME")
+		os.Remove(buildpackTGZ)
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	createBundle := func(opts CreateBundleOptions) {
+		t.Helper()
+		mockDockerClient.EXPECT().
+			ImageSave(gomock.Any(), gomock.Any()).
+			Return(ioutil.NopCloser(bytes.NewBufferString("some-images")), nil)
+
+		opts.Path = bundlePath
+		opts.Builder = builderName
+		h.AssertNil(t, subject.CreateBundle(context.TODO(), opts))
+	}
+
+	when("#CreateBundle", func() {
+		it("includes the builder, run image and lifecycle image", func() {
+			mockDockerClient.EXPECT().
+				ImageSave(gomock.Any(), []string{builderName, runImageName, lifecycleImageName}).
+				Return(ioutil.NopCloser(bytes.NewBufferString("some-images")), nil)
+
+			h.AssertNil(t, subject.CreateBundle(context.TODO(), CreateBundleOptions{
+				Path:       bundlePath,
+				Builder:    builderName,
+				PullPolicy: image.PullIfNotPresent,
+			}))
+
+			_, contents, err := archive.ReadTarEntry(mustOpen(t, bundlePath), "images.tar")
+			h.AssertNil(t, err)
+			h.AssertEq(t, string(contents), "some-images")
+			h.AssertEq(t, fakeImageFetcher.FetchCalls[runImageName].PullPolicy, image.PullIfNotPresent)
+			h.AssertContains(t, outBuf.String(), "with 3 images, 0 buildpack downloads and 0 buildpack registries")
+		})
+
+		it("includes the buildpacks of the project and the buildpacks provided", func() {
+			createBundle(CreateBundleOptions{
+				Buildpacks: []string{"docker://" + packageImageName, filepath.Join("testdata", "buildpack")},
+				ProjectDescriptor: projectTypes.Descriptor{
+					Build: projectTypes.Build{
+						Buildpacks: []projectTypes.Buildpack{{URI: buildpackURI}},
+					},
+				},
+			})
+
+			_, manifest, err := archive.ReadTarEntry(mustOpen(t, bundlePath), "bundle.toml")
+			h.AssertNil(t, err)
+			h.AssertContains(t, string(manifest), packageImageName)
+			h.AssertContains(t, string(manifest), buildpackURI)
+
+			expected, err := ioutil.ReadFile(buildpackTGZ)
+			h.AssertNil(t, err)
+			_, contents, err := archive.ReadTarEntry(mustOpen(t, bundlePath), fmt.Sprintf("blobs/%x", sha256.Sum256([]byte(buildpackURI))))
+			h.AssertNil(t, err)
+			h.AssertEq(t, contents, expected)
+		})
+
+		when("the builder can't be fetched", func() {
+			it("returns an error", func() {
+				err := subject.CreateBundle(context.TODO(), CreateBundleOptions{Path: bundlePath, Builder: "some/missing-builder"})
+				h.AssertError(t, err, "failed to fetch builder image")
+
+				_, err = os.Stat(bundlePath)
+				h.AssertEq(t, os.IsNotExist(err), true)
+			})
+		})
+
+		when("no path is provided", func() {
+			it("returns an error", func() {
+				err := subject.CreateBundle(context.TODO(), CreateBundleOptions{Builder: builderName})
+				h.AssertError(t, err, "bundle path must be provided")
+			})
+		})
+	})
+
+	when("#LoadBundle", func() {
+		it("loads the images and seeds the download cache", func() {
+			createBundle(CreateBundleOptions{
+				ProjectDescriptor: projectTypes.Descriptor{
+					Build: projectTypes.Build{
+						Buildpacks: []projectTypes.Buildpack{{URI: buildpackURI}},
+					},
+				},
+			})
+			server.Close()
+			h.AssertNil(t, os.RemoveAll(downloadCacheDir))
+
+			mockDockerClient.EXPECT().
+				ImageLoad(gomock.Any(), gomock.Any(), true).
+				DoAndReturn(func(_ context.Context, r io.Reader, _ bool) (types.ImageLoadResponse, error) {
+					contents, err := ioutil.ReadAll(r)
+					h.AssertNil(t, err)
+					h.AssertEq(t, string(contents), "some-images")
+					return types.ImageLoadResponse{Body: ioutil.NopCloser(bytes.NewBufferString(`{"stream":"Loaded image"}`))}, nil
+				})
+
+			h.AssertNil(t, subject.LoadBundle(context.TODO(), LoadBundleOptions{Path: bundlePath}))
+			h.AssertContains(t, outBuf.String(), "Loaded 3 images, 1 buildpack downloads and 0 buildpack registries")
+
+			offline := blob.NewDownloader(logging.NewSimpleLogger(&outBuf), downloadCacheDir, blob.WithOffline(true))
+			_, err := offline.Download(context.TODO(), buildpackURI)
+			h.AssertNil(t, err)
+		})
+
+		when("the images can't be loaded", func() {
+			it("returns an error", func() {
+				createBundle(CreateBundleOptions{})
+
+				mockDockerClient.EXPECT().
+					ImageLoad(gomock.Any(), gomock.Any(), true).
+					Return(types.ImageLoadResponse{Body: ioutil.NopCloser(bytes.NewBufferString(`{"errorDetail":{"message":"some-error"},"error":"some-error"}`))}, nil)
+
+				err := subject.LoadBundle(context.TODO(), LoadBundleOptions{Path: bundlePath})
+				h.AssertError(t, err, "loading images: some-error")
+			})
+		})
+
+		when("the bundle contains buildpack registries", func() {
+			var (
+				registryName = "registry-" + strings.Repeat("a", 64)
+				packHome     string
+			)
+
+			writeBundle := func(manifest string, entries map[string]string) {
+				t.Helper()
+				fh, err := os.Create(bundlePath)
+				h.AssertNil(t, err)
+				defer fh.Close()
+				tw := tar.NewWriter(fh)
+				h.AssertNil(t, tw.WriteHeader(&tar.Header{Name: "bundle.toml", Mode: 0644, Size: int64(len(manifest))}))
+				_, err = tw.Write([]byte(manifest))
+				h.AssertNil(t, err)
+				for name, contents := range entries {
+					h.AssertNil(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(contents))}))
+					_, err = tw.Write([]byte(contents))
+					h.AssertNil(t, err)
+				}
+				h.AssertNil(t, tw.Close())
+			}
+
+			it.Before(func() {
+				packHome = filepath.Join(tmpDir, "pack-home")
+				h.AssertNil(t, os.MkdirAll(packHome, 0750))
+			})
+
+			it("loads the registries listed in the manifest", func() {
+				writeBundle(fmt.Sprintf("registries = [\"registries/%s\"]\n", registryName), map[string]string{
+					"registries/" + registryName + "/index": "some-index",
+				})
+
+				h.AssertNil(t, subject.LoadBundle(context.TODO(), LoadBundleOptions{Path: bundlePath}))
+				contents, err := ioutil.ReadFile(filepath.Join(packHome, registryName, "index"))
+				h.AssertNil(t, err)
+				h.AssertEq(t, string(contents), "some-index")
+			})
+
+			it("rejects entries of registries not listed in the manifest", func() {
+				h.AssertNil(t, os.MkdirAll(filepath.Join(packHome, "config"), 0750))
+				writeBundle(fmt.Sprintf("registries = [\"registries/%s\"]\n", registryName), map[string]string{
+					"registries/config/some-file": "some-contents",
+				})
+
+				err := subject.LoadBundle(context.TODO(), LoadBundleOptions{Path: bundlePath})
+				h.AssertError(t, err, "isn't part of a buildpack registry listed in the bundle manifest")
+
+				_, err = os.Stat(filepath.Join(packHome, "config"))
+				h.AssertNil(t, err)
+			})
+
+			it("rejects registries of the manifest that aren't registry caches", func() {
+				writeBundle("registries = [\"registries/config\"]\n", nil)
+
+				err := subject.LoadBundle(context.TODO(), LoadBundleOptions{Path: bundlePath})
+				h.AssertError(t, err, "invalid buildpack registry 'registries/config'")
+			})
+		})
+
+		when("the file isn't a bundle", func() {
+			it("returns an error", func() {
+				h.AssertNil(t, archive.CreateSingleFileTar(bundlePath, "some-file", "some-content"))
+
+				err := subject.LoadBundle(context.TODO(), LoadBundleOptions{Path: bundlePath})
+				h.AssertError(t, err, fmt.Sprintf("'%s' is not a bundle created with 'pack bundle create'", bundlePath))
+			})
+		})
+	})
+}
+
+func mustOpen(t *testing.T, path string) io.Reader {
+	t.Helper()
+	contents, err := ioutil.ReadFile(path)
+	h.AssertNil(t, err)
+	return bytes.NewReader(contents)
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/buildpacks/imgutil"
 	"github.com/buildpacks/imgutil/local"
@@ -84,8 +85,9 @@ type BuildpackDownloader interface {
 // build an app image using Cloud Native Buildpacks.
 // All settings on this object should be changed through ClientOption functions.
 type Client struct {
-	logger logging.Logger
-	docker dockerClient.CommonAPIClient
+	logger     logging.Logger
+	docker     dockerClient.CommonAPIClient
+	dockerHost string
 
 	keychain            authn.Keychain
 	imageFactory        ImageFactory
@@ -94,9 +96,17 @@ type Client struct {
 	lifecycleExecutor   LifecycleExecutor
 	buildpackDownloader BuildpackDownloader
 
-	experimental    bool
-	registryMirrors map[string]string
-	version         string
+	experimental      bool
+	registryMirrors   map[string]string
+	version           string
+	fetchConcurrency  int
+	fetchSlots        chan struct{}
+	fetchSlotsOnce    sync.Once
+	lifecycleMirror   string
+	lifecycleCacheDir string
+	layerCacheDir     string
+	downloaderOptions []blob.DownloaderOption
+	offline           bool
 }
 
 // Option is a type of function that mutate settings on the client.
@@ -157,6 +167,14 @@ func WithDockerClient(docker dockerClient.CommonAPIClient) Option {
 	}
 }
 
+// WithDockerHost supply the address of the docker daemon the docker client is connected to, if it isn't DOCKER_HOST
+// (e.g. the daemon of a docker context). It's exposed to build containers when BuildOptions.DockerHost is 'inherit'.
+func WithDockerHost(host string) Option {
+	return func(c *Client) {
+		c.dockerHost = host
+	}
+}
+
 // WithExperimental sets whether experimental features should be enabled.
 func WithExperimental(experimental bool) Option {
 	return func(c *Client) {
@@ -178,6 +196,54 @@ func WithKeychain(keychain authn.Keychain) Option {
 	}
 }
 
+// WithFetchConcurrency sets the maximum number of images and buildpacks fetched at the same time,
+// across all the fetches of the client.
+func WithFetchConcurrency(n int) Option {
+	return func(c *Client) {
+		c.fetchConcurrency = n
+	}
+}
+
+// WithLifecycleMirror sets the base URL lifecycle releases are downloaded from, instead of GitHub.
+// The mirror must have the same layout as the GitHub releases, e.g. <mirror>/v0.13.3/lifecycle-v0.13.3+linux.x86-64.tgz.
+// Releases downloaded from the mirror are still verified against the checksums published on GitHub.
+func WithLifecycleMirror(mirror string) Option {
+	return func(c *Client) {
+		c.lifecycleMirror = mirror
+	}
+}
+
+// WithLifecycleCacheDir sets the directory verified lifecycle archives are cached in.
+func WithLifecycleCacheDir(path string) Option {
+	return func(c *Client) {
+		c.lifecycleCacheDir = path
+	}
+}
+
+// WithLayerCacheDir sets the directory the layers of buildpacks are cached in by diff ID when creating builders.
+func WithLayerCacheDir(path string) Option {
+	return func(c *Client) {
+		c.layerCacheDir = path
+	}
+}
+
+// WithDownloaderOptions sets options of the default downloader, such as credentials and CA certificates for the hosts
+// buildpacks and lifecycles are downloaded from. It is ignored if a downloader is supplied with WithDownloader.
+func WithDownloaderOptions(opts ...blob.DownloaderOption) Option {
+	return func(c *Client) {
+		c.downloaderOptions = append(c.downloaderOptions, opts...)
+	}
+}
+
+// WithOffline sets whether the client works without network access. Images are only fetched from the daemon, as if the
+// pull policy was always 'never', buildpacks and lifecycles are only downloaded from the download cache, and buildpack
+// registries are used as of their last refresh. Use LoadBundle to provide what's needed.
+func WithOffline(offline bool) Option {
+	return func(c *Client) {
+		c.offline = offline
+	}
+}
+
 const DockerAPIVersion = "1.38"
 
 // NewClient allocates and returns a Client configured with the specified options.
@@ -206,16 +272,36 @@ func NewClient(opts ...Option) (*Client, error) {
 		}
 	}
 
+	packHome, err := iconfig.PackHome()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting pack home")
+	}
+
 	if client.downloader == nil {
-		packHome, err := iconfig.PackHome()
-		if err != nil {
-			return nil, errors.Wrap(err, "getting pack home")
-		}
-		client.downloader = blob.NewDownloader(client.logger, filepath.Join(packHome, "download-cache"))
+		client.downloader = blob.NewDownloader(
+			client.logger,
+			filepath.Join(packHome, "download-cache"),
+			append([]blob.DownloaderOption{blob.WithKeychain(client.keychain), blob.WithOffline(client.offline)}, client.downloaderOptions...)...,
+		)
+	}
+
+	if client.lifecycleCacheDir == "" {
+		client.lifecycleCacheDir = filepath.Join(packHome, "lifecycle-cache")
+	}
+
+	if client.layerCacheDir == "" {
+		client.layerCacheDir = filepath.Join(packHome, "layer-cache")
 	}
 
 	if client.imageFetcher == nil {
-		client.imageFetcher = image.NewFetcher(client.logger, client.docker, image.WithRegistryMirrors(client.registryMirrors), image.WithKeychain(client.keychain))
+		client.imageFetcher = image.NewFetcher(
+			client.logger,
+			client.docker,
+			image.WithRegistryMirrors(client.registryMirrors),
+			image.WithKeychain(client.keychain),
+			image.WithPullRecords(image.NewPullRecords(filepath.Join(packHome, "image-pull-records.json"))),
+			image.WithOffline(client.offline),
+		)
 	}
 
 	if client.imageFactory == nil {
@@ -231,7 +317,8 @@ func NewClient(opts ...Option) (*Client, error) {
 			client.imageFetcher,
 			client.downloader,
 			&registryResolver{
-				logger: client.logger,
+				logger:  client.logger,
+				offline: client.offline,
 			},
 		)
 	}
@@ -242,11 +329,12 @@ func NewClient(opts ...Option) (*Client, error) {
 }
 
 type registryResolver struct {
-	logger logging.Logger
+	logger  logging.Logger
+	offline bool
 }
 
 func (r *registryResolver) Resolve(registryName, bpName string) (string, error) {
-	cache, err := getRegistry(r.logger, registryName)
+	cache, err := getRegistry(r.logger, registryName, r.offline)
 	if err != nil {
 		return "", errors.Wrapf(err, "lookup registry %s", style.Symbol(registryName))
 	}
@@ -57,7 +57,21 @@ func (c *Client) resolveRunImage(runImage, imgRegistry, bldrRegistry string, sta
 	return runImageName
 }
 
-func getRegistry(logger logging.Logger, registryName string) (registry.Cache, error) {
+// validatePublish returns an error if images are to be published while the client is offline
+func (c *Client) validatePublish(publish bool) error {
+	if publish && c.offline {
+		return errors.New("images can't be published in offline mode")
+	}
+	return nil
+}
+
+func getRegistry(logger logging.Logger, registryName string, offline bool) (registry.Cache, error) {
+	cache, err := newRegistryCache(logger, registryName)
+	cache.Offline = offline
+	return cache, err
+}
+
+func newRegistryCache(logger logging.Logger, registryName string) (registry.Cache, error) {
 	home, err := config.PackHome()
 	if err != nil {
 		return registry.Cache{}, err
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Masterminds/semver"
 	"github.com/buildpacks/imgutil"
@@ -12,7 +13,9 @@ import (
 	"github.com/buildpacks/pack/internal/builder"
 	"github.com/buildpacks/pack/internal/paths"
 	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/blob"
 	"github.com/buildpacks/pack/pkg/buildpack"
+	"github.com/buildpacks/pack/pkg/dist"
 	"github.com/buildpacks/pack/pkg/image"
 )
 
@@ -41,21 +44,61 @@ type CreateBuilderOptions struct {
 
 // CreateBuilder creates and saves a builder image to a registry with the provided options.
 // If any configuration is invalid, it will error and exit without creating any images.
+//
+// When the config declares a base-builder, the lifecycle, stack, buildpacks and order of that builder are used
+// wherever the config doesn't provide them. A buildpack added with the same ID as a buildpack of the base builder
+// replaces it, including in the order of the base builder.
 func (c *Client) CreateBuilder(ctx context.Context, opts CreateBuilderOptions) error {
+	if err := c.validatePublish(opts.Publish); err != nil {
+		return err
+	}
+
+	var (
+		baseImage      imgutil.Image
+		inheritedOrder bool
+	)
+	if opts.Config.BaseBuilder != "" {
+		var err error
+		baseImage, err = c.imageFetcher.Fetch(ctx, opts.Config.BaseBuilder, image.FetchOptions{Daemon: !opts.Publish, PullPolicy: opts.PullPolicy})
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch base builder")
+		}
+
+		inheritedOrder = len(opts.Config.Order) == 0
+		opts.Config, err = inheritBaseBuilderConfig(opts.Config, baseImage)
+		if err != nil {
+			return errors.Wrapf(err, "invalid base builder %s", style.Symbol(opts.Config.BaseBuilder))
+		}
+	}
+
 	if err := c.validateConfig(ctx, opts); err != nil {
 		return err
 	}
 
-	bldr, err := c.createBaseBuilder(ctx, opts)
+	bldr, err := c.createBaseBuilder(ctx, opts, baseImage)
 	if err != nil {
 		return errors.Wrap(err, "failed to create builder")
 	}
 
+	removed, err := removeBuildpacksFromBuilder(opts.Config.RemoveBuildpacks, bldr)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove buildpacks from builder")
+	}
+
+	baseBuildpacks := bldr.Buildpacks()
 	if err := c.addBuildpacksToBuilder(ctx, opts, bldr); err != nil {
 		return errors.Wrap(err, "failed to add buildpacks to builder")
 	}
 
-	bldr.SetOrder(opts.Config.Order)
+	order := opts.Config.Order
+	if opts.Config.BaseBuilder != "" {
+		upgraded := upgradeBuildpacksOfBuilder(baseBuildpacks, bldr)
+		if inheritedOrder {
+			order = updateInheritedOrder(order, removed, upgraded)
+		}
+	}
+
+	bldr.SetOrder(order)
 	bldr.SetStack(opts.Config.Stack)
 
 	return bldr.Save(c.logger, builder.CreatorMetadata{Version: c.version})
@@ -118,13 +161,19 @@ func (c *Client) validateRunImageConfig(ctx context.Context, opts CreateBuilderO
 	return nil
 }
 
-func (c *Client) createBaseBuilder(ctx context.Context, opts CreateBuilderOptions) (*builder.Builder, error) {
-	baseImage, err := c.imageFetcher.Fetch(ctx, opts.Config.Stack.BuildImage, image.FetchOptions{Daemon: !opts.Publish, PullPolicy: opts.PullPolicy})
-	if err != nil {
-		return nil, errors.Wrap(err, "fetch build image")
-	}
+// createBaseBuilder creates a builder from the base builder image, if provided, or from the build image of the config.
+func (c *Client) createBaseBuilder(ctx context.Context, opts CreateBuilderOptions, baseImage imgutil.Image) (*builder.Builder, error) {
+	if baseImage != nil {
+		c.logger.Debugf("Creating builder %s from base builder %s", style.Symbol(opts.BuilderName), style.Symbol(baseImage.Name()))
+	} else {
+		var err error
+		baseImage, err = c.imageFetcher.Fetch(ctx, opts.Config.Stack.BuildImage, image.FetchOptions{Daemon: !opts.Publish, PullPolicy: opts.PullPolicy})
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch build image")
+		}
 
-	c.logger.Debugf("Creating builder %s from build-image %s", style.Symbol(opts.BuilderName), style.Symbol(baseImage.Name()))
+		c.logger.Debugf("Creating builder %s from build-image %s", style.Symbol(opts.BuilderName), style.Symbol(baseImage.Name()))
+	}
 	bldr, err := builder.New(baseImage, opts.BuilderName)
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid build-image")
@@ -140,6 +189,14 @@ func (c *Client) createBaseBuilder(ctx context.Context, opts CreateBuilderOption
 	}
 
 	bldr.SetDescription(opts.Config.Description)
+	bldr.SetBuildEnv(opts.Config.Build.Env)
+	bldr.SetLayerCache(c.layerCacheDir)
+
+	if opts.Publish {
+		bldr.SetLayerCompression(opts.Config.Compression)
+	} else if !opts.Config.Compression.IsDefault() {
+		c.logger.Warn("Layer compression only applies to published builders, and will be ignored")
+	}
 
 	if bldr.StackID != opts.Config.Stack.ID {
 		return nil, fmt.Errorf(
@@ -149,7 +206,11 @@ func (c *Client) createBaseBuilder(ctx context.Context, opts CreateBuilderOption
 		)
 	}
 
-	lifecycle, err := c.fetchLifecycle(ctx, opts.Config.Lifecycle, opts.RelativeBaseDir, os)
+	if opts.Config.BaseBuilder != "" && opts.Config.Lifecycle == (pubbldr.LifecycleConfig{}) {
+		return bldr, nil
+	}
+
+	lifecycle, err := c.fetchLifecycle(ctx, opts, os)
 	if err != nil {
 		return nil, errors.Wrap(err, "fetch lifecycle")
 	}
@@ -159,7 +220,137 @@ func (c *Client) createBaseBuilder(ctx context.Context, opts CreateBuilderOption
 	return bldr, nil
 }
 
-func (c *Client) fetchLifecycle(ctx context.Context, config pubbldr.LifecycleConfig, relativeBaseDir, os string) (builder.Lifecycle, error) {
+// inheritBaseBuilderConfig returns config with the stack, description and order of the base builder
+// wherever config doesn't provide them. The build env of the base builder is kept unless config
+// provides a variable of the same name.
+func inheritBaseBuilderConfig(config pubbldr.Config, baseImage imgutil.Image) (pubbldr.Config, error) {
+	base, err := builder.FromImage(baseImage)
+	if err != nil {
+		return pubbldr.Config{}, err
+	}
+
+	if config.Description == "" {
+		config.Description = base.Description()
+	}
+
+	if config.Stack.ID == "" {
+		config.Stack.ID = base.StackID
+	}
+
+	if config.Stack.RunImage == "" {
+		config.Stack.RunImage = base.Stack().RunImage.Image
+		if len(config.Stack.RunImageMirrors) == 0 {
+			config.Stack.RunImageMirrors = base.Stack().RunImage.Mirrors
+		}
+	}
+
+	if len(config.Order) == 0 {
+		config.Order = base.Order()
+	}
+
+	var buildEnv []pubbldr.BuildEnv
+	for _, baseEnv := range base.BuildEnv() {
+		replaced := false
+		for _, env := range config.Build.Env {
+			if env.Name == baseEnv.Name {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			buildEnv = append(buildEnv, baseEnv)
+		}
+	}
+	config.Build.Env = append(buildEnv, config.Build.Env...)
+
+	return config, nil
+}
+
+// removeBuildpacksFromBuilder removes the buildpacks of the base builder matching refs, in the form of
+// '<id>' or '<id>@<version>', and returns them.
+func removeBuildpacksFromBuilder(refs []string, bldr *builder.Builder) ([]dist.BuildpackInfo, error) {
+	var removed []dist.BuildpackInfo
+	for _, ref := range refs {
+		id, version := buildpack.ParseIDLocator(ref)
+
+		found := false
+		for _, bp := range bldr.Buildpacks() {
+			if bp.ID == id && (version == "" || bp.Version == version) {
+				bldr.RemoveBuildpack(bp)
+				removed = append(removed, bp)
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, errors.Errorf("buildpack %s was not found on the base builder", style.Symbol(ref))
+		}
+	}
+
+	return removed, nil
+}
+
+// upgradeBuildpacksOfBuilder removes the buildpacks of the base builder that have the same ID as a single buildpack
+// that was added with a different version, and returns the version each of them was upgraded to by full name.
+func upgradeBuildpacksOfBuilder(baseBuildpacks []dist.BuildpackInfo, bldr *builder.Builder) map[string]string {
+	baseNames := map[string]bool{}
+	for _, bp := range baseBuildpacks {
+		baseNames[bp.FullName()] = true
+	}
+
+	addedVersions := map[string][]string{}
+	for _, bp := range bldr.Buildpacks() {
+		if !baseNames[bp.FullName()] {
+			addedVersions[bp.ID] = append(addedVersions[bp.ID], bp.Version)
+		}
+	}
+
+	upgraded := map[string]string{}
+	for _, bp := range baseBuildpacks {
+		versions := addedVersions[bp.ID]
+		if len(versions) != 1 || versions[0] == bp.Version {
+			continue
+		}
+
+		bldr.RemoveBuildpack(bp)
+		upgraded[bp.FullName()] = versions[0]
+	}
+
+	return upgraded
+}
+
+// updateInheritedOrder drops removed buildpacks from order, along with any group left empty,
+// and points references to upgraded buildpacks at their new version.
+func updateInheritedOrder(order dist.Order, removed []dist.BuildpackInfo, upgraded map[string]string) dist.Order {
+	removedNames := map[string]bool{}
+	for _, bp := range removed {
+		removedNames[bp.FullName()] = true
+	}
+
+	var updatedOrder dist.Order
+	for _, entry := range order {
+		var group []dist.BuildpackRef
+		for _, ref := range entry.Group {
+			if removedNames[ref.FullName()] {
+				continue
+			}
+
+			if version, ok := upgraded[ref.FullName()]; ok {
+				ref.Version = version
+			}
+			group = append(group, ref)
+		}
+
+		if len(group) > 0 {
+			updatedOrder = append(updatedOrder, dist.OrderEntry{Group: group})
+		}
+	}
+
+	return updatedOrder
+}
+
+func (c *Client) fetchLifecycle(ctx context.Context, opts CreateBuilderOptions, os string) (builder.Lifecycle, error) {
+	config := opts.Config.Lifecycle
 	if config.Version != "" && config.URI != "" {
 		return nil, errors.Errorf(
 			"%s can only declare %s or %s, not both",
@@ -167,31 +358,58 @@ func (c *Client) fetchLifecycle(ctx context.Context, config pubbldr.LifecycleCon
 		)
 	}
 
-	var uri string
-	var err error
+	if config.Image != "" && (config.Version != "" || config.URI != "") {
+		return nil, errors.Errorf(
+			"%s can't declare %s along with %s or %s",
+			style.Symbol("lifecycle"), style.Symbol("image"), style.Symbol("version"), style.Symbol("uri"),
+		)
+	}
+
+	var lifecycleBlob blob.Blob
 	switch {
+	case config.Image != "":
+		var err error
+		lifecycleBlob, err = c.lifecycleFromImage(ctx, config.Image, os, image.FetchOptions{Daemon: !opts.Publish, PullPolicy: opts.PullPolicy})
+		if err != nil {
+			return nil, err
+		}
 	case config.Version != "":
 		v, err := semver.NewVersion(config.Version)
 		if err != nil {
 			return nil, errors.Wrapf(err, "%s must be a valid semver", style.Symbol("lifecycle.version"))
 		}
 
-		uri = uriFromLifecycleVersion(*v, os)
+		lifecycleBlob, err = c.downloadLifecycleVersion(ctx, *v, os, config.Digest)
+		if err != nil {
+			return nil, err
+		}
 	case config.URI != "":
-		uri, err = paths.FilePathToURI(config.URI, relativeBaseDir)
+		uri, digest, err := blob.SplitDigest(config.URI)
 		if err != nil {
 			return nil, err
 		}
-	default:
-		uri = uriFromLifecycleVersion(*semver.MustParse(builder.DefaultLifecycleVersion), os)
-	}
+		if config.Digest != "" {
+			digest = config.Digest
+		}
 
-	blob, err := c.downloader.Download(ctx, uri)
-	if err != nil {
-		return nil, errors.Wrap(err, "downloading lifecycle")
+		uri, err = paths.FilePathToURI(uri, opts.RelativeBaseDir)
+		if err != nil {
+			return nil, err
+		}
+
+		lifecycleBlob, err = c.downloader.Download(ctx, blob.WithDigest(uri, digest))
+		if err != nil {
+			return nil, errors.Wrap(err, "downloading lifecycle")
+		}
+	default:
+		var err error
+		lifecycleBlob, err = c.downloadLifecycleVersion(ctx, *semver.MustParse(builder.DefaultLifecycleVersion), os, "")
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	lifecycle, err := builder.NewLifecycle(blob)
+	lifecycle, err := builder.NewLifecycle(lifecycleBlob)
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid lifecycle")
 	}
@@ -200,27 +418,48 @@ func (c *Client) fetchLifecycle(ctx context.Context, config pubbldr.LifecycleCon
 }
 
 func (c *Client) addBuildpacksToBuilder(ctx context.Context, opts CreateBuilderOptions, bldr *builder.Builder) error {
-	for _, b := range opts.Config.Buildpacks {
-		c.logger.Debugf("Looking up buildpack %s", style.Symbol(b.DisplayString()))
+	imageOS, err := bldr.Image().OS()
+	if err != nil {
+		return errors.Wrapf(err, "getting OS from %s", style.Symbol(bldr.Image().Name()))
+	}
 
-		imageOS, err := bldr.Image().OS()
-		if err != nil {
-			return errors.Wrapf(err, "getting OS from %s", style.Symbol(bldr.Image().Name()))
-		}
+	type downloadedBuildpack struct {
+		mainBP buildpack.Buildpack
+		depBPs []buildpack.Buildpack
+	}
 
-		mainBP, depBPs, err := c.buildpackDownloader.Download(ctx, b.URI, buildpack.DownloadOptions{
-			RegistryName:    opts.Registry,
-			ImageOS:         imageOS,
-			RelativeBaseDir: opts.RelativeBaseDir,
-			Daemon:          !opts.Publish,
-			PullPolicy:      opts.PullPolicy,
-			ImageName:       b.ImageName,
+	downloads := make([]downloadedBuildpack, len(opts.Config.Buildpacks))
+	fetches := c.newFetchGroup()
+	for i, b := range opts.Config.Buildpacks {
+		i, b := i, b
+		fetches.Add(b.DisplayString(), func(ctx context.Context) error {
+			c.logger.Debugf("Looking up buildpack %s", style.Symbol(b.DisplayString()))
+
+			mainBP, depBPs, err := c.buildpackDownloader.Download(ctx, blob.WithDigest(b.URI, b.Digest), buildpack.DownloadOptions{
+				RegistryName:    opts.Registry,
+				ImageOS:         imageOS,
+				RelativeBaseDir: opts.RelativeBaseDir,
+				Daemon:          !opts.Publish,
+				PullPolicy:      opts.PullPolicy,
+				ImageName:       b.ImageName,
+			})
+			if err != nil {
+				return errors.Wrap(err, "downloading buildpack")
+			}
+
+			downloads[i] = downloadedBuildpack{mainBP: mainBP, depBPs: depBPs}
+			return nil
 		})
-		if err != nil {
-			return errors.Wrap(err, "downloading buildpack")
-		}
+	}
+
+	if err := fetches.Wait(ctx); err != nil {
+		return err
+	}
 
-		err = validateBuildpack(mainBP, b.URI, b.ID, b.Version)
+	for i, b := range opts.Config.Buildpacks {
+		mainBP, depBPs := downloads[i].mainBP, downloads[i].depBPs
+
+		err := validateBuildpack(mainBP, b.URI, b.ID, b.Version)
 		if err != nil {
 			return errors.Wrap(err, "invalid buildpack")
 		}
@@ -263,10 +502,15 @@ func validateBuildpack(bp buildpack.Buildpack, source, expectedID, expectedBPVer
 	return nil
 }
 
-func uriFromLifecycleVersion(version semver.Version, os string) string {
+func uriFromLifecycleVersion(version semver.Version, os, mirror string) string {
+	baseURL := lifecycleReleasesURL
+	if mirror != "" {
+		baseURL = strings.TrimSuffix(mirror, "/")
+	}
+
 	if os == "windows" {
-		return fmt.Sprintf("https://github.com/buildpacks/lifecycle/releases/download/v%s/lifecycle-v%s+windows.x86-64.tgz", version.String(), version.String())
+		return fmt.Sprintf("%s/v%s/lifecycle-v%s+windows.x86-64.tgz", baseURL, version.String(), version.String())
 	}
 
-	return fmt.Sprintf("https://github.com/buildpacks/lifecycle/releases/download/v%s/lifecycle-v%s+linux.x86-64.tgz", version.String(), version.String())
+	return fmt.Sprintf("%s/v%s/lifecycle-v%s+linux.x86-64.tgz", baseURL, version.String(), version.String())
 }
@@ -3,10 +3,12 @@ package client_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/buildpacks/imgutil/fakes"
@@ -58,6 +60,9 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 			logger                  logging.Logger
 			out                     bytes.Buffer
 			tmpDir                  string
+			lifecycleCacheDir       string
+			layerCacheDir           string
+			lifecycleTgz            string
 		)
 		var prepareFetcherWithRunImages = func() {
 			mockImageFetcher.EXPECT().Fetch(gomock.Any(), "some/run-image", gomock.Any()).Return(fakeRunImage, nil).AnyTimes()
@@ -68,11 +73,26 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 			mockImageFetcher.EXPECT().Fetch(gomock.Any(), "some/build-image", gomock.Any()).Return(fakeBuildImage, nil)
 		}
 
+		var prepareDownloaderWithMirroredLifecycle = func(uri, checksumURI string) {
+			contents, err := ioutil.ReadFile(lifecycleTgz)
+			h.AssertNil(t, err)
+			checksumFile := filepath.Join(tmpDir, "lifecycle.tgz.sha256")
+			h.AssertNil(t, ioutil.WriteFile(checksumFile, []byte(fmt.Sprintf("%x  lifecycle.tgz\n", sha256.Sum256(contents))), 0600))
+
+			mockDownloader.EXPECT().Download(gomock.Any(), checksumURI).Return(blob.NewBlob(checksumFile), nil)
+			mockDownloader.EXPECT().Download(gomock.Any(), fmt.Sprintf("%s#sha256:%x", uri, sha256.Sum256(contents))).Return(blob.NewBlob(lifecycleTgz), nil)
+		}
+
 		var createBuildpack = func(descriptor dist.BuildpackDescriptor) buildpack.Buildpack {
 			buildpack, err := ifakes.NewFakeBuildpack(descriptor, 0644)
 			h.AssertNil(t, err)
 			return buildpack
 		}
+
+		var prepareDownloaderWithLifecycle = func(uri string) {
+			prepareDownloaderWithMirroredLifecycle(uri, uri+".sha256")
+		}
+
 		var shouldCallBuildpackDownloaderWith = func(uri string, buildpackDownloadOptions buildpack.DownloadOptions) {
 			buildpack := createBuildpack(dist.BuildpackDescriptor{
 				API:    api.MustParse("0.3"),
@@ -103,6 +123,13 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 			fakeRunImageMirror = fakes.NewImage("localhost:5000/some/run-image", "", nil)
 			h.AssertNil(t, fakeRunImageMirror.SetLabel("io.buildpacks.stack.id", "some.stack.id"))
 
+			var err error
+			lifecycleCacheDir, err = ioutil.TempDir("", "lifecycle-cache")
+			h.AssertNil(t, err)
+			layerCacheDir, err = ioutil.TempDir("", "layer-cache")
+			h.AssertNil(t, err)
+			lifecycleTgz = h.CreateTGZ(t, filepath.Join("testdata", "lifecycle", "platform-0.4"), ".", -1)
+
 			exampleBuildpackBlob := blob.NewBlob(filepath.Join("testdata", "buildpack"))
 			mockDownloader.EXPECT().Download(gomock.Any(), "https://example.fake/bp-one.tgz").Return(exampleBuildpackBlob, nil).AnyTimes()
 			mockDownloader.EXPECT().Download(gomock.Any(), "some/buildpack/dir").Return(blob.NewBlob(filepath.Join("testdata", "buildpack")), nil).AnyTimes()
@@ -116,6 +143,8 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 			subject, err = client.NewClient(
 				client.WithLogger(logger),
 				client.WithDownloader(mockDownloader),
+				client.WithLifecycleCacheDir(lifecycleCacheDir),
+				client.WithLayerCacheDir(layerCacheDir),
 				client.WithImageFactory(mockImageFactory),
 				client.WithFetcher(mockImageFetcher),
 				client.WithDockerClient(mockDockerClient),
@@ -164,6 +193,9 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 		it.After(func() {
 			mockController.Finish()
 			h.AssertNil(t, os.RemoveAll(tmpDir))
+			h.AssertNil(t, os.RemoveAll(lifecycleCacheDir))
+			h.AssertNil(t, os.RemoveAll(layerCacheDir))
+			h.AssertNil(t, os.Remove(lifecycleTgz))
 		})
 
 		var successfullyCreateBuilder = func() *builder.Builder {
@@ -363,6 +395,8 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 						packClientWithExperimental, err := client.NewClient(
 							client.WithLogger(logger),
 							client.WithDownloader(mockDownloader),
+							client.WithLifecycleCacheDir(lifecycleCacheDir),
+							client.WithLayerCacheDir(layerCacheDir),
 							client.WithImageFactory(mockImageFactory),
 							client.WithFetcher(mockImageFetcher),
 							client.WithExperimental(true),
@@ -423,6 +457,24 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 					h.AssertError(t, err, "invalid lifecycle")
 				})
 			})
+
+			when("the lifecycle uri is pinned to a digest", func() {
+				it("downloads the lifecycle pinned to the digest", func() {
+					prepareFetcherWithBuildImage()
+					prepareFetcherWithRunImages()
+					digest := "sha256:" + strings.Repeat("a", 64)
+					opts.Config.Lifecycle.URI = "fake"
+					opts.Config.Lifecycle.Digest = digest
+
+					uri, err := paths.FilePathToURI(opts.Config.Lifecycle.URI, opts.RelativeBaseDir)
+					h.AssertNil(t, err)
+
+					mockDownloader.EXPECT().Download(gomock.Any(), uri+"#"+digest).Return(nil, errors.New("checksum mismatch")).Times(1)
+
+					err = subject.CreateBuilder(context.TODO(), opts)
+					h.AssertError(t, err, "checksum mismatch")
+				})
+			})
 		})
 
 		when("only lifecycle version is provided", func() {
@@ -432,12 +484,7 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 				opts.Config.Lifecycle.URI = ""
 				opts.Config.Lifecycle.Version = "3.4.5"
 
-				mockDownloader.EXPECT().Download(
-					gomock.Any(),
-					"https://github.com/buildpacks/lifecycle/releases/download/v3.4.5/lifecycle-v3.4.5+linux.x86-64.tgz",
-				).Return(
-					blob.NewBlob(filepath.Join("testdata", "lifecycle", "platform-0.4")), nil,
-				)
+				prepareDownloaderWithLifecycle("https://github.com/buildpacks/lifecycle/releases/download/v3.4.5/lifecycle-v3.4.5+linux.x86-64.tgz")
 
 				err := subject.CreateBuilder(context.TODO(), opts)
 				h.AssertNil(t, err)
@@ -448,6 +495,8 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 					packClientWithExperimental, err := client.NewClient(
 						client.WithLogger(logger),
 						client.WithDownloader(mockDownloader),
+						client.WithLifecycleCacheDir(lifecycleCacheDir),
+						client.WithLayerCacheDir(layerCacheDir),
 						client.WithImageFactory(mockImageFactory),
 						client.WithFetcher(mockImageFetcher),
 						client.WithExperimental(true),
@@ -460,12 +509,7 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 					opts.Config.Lifecycle.Version = "3.4.5"
 					h.AssertNil(t, fakeBuildImage.SetOS("windows"))
 
-					mockDownloader.EXPECT().Download(
-						gomock.Any(),
-						"https://github.com/buildpacks/lifecycle/releases/download/v3.4.5/lifecycle-v3.4.5+windows.x86-64.tgz",
-					).Return(
-						blob.NewBlob(filepath.Join("testdata", "lifecycle", "platform-0.4")), nil,
-					)
+					prepareDownloaderWithLifecycle("https://github.com/buildpacks/lifecycle/releases/download/v3.4.5/lifecycle-v3.4.5+windows.x86-64.tgz")
 
 					err = packClientWithExperimental.CreateBuilder(context.TODO(), opts)
 					h.AssertNil(t, err)
@@ -480,16 +524,11 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 				opts.Config.Lifecycle.URI = ""
 				opts.Config.Lifecycle.Version = ""
 
-				mockDownloader.EXPECT().Download(
-					gomock.Any(),
-					fmt.Sprintf(
-						"https://github.com/buildpacks/lifecycle/releases/download/v%s/lifecycle-v%s+linux.x86-64.tgz",
-						builder.DefaultLifecycleVersion,
-						builder.DefaultLifecycleVersion,
-					),
-				).Return(
-					blob.NewBlob(filepath.Join("testdata", "lifecycle", "platform-0.4")), nil,
-				)
+				prepareDownloaderWithLifecycle(fmt.Sprintf(
+					"https://github.com/buildpacks/lifecycle/releases/download/v%s/lifecycle-v%s+linux.x86-64.tgz",
+					builder.DefaultLifecycleVersion,
+					builder.DefaultLifecycleVersion,
+				))
 
 				err := subject.CreateBuilder(context.TODO(), opts)
 				h.AssertNil(t, err)
@@ -500,6 +539,8 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 					packClientWithExperimental, err := client.NewClient(
 						client.WithLogger(logger),
 						client.WithDownloader(mockDownloader),
+						client.WithLifecycleCacheDir(lifecycleCacheDir),
+						client.WithLayerCacheDir(layerCacheDir),
 						client.WithImageFactory(mockImageFactory),
 						client.WithFetcher(mockImageFetcher),
 						client.WithExperimental(true),
@@ -512,16 +553,11 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 					opts.Config.Lifecycle.Version = ""
 					h.AssertNil(t, fakeBuildImage.SetOS("windows"))
 
-					mockDownloader.EXPECT().Download(
-						gomock.Any(),
-						fmt.Sprintf(
-							"https://github.com/buildpacks/lifecycle/releases/download/v%s/lifecycle-v%s+windows.x86-64.tgz",
-							builder.DefaultLifecycleVersion,
-							builder.DefaultLifecycleVersion,
-						),
-					).Return(
-						blob.NewBlob(filepath.Join("testdata", "lifecycle", "platform-0.4")), nil,
-					)
+					prepareDownloaderWithLifecycle(fmt.Sprintf(
+						"https://github.com/buildpacks/lifecycle/releases/download/v%s/lifecycle-v%s+windows.x86-64.tgz",
+						builder.DefaultLifecycleVersion,
+						builder.DefaultLifecycleVersion,
+					))
 
 					err = packClientWithExperimental.CreateBuilder(context.TODO(), opts)
 					h.AssertNil(t, err)
@@ -529,6 +565,169 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("the lifecycle is downloaded by version", func() {
+			var lifecycleURI = "https://github.com/buildpacks/lifecycle/releases/download/v3.4.5/lifecycle-v3.4.5+linux.x86-64.tgz"
+
+			it.Before(func() {
+				opts.Config.Lifecycle.URI = ""
+				opts.Config.Lifecycle.Version = "3.4.5"
+			})
+
+			it("should fail when the lifecycle doesn't match its published checksum", func() {
+				prepareFetcherWithBuildImage()
+				prepareFetcherWithRunImages()
+
+				checksumFile := filepath.Join(tmpDir, "lifecycle.tgz.sha256")
+				h.AssertNil(t, ioutil.WriteFile(checksumFile, []byte(strings.Repeat("0", 64)), 0600))
+				mockDownloader.EXPECT().Download(gomock.Any(), lifecycleURI+".sha256").Return(blob.NewBlob(checksumFile), nil)
+				mockDownloader.EXPECT().Download(gomock.Any(), lifecycleURI+"#sha256:"+strings.Repeat("0", 64)).Return(blob.NewBlob(lifecycleTgz), nil)
+
+				err := subject.CreateBuilder(context.TODO(), opts)
+				h.AssertError(t, err, "checksum mismatch")
+			})
+
+			it("should use the cached lifecycle once it was verified", func() {
+				prepareFetcherWithRunImages()
+
+				prepareFetcherWithBuildImage()
+				prepareDownloaderWithLifecycle(lifecycleURI)
+				h.AssertNil(t, subject.CreateBuilder(context.TODO(), opts))
+
+				prepareFetcherWithBuildImage()
+				h.AssertNil(t, subject.CreateBuilder(context.TODO(), opts))
+
+				h.AssertContains(t, out.String(), "Using cached lifecycle")
+			})
+
+			it("should download from the lifecycle mirror, verified against the checksum published on GitHub", func() {
+				packClientWithMirror, err := client.NewClient(
+					client.WithLogger(logger),
+					client.WithDownloader(mockDownloader),
+					client.WithLifecycleCacheDir(lifecycleCacheDir),
+					client.WithLayerCacheDir(layerCacheDir),
+					client.WithLifecycleMirror("https://mirror.example.com/lifecycle/"),
+					client.WithImageFactory(mockImageFactory),
+					client.WithFetcher(mockImageFetcher),
+					client.WithDockerClient(mockDockerClient),
+				)
+				h.AssertNil(t, err)
+
+				prepareFetcherWithBuildImage()
+				prepareFetcherWithRunImages()
+				prepareDownloaderWithMirroredLifecycle("https://mirror.example.com/lifecycle/v3.4.5/lifecycle-v3.4.5+linux.x86-64.tgz", lifecycleURI+".sha256")
+
+				h.AssertNil(t, packClientWithMirror.CreateBuilder(context.TODO(), opts))
+			})
+
+			it("should verify the lifecycle against its pinned digest, without downloading the checksum published on GitHub", func() {
+				packClientWithMirror, err := client.NewClient(
+					client.WithLogger(logger),
+					client.WithDownloader(mockDownloader),
+					client.WithLifecycleCacheDir(lifecycleCacheDir),
+					client.WithLayerCacheDir(layerCacheDir),
+					client.WithLifecycleMirror("https://mirror.example.com/lifecycle/"),
+					client.WithImageFactory(mockImageFactory),
+					client.WithFetcher(mockImageFetcher),
+					client.WithDockerClient(mockDockerClient),
+				)
+				h.AssertNil(t, err)
+
+				contents, err := ioutil.ReadFile(lifecycleTgz)
+				h.AssertNil(t, err)
+				opts.Config.Lifecycle.Digest = fmt.Sprintf("sha256:%x", sha256.Sum256(contents))
+
+				prepareFetcherWithBuildImage()
+				prepareFetcherWithRunImages()
+				mockDownloader.EXPECT().Download(gomock.Any(), "https://mirror.example.com/lifecycle/v3.4.5/lifecycle-v3.4.5+linux.x86-64.tgz#"+opts.Config.Lifecycle.Digest).Return(blob.NewBlob(lifecycleTgz), nil)
+
+				h.AssertNil(t, packClientWithMirror.CreateBuilder(context.TODO(), opts))
+			})
+
+			it("should fail when the lifecycle doesn't match its pinned digest", func() {
+				prepareFetcherWithBuildImage()
+				prepareFetcherWithRunImages()
+
+				opts.Config.Lifecycle.Digest = "sha256:" + strings.Repeat("0", 64)
+				mockDownloader.EXPECT().Download(gomock.Any(), lifecycleURI+"#"+opts.Config.Lifecycle.Digest).Return(blob.NewBlob(lifecycleTgz), nil)
+
+				err := subject.CreateBuilder(context.TODO(), opts)
+				h.AssertError(t, err, "checksum mismatch")
+			})
+
+			it("should not use a cached lifecycle that doesn't match its pinned digest", func() {
+				prepareFetcherWithRunImages()
+
+				prepareFetcherWithBuildImage()
+				prepareDownloaderWithLifecycle(lifecycleURI)
+				h.AssertNil(t, subject.CreateBuilder(context.TODO(), opts))
+
+				prepareFetcherWithBuildImage()
+				opts.Config.Lifecycle.Digest = "sha256:" + strings.Repeat("0", 64)
+				mockDownloader.EXPECT().Download(gomock.Any(), lifecycleURI+"#"+opts.Config.Lifecycle.Digest).Return(blob.NewBlob(lifecycleTgz), nil)
+
+				err := subject.CreateBuilder(context.TODO(), opts)
+				h.AssertError(t, err, "checksum mismatch")
+			})
+		})
+
+		when("a lifecycle image is provided", func() {
+			var (
+				lifecycleImage *fakes.Image
+				layerTar       string
+			)
+
+			it.Before(func() {
+				opts.Config.Lifecycle.URI = ""
+				opts.Config.Lifecycle.Image = "buildpacksio/lifecycle:0.13.3"
+
+				lifecycleImage = fakes.NewImage("buildpacksio/lifecycle:0.13.3", "sha256:lifecycle-layer", nil)
+				h.AssertNil(t, lifecycleImage.SetLabel("io.buildpacks.lifecycle.version", "0.13.3"))
+				h.AssertNil(t, lifecycleImage.SetLabel("io.buildpacks.lifecycle.apis", `{"buildpack": {"deprecated": [], "supported": ["0.2", "0.3", "0.7"]}, "platform": {"deprecated": [], "supported": ["0.3", "0.8"]}}`))
+
+				layerTar = h.CreateTAR(t, filepath.Join("testdata", "lifecycle", "platform-0.4", "lifecycle-v0.0.0-arch"), "/cnb/lifecycle", -1)
+				h.AssertNil(t, lifecycleImage.AddLayerWithDiffID(layerTar, "sha256:lifecycle-layer"))
+			})
+
+			it.After(func() {
+				h.AssertNil(t, os.Remove(layerTar))
+			})
+
+			it("should extract the lifecycle from the image", func() {
+				prepareFetcherWithBuildImage()
+				prepareFetcherWithRunImages()
+				mockImageFetcher.EXPECT().Fetch(gomock.Any(), "buildpacksio/lifecycle:0.13.3", image.FetchOptions{Daemon: true, PullPolicy: image.PullAlways}).Return(lifecycleImage, nil)
+
+				bldr := successfullyCreateBuilder()
+
+				h.AssertEq(t, bldr.LifecycleDescriptor().Info.Version.String(), "0.13.3")
+				h.AssertEq(t, bldr.LifecycleDescriptor().APIs.Platform.Supported.AsStrings(), []string{"0.3", "0.8"})
+
+				layerTar, err := fakeBuildImage.FindLayerWithPath("/cnb/lifecycle")
+				h.AssertNil(t, err)
+				h.AssertTarHasFile(t, layerTar, "/cnb/lifecycle/detector")
+				h.AssertTarHasFile(t, layerTar, "/cnb/lifecycle/creator")
+			})
+
+			it("should fail when the image is missing lifecycle labels", func() {
+				prepareFetcherWithBuildImage()
+				prepareFetcherWithRunImages()
+				h.AssertNil(t, lifecycleImage.SetLabel("io.buildpacks.lifecycle.apis", ""))
+				mockImageFetcher.EXPECT().Fetch(gomock.Any(), "buildpacksio/lifecycle:0.13.3", gomock.Any()).Return(lifecycleImage, nil)
+
+				err := subject.CreateBuilder(context.TODO(), opts)
+				h.AssertError(t, err, "lifecycle image 'buildpacksio/lifecycle:0.13.3' missing label 'io.buildpacks.lifecycle.apis'")
+			})
+
+			it("should fail when a version is also provided", func() {
+				prepareFetcherWithBuildImage()
+				prepareFetcherWithRunImages()
+				opts.Config.Lifecycle.Version = "0.13.3"
+
+				err := subject.CreateBuilder(context.TODO(), opts)
+				h.AssertError(t, err, "'lifecycle' can't declare 'image' along with 'version' or 'uri'")
+			})
+		})
+
 		when("buildpack mixins are not satisfied", func() {
 			it("should return an error", func() {
 				prepareFetcherWithBuildImage()
@@ -576,6 +775,24 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 				}})
 			})
 
+			it("should set the build env", func() {
+				prepareFetcherWithBuildImage()
+				prepareFetcherWithRunImages()
+				opts.Config.Build.Env = []pubbldr.BuildEnv{
+					{Name: "BP_MIRROR", Value: "https://mirror.example.com"},
+					{Name: "HTTPS_PROXY", Value: "http://proxy.example.com", Mode: pubbldr.BuildEnvModeOverride},
+				}
+
+				bldr := successfullyCreateBuilder()
+
+				h.AssertEq(t, bldr.BuildEnv(), opts.Config.Build.Env)
+
+				layerTar, err := fakeBuildImage.FindLayerWithPath("/platform/env/BP_MIRROR")
+				h.AssertNil(t, err)
+				h.AssertOnTarEntry(t, layerTar, "/platform/env/BP_MIRROR", h.ContentEquals("https://mirror.example.com"))
+				h.AssertOnTarEntry(t, layerTar, "/platform/env/HTTPS_PROXY", h.ContentEquals("http://proxy.example.com"))
+			})
+
 			it("should embed the lifecycle", func() {
 				prepareFetcherWithBuildImage()
 				prepareFetcherWithRunImages()
@@ -699,6 +916,125 @@ func testCreateBuilder(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("a base builder is provided", func() {
+			var fakeBaseBuilder *fakes.Image
+
+			it.Before(func() {
+				fakeBaseBuilder = fakes.NewImage("some/base-builder", "", nil)
+				h.AssertNil(t, fakeBaseBuilder.SetLabel("io.buildpacks.stack.id", "some.stack.id"))
+				h.AssertNil(t, fakeBaseBuilder.SetLabel("io.buildpacks.stack.mixins", `["mixinX", "build:mixinY"]`))
+				h.AssertNil(t, fakeBaseBuilder.SetLabel("io.buildpacks.builder.metadata", `{
+  "description": "Base description",
+  "stack": {"runImage": {"image": "some/run-image", "mirrors": ["localhost:5000/some/run-image"]}},
+  "buildpacks": [{"id": "bp.one", "version": "1.0.0"}, {"id": "bp.two", "version": "2.0.0"}],
+  "lifecycle": {"version": "0.13.5", "apis": {"buildpack": {"supported": ["0.3"]}, "platform": {"supported": ["0.4"]}}},
+  "buildEnv": [{"name": "BP_MIRROR", "value": "https://base.example.com"}, {"name": "HTTPS_PROXY", "value": "http://proxy.example.com", "mode": "override"}]
+}`))
+				h.AssertNil(t, fakeBaseBuilder.SetLabel("io.buildpacks.buildpack.order", `[{"group": [{"id": "bp.one", "version": "1.0.0"}, {"id": "bp.two", "version": "2.0.0", "optional": true}]}]`))
+				h.AssertNil(t, fakeBaseBuilder.SetLabel("io.buildpacks.buildpack.layers", `{
+  "bp.one": {"1.0.0": {"api": "0.3", "stacks": [{"id": "some.stack.id"}], "layerDiffID": "sha256:one"}},
+  "bp.two": {"2.0.0": {"api": "0.3", "stacks": [{"id": "some.stack.id"}], "layerDiffID": "sha256:two"}}
+}`))
+				h.AssertNil(t, fakeBaseBuilder.SetEnv("CNB_USER_ID", "1234"))
+				h.AssertNil(t, fakeBaseBuilder.SetEnv("CNB_GROUP_ID", "4321"))
+
+				mockImageFetcher.EXPECT().Fetch(gomock.Any(), "some/base-builder", image.FetchOptions{Daemon: true, PullPolicy: image.PullAlways}).Return(fakeBaseBuilder, nil)
+				prepareFetcherWithRunImages()
+
+				opts.Config = pubbldr.Config{
+					BaseBuilder:      "some/base-builder",
+					Buildpacks:       opts.Config.Buildpacks,
+					RemoveBuildpacks: []string{"bp.two"},
+				}
+			})
+
+			var successfullyCreateLayeredBuilder = func() *builder.Builder {
+				t.Helper()
+
+				h.AssertNil(t, subject.CreateBuilder(context.TODO(), opts))
+
+				h.AssertEq(t, fakeBaseBuilder.IsSaved(), true)
+				bldr, err := builder.FromImage(fakeBaseBuilder)
+				h.AssertNil(t, err)
+
+				return bldr
+			}
+
+			it("inherits the stack, description and lifecycle of the base builder", func() {
+				bldr := successfullyCreateLayeredBuilder()
+
+				h.AssertEq(t, bldr.Name(), "some/builder")
+				h.AssertEq(t, bldr.Description(), "Base description")
+				h.AssertEq(t, bldr.StackID, "some.stack.id")
+				h.AssertEq(t, bldr.Stack().RunImage.Image, "some/run-image")
+				h.AssertEq(t, bldr.Stack().RunImage.Mirrors, []string{"localhost:5000/some/run-image"})
+				h.AssertEq(t, bldr.LifecycleDescriptor().Info.Version.String(), "0.13.5")
+
+				_, err := fakeBaseBuilder.FindLayerWithPath("/cnb/lifecycle")
+				h.AssertNotNil(t, err)
+			})
+
+			it("upgrades and removes buildpacks of the base builder, along with its order", func() {
+				bldr := successfullyCreateLayeredBuilder()
+
+				h.AssertEq(t, bldr.Buildpacks(), []dist.BuildpackInfo{{ID: "bp.one", Version: "1.2.3", Homepage: "http://one.buildpack"}})
+				h.AssertEq(t, bldr.Order(), dist.Order{{
+					Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "bp.one", Version: "1.2.3"}}},
+				}})
+
+				for _, whiteout := range []string{"/cnb/buildpacks/bp.one/.wh.1.0.0", "/cnb/buildpacks/bp.two/.wh.2.0.0"} {
+					_, err := fakeBaseBuilder.FindLayerWithPath(whiteout)
+					h.AssertNil(t, err)
+				}
+
+				var layers dist.BuildpackLayers
+				_, err := dist.GetLabel(fakeBaseBuilder, dist.BuildpackLayersLabel, &layers)
+				h.AssertNil(t, err)
+				_, ok := layers.Get("bp.two", "2.0.0")
+				h.AssertFalse(t, ok)
+				_, ok = layers.Get("bp.one", "1.0.0")
+				h.AssertFalse(t, ok)
+			})
+
+			it("swaps the run image", func() {
+				opts.Config.Stack.RunImage = "localhost:5000/some/run-image"
+
+				bldr := successfullyCreateLayeredBuilder()
+
+				h.AssertEq(t, bldr.Stack().RunImage.Image, "localhost:5000/some/run-image")
+			})
+
+			it("replaces the order", func() {
+				opts.Config.RemoveBuildpacks = nil
+				opts.Config.Order = dist.Order{
+					{Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "bp.two", Version: "2.0.0"}}}},
+				}
+
+				bldr := successfullyCreateLayeredBuilder()
+
+				h.AssertEq(t, bldr.Order(), opts.Config.Order)
+			})
+
+			it("keeps the build env of the base builder unless it is replaced", func() {
+				opts.Config.Build.Env = []pubbldr.BuildEnv{{Name: "BP_MIRROR", Value: "https://mirror.example.com"}}
+
+				bldr := successfullyCreateLayeredBuilder()
+
+				h.AssertEq(t, bldr.BuildEnv(), []pubbldr.BuildEnv{
+					{Name: "HTTPS_PROXY", Value: "http://proxy.example.com", Mode: "override"},
+					{Name: "BP_MIRROR", Value: "https://mirror.example.com"},
+				})
+			})
+
+			it("fails when a removed buildpack is not on the base builder", func() {
+				opts.Config.RemoveBuildpacks = []string{"bp.three"}
+
+				err := subject.CreateBuilder(context.TODO(), opts)
+
+				h.AssertError(t, err, "buildpack 'bp.three' was not found on the base builder")
+			})
+		})
+
 		when("packages", func() {
 			when("package image lives in cnb registry", func() {
 				when("publish=false and pull-policy=always", func() {
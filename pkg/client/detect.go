@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/buildpacks/lifecycle/buildpack"
+	"github.com/buildpacks/lifecycle/platform"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/build"
+	"github.com/buildpacks/pack/internal/builder"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/image"
+	projectTypes "github.com/buildpacks/pack/pkg/project/types"
+)
+
+// DetectOptions defines configuration settings for Detect.
+type DetectOptions struct {
+	// The base directory to use to resolve relative assets
+	RelativeBaseDir string
+
+	// required. Builder image name.
+	Builder string
+
+	// Name of the buildpack registry. Used to
+	// add buildpacks to the detection order.
+	Registry string
+
+	// AppPath is the path to application bits.
+	// If unset it defaults to current working directory.
+	AppPath string
+
+	// User provided environment variables to the buildpacks.
+	Env map[string]string
+
+	// EnvVars are applied in order after Env, each combining with the value
+	// the variable has so far as its Action says.
+	EnvVars []EnvVar
+
+	// List of buildpack images or archives to detect with instead
+	// of the builder's order.
+	Buildpacks []string
+
+	// Configure the proxy environment variables,
+	// These variables will only be set in the detect container
+	// and will not be used if proxy env vars are already set.
+	ProxyConfig *ProxyConfig
+
+	// Configure network and volume mounts for the detect container.
+	ContainerConfig ContainerConfig
+
+	// Strategy for updating local images before detecting.
+	PullPolicy image.PullPolicy
+
+	// ProjectDescriptorBaseDir is the base directory to find relative resources referenced by the ProjectDescriptor
+	ProjectDescriptorBaseDir string
+
+	// ProjectDescriptor describes the project and any configuration specific to the project
+	ProjectDescriptor projectTypes.Descriptor
+
+	// The location at which to mount the AppDir in the build image.
+	Workspace string
+
+	// Secrets are mounted read-only to /platform/secrets/<id> during detect.
+	// They override secrets of the ProjectDescriptor with the same id.
+	Secrets []Secret
+}
+
+// DetectResult describes which buildpacks would take part in a build of an app.
+type DetectResult struct {
+	// Buildpacks that passed detection, in the order they would build.
+	// Empty if no group passed detection.
+	Group []buildpack.GroupBuildpack
+
+	// Build plan entries resolved for Group, each listing
+	// the buildpacks that provide it and what is required of it.
+	Plan []platform.BuildPlanEntry
+
+	// The outcome of each buildpack, for every group of the order that was tried.
+	Trials []build.DetectTrial
+}
+
+// Detect runs only the detect phase of the lifecycle for an app, using the builder's order
+// or the provided buildpacks, and reports the buildpack group and build plan that were selected.
+// If no group passes detection an error is returned along with the outcome of each buildpack tried.
+func (c *Client) Detect(ctx context.Context, opts DetectOptions) (*DetectResult, error) {
+	appPath, err := c.processAppPath(opts.AppPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid app path '%s'", opts.AppPath)
+	}
+
+	proxyConfig := c.processProxyConfig(opts.ProxyConfig)
+
+	builderRef, err := c.processBuilderName(opts.Builder)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid builder '%s'", opts.Builder)
+	}
+
+	rawBuilderImage, err := c.imageFetcher.Fetch(ctx, builderRef.Name(), image.FetchOptions{Daemon: true, PullPolicy: opts.PullPolicy})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch builder image '%s'", builderRef.Name())
+	}
+
+	bldr, err := c.getBuilder(rawBuilderImage)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid builder %s", style.Symbol(opts.Builder))
+	}
+
+	imgOS, err := rawBuilderImage.OS()
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting builder OS")
+	}
+
+	fetchedBPs, order, err := c.processBuildpacks(ctx, bldr.Image(), bldr.Buildpacks(), bldr.Order(), bldr.StackID, BuildOptions{
+		RelativeBaseDir:          opts.RelativeBaseDir,
+		Registry:                 opts.Registry,
+		Buildpacks:               opts.Buildpacks,
+		PullPolicy:               opts.PullPolicy,
+		ProjectDescriptorBaseDir: opts.ProjectDescriptorBaseDir,
+		ProjectDescriptor:        opts.ProjectDescriptor,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	detectEnvs, err := c.resolveEnv(bldr.BuildEnv(), opts.ProjectDescriptor.Build.Env, opts.Env, opts.EnvVars)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeralBuilder, err := c.createEphemeralBuilder(rawBuilderImage, detectEnvs, order, fetchedBPs)
+	if err != nil {
+		return nil, err
+	}
+	defer c.docker.ImageRemove(context.Background(), ephemeralBuilder.Name(), types.ImageRemoveOptions{Force: true})
+
+	var builderPlatformAPIs builder.APISet
+	builderPlatformAPIs = append(builderPlatformAPIs, ephemeralBuilder.LifecycleDescriptor().APIs.Platform.Deprecated...)
+	builderPlatformAPIs = append(builderPlatformAPIs, ephemeralBuilder.LifecycleDescriptor().APIs.Platform.Supported...)
+
+	if !supportsPlatformAPI(builderPlatformAPIs) {
+		c.logger.Debugf("pack %s supports Platform API(s): %s", c.version, strings.Join(build.SupportedPlatformAPIVersions.AsStrings(), ", "))
+		c.logger.Debugf("Builder %s supports Platform API(s): %s", style.Symbol(opts.Builder), strings.Join(builderPlatformAPIs.AsStrings(), ", "))
+		return nil, errors.Errorf("Builder %s is incompatible with this version of pack", style.Symbol(opts.Builder))
+	}
+
+	processedVolumes, warnings, err := processVolumes(imgOS, opts.ContainerConfig.Volumes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, warning := range warnings {
+		c.logger.Warn(warning)
+	}
+
+	fileFilter, err := getFileFilter(opts.ProjectDescriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	containerSettings, err := processContainerConfig(imgOS, opts.ContainerConfig, opts.ProjectDescriptor.Build.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := c.processSecrets(opts.Secrets, opts.ProjectDescriptor.Build.Secrets, opts.ProjectDescriptorBaseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	detectResult, err := c.lifecycleExecutor.Detect(ctx, build.LifecycleOptions{
+		AppPath:    appPath,
+		Builder:    ephemeralBuilder,
+		HTTPProxy:  proxyConfig.HTTPProxy,
+		HTTPSProxy: proxyConfig.HTTPSProxy,
+		NoProxy:    proxyConfig.NoProxy,
+		Network:    opts.ContainerConfig.Network,
+		Volumes:    processedVolumes,
+		FileFilter: fileFilter,
+		Workspace:  opts.Workspace,
+		Keychain:   c.keychain,
+		Engine:     c.detectEngine(ctx),
+		Container:  containerSettings,
+		Secrets:    secrets,
+	})
+
+	result := &DetectResult{
+		Group:  detectResult.Group.Group,
+		Plan:   detectResult.Plan.Entries,
+		Trials: detectResult.Trials,
+	}
+	if err != nil {
+		return result, errors.Wrap(err, "executing detector")
+	}
+
+	return result, nil
+}
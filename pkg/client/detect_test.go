@@ -0,0 +1,165 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/buildpacks/imgutil/fakes"
+	"github.com/buildpacks/lifecycle/buildpack"
+	"github.com/buildpacks/lifecycle/platform"
+	dockerclient "github.com/docker/docker/client"
+	"github.com/heroku/color"
+	"github.com/pkg/errors"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpacks/pack/internal/build"
+	"github.com/buildpacks/pack/internal/builder"
+	ifakes "github.com/buildpacks/pack/internal/fakes"
+	"github.com/buildpacks/pack/pkg/blob"
+	pbuildpack "github.com/buildpacks/pack/pkg/buildpack"
+	"github.com/buildpacks/pack/pkg/dist"
+	"github.com/buildpacks/pack/pkg/logging"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestDetect(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "detect", testDetect, spec.Report(report.Terminal{}))
+}
+
+func testDetect(t *testing.T, when spec.G, it spec.S) {
+	var (
+		subject            *Client
+		fakeImageFetcher   *ifakes.FakeImageFetcher
+		fakeLifecycle      *ifakes.FakeLifecycle
+		builderImage       *fakes.Image
+		builderName        = "example.com/default/builder:tag"
+		tmpDir             string
+		outBuf             bytes.Buffer
+		logger             *logging.LogWithWriters
+		detectedGroup      = []buildpack.GroupBuildpack{{ID: "buildpack.1.id", Version: "buildpack.1.version"}}
+		detectedPlan       = []platform.BuildPlanEntry{{Providers: detectedGroup, Requires: []buildpack.Require{{Name: "some-dependency"}}}}
+		detectedBuildpacks = []build.DetectTrial{
+			{Buildpacks: []build.DetectedBuildpack{{ID: "buildpack.1.id", Version: "buildpack.1.version", Status: "pass"}}},
+		}
+	)
+
+	it.Before(func() {
+		var err error
+
+		fakeImageFetcher = ifakes.NewFakeImageFetcher()
+		fakeLifecycle = &ifakes.FakeLifecycle{
+			ReturnForDetect: build.DetectResult{
+				Group:  buildpack.Group{Group: detectedGroup},
+				Plan:   platform.BuildPlan{Entries: detectedPlan},
+				Trials: detectedBuildpacks,
+			},
+		}
+
+		tmpDir, err = ioutil.TempDir("", "detect-test")
+		h.AssertNil(t, err)
+
+		builderImage = newFakeBuilderImage(t, tmpDir, builderName, "some.stack.id", "default/run", builder.DefaultLifecycleVersion, newLinuxImage)
+		fakeImageFetcher.LocalImages[builderImage.Name()] = builderImage
+
+		docker, err := dockerclient.NewClientWithOpts(dockerclient.FromEnv, dockerclient.WithVersion("1.38"))
+		h.AssertNil(t, err)
+
+		logger = logging.NewLogWithWriters(&outBuf, &outBuf)
+
+		dlCacheDir, err := ioutil.TempDir(tmpDir, "dl-cache")
+		h.AssertNil(t, err)
+
+		blobDownloader := blob.NewDownloader(logger, dlCacheDir)
+		subject = &Client{
+			logger:              logger,
+			imageFetcher:        fakeImageFetcher,
+			downloader:          blobDownloader,
+			lifecycleExecutor:   fakeLifecycle,
+			docker:              docker,
+			buildpackDownloader: pbuildpack.NewDownloader(logger, fakeImageFetcher, blobDownloader, &registryResolver{logger: logger}),
+		}
+	})
+
+	it.After(func() {
+		h.AssertNilE(t, builderImage.Cleanup())
+		os.RemoveAll(tmpDir)
+	})
+
+	when("#Detect", func() {
+		it("runs the detector against the builder and reports the outcome", func() {
+			result, err := subject.Detect(context.TODO(), DetectOptions{
+				Builder: builderName,
+				AppPath: tmpDir,
+			})
+			h.AssertNil(t, err)
+
+			h.AssertTrue(t, fakeLifecycle.DetectCalled)
+			h.AssertEq(t, fakeLifecycle.Opts.Builder.Name(), builderImage.Name())
+			h.AssertEq(t, fakeLifecycle.Opts.AppPath, tmpDir)
+
+			h.AssertEq(t, result.Group, detectedGroup)
+			h.AssertEq(t, result.Plan, detectedPlan)
+			h.AssertEq(t, result.Trials, detectedBuildpacks)
+		})
+
+		it("requires a builder", func() {
+			_, err := subject.Detect(context.TODO(), DetectOptions{AppPath: tmpDir})
+			h.AssertError(t, err, "invalid builder ''")
+		})
+
+		when("Buildpacks option", func() {
+			it("detects with only the provided buildpacks", func() {
+				_, err := subject.Detect(context.TODO(), DetectOptions{
+					Builder:    builderName,
+					AppPath:    tmpDir,
+					Buildpacks: []string{"buildpack.2.id@buildpack.2.version"},
+				})
+				h.AssertNil(t, err)
+
+				bldr, err := builder.FromImage(builderImage)
+				h.AssertNil(t, err)
+				h.AssertEq(t, bldr.Order(), dist.Order{
+					{Group: []dist.BuildpackRef{
+						{BuildpackInfo: dist.BuildpackInfo{ID: "buildpack.2.id", Version: "buildpack.2.version"}},
+					}},
+				})
+			})
+		})
+
+		when("Env option", func() {
+			it("sets the env on the ephemeral builder", func() {
+				_, err := subject.Detect(context.TODO(), DetectOptions{
+					Builder: builderName,
+					AppPath: tmpDir,
+					Env:     map[string]string{"key1": "value1"},
+				})
+				h.AssertNil(t, err)
+
+				layerTar, err := builderImage.FindLayerWithPath("/platform/env/key1")
+				h.AssertNil(t, err)
+				h.AssertTarFileContents(t, layerTar, "/platform/env/key1", `value1`)
+			})
+		})
+
+		when("no group passes detection", func() {
+			it("returns the outcome of each buildpack with the error", func() {
+				fakeLifecycle.ReturnForDetect = build.DetectResult{Trials: detectedBuildpacks}
+				fakeLifecycle.ReturnForDetectErr = errors.New("failed with status code: 20")
+
+				result, err := subject.Detect(context.TODO(), DetectOptions{
+					Builder: builderName,
+					AppPath: tmpDir,
+				})
+				h.AssertError(t, err, "executing detector: failed with status code: 20")
+				h.AssertEq(t, len(result.Group), 0)
+				h.AssertEq(t, result.Trials, detectedBuildpacks)
+			})
+		})
+	})
+}
@@ -0,0 +1,348 @@
+package client
+
+import (
+	"sort"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+
+	pubbldr "github.com/buildpacks/pack/builder"
+	"github.com/buildpacks/pack/internal/builder"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/dist"
+)
+
+// BuilderDiff describes what changed between two builders.
+type BuilderDiff struct {
+	// Names of the builders that were compared.
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	Buildpacks BuildpacksDiff `json:"buildpacks"`
+
+	// Changes to each group of the builder's order, by position.
+	Order []OrderGroupDiff `json:"order,omitempty"`
+
+	Lifecycle LifecycleDiff `json:"lifecycle"`
+
+	Stack    *ValueChange `json:"stack,omitempty"`
+	Mixins   ListDiff     `json:"mixins"`
+	RunImage *ValueChange `json:"run_image,omitempty"`
+
+	RunImageMirrors ListDiff `json:"run_image_mirrors"`
+}
+
+// BuildpacksDiff lists the buildpacks that were added, removed, or changed version.
+type BuildpacksDiff struct {
+	Added      []dist.BuildpackInfo `json:"added,omitempty"`
+	Removed    []dist.BuildpackInfo `json:"removed,omitempty"`
+	Upgraded   []VersionChange      `json:"upgraded,omitempty"`
+	Downgraded []VersionChange      `json:"downgraded,omitempty"`
+}
+
+// VersionChange is a buildpack that is present in both builders with a different version.
+type VersionChange struct {
+	ID   string `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// OrderGroupDiff describes the changes to a single group of the order.
+type OrderGroupDiff struct {
+	// Group is the 1-based position of the group in the order.
+	Group int `json:"group"`
+
+	Added   []dist.BuildpackRef `json:"added,omitempty"`
+	Removed []dist.BuildpackRef `json:"removed,omitempty"`
+
+	// Reordered is set when the buildpacks common to both groups are in a different order.
+	Reordered bool `json:"reordered,omitempty"`
+}
+
+// LifecycleDiff describes changes to the lifecycle and the APIs it supports.
+type LifecycleDiff struct {
+	Version       *ValueChange    `json:"version,omitempty"`
+	BuildpackAPIs APIVersionsDiff `json:"buildpack_apis"`
+	PlatformAPIs  APIVersionsDiff `json:"platform_apis"`
+}
+
+// APIVersionsDiff lists the API versions that were added or removed.
+type APIVersionsDiff struct {
+	Deprecated ListDiff `json:"deprecated"`
+	Supported  ListDiff `json:"supported"`
+}
+
+// ValueChange is a single value that differs between two builders.
+type ValueChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ListDiff lists the values that are only present in one of two builders.
+type ListDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// IsEmpty returns true if no values were added or removed.
+func (d ListDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// IsEmpty returns true if no API versions were added or removed.
+func (d APIVersionsDiff) IsEmpty() bool {
+	return d.Deprecated.IsEmpty() && d.Supported.IsEmpty()
+}
+
+// IsEmpty returns true if the lifecycle and its APIs are unchanged.
+func (d LifecycleDiff) IsEmpty() bool {
+	return d.Version == nil && d.BuildpackAPIs.IsEmpty() && d.PlatformAPIs.IsEmpty()
+}
+
+// IsEmpty returns true if the buildpacks are unchanged.
+func (d BuildpacksDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Upgraded) == 0 && len(d.Downgraded) == 0
+}
+
+// IsEmpty returns true if the builders are equivalent.
+func (d *BuilderDiff) IsEmpty() bool {
+	return d.Buildpacks.IsEmpty() &&
+		len(d.Order) == 0 &&
+		d.Lifecycle.IsEmpty() &&
+		d.Stack == nil &&
+		d.Mixins.IsEmpty() &&
+		d.RunImage == nil &&
+		d.RunImageMirrors.IsEmpty()
+}
+
+// DiffBuilders compares two builders and reports what changed from the first to the second.
+// Each builder is read from the daemon if present, and from its registry otherwise.
+func (c *Client) DiffBuilders(from, to string) (*BuilderDiff, error) {
+	fromInfo, err := c.inspectBuilderForDiff(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toInfo, err := c.inspectBuilderForDiff(to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffBuilders(fromInfo, toInfo)
+	diff.From = from
+	diff.To = to
+	return diff, nil
+}
+
+func (c *Client) inspectBuilderForDiff(name string) (*BuilderInfo, error) {
+	for _, daemon := range []bool{true, false} {
+		info, err := c.InspectBuilder(name, daemon, WithDetectionOrderDepth(pubbldr.OrderDetectionNone))
+		if err != nil {
+			return nil, errors.Wrapf(err, "inspecting builder %s", style.Symbol(name))
+		}
+		if info != nil {
+			return info, nil
+		}
+	}
+
+	return nil, errors.Errorf("unable to find builder %s locally or remotely", style.Symbol(name))
+}
+
+func diffBuilders(from, to *BuilderInfo) *BuilderDiff {
+	return &BuilderDiff{
+		Buildpacks: diffBuildpacks(from.Buildpacks, to.Buildpacks),
+		Order:      diffOrder(from.Order, to.Order),
+		Lifecycle: LifecycleDiff{
+			Version: diffValue(lifecycleVersion(from.Lifecycle), lifecycleVersion(to.Lifecycle)),
+			BuildpackAPIs: APIVersionsDiff{
+				Deprecated: diffList(from.Lifecycle.APIs.Buildpack.Deprecated.AsStrings(), to.Lifecycle.APIs.Buildpack.Deprecated.AsStrings()),
+				Supported:  diffList(from.Lifecycle.APIs.Buildpack.Supported.AsStrings(), to.Lifecycle.APIs.Buildpack.Supported.AsStrings()),
+			},
+			PlatformAPIs: APIVersionsDiff{
+				Deprecated: diffList(from.Lifecycle.APIs.Platform.Deprecated.AsStrings(), to.Lifecycle.APIs.Platform.Deprecated.AsStrings()),
+				Supported:  diffList(from.Lifecycle.APIs.Platform.Supported.AsStrings(), to.Lifecycle.APIs.Platform.Supported.AsStrings()),
+			},
+		},
+		Stack:           diffValue(from.Stack, to.Stack),
+		Mixins:          diffList(from.Mixins, to.Mixins),
+		RunImage:        diffValue(from.RunImage, to.RunImage),
+		RunImageMirrors: diffList(from.RunImageMirrors, to.RunImageMirrors),
+	}
+}
+
+// diffBuildpacks pairs the versions of a buildpack that are only present in one of the builders
+// as an upgrade or downgrade when there is exactly one such version in each.
+func diffBuildpacks(from, to []dist.BuildpackInfo) BuildpacksDiff {
+	var (
+		diff    BuildpacksDiff
+		removed = map[string][]dist.BuildpackInfo{}
+		added   = map[string][]dist.BuildpackInfo{}
+		ids     []string
+	)
+
+	fromNames := map[string]bool{}
+	for _, bp := range from {
+		fromNames[bp.FullName()] = true
+	}
+	toNames := map[string]bool{}
+	for _, bp := range to {
+		toNames[bp.FullName()] = true
+	}
+
+	for _, bp := range from {
+		if !toNames[bp.FullName()] {
+			if _, ok := removed[bp.ID]; !ok {
+				ids = append(ids, bp.ID)
+			}
+			removed[bp.ID] = append(removed[bp.ID], bp)
+		}
+	}
+	for _, bp := range to {
+		if !fromNames[bp.FullName()] {
+			if _, ok := removed[bp.ID]; !ok {
+				if _, ok := added[bp.ID]; !ok {
+					ids = append(ids, bp.ID)
+				}
+			}
+			added[bp.ID] = append(added[bp.ID], bp)
+		}
+	}
+
+	sort.Strings(ids)
+	for _, id := range ids {
+		if len(removed[id]) == 1 && len(added[id]) == 1 {
+			change := VersionChange{ID: id, From: removed[id][0].Version, To: added[id][0].Version}
+			if versionLess(change.To, change.From) {
+				diff.Downgraded = append(diff.Downgraded, change)
+			} else {
+				diff.Upgraded = append(diff.Upgraded, change)
+			}
+			continue
+		}
+
+		diff.Removed = append(diff.Removed, removed[id]...)
+		diff.Added = append(diff.Added, added[id]...)
+	}
+
+	return diff
+}
+
+func diffOrder(from, to pubbldr.DetectionOrder) []OrderGroupDiff {
+	var diffs []OrderGroupDiff
+	for i := 0; i < len(from) || i < len(to); i++ {
+		var fromGroup, toGroup []dist.BuildpackRef
+		if i < len(from) {
+			fromGroup = groupRefs(from[i].GroupDetectionOrder)
+		}
+		if i < len(to) {
+			toGroup = groupRefs(to[i].GroupDetectionOrder)
+		}
+
+		groupDiff := OrderGroupDiff{Group: i + 1}
+
+		fromKeys := map[string]bool{}
+		for _, ref := range fromGroup {
+			fromKeys[orderRefKey(ref)] = true
+		}
+		toKeys := map[string]bool{}
+		for _, ref := range toGroup {
+			toKeys[orderRefKey(ref)] = true
+		}
+
+		var fromCommon, toCommon []string
+		for _, ref := range fromGroup {
+			if toKeys[orderRefKey(ref)] {
+				fromCommon = append(fromCommon, orderRefKey(ref))
+			} else {
+				groupDiff.Removed = append(groupDiff.Removed, ref)
+			}
+		}
+		for _, ref := range toGroup {
+			if fromKeys[orderRefKey(ref)] {
+				toCommon = append(toCommon, orderRefKey(ref))
+			} else {
+				groupDiff.Added = append(groupDiff.Added, ref)
+			}
+		}
+
+		for j := range fromCommon {
+			if j >= len(toCommon) || fromCommon[j] != toCommon[j] {
+				groupDiff.Reordered = true
+				break
+			}
+		}
+
+		if len(groupDiff.Added) > 0 || len(groupDiff.Removed) > 0 || groupDiff.Reordered {
+			diffs = append(diffs, groupDiff)
+		}
+	}
+
+	return diffs
+}
+
+func groupRefs(group pubbldr.DetectionOrder) []dist.BuildpackRef {
+	var refs []dist.BuildpackRef
+	for _, entry := range group {
+		refs = append(refs, dist.BuildpackRef{
+			BuildpackInfo: dist.BuildpackInfo{ID: entry.ID, Version: entry.Version},
+			Optional:      entry.Optional,
+		})
+	}
+	return refs
+}
+
+func orderRefKey(ref dist.BuildpackRef) string {
+	if ref.Optional {
+		return ref.FullName() + " (optional)"
+	}
+	return ref.FullName()
+}
+
+func diffList(from, to []string) ListDiff {
+	var diff ListDiff
+
+	fromValues := map[string]bool{}
+	for _, value := range from {
+		fromValues[value] = true
+	}
+	toValues := map[string]bool{}
+	for _, value := range to {
+		toValues[value] = true
+	}
+
+	for _, value := range from {
+		if !toValues[value] {
+			diff.Removed = append(diff.Removed, value)
+		}
+	}
+	for _, value := range to {
+		if !fromValues[value] {
+			diff.Added = append(diff.Added, value)
+		}
+	}
+
+	return diff
+}
+
+func diffValue(from, to string) *ValueChange {
+	if from == to {
+		return nil
+	}
+	return &ValueChange{From: from, To: to}
+}
+
+func lifecycleVersion(descriptor builder.LifecycleDescriptor) string {
+	if descriptor.Info.Version == nil {
+		return ""
+	}
+	return descriptor.Info.Version.String()
+}
+
+func versionLess(a, b string) bool {
+	versionA, errA := semver.NewVersion(a)
+	versionB, errB := semver.NewVersion(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return versionA.LessThan(versionB)
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/buildpacks/imgutil/fakes"
+	"github.com/buildpacks/lifecycle/api"
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	pubbldr "github.com/buildpacks/pack/builder"
+	"github.com/buildpacks/pack/internal/builder"
+	"github.com/buildpacks/pack/pkg/dist"
+	"github.com/buildpacks/pack/pkg/image"
+	"github.com/buildpacks/pack/pkg/logging"
+	"github.com/buildpacks/pack/pkg/testmocks"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestDiffBuilders(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "DiffBuilders", testDiffBuilders, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testDiffBuilders(t *testing.T, when spec.G, it spec.S) {
+	var (
+		subject          *Client
+		mockImageFetcher *testmocks.MockImageFetcher
+		mockController   *gomock.Controller
+		out              bytes.Buffer
+		assert           = h.NewAssertionManager(t)
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockImageFetcher = testmocks.NewMockImageFetcher(mockController)
+
+		subject = &Client{
+			logger:       logging.NewLogWithWriters(&out, &out),
+			imageFetcher: mockImageFetcher,
+		}
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	newBuilderImage := func(name, stackID, metadata string) *fakes.Image {
+		builderImage := fakes.NewImage(name, "", nil)
+		assert.Succeeds(builderImage.SetLabel("io.buildpacks.stack.id", stackID))
+		assert.Succeeds(builderImage.SetLabel("io.buildpacks.builder.metadata", metadata))
+		return builderImage
+	}
+
+	when("#DiffBuilders", func() {
+		it("compares a local builder to a remote one", func() {
+			fromImage := newBuilderImage("some/builder:1", "some.stack", `{"stack": {"runImage": {"image": "some/run:1"}}, "buildpacks": [{"id": "some/bp", "version": "1.0.0"}], "lifecycle": {"version": "0.13.0"}}`)
+			toImage := newBuilderImage("some/builder:2", "some.stack", `{"stack": {"runImage": {"image": "some/run:2"}}, "buildpacks": [{"id": "some/bp", "version": "1.1.0"}], "lifecycle": {"version": "0.13.5"}}`)
+
+			mockImageFetcher.EXPECT().Fetch(gomock.Any(), "some/builder:1", image.FetchOptions{Daemon: true, PullPolicy: image.PullNever}).Return(fromImage, nil)
+			mockImageFetcher.EXPECT().Fetch(gomock.Any(), "some/builder:2", image.FetchOptions{Daemon: true, PullPolicy: image.PullNever}).Return(nil, image.ErrNotFound)
+			mockImageFetcher.EXPECT().Fetch(gomock.Any(), "some/builder:2", image.FetchOptions{Daemon: false, PullPolicy: image.PullNever}).Return(toImage, nil)
+
+			diff, err := subject.DiffBuilders("some/builder:1", "some/builder:2")
+			assert.Nil(err)
+
+			assert.Equal(diff.From, "some/builder:1")
+			assert.Equal(diff.To, "some/builder:2")
+			assert.Equal(diff.Buildpacks.Upgraded, []VersionChange{{ID: "some/bp", From: "1.0.0", To: "1.1.0"}})
+			assert.Equal(diff.Lifecycle.Version, &ValueChange{From: "0.13.0", To: "0.13.5"})
+			assert.Equal(diff.RunImage, &ValueChange{From: "some/run:1", To: "some/run:2"})
+			assert.Nil(diff.Stack)
+		})
+
+		when("a builder can't be found", func() {
+			it("returns an error", func() {
+				mockImageFetcher.EXPECT().Fetch(gomock.Any(), "some/builder:1", gomock.Any()).Return(nil, image.ErrNotFound).Times(2)
+
+				_, err := subject.DiffBuilders("some/builder:1", "some/builder:2")
+				assert.ErrorWithMessage(err, "unable to find builder 'some/builder:1' locally or remotely")
+			})
+		})
+	})
+
+	when("#diffBuilders", func() {
+		var from, to *BuilderInfo
+
+		it.Before(func() {
+			from = &BuilderInfo{
+				Stack:           "some.stack",
+				Mixins:          []string{"mixinA", "build:mixinB"},
+				RunImage:        "some/run",
+				RunImageMirrors: []string{"first/mirror"},
+				Buildpacks: []dist.BuildpackInfo{
+					{ID: "some/bp", Version: "1.0.0"},
+					{ID: "removed/bp", Version: "0.1.0"},
+					{ID: "downgraded/bp", Version: "2.0.0"},
+				},
+				Order: pubbldr.DetectionOrder{
+					{GroupDetectionOrder: pubbldr.DetectionOrder{
+						{BuildpackRef: dist.BuildpackRef{BuildpackInfo: dist.BuildpackInfo{ID: "some/bp", Version: "1.0.0"}}},
+						{BuildpackRef: dist.BuildpackRef{BuildpackInfo: dist.BuildpackInfo{ID: "removed/bp", Version: "0.1.0"}, Optional: true}},
+					}},
+					{GroupDetectionOrder: pubbldr.DetectionOrder{
+						{BuildpackRef: dist.BuildpackRef{BuildpackInfo: dist.BuildpackInfo{ID: "downgraded/bp", Version: "2.0.0"}}},
+					}},
+				},
+				Lifecycle: builder.LifecycleDescriptor{
+					Info: builder.LifecycleInfo{Version: builder.VersionMustParse("0.13.0")},
+					APIs: builder.LifecycleAPIs{
+						Buildpack: builder.APIVersions{Supported: builder.APISet{api.MustParse("0.2"), api.MustParse("0.7")}},
+						Platform:  builder.APIVersions{Supported: builder.APISet{api.MustParse("0.8")}},
+					},
+				},
+			}
+			to = &BuilderInfo{
+				Stack:           "some.stack",
+				Mixins:          []string{"mixinA", "mixinC"},
+				RunImage:        "some/run",
+				RunImageMirrors: []string{"first/mirror"},
+				Buildpacks: []dist.BuildpackInfo{
+					{ID: "some/bp", Version: "1.0.0"},
+					{ID: "added/bp", Version: "3.0.0"},
+					{ID: "downgraded/bp", Version: "1.9.0"},
+				},
+				Order: pubbldr.DetectionOrder{
+					{GroupDetectionOrder: pubbldr.DetectionOrder{
+						{BuildpackRef: dist.BuildpackRef{BuildpackInfo: dist.BuildpackInfo{ID: "added/bp", Version: "3.0.0"}}},
+						{BuildpackRef: dist.BuildpackRef{BuildpackInfo: dist.BuildpackInfo{ID: "some/bp", Version: "1.0.0"}}},
+					}},
+				},
+				Lifecycle: builder.LifecycleDescriptor{
+					Info: builder.LifecycleInfo{Version: builder.VersionMustParse("0.13.0")},
+					APIs: builder.LifecycleAPIs{
+						Buildpack: builder.APIVersions{Supported: builder.APISet{api.MustParse("0.7"), api.MustParse("0.8")}},
+						Platform:  builder.APIVersions{Supported: builder.APISet{api.MustParse("0.8")}},
+					},
+				},
+			}
+		})
+
+		it("reports added, removed, upgraded and downgraded buildpacks", func() {
+			diff := diffBuilders(from, to)
+
+			assert.Equal(diff.Buildpacks, BuildpacksDiff{
+				Added:      []dist.BuildpackInfo{{ID: "added/bp", Version: "3.0.0"}},
+				Removed:    []dist.BuildpackInfo{{ID: "removed/bp", Version: "0.1.0"}},
+				Downgraded: []VersionChange{{ID: "downgraded/bp", From: "2.0.0", To: "1.9.0"}},
+			})
+		})
+
+		it("reports changes to each group of the order", func() {
+			diff := diffBuilders(from, to)
+
+			assert.Equal(diff.Order, []OrderGroupDiff{
+				{
+					Group:   1,
+					Added:   []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "added/bp", Version: "3.0.0"}}},
+					Removed: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "removed/bp", Version: "0.1.0"}, Optional: true}},
+				},
+				{
+					Group:   2,
+					Removed: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "downgraded/bp", Version: "2.0.0"}}},
+				},
+			})
+		})
+
+		it("reports reordered groups", func() {
+			to.Order = pubbldr.DetectionOrder{
+				{GroupDetectionOrder: pubbldr.DetectionOrder{
+					from.Order[0].GroupDetectionOrder[1],
+					from.Order[0].GroupDetectionOrder[0],
+				}},
+				from.Order[1],
+			}
+
+			diff := diffBuilders(from, to)
+
+			assert.Equal(diff.Order, []OrderGroupDiff{{Group: 1, Reordered: true}})
+		})
+
+		it("reports lifecycle API and mixin changes", func() {
+			diff := diffBuilders(from, to)
+
+			assert.Nil(diff.Lifecycle.Version)
+			assert.Equal(diff.Lifecycle.BuildpackAPIs.Supported, ListDiff{Added: []string{"0.8"}, Removed: []string{"0.2"}})
+			assert.TrueWithMessage(diff.Lifecycle.PlatformAPIs.IsEmpty(), "expected platform APIs to be unchanged")
+			assert.Equal(diff.Mixins, ListDiff{Added: []string{"mixinC"}, Removed: []string{"build:mixinB"}})
+			assert.TrueWithMessage(diff.RunImageMirrors.IsEmpty(), "expected run image mirrors to be unchanged")
+		})
+
+		it("is empty for the same builder", func() {
+			diff := diffBuilders(from, from)
+			assert.TrueWithMessage(diff.IsEmpty(), "expected diff to be empty")
+		})
+	})
+}
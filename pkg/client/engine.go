@@ -0,0 +1,24 @@
+package client
+
+import (
+	"context"
+
+	"github.com/buildpacks/pack/internal/engine"
+)
+
+// ContainerEngine detects the container engine the docker client is connected to, e.g. Docker or rootless Podman.
+func (c *Client) ContainerEngine(ctx context.Context) (engine.Engine, error) {
+	return engine.Detect(ctx, c.docker)
+}
+
+// build containers are adapted to the engine, but a build shouldn't fail because the engine couldn't be detected
+func (c *Client) detectEngine(ctx context.Context) engine.Engine {
+	detected, err := c.ContainerEngine(ctx)
+	if err != nil {
+		c.logger.Debugf("Unable to detect the container engine: %s", err)
+		return engine.Engine{}
+	}
+
+	c.logger.Debugf("Using container engine %s", detected)
+	return detected
+}
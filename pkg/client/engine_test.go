@@ -0,0 +1,77 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpacks/pack/internal/engine"
+	"github.com/buildpacks/pack/pkg/logging"
+	"github.com/buildpacks/pack/pkg/testmocks"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestContainerEngine(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "ContainerEngine", testContainerEngine, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testContainerEngine(t *testing.T, when spec.G, it spec.S) {
+	var (
+		subject          *Client
+		mockDockerClient *testmocks.MockCommonAPIClient
+		mockController   *gomock.Controller
+		out              bytes.Buffer
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockDockerClient = testmocks.NewMockCommonAPIClient(mockController)
+
+		var err error
+		subject, err = NewClient(WithLogger(logging.NewLogWithWriters(&out, &out)), WithDockerClient(mockDockerClient))
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+	})
+
+	when("#ContainerEngine", func() {
+		it("detects the engine of the docker client", func() {
+			mockDockerClient.EXPECT().ServerVersion(gomock.Any()).Return(types.Version{
+				Components: []types.ComponentVersion{{Name: "Podman Engine", Version: "4.2.0"}},
+			}, nil)
+			mockDockerClient.EXPECT().Info(gomock.Any()).Return(types.Info{SecurityOptions: []string{"name=rootless"}}, nil)
+			mockDockerClient.EXPECT().DaemonHost().Return("unix:///run/user/1000/podman/podman.sock")
+
+			detected, err := subject.ContainerEngine(context.TODO())
+			h.AssertNil(t, err)
+			h.AssertEq(t, detected.String(), "Podman 4.2.0 (rootless)")
+			h.AssertEq(t, detected.Socket, "/run/user/1000/podman/podman.sock")
+		})
+
+		when("the engine can't be reached", func() {
+			it("returns an error", func() {
+				mockDockerClient.EXPECT().ServerVersion(gomock.Any()).Return(types.Version{}, errors.New("connection refused"))
+
+				_, err := subject.ContainerEngine(context.TODO())
+				h.AssertError(t, err, "connection refused")
+			})
+
+			it("builds for an undetected engine", func() {
+				mockDockerClient.EXPECT().ServerVersion(gomock.Any()).Return(types.Version{}, errors.New("connection refused"))
+
+				h.AssertEq(t, subject.detectEngine(context.TODO()), engine.Engine{})
+			})
+		})
+	})
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	pubbldr "github.com/buildpacks/pack/builder"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/logging"
+	projectTypes "github.com/buildpacks/pack/pkg/project/types"
+)
+
+// EnvAction is how an environment variable of the build combines with the value the variable has from earlier sources
+type EnvAction string
+
+const (
+	// EnvOverride replaces the value, it's the action of variables without one
+	EnvOverride EnvAction = "override"
+
+	// EnvDefault sets the value only if the variable has no value yet
+	EnvDefault EnvAction = "default"
+
+	// EnvAppend appends the value, after the delimiter if the variable has a value
+	EnvAppend EnvAction = "append"
+
+	// EnvPrepend prepends the value, before the delimiter if the variable has a value
+	EnvPrepend EnvAction = "prepend"
+)
+
+// ParseEnvAction parses the action of an environment variable, an empty action is EnvOverride
+func ParseEnvAction(action string) (EnvAction, error) {
+	switch EnvAction(action) {
+	case "", EnvOverride:
+		return EnvOverride, nil
+	case EnvDefault, EnvAppend, EnvPrepend:
+		return EnvAction(action), nil
+	}
+	return "", errors.Errorf("invalid action %s, must be one of %s, %s, %s or %s",
+		style.Symbol(action), style.Symbol(string(EnvOverride)), style.Symbol(string(EnvDefault)), style.Symbol(string(EnvAppend)), style.Symbol(string(EnvPrepend)))
+}
+
+// EnvVar is an environment variable provided to the buildpacks, along with where it comes from
+type EnvVar struct {
+	Name  string
+	Value string
+
+	// Action is how Value combines with the value of the variable from earlier sources, EnvOverride if empty.
+	Action EnvAction
+
+	// Delim separates the values combined by EnvAppend and EnvPrepend.
+	Delim string
+
+	// Source describes where the variable comes from, e.g. "--env-file .env". It's reported with the
+	// effective environment of the build in verbose mode.
+	Source string
+}
+
+const (
+	envSourceBuilder         = "builder"
+	envSourceBuilderOverride = "builder (override)"
+	envSourceDescriptor      = "project.toml"
+	envSourceOptions         = "options"
+
+	// shorter values of variables named like secrets, e.g. SKIP_SECRET_SCAN=true, aren't masked everywhere
+	minSensitiveValueLength = 8
+)
+
+// effectiveEnvVar is the value of a variable once all its sources are combined
+type effectiveEnvVar struct {
+	value      string
+	sources    []string
+	overridden []string
+}
+
+// resolveEnv combines the build environment of the builder, the project descriptor, env and vars, in that order.
+// Override values of the builder always win, as they do when the builder writes the environment.
+// The effective environment is logged in verbose mode, with the sources of each variable.
+func (c *Client) resolveEnv(builderEnv []pubbldr.BuildEnv, descriptorEnv []projectTypes.EnvVar, env map[string]string, vars []EnvVar) (map[string]string, error) {
+	var all []EnvVar
+	for _, buildEnv := range builderEnv {
+		if !buildEnv.IsOverride() {
+			all = append(all, EnvVar{Name: buildEnv.Name, Value: buildEnv.Value, Source: envSourceBuilder})
+		}
+	}
+	for _, envVar := range descriptorEnv {
+		all = append(all, EnvVar{Name: envVar.Name, Value: envVar.Value, Action: EnvAction(envVar.Action), Delim: envVar.Delim, Source: envSourceDescriptor})
+	}
+	// a map has no order, sort it so the outcome is the same every time
+	var names []string
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		all = append(all, EnvVar{Name: name, Value: env[name], Source: envSourceOptions})
+	}
+	all = append(all, vars...)
+
+	effective := map[string]*effectiveEnvVar{}
+	for _, envVar := range all {
+		action, err := ParseEnvAction(string(envVar.Action))
+		if err != nil {
+			return nil, errors.Wrapf(err, "environment variable %s from %s", style.Symbol(envVar.Name), envVar.Source)
+		}
+
+		current, ok := effective[envVar.Name]
+		if !ok {
+			effective[envVar.Name] = &effectiveEnvVar{value: envVar.Value, sources: []string{envVar.Source}}
+			continue
+		}
+
+		switch action {
+		case EnvOverride:
+			current.overridden = append(current.overridden, current.sources...)
+			current.value = envVar.Value
+			current.sources = []string{envVar.Source}
+		case EnvAppend:
+			current.value = joinEnvValues(current.value, envVar.Value, envVar.Delim)
+			current.sources = append(current.sources, fmt.Sprintf("%s (%s)", envVar.Source, action))
+		case EnvPrepend:
+			current.value = joinEnvValues(envVar.Value, current.value, envVar.Delim)
+			current.sources = append(current.sources, fmt.Sprintf("%s (%s)", envVar.Source, action))
+		}
+	}
+
+	for _, buildEnv := range builderEnv {
+		if !buildEnv.IsOverride() {
+			continue
+		}
+		current, ok := effective[buildEnv.Name]
+		if !ok {
+			effective[buildEnv.Name] = &effectiveEnvVar{value: buildEnv.Value, sources: []string{envSourceBuilderOverride}}
+			continue
+		}
+		if current.value != buildEnv.Value {
+			c.logger.Warnf("Environment variable %s is overridden by the builder and will be ignored", style.Symbol(buildEnv.Name))
+		}
+		current.overridden = append(current.overridden, current.sources...)
+		current.value = buildEnv.Value
+		current.sources = []string{envSourceBuilderOverride}
+	}
+
+	resolved := map[string]string{}
+	for name, envVar := range effective {
+		resolved[name] = envVar.value
+		// the values of variables named like secrets are masked wherever they're logged, not only next to their names
+		if logging.IsSensitiveName(name) && len(envVar.value) >= minSensitiveValueLength {
+			logging.AddRedactions(c.logger, envVar.value)
+		}
+	}
+
+	if c.logger.IsVerbose() && len(effective) > 0 {
+		c.logger.Debug(envTable(effective))
+	}
+
+	return resolved, nil
+}
+
+func joinEnvValues(first, second, delim string) string {
+	if first == "" {
+		return second
+	}
+	if second == "" {
+		return first
+	}
+	return first + delim + second
+}
+
+// envTable describes the effective environment of the build, sorted by name
+func envTable(effective map[string]*effectiveEnvVar) string {
+	var names []string
+	for name := range effective {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("Build environment:\n")
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	// variables are printed as NAME=VALUE, so the values of variables named like secrets are redacted
+	fmt.Fprintln(tw, "  VARIABLE\tSOURCE")
+	for _, name := range names {
+		envVar := effective[name]
+		source := strings.Join(envVar.sources, ", ")
+		if len(envVar.overridden) > 0 {
+			source = fmt.Sprintf("%s, overriding %s", source, strings.Join(envVar.overridden, ", "))
+		}
+		fmt.Fprintf(tw, "  %s=%s\t%s\n", name, strings.ReplaceAll(envVar.value, "\n", `\n`), source)
+	}
+	tw.Flush()
+	return strings.TrimSuffix(sb.String(), "\n")
+}
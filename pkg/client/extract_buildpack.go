@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/buildpack"
+	"github.com/buildpacks/pack/pkg/image"
+)
+
+// ExtractBuildpackOptions are options available for ExtractBuildpack
+type ExtractBuildpackOptions struct {
+	// URI of the buildpack package to extract: an image, a '.cnb' file or a buildpack registry reference.
+	URI string
+
+	// Directory to extract the buildpacks of the package to. It must not exist, or be empty.
+	Dir string
+
+	// RegistryName to search for buildpacks from.
+	RegistryName string
+
+	// RelativeBaseDir to resolve relative assets from.
+	RelativeBaseDir string
+
+	// Strategy for updating the package image before extracting it.
+	PullPolicy image.PullPolicy
+}
+
+// ExtractBuildpack extracts the buildpacks of a buildpack package to a directory, in the same layout as
+// PackageBuildpack with FormatDir, so that they can be read, changed and packaged again.
+func (c *Client) ExtractBuildpack(ctx context.Context, opts ExtractBuildpackOptions) error {
+	if opts.URI == "" {
+		return errors.New("buildpack URI must be provided")
+	}
+
+	mainBP, depBPs, err := c.buildpackDownloader.Download(ctx, opts.URI, buildpack.DownloadOptions{
+		RegistryName:    opts.RegistryName,
+		RelativeBaseDir: opts.RelativeBaseDir,
+		ImageOS:         "linux",
+		Daemon:          true,
+		PullPolicy:      opts.PullPolicy,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "downloading buildpack %s", style.Symbol(opts.URI))
+	}
+
+	packageBuilder := buildpack.NewBuilder(c.imageFactory)
+	packageBuilder.SetBuildpack(mainBP)
+	for _, depBP := range depBPs {
+		packageBuilder.AddDependency(depBP)
+	}
+
+	return packageBuilder.SaveAsDir(opts.Dir)
+}
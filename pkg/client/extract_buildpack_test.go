@@ -0,0 +1,128 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/imgutil/fakes"
+	"github.com/buildpacks/lifecycle/api"
+	"github.com/golang/mock/gomock"
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	pubbldpkg "github.com/buildpacks/pack/buildpackage"
+	ifakes "github.com/buildpacks/pack/internal/fakes"
+	"github.com/buildpacks/pack/pkg/client"
+	"github.com/buildpacks/pack/pkg/dist"
+	"github.com/buildpacks/pack/pkg/image"
+	"github.com/buildpacks/pack/pkg/logging"
+	"github.com/buildpacks/pack/pkg/testmocks"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestExtractBuildpack(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "ExtractBuildpack", testExtractBuildpack, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testExtractBuildpack(t *testing.T, when spec.G, it spec.S) {
+	var (
+		subject          *client.Client
+		mockController   *gomock.Controller
+		mockDownloader   *testmocks.MockBlobDownloader
+		mockImageFactory *testmocks.MockImageFactory
+		mockImageFetcher *testmocks.MockImageFetcher
+		out              bytes.Buffer
+		tmpDir           string
+	)
+
+	it.Before(func() {
+		mockController = gomock.NewController(t)
+		mockDownloader = testmocks.NewMockBlobDownloader(mockController)
+		mockImageFactory = testmocks.NewMockImageFactory(mockController)
+		mockImageFetcher = testmocks.NewMockImageFetcher(mockController)
+
+		var err error
+		subject, err = client.NewClient(
+			client.WithLogger(logging.NewLogWithWriters(&out, &out)),
+			client.WithDownloader(mockDownloader),
+			client.WithImageFactory(mockImageFactory),
+			client.WithFetcher(mockImageFetcher),
+		)
+		h.AssertNil(t, err)
+
+		tmpDir, err = ioutil.TempDir("", "extract-buildpack")
+		h.AssertNil(t, err)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	createBuildpack := func(descriptor dist.BuildpackDescriptor) string {
+		bp, err := ifakes.NewFakeBuildpackBlob(descriptor, 0644)
+		h.AssertNil(t, err)
+		url := fmt.Sprintf("https://example.com/bp.%s.tgz", h.RandString(12))
+		mockDownloader.EXPECT().Download(gomock.Any(), url).Return(bp, nil).AnyTimes()
+		return url
+	}
+
+	when("#ExtractBuildpack", func() {
+		it("extracts the buildpacks of a package image", func() {
+			packageImage := fakes.NewImage("some/package-"+h.RandString(12), "", nil)
+			mockImageFactory.EXPECT().NewImage(packageImage.Name(), false, "linux").Return(packageImage, nil)
+
+			h.AssertNil(t, subject.PackageBuildpack(context.TODO(), client.PackageBuildpackOptions{
+				Name: packageImage.Name(),
+				Config: pubbldpkg.Config{
+					Platform: dist.Platform{OS: "linux"},
+					Buildpack: dist.BuildpackURI{URI: createBuildpack(dist.BuildpackDescriptor{
+						API:  api.MustParse("0.2"),
+						Info: dist.BuildpackInfo{ID: "bp.meta", Version: "1.0.0"},
+						Order: dist.Order{{
+							Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "bp.nested", Version: "2.0.0"}}},
+						}},
+					})},
+					Dependencies: []dist.ImageOrURI{{BuildpackURI: dist.BuildpackURI{URI: createBuildpack(dist.BuildpackDescriptor{
+						API:    api.MustParse("0.2"),
+						Info:   dist.BuildpackInfo{ID: "bp.nested", Version: "2.0.0"},
+						Stacks: []dist.Stack{{ID: "some.stack.id"}},
+					})}}},
+				},
+				Publish:    true,
+				PullPolicy: image.PullAlways,
+			}))
+
+			mockImageFetcher.EXPECT().Fetch(gomock.Any(), packageImage.Name(), image.FetchOptions{Daemon: true, PullPolicy: image.PullIfNotPresent}).Return(packageImage, nil)
+
+			outputDir := filepath.Join(tmpDir, "package")
+			h.AssertNil(t, subject.ExtractBuildpack(context.TODO(), client.ExtractBuildpackOptions{
+				URI:        "docker://" + packageImage.Name(),
+				Dir:        outputDir,
+				PullPolicy: image.PullIfNotPresent,
+			}))
+
+			contents, err := ioutil.ReadFile(filepath.Join(outputDir, "bp.nested", "2.0.0", "bin", "detect"))
+			h.AssertNil(t, err)
+			h.AssertEq(t, string(contents), "detect-contents")
+
+			_, err = os.Stat(filepath.Join(outputDir, "bp.meta", "1.0.0", "buildpack.toml"))
+			h.AssertNil(t, err)
+		})
+
+		when("no URI is provided", func() {
+			it("errors", func() {
+				err := subject.ExtractBuildpack(context.TODO(), client.ExtractBuildpackOptions{Dir: tmpDir})
+				h.AssertError(t, err, "buildpack URI must be provided")
+			})
+		})
+	})
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/logging"
+)
+
+// defaultFetchConcurrency is the default number of fetches that run at the same time.
+const defaultFetchConcurrency = 4
+
+// fetchGroup runs independent fetches, such as pulling images and downloading buildpacks,
+// concurrently with a bounded number of fetches in flight. The bound is shared by all the
+// groups of a client, including groups started by the fetches of another group.
+type fetchGroup struct {
+	logger logging.Logger
+	slots  chan struct{}
+	tasks  []fetchTask
+}
+
+// fetchSlotKey marks the context of a fetch that holds a slot
+type fetchSlotKey struct{}
+
+type fetchTask struct {
+	name  string
+	fetch func(ctx context.Context) error
+}
+
+func (c *Client) newFetchGroup() *fetchGroup {
+	c.fetchSlotsOnce.Do(func() {
+		limit := c.fetchConcurrency
+		if limit < 1 {
+			limit = defaultFetchConcurrency
+		}
+		c.fetchSlots = make(chan struct{}, limit)
+	})
+
+	return &fetchGroup{
+		logger: c.logger,
+		slots:  c.fetchSlots,
+	}
+}
+
+// Add queues a fetch. Fetches don't start until Wait is called.
+func (g *fetchGroup) Add(name string, fetch func(ctx context.Context) error) {
+	g.tasks = append(g.tasks, fetchTask{name: name, fetch: fetch})
+}
+
+// Wait runs all queued fetches and returns the first error encountered, if any.
+// Once a fetch fails, the context passed to the remaining fetches is cancelled.
+func (g *fetchGroup) Wait(ctx context.Context) error {
+	if len(g.tasks) == 1 {
+		return g.tasks[0].fetch(ctx)
+	}
+
+	// a fetch waiting for the fetches it started gives up its slot meanwhile, so that they can't
+	// wait for the slots held by the fetches waiting for them
+	if ctx.Value(fetchSlotKey{}) != nil {
+		<-g.slots
+		defer func() { g.slots <- struct{}{} }()
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	taskCtx := context.WithValue(egCtx, fetchSlotKey{}, true)
+
+	var completed int32
+	total := len(g.tasks)
+	if total > 0 {
+		g.logger.Debugf("Fetching %d items with up to %d at a time", total, cap(g.slots))
+	}
+
+	for _, task := range g.tasks {
+		task := task
+		eg.Go(func() error {
+			select {
+			case g.slots <- struct{}{}:
+			case <-egCtx.Done():
+				return egCtx.Err()
+			}
+			defer func() { <-g.slots }()
+
+			if err := task.fetch(taskCtx); err != nil {
+				return err
+			}
+
+			g.logger.Debugf("Fetched %s (%d/%d)", style.Symbol(task.name), atomic.AddInt32(&completed, 1), total)
+			return nil
+		})
+	}
+
+	return eg.Wait()
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/heroku/color"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpacks/pack/pkg/logging"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestFetchGroup(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+	spec.Run(t, "FetchGroup", testFetchGroup, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testFetchGroup(t *testing.T, when spec.G, it spec.S) {
+	var (
+		subject *Client
+		out     bytes.Buffer
+	)
+
+	it.Before(func() {
+		subject = &Client{
+			logger:           logging.NewLogWithWriters(&out, &out, logging.WithVerbose()),
+			fetchConcurrency: 2,
+		}
+	})
+
+	when("#Wait", func() {
+		it("runs all fetches", func() {
+			var (
+				mu      sync.Mutex
+				fetched []string
+			)
+
+			fetches := subject.newFetchGroup()
+			for _, name := range []string{"a", "b", "c"} {
+				name := name
+				fetches.Add(name, func(ctx context.Context) error {
+					mu.Lock()
+					defer mu.Unlock()
+					fetched = append(fetched, name)
+					return nil
+				})
+			}
+
+			h.AssertNil(t, fetches.Wait(context.TODO()))
+			h.AssertSliceContainsOnly(t, fetched, "a", "b", "c")
+			h.AssertContains(t, out.String(), "Fetching 3 items with up to 2 at a time")
+			h.AssertContains(t, out.String(), "(3/3)")
+		})
+
+		it("limits the number of fetches in flight", func() {
+			var inFlight, maxInFlight int32
+
+			fetches := subject.newFetchGroup()
+			for i := 0; i < 6; i++ {
+				fetches.Add("item", func(ctx context.Context) error {
+					current := atomic.AddInt32(&inFlight, 1)
+					for {
+						max := atomic.LoadInt32(&maxInFlight)
+						if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+							break
+						}
+					}
+					time.Sleep(10 * time.Millisecond)
+					atomic.AddInt32(&inFlight, -1)
+					return nil
+				})
+			}
+
+			h.AssertNil(t, fetches.Wait(context.TODO()))
+			h.AssertTrue(t, atomic.LoadInt32(&maxInFlight) <= 2)
+		})
+
+		it("returns the first error and cancels the remaining fetches", func() {
+			fetches := subject.newFetchGroup()
+			fetches.Add("failing", func(ctx context.Context) error {
+				return errors.New("some fetch error")
+			})
+			fetches.Add("waiting", func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+
+			h.AssertError(t, fetches.Wait(context.TODO()), "some fetch error")
+		})
+
+		it("defaults the concurrency when it isn't set", func() {
+			subject.fetchConcurrency = 0
+			h.AssertEq(t, cap(subject.newFetchGroup().slots), defaultFetchConcurrency)
+		})
+
+		it("shares the limit with the groups started by its fetches", func() {
+			var inFlight, maxInFlight, leaves int32
+
+			fetches := subject.newFetchGroup()
+			for i := 0; i < 3; i++ {
+				fetches.Add("group", func(ctx context.Context) error {
+					nested := subject.newFetchGroup()
+					for j := 0; j < 3; j++ {
+						nested.Add("item", func(ctx context.Context) error {
+							current := atomic.AddInt32(&inFlight, 1)
+							for {
+								max := atomic.LoadInt32(&maxInFlight)
+								if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+									break
+								}
+							}
+							time.Sleep(10 * time.Millisecond)
+							atomic.AddInt32(&inFlight, -1)
+							atomic.AddInt32(&leaves, 1)
+							return nil
+						})
+					}
+					return nested.Wait(ctx)
+				})
+			}
+
+			h.AssertNil(t, fetches.Wait(context.TODO()))
+			h.AssertEq(t, atomic.LoadInt32(&leaves), int32(9))
+			h.AssertTrue(t, atomic.LoadInt32(&maxInFlight) <= 2)
+		})
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	pubbldr "github.com/buildpacks/pack/builder"
 
+	"github.com/buildpacks/pack/internal/build"
 	"github.com/buildpacks/pack/internal/builder"
 	"github.com/buildpacks/pack/pkg/dist"
 	"github.com/buildpacks/pack/pkg/image"
@@ -48,6 +49,10 @@ type BuilderInfo struct {
 	// Name and Version information from tooling used
 	// to produce this builder.
 	CreatedBy builder.CreatorMetadata
+
+	// Environment variables the builder provides to every build, with their
+	// default or override mode.
+	BuildEnv []pubbldr.BuildEnv
 }
 
 // BuildpackInfoKey contains all information needed to determine buildpack equivalence.
@@ -58,6 +63,7 @@ type BuildpackInfoKey struct {
 
 type BuilderInspectionConfig struct {
 	OrderDetectionDepth int
+	DetectResult        *DetectResult
 }
 
 type BuilderInspectionModifier func(config *BuilderInspectionConfig)
@@ -68,6 +74,14 @@ func WithDetectionOrderDepth(depth int) BuilderInspectionModifier {
 	}
 }
 
+// WithDetectResult annotates the detection order with the outcome of detecting an app,
+// as returned by Detect.
+func WithDetectResult(result *DetectResult) BuilderInspectionModifier {
+	return func(config *BuilderInspectionConfig) {
+		config.DetectResult = result
+	}
+}
+
 // InspectBuilder reads label metadata of a local or remote builder image. It initializes a BuilderInfo
 // object with this metadata, and returns it. This method will error if the name image cannot be found
 // both locally and remotely, or if the found image does not contain the proper labels.
@@ -91,6 +105,10 @@ func (c *Client) InspectBuilder(name string, daemon bool, modifiers ...BuilderIn
 		return nil, err
 	}
 
+	if inspectionConfig.DetectResult != nil {
+		annotateDetectionOrder(info.Order, inspectionConfig.DetectResult)
+	}
+
 	return &BuilderInfo{
 		Description:     info.Description,
 		Stack:           info.StackID,
@@ -102,5 +120,151 @@ func (c *Client) InspectBuilder(name string, daemon bool, modifiers ...BuilderIn
 		BuildpackLayers: info.BuildpackLayers,
 		Lifecycle:       info.Lifecycle,
 		CreatedBy:       info.CreatedBy,
+		BuildEnv:        info.BuildEnv,
 	}, nil
 }
+
+// annotateDetectionOrder records the outcome of each buildpack in order and marks the group that was selected.
+func annotateDetectionOrder(order pubbldr.DetectionOrder, result *DetectResult) {
+	runs, tried := detectionRunsByGroup(order, result.Trials)
+
+	selected := map[string]bool{}
+	for _, bp := range result.Group {
+		selected[bp.String()] = true
+	}
+
+	// only the group of the last trial can have passed, as detection stops at the first group that passes
+	for i := range order {
+		var groupSelected map[string]bool
+		if i == tried && len(result.Group) > 0 {
+			groupSelected = selected
+		}
+		if annotateDetectionOrderGroup(order[i].GroupDetectionOrder, runs[i], groupSelected) {
+			order[i].Selected = true
+		}
+	}
+}
+
+// detectionRunsByGroup assigns each trial to the group of order it was tried for and returns the outcome of
+// each buildpack per group, along with the index of the group of the last trial (-1 if there were no trials).
+// Trials are assigned in order, as the lifecycle tries groups in the order they appear. A buildpack that was
+// tried more than once for a group keeps the outcome of its first trial.
+func detectionRunsByGroup(order pubbldr.DetectionOrder, trials []build.DetectTrial) ([]map[string]build.DetectedBuildpack, int) {
+	runs := make([]map[string]build.DetectedBuildpack, len(order))
+	for i := range runs {
+		runs[i] = map[string]build.DetectedBuildpack{}
+	}
+	if len(order) == 0 {
+		return runs, -1
+	}
+
+	current := -1
+	for _, trial := range trials {
+		start := current
+		if start < 0 {
+			start = 0
+		}
+		for i := start; i < len(order); i++ {
+			if triedForGroup(order[i].GroupDetectionOrder, trial) {
+				current = i
+				break
+			}
+		}
+		// a trial that can't be matched, e.g. because the order isn't expanded deep enough, stays with the current group
+		if current < 0 {
+			current = 0
+		}
+
+		for _, bp := range trial.Buildpacks {
+			name := bp.ID + "@" + bp.Version
+			if _, ok := runs[current][name]; !ok {
+				runs[current][name] = bp
+			}
+		}
+	}
+	return runs, current
+}
+
+// triedForGroup reports whether every buildpack of trial is part of group or its nested groups.
+func triedForGroup(group pubbldr.DetectionOrder, trial build.DetectTrial) bool {
+	names := map[string]bool{}
+	var collect func(pubbldr.DetectionOrder)
+	collect = func(entries pubbldr.DetectionOrder) {
+		for _, entry := range entries {
+			names[entry.FullName()] = true
+			collect(entry.GroupDetectionOrder)
+		}
+	}
+	collect(group)
+
+	for _, bp := range trial.Buildpacks {
+		if !names[bp.ID+"@"+bp.Version] {
+			return false
+		}
+	}
+	return true
+}
+
+// annotateDetectionOrderGroup annotates each buildpack in group and reports whether the group was selected,
+// which is the case when every required buildpack is part of the selected group.
+func annotateDetectionOrderGroup(group pubbldr.DetectionOrder, runs map[string]build.DetectedBuildpack, selected map[string]bool) bool {
+	if len(group) == 0 {
+		return false
+	}
+
+	var (
+		participating = false
+		satisfied     = true
+		metaSelected  = map[string]bool{}
+		metaOptional  = map[string]bool{}
+	)
+
+	for i := range group {
+		entry := &group[i]
+		name := entry.FullName()
+
+		// a meta-buildpack appears once for each group of its order
+		if len(entry.GroupDetectionOrder) > 0 {
+			metaOptional[name] = entry.Optional
+			if _, ok := metaSelected[name]; !ok {
+				metaSelected[name] = false
+			}
+			if annotateDetectionOrderGroup(entry.GroupDetectionOrder, runs, selected) && !metaSelected[name] {
+				entry.Selected = true
+				metaSelected[name] = true
+			}
+			continue
+		}
+
+		if selected[name] {
+			entry.Detect = build.DetectStatusPass
+			participating = true
+			continue
+		}
+
+		if run, ok := runs[name]; ok {
+			entry.Detect = run.Status
+			// whether a failure is skipped depends on the group the buildpack is in
+			if run.Status == build.DetectStatusFail || run.Status == build.DetectStatusSkip {
+				entry.Detect = build.DetectStatusFail
+				if entry.Optional {
+					entry.Detect = build.DetectStatusSkip
+				}
+			}
+		}
+
+		if !entry.Optional {
+			satisfied = false
+		}
+	}
+
+	for name, isSelected := range metaSelected {
+		if isSelected {
+			participating = true
+		} else if !metaOptional[name] {
+			satisfied = false
+		}
+	}
+
+	return participating && satisfied
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/buildpacks/imgutil/fakes"
 	"github.com/buildpacks/lifecycle/api"
+	"github.com/buildpacks/lifecycle/buildpack"
 	"github.com/golang/mock/gomock"
 	"github.com/google/go-cmp/cmp"
 	"github.com/heroku/color"
@@ -16,6 +17,7 @@ import (
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 
+	"github.com/buildpacks/pack/internal/build"
 	"github.com/buildpacks/pack/internal/builder"
 	"github.com/buildpacks/pack/pkg/dist"
 	"github.com/buildpacks/pack/pkg/image"
@@ -140,7 +142,8 @@ func testInspectBuilder(t *testing.T, when spec.G, it spec.S) {
 	"buildpack": {"deprecated": ["0.1"], "supported": ["1.2", "1.3"]},
 	"platform": {"deprecated": [], "supported": ["2.3", "2.4"]}
   }},
-  "createdBy": {"name": "pack", "version": "1.2.3"}
+  "createdBy": {"name": "pack", "version": "1.2.3"},
+  "buildEnv": [{"name": "BP_MIRROR", "value": "https://mirror.example.com"}, {"name": "HTTPS_PROXY", "value": "http://proxy.example.com", "mode": "override"}]
 }`))
 
 						assert.Succeeds(builderImage.SetLabel(
@@ -337,6 +340,10 @@ func testInspectBuilder(t *testing.T, when spec.G, it spec.S) {
 								Name:    "pack",
 								Version: "1.2.3",
 							},
+							BuildEnv: []pubbldr.BuildEnv{
+								{Name: "BP_MIRROR", Value: "https://mirror.example.com"},
+								{Name: "HTTPS_PROXY", Value: "http://proxy.example.com", Mode: "override"},
+							},
 						}
 
 						if diff := cmp.Diff(want, *builderInfo); diff != "" {
@@ -395,6 +402,39 @@ func testInspectBuilder(t *testing.T, when spec.G, it spec.S) {
 							}
 						})
 					})
+
+					when("a detect result is provided", func() {
+						it("annotates the order with the outcome of each buildpack", func() {
+							builderInfo, err := subject.InspectBuilder(
+								"some/builder",
+								useDaemon,
+								WithDetectionOrderDepth(pubbldr.OrderDetectionMaxDepth),
+								WithDetectResult(&DetectResult{
+									Group: []buildpack.GroupBuildpack{
+										{ID: "test.bp.one", Version: "test.bp.one.version"},
+										{ID: "test.bp.two", Version: "test.bp.two.version"},
+									},
+									Trials: []build.DetectTrial{{Buildpacks: []build.DetectedBuildpack{
+										{ID: "test.bp.one", Version: "test.bp.one.version", Status: "pass"},
+										{ID: "test.bp.two", Version: "test.bp.two.version", Status: "pass"},
+									}}},
+								}),
+							)
+							h.AssertNil(t, err)
+
+							h.AssertEq(t, len(builderInfo.Order), 1)
+							h.AssertTrue(t, builderInfo.Order[0].Selected)
+
+							nested := builderInfo.Order[0].GroupDetectionOrder[0]
+							h.AssertTrue(t, nested.Selected)
+							h.AssertEq(t, nested.GroupDetectionOrder[0].Detect, "pass")
+							h.AssertEq(t, nested.GroupDetectionOrder[1].Detect, "pass")
+
+							optional := builderInfo.Order[0].GroupDetectionOrder[1]
+							h.AssertFalse(t, optional.Selected)
+							h.AssertEq(t, optional.Detect, "")
+						})
+					})
 				})
 			})
 		}
@@ -414,3 +454,97 @@ func testInspectBuilder(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 }
+
+func TestAnnotateDetectionOrder(t *testing.T) {
+	ref := func(id string, optional bool) dist.BuildpackRef {
+		return dist.BuildpackRef{BuildpackInfo: dist.BuildpackInfo{ID: id, Version: "1.0"}, Optional: optional}
+	}
+
+	order := pubbldr.DetectionOrder{
+		{GroupDetectionOrder: pubbldr.DetectionOrder{
+			{BuildpackRef: ref("some/go", false)},
+		}},
+		{GroupDetectionOrder: pubbldr.DetectionOrder{
+			{BuildpackRef: ref("some/node", false)},
+			{BuildpackRef: ref("some/yarn", true)},
+			{BuildpackRef: ref("some/procfile", true)},
+		}},
+		{GroupDetectionOrder: pubbldr.DetectionOrder{
+			{BuildpackRef: ref("some/node", false)},
+		}},
+	}
+
+	annotateDetectionOrder(order, &DetectResult{
+		Group: []buildpack.GroupBuildpack{
+			{ID: "some/node", Version: "1.0"},
+			{ID: "some/procfile", Version: "1.0"},
+		},
+		Trials: []build.DetectTrial{
+			{Buildpacks: []build.DetectedBuildpack{{ID: "some/go", Version: "1.0", Status: "fail"}}},
+			{Buildpacks: []build.DetectedBuildpack{
+				{ID: "some/node", Version: "1.0", Status: "pass"},
+				{ID: "some/yarn", Version: "1.0", Status: "skip"},
+				{ID: "some/procfile", Version: "1.0", Status: "pass"},
+			}},
+		},
+	})
+
+	h.AssertFalse(t, order[0].Selected)
+	h.AssertEq(t, order[0].GroupDetectionOrder[0].Detect, "fail")
+
+	h.AssertTrue(t, order[1].Selected)
+	h.AssertEq(t, order[1].GroupDetectionOrder[0].Detect, "pass")
+	h.AssertEq(t, order[1].GroupDetectionOrder[1].Detect, "skip")
+	h.AssertEq(t, order[1].GroupDetectionOrder[2].Detect, "pass")
+
+	// only the first group that passes is selected
+	h.AssertFalse(t, order[2].Selected)
+}
+
+func TestAnnotateDetectionOrderKeepsTheOutcomeOfEachGroup(t *testing.T) {
+	ref := func(id string, optional bool) dist.BuildpackRef {
+		return dist.BuildpackRef{BuildpackInfo: dist.BuildpackInfo{ID: id, Version: "1.0"}, Optional: optional}
+	}
+
+	order := pubbldr.DetectionOrder{
+		{GroupDetectionOrder: pubbldr.DetectionOrder{
+			{BuildpackRef: ref("some/procfile", true)},
+			{BuildpackRef: ref("some/go", false)},
+		}},
+		{GroupDetectionOrder: pubbldr.DetectionOrder{
+			{BuildpackRef: ref("some/procfile", true)},
+			{BuildpackRef: ref("some/node", false)},
+		}},
+		{GroupDetectionOrder: pubbldr.DetectionOrder{
+			{BuildpackRef: ref("some/procfile", false)},
+		}},
+	}
+
+	annotateDetectionOrder(order, &DetectResult{
+		Group: []buildpack.GroupBuildpack{
+			{ID: "some/node", Version: "1.0"},
+		},
+		Trials: []build.DetectTrial{
+			{Buildpacks: []build.DetectedBuildpack{
+				{ID: "some/procfile", Version: "1.0", Status: "pass"},
+				{ID: "some/go", Version: "1.0", Status: "fail"},
+			}},
+			{Buildpacks: []build.DetectedBuildpack{
+				{ID: "some/procfile", Version: "1.0", Status: "skip", Reason: "provides unused web"},
+				{ID: "some/node", Version: "1.0", Status: "pass"},
+			}},
+		},
+	})
+
+	h.AssertFalse(t, order[0].Selected)
+	h.AssertEq(t, order[0].GroupDetectionOrder[0].Detect, "pass")
+	h.AssertEq(t, order[0].GroupDetectionOrder[1].Detect, "fail")
+
+	h.AssertTrue(t, order[1].Selected)
+	h.AssertEq(t, order[1].GroupDetectionOrder[0].Detect, "skip")
+	h.AssertEq(t, order[1].GroupDetectionOrder[1].Detect, "pass")
+
+	// groups after the one that passed are not tried
+	h.AssertFalse(t, order[2].Selected)
+	h.AssertEq(t, order[2].GroupDetectionOrder[0].Detect, "")
+}
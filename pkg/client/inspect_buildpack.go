@@ -67,7 +67,7 @@ func (c *Client) InspectBuildpack(opts InspectBuildpackOptions) (*BuildpackInfo,
 }
 
 func metadataFromRegistry(client *Client, name, registry string) (buildpackMd buildpack.Metadata, layersMd dist.BuildpackLayers, err error) {
-	registryCache, err := getRegistry(client.logger, registry)
+	registryCache, err := getRegistry(client.logger, registry, client.offline)
 	if err != nil {
 		return buildpack.Metadata{}, dist.BuildpackLayers{}, fmt.Errorf("invalid registry %s: %q", registry, err)
 	}
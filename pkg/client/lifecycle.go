@@ -0,0 +1,313 @@
+package client
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Masterminds/semver"
+	"github.com/buildpacks/imgutil"
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/builder"
+	"github.com/buildpacks/pack/internal/style"
+	"github.com/buildpacks/pack/pkg/archive"
+	"github.com/buildpacks/pack/pkg/blob"
+	"github.com/buildpacks/pack/pkg/image"
+)
+
+const (
+	lifecycleReleasesURL  = "https://github.com/buildpacks/lifecycle/releases/download"
+	lifecycleVersionLabel = "io.buildpacks.lifecycle.version"
+	lifecycleAPIsLabel    = "io.buildpacks.lifecycle.apis"
+)
+
+var sha256Pattern = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+
+// downloadLifecycleVersion returns the archive of a lifecycle release, verified against digest, the pinned
+// 'sha256:<hex>' digest of the archive, or else the checksum published with the release on GitHub. The
+// checksum is never taken from the lifecycle mirror, so a mirror can't serve a lifecycle other than the one
+// released, and GitHub isn't needed when the digest is pinned. Verified archives are cached by version, so
+// that builders can be created again without access to the releases.
+func (c *Client) downloadLifecycleVersion(ctx context.Context, version semver.Version, os, digest string) (blob.Blob, error) {
+	uri := uriFromLifecycleVersion(version, os, c.lifecycleMirror)
+	archivePath := filepath.Join(c.lifecycleCacheDir, version.String(), path.Base(uri))
+	checksum := strings.ToLower(strings.TrimPrefix(digest, "sha256:"))
+
+	valid, err := cachedLifecycleIsValid(archivePath, checksum)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading lifecycle cache")
+	}
+	if valid {
+		c.logger.Debugf("Using cached lifecycle %s", style.Symbol(archivePath))
+		return blob.NewBlob(archivePath), nil
+	}
+
+	if checksum == "" {
+		checksum, err = c.downloadLifecycleChecksum(ctx, uriFromLifecycleVersion(version, os, "")+".sha256")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	downloaded, err := c.downloader.Download(ctx, blob.WithDigest(uri, "sha256:"+checksum))
+	if err != nil {
+		return nil, errors.Wrap(err, "downloading lifecycle")
+	}
+
+	if err := cacheVerifiedLifecycle(downloaded, checksum, archivePath); err != nil {
+		return nil, errors.Wrapf(err, "verifying lifecycle %s", style.Symbol(uri))
+	}
+
+	return blob.NewBlob(archivePath), nil
+}
+
+func (c *Client) downloadLifecycleChecksum(ctx context.Context, uri string) (string, error) {
+	checksumBlob, err := c.downloader.Download(ctx, uri)
+	if err != nil {
+		return "", errors.Wrap(err, "downloading lifecycle checksum")
+	}
+
+	rc, err := checksumBlob.Open()
+	if err != nil {
+		return "", errors.Wrap(err, "opening lifecycle checksum")
+	}
+	defer rc.Close()
+
+	contents, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return "", errors.Wrap(err, "reading lifecycle checksum")
+	}
+
+	return parseChecksum(string(contents))
+}
+
+// parseChecksum reads the digest from a checksum file in the format of sha256sum, e.g. '<digest>  <file name>'
+func parseChecksum(contents string) (string, error) {
+	fields := strings.Fields(contents)
+	if len(fields) == 0 || !sha256Pattern.MatchString(fields[0]) {
+		return "", errors.Errorf("invalid sha256 checksum %s", style.Symbol(strings.TrimSpace(contents)))
+	}
+
+	return strings.ToLower(fields[0]), nil
+}
+
+// cachedLifecycleIsValid returns true if the archive is cached and still matches the checksum it was verified against,
+// which must be checksum unless it's empty
+func cachedLifecycleIsValid(archivePath, checksum string) (bool, error) {
+	expected, err := ioutil.ReadFile(archivePath + ".sha256")
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if checksum != "" && strings.TrimSpace(string(expected)) != checksum {
+		return false, nil
+	}
+
+	digest, err := blob.Digest(blob.NewBlob(archivePath))
+	if os.IsNotExist(errors.Cause(err)) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return digest == "sha256:"+strings.TrimSpace(string(expected)), nil
+}
+
+// cacheVerifiedLifecycle copies a downloaded lifecycle archive to archivePath, if it matches checksum
+func cacheVerifiedLifecycle(downloaded blob.Blob, checksum, archivePath string) error {
+	rc, err := blob.OpenRaw(downloaded)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0750); err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(archivePath), "download-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmpFile, hasher), rc); err != nil {
+		return err
+	}
+
+	if digest := hex.EncodeToString(hasher.Sum(nil)); digest != checksum {
+		return errors.Errorf("checksum mismatch: expected %s, got %s", style.Symbol("sha256:"+checksum), style.Symbol("sha256:"+digest))
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpFile.Name(), archivePath); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(archivePath+".sha256", []byte(checksum), 0600)
+}
+
+// lifecycleFromImage extracts the lifecycle from a lifecycle image, such as buildpacksio/lifecycle, whose top layer
+// contains the lifecycle under /cnb/lifecycle. Extracted lifecycles are cached by the diff ID of that layer.
+func (c *Client) lifecycleFromImage(ctx context.Context, imageName, builderOS string, fetchOptions image.FetchOptions) (blob.Blob, error) {
+	img, err := c.imageFetcher.Fetch(ctx, imageName, fetchOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching lifecycle image")
+	}
+
+	imgOS, err := img.OS()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting lifecycle image OS")
+	}
+	if imgOS != builderOS {
+		return nil, errors.Errorf("lifecycle image %s is for OS %s, but the builder is for OS %s", style.Symbol(imageName), style.Symbol(imgOS), style.Symbol(builderOS))
+	}
+
+	descriptor, err := lifecycleDescriptorFromLabels(img)
+	if err != nil {
+		return nil, err
+	}
+
+	diffID, err := img.TopLayer()
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting top layer of lifecycle image %s", style.Symbol(imageName))
+	}
+
+	archivePath := filepath.Join(c.lifecycleCacheDir, "image", strings.TrimPrefix(diffID, "sha256:"), "lifecycle.tar")
+	if _, err := os.Stat(archivePath); err == nil {
+		c.logger.Debugf("Using cached lifecycle %s", style.Symbol(archivePath))
+		return blob.NewBlob(archivePath), nil
+	}
+
+	layer, err := img.GetLayer(diffID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading top layer of lifecycle image %s", style.Symbol(imageName))
+	}
+	defer layer.Close()
+
+	if err := writeLifecycleArchive(layer, descriptor, archivePath); err != nil {
+		return nil, errors.Wrapf(err, "extracting lifecycle from image %s", style.Symbol(imageName))
+	}
+
+	return blob.NewBlob(archivePath), nil
+}
+
+func lifecycleDescriptorFromLabels(img imgutil.Image) (builder.LifecycleDescriptor, error) {
+	labels := map[string]string{}
+	for _, label := range []string{lifecycleVersionLabel, lifecycleAPIsLabel} {
+		value, err := img.Label(label)
+		if err != nil {
+			return builder.LifecycleDescriptor{}, errors.Wrapf(err, "getting label %s", label)
+		}
+		if value == "" {
+			return builder.LifecycleDescriptor{}, errors.Errorf("lifecycle image %s missing label %s", style.Symbol(img.Name()), style.Symbol(label))
+		}
+		labels[label] = value
+	}
+
+	version, err := semver.NewVersion(labels[lifecycleVersionLabel])
+	if err != nil {
+		return builder.LifecycleDescriptor{}, errors.Wrapf(err, "parsing label %s", lifecycleVersionLabel)
+	}
+
+	var apis builder.LifecycleAPIs
+	if err := json.Unmarshal([]byte(labels[lifecycleAPIsLabel]), &apis); err != nil {
+		return builder.LifecycleDescriptor{}, errors.Wrapf(err, "parsing label %s", lifecycleAPIsLabel)
+	}
+
+	return builder.LifecycleDescriptor{
+		Info: builder.LifecycleInfo{Version: &builder.Version{Version: *version}},
+		APIs: apis,
+	}, nil
+}
+
+// writeLifecycleArchive writes a lifecycle archive, in the layout of a lifecycle release, from a layer of a lifecycle image
+func writeLifecycleArchive(layer io.Reader, descriptor builder.LifecycleDescriptor, archivePath string) error {
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0750); err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(archivePath), "extract-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	var descriptorBuf bytes.Buffer
+	if err := toml.NewEncoder(&descriptorBuf).Encode(descriptor); err != nil {
+		return errors.Wrap(err, "encoding lifecycle descriptor")
+	}
+
+	tw := tar.NewWriter(tmpFile)
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    "lifecycle.toml",
+		Size:    int64(descriptorBuf.Len()),
+		Mode:    0644,
+		ModTime: archive.NormalizedDateTime,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(descriptorBuf.Bytes()); err != nil {
+		return err
+	}
+
+	found := false
+	tr := tar.NewReader(layer)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "reading layer")
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/cnb/")
+		if name != "lifecycle" && !strings.HasPrefix(name, "lifecycle/") {
+			continue
+		}
+		found = true
+
+		header.Name = name
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no lifecycle found in %s", style.Symbol("/cnb/lifecycle"))
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), archivePath)
+}
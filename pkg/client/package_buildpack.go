@@ -21,6 +21,10 @@ const (
 	// Packaging indicator that format of output will be a file on the host filesystem.
 	FormatFile = "file"
 
+	// Packaging indicator that format of output will be a directory on the host filesystem, with a
+	// buildpack.toml and bin/ per buildpack.
+	FormatDir = "dir"
+
 	// CNBExtension is the file extension for a cloud native buildpack tar archive
 	CNBExtension = ".cnb"
 )
@@ -34,7 +38,7 @@ type PackageBuildpackOptions struct {
 	// The name of the output buildpack artifact.
 	Name string
 
-	// Type of output format, The options are the either the const FormatImage, or FormatFile.
+	// Type of output format, The options are the either the const FormatImage, FormatFile, or FormatDir.
 	Format string
 
 	// Defines the Buildpacks configuration.
@@ -62,6 +66,10 @@ func (c *Client) PackageBuildpack(ctx context.Context, opts PackageBuildpackOpti
 		return NewExperimentError("Windows buildpackage support is currently experimental.")
 	}
 
+	if err := c.validatePublish(opts.Publish); err != nil {
+		return err
+	}
+
 	err := c.validateOSPlatform(ctx, opts.Config.Platform.OS, opts.Publish, opts.Format)
 	if err != nil {
 		return err
@@ -72,14 +80,27 @@ func (c *Client) PackageBuildpack(ctx context.Context, opts PackageBuildpackOpti
 		return errors.Wrap(err, "creating layer writer factory")
 	}
 
+	if err := opts.Config.Compression.Validate(); err != nil {
+		return err
+	}
+
+	switch {
+	case opts.Format == FormatImage && !opts.Publish && !opts.Config.Compression.IsDefault():
+		c.logger.Warn("Layer compression only applies to published images, and will be ignored")
+	case opts.Format == FormatDir && !opts.Config.Compression.IsDefault():
+		c.logger.Warn("Layer compression doesn't apply to packages saved as directories, and will be ignored")
+	}
+
 	packageBuilder := buildpack.NewBuilder(c.imageFactory)
+	packageBuilder.SetLayerCompression(opts.Config.Compression)
+	packageBuilder.SetKeychain(c.keychain)
 
 	bpURI := opts.Config.Buildpack.URI
 	if bpURI == "" {
 		return errors.New("buildpack URI must be provided")
 	}
 
-	mainBlob, err := c.downloadBuildpackFromURI(ctx, bpURI, opts.RelativeBaseDir)
+	mainBlob, err := c.downloadBuildpackFromURI(ctx, blob.WithDigest(bpURI, opts.Config.Buildpack.Digest), opts.RelativeBaseDir)
 	if err != nil {
 		return err
 	}
@@ -91,30 +112,58 @@ func (c *Client) PackageBuildpack(ctx context.Context, opts PackageBuildpackOpti
 
 	packageBuilder.SetBuildpack(bp)
 
-	for _, dep := range opts.Config.Dependencies {
-		var depBPs []buildpack.Buildpack
-		mainBP, deps, err := c.buildpackDownloader.Download(ctx, dep.URI, buildpack.DownloadOptions{
-			RegistryName:    opts.Registry,
-			RelativeBaseDir: opts.RelativeBaseDir,
-			ImageOS:         opts.Config.Platform.OS,
-			ImageName:       dep.ImageName,
-			Daemon:          !opts.Publish,
-			PullPolicy:      opts.PullPolicy,
+	depBPs := make([][]buildpack.Buildpack, len(opts.Config.Dependencies))
+	fetches := c.newFetchGroup()
+	for i, dep := range opts.Config.Dependencies {
+		i, dep := i, dep
+		name := dep.URI
+		if name == "" {
+			name = dep.ImageName
+		}
+
+		fetches.Add(name, func(ctx context.Context) error {
+			mainBP, deps, err := c.buildpackDownloader.Download(ctx, blob.WithDigest(dep.URI, dep.Digest), buildpack.DownloadOptions{
+				RegistryName:    opts.Registry,
+				RelativeBaseDir: opts.RelativeBaseDir,
+				ImageOS:         opts.Config.Platform.OS,
+				ImageName:       dep.ImageName,
+				Daemon:          !opts.Publish,
+				PullPolicy:      opts.PullPolicy,
+			})
+
+			if err != nil {
+				return errors.Wrapf(err, "packaging dependencies (uri=%s,image=%s)", style.Symbol(dep.URI), style.Symbol(dep.ImageName))
+			}
+
+			depBPs[i] = append([]buildpack.Buildpack{mainBP}, deps...)
+			return nil
 		})
+	}
 
-		if err != nil {
-			return errors.Wrapf(err, "packaging dependencies (uri=%s,image=%s)", style.Symbol(dep.URI), style.Symbol(dep.ImageName))
-		}
+	if err := fetches.Wait(ctx); err != nil {
+		return err
+	}
 
-		depBPs = append([]buildpack.Buildpack{mainBP}, deps...)
-		for _, depBP := range depBPs {
+	for _, bps := range depBPs {
+		for _, depBP := range bps {
 			packageBuilder.AddDependency(depBP)
 		}
 	}
 
+	tree, err := packageBuilder.DependencyTree()
+	if err != nil {
+		return err
+	}
+	if len(tree.Groups) > 0 {
+		c.logger.Debug("Dependency tree:")
+		c.logDependencyNode(tree, "  ")
+	}
+
 	switch opts.Format {
 	case FormatFile:
 		return packageBuilder.SaveAsFile(opts.Name, opts.Config.Platform.OS)
+	case FormatDir:
+		return packageBuilder.SaveAsDir(opts.Name)
 	case FormatImage:
 		_, err = packageBuilder.SaveAsImage(opts.Name, opts.Publish, opts.Config.Platform.OS)
 		return errors.Wrapf(err, "saving image")
@@ -123,12 +172,32 @@ func (c *Client) PackageBuildpack(ctx context.Context, opts PackageBuildpackOpti
 	}
 }
 
+func (c *Client) logDependencyNode(node buildpack.DependencyNode, indent string) {
+	optional := ""
+	if node.Optional {
+		optional = " (optional)"
+	}
+	c.logger.Debugf("%s%s%s", indent, node.Info.FullName(), optional)
+
+	for i, group := range node.Groups {
+		c.logger.Debugf("%s  Group #%d:", indent, i+1)
+		for _, dep := range group {
+			c.logDependencyNode(dep, indent+"    ")
+		}
+	}
+}
+
 func (c *Client) downloadBuildpackFromURI(ctx context.Context, uri, relativeBaseDir string) (blob.Blob, error) {
+	uri, digest, err := blob.SplitDigest(uri)
+	if err != nil {
+		return nil, err
+	}
+
 	absPath, err := paths.FilePathToURI(uri, relativeBaseDir)
 	if err != nil {
 		return nil, errors.Wrapf(err, "making absolute: %s", style.Symbol(uri))
 	}
-	uri = absPath
+	uri = blob.WithDigest(absPath, digest)
 
 	c.logger.Debugf("Downloading buildpack from URI: %s", style.Symbol(uri))
 	blob, err := c.downloader.Download(ctx, uri)
@@ -140,7 +209,7 @@ func (c *Client) downloadBuildpackFromURI(ctx context.Context, uri, relativeBase
 }
 
 func (c *Client) validateOSPlatform(ctx context.Context, os string, publish bool, format string) error {
-	if publish || format == FormatFile {
+	if publish || format == FormatFile || format == FormatDir {
 		return nil
 	}
 
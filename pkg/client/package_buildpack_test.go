@@ -548,6 +548,85 @@ func testPackageBuildpack(t *testing.T, when spec.G, it spec.S) {
 					assertPackageBPFileHasBuildpacks(t, packagePath, []dist.BuildpackDescriptor{packageDescriptor, childDescriptor})
 				})
 
+				it("writes the buildpacks to a directory with the dir format", func() {
+					packageDir := filepath.Join(tmpDir, "package")
+
+					h.AssertNil(t, subject.PackageBuildpack(context.TODO(), client.PackageBuildpackOptions{
+						Name: packageDir,
+						Config: pubbldpkg.Config{
+							Platform:     dist.Platform{OS: "linux"},
+							Buildpack:    dist.BuildpackURI{URI: createBuildpack(packageDescriptor)},
+							Dependencies: []dist.ImageOrURI{{BuildpackURI: dist.BuildpackURI{URI: createBuildpack(childDescriptor)}}},
+						},
+						PullPolicy: image.PullAlways,
+						Format:     client.FormatDir,
+					}))
+
+					for _, descriptorPath := range []string{"bp.1/1.2.3/buildpack.toml", "bp.nested/2.3.4/buildpack.toml"} {
+						_, err := os.Stat(filepath.Join(packageDir, descriptorPath))
+						h.AssertNil(t, err)
+					}
+				})
+
+				it("prints the dependency tree when verbose", func() {
+					packagePath := filepath.Join(tmpDir, "test.cnb")
+
+					verboseClient, err := client.NewClient(
+						client.WithLogger(logging.NewLogWithWriters(&out, &out, logging.WithVerbose())),
+						client.WithDownloader(mockDownloader),
+						client.WithImageFactory(mockImageFactory),
+						client.WithFetcher(mockImageFetcher),
+						client.WithDockerClient(mockDockerClient),
+					)
+					h.AssertNil(t, err)
+
+					h.AssertNil(t, verboseClient.PackageBuildpack(context.TODO(), client.PackageBuildpackOptions{
+						Name: packagePath,
+						Config: pubbldpkg.Config{
+							Platform:     dist.Platform{OS: "linux"},
+							Buildpack:    dist.BuildpackURI{URI: createBuildpack(packageDescriptor)},
+							Dependencies: []dist.ImageOrURI{{BuildpackURI: dist.BuildpackURI{URI: createBuildpack(childDescriptor)}}},
+						},
+						Publish:    false,
+						PullPolicy: image.PullAlways,
+						Format:     client.FormatFile,
+					}))
+
+					h.AssertContains(t, out.String(), `Dependency tree:
+  bp.1@1.2.3
+    Group #1:
+      bp.nested@2.3.4
+`)
+				})
+
+				when("dependencies form a cycle", func() {
+					it("should error", func() {
+						childDescriptor.Order = dist.Order{{
+							Group: []dist.BuildpackRef{{BuildpackInfo: dist.BuildpackInfo{ID: "bp.1", Version: "1.2.3"}}},
+						}}
+						childDescriptor.Stacks = nil
+						cycleDescriptor := packageDescriptor
+
+						packagePath := filepath.Join(tmpDir, "test.cnb")
+
+						err = subject.PackageBuildpack(context.TODO(), client.PackageBuildpackOptions{
+							Name: packagePath,
+							Config: pubbldpkg.Config{
+								Platform:  dist.Platform{OS: "linux"},
+								Buildpack: dist.BuildpackURI{URI: createBuildpack(packageDescriptor)},
+								Dependencies: []dist.ImageOrURI{
+									{BuildpackURI: dist.BuildpackURI{URI: createBuildpack(childDescriptor)}},
+									{BuildpackURI: dist.BuildpackURI{URI: createBuildpack(cycleDescriptor)}},
+								},
+							},
+							Publish:    false,
+							PullPolicy: image.PullAlways,
+							Format:     client.FormatFile,
+						})
+						h.AssertError(t, err, "buildpack 'bp.1@1.2.3' has a dependency cycle: bp.1@1.2.3 -> bp.nested@2.3.4 -> bp.1@1.2.3")
+					})
+				})
+
 				when("dependency download fails", func() {
 					it("should error", func() {
 						bpURL := fmt.Sprintf("https://example.com/bp.%s.tgz", h.RandString(12))
@@ -795,6 +874,23 @@ func testPackageBuildpack(t *testing.T, when spec.G, it spec.S) {
 			h.AssertError(t, err, "unknown format: 'invalid-format'")
 		})
 	})
+
+	when("an unknown compression is provided", func() {
+		it("should error", func() {
+			err := subject.PackageBuildpack(context.TODO(), client.PackageBuildpackOptions{
+				Name:   "some-buildpack",
+				Format: client.FormatImage,
+				Config: pubbldpkg.Config{
+					Platform:    dist.Platform{OS: "linux"},
+					Buildpack:   dist.BuildpackURI{URI: "https://example.com/bp.tgz"},
+					Compression: dist.LayerCompression{Algorithm: "lz4"},
+				},
+				Publish:    true,
+				PullPolicy: image.PullAlways,
+			})
+			h.AssertError(t, err, "compression algorithm 'lz4' must be one of 'gzip', 'zstd' or 'estargz'")
+		})
+	})
 }
 
 func assertPackageBPFileHasBuildpacks(t *testing.T, path string, descriptors []dist.BuildpackDescriptor) {
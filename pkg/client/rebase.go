@@ -2,18 +2,33 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/Masterminds/semver"
+
+	"github.com/buildpacks/imgutil"
 	"github.com/buildpacks/lifecycle"
 	"github.com/buildpacks/lifecycle/platform"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/pkg/errors"
 
 	"github.com/buildpacks/pack/internal/build"
 	"github.com/buildpacks/pack/internal/builder"
+	"github.com/buildpacks/pack/internal/stack"
+	"github.com/buildpacks/pack/internal/stringset"
 	"github.com/buildpacks/pack/internal/style"
 	"github.com/buildpacks/pack/pkg/dist"
 	"github.com/buildpacks/pack/pkg/image"
 )
 
+// imageVersionLabel is the OCI annotation run images use to record their version.
+const imageVersionLabel = "org.opencontainers.image.version"
+
 // RebaseOptions is a configuration struct that controls image rebase behavior.
 type RebaseOptions struct {
 	// Name of image we wish to rebase.
@@ -34,26 +49,223 @@ type RebaseOptions struct {
 	// AdditionalMirrors gives us inputs to recalculate the 'best' run image
 	// based on the registry we are publishing to.
 	AdditionalMirrors map[string][]string
+
+	// If true, report whether the app image would be rebased and
+	// which run image layers would be swapped, without modifying the image.
+	DryRun bool
+
+	// If true, rebase even if the new run image is older than the
+	// run image the app image is currently based on.
+	Force bool
+}
+
+// RebaseReport describes the changes a rebase would make to an app image.
+type RebaseReport struct {
+	// Name of the run image the app image would be rebased onto.
+	RunImage string
+
+	// Run image currently recorded in the app image metadata.
+	Current platform.RunImageMetadata
+
+	// Run image the app image would be rebased onto.
+	Next platform.RunImageMetadata
+
+	// RemovedLayers are the diff IDs of the run image layers the rebase would remove from the app image, bottom first.
+	RemovedLayers []string
+
+	// AddedLayers are the diff IDs of the run image layers the rebase would add to the app image, bottom first.
+	AddedLayers []string
+}
+
+// RebaseNeeded returns true if rebasing would change the run image layers of the app image.
+func (r RebaseReport) RebaseNeeded() bool {
+	return r.Current.TopLayer != r.Next.TopLayer
 }
 
 // Rebase updates the run image layers in an app image.
-// This operation mutates the image specified in opts.
+// This operation mutates the image specified in opts, unless opts.DryRun is set,
+// in which case it only reports the changes, see CheckRebase.
 func (c *Client) Rebase(ctx context.Context, opts RebaseOptions) error {
-	imageRef, err := c.parseTagReference(opts.RepoName)
+	if opts.DryRun {
+		_, err := c.CheckRebase(ctx, opts)
+		return err
+	}
+
+	if err := c.validatePublish(opts.Publish); err != nil {
+		return err
+	}
+
+	appImage, baseImage, md, err := c.fetchRebaseImages(ctx, opts)
 	if err != nil {
-		return errors.Wrapf(err, "invalid image name '%s'", opts.RepoName)
+		return err
 	}
 
-	appImage, err := c.imageFetcher.Fetch(ctx, opts.RepoName, image.FetchOptions{Daemon: !opts.Publish, PullPolicy: opts.PullPolicy})
+	if err := c.validateRebaseRunImage(ctx, appImage, baseImage, md, opts); err != nil {
+		return err
+	}
+
+	c.logger.Infof("Rebasing %s on run image %s", style.Symbol(appImage.Name()), style.Symbol(baseImage.Name()))
+	rebaser := &lifecycle.Rebaser{Logger: c.logger, PlatformAPI: build.SupportedPlatformAPIVersions.Latest()}
+	_, err = rebaser.Rebase(appImage, baseImage, nil)
+	if err != nil {
+		return err
+	}
+
+	appImageIdentifier, err := appImage.Identifier()
 	if err != nil {
 		return err
 	}
 
+	c.logger.Infof("Rebased Image: %s", style.Symbol(appImageIdentifier.String()))
+	return nil
+}
+
+// CheckRebase compares the run image recorded in an app image with the run image
+// it would be rebased onto, without modifying the app image, and logs the run image
+// layers a rebase would swap. It fails if Rebase would refuse the run image.
+func (c *Client) CheckRebase(ctx context.Context, opts RebaseOptions) (*RebaseReport, error) {
+	appImage, baseImage, md, err := c.fetchRebaseImages(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.validateRebaseRunImage(ctx, appImage, baseImage, md, opts); err != nil {
+		return nil, err
+	}
+
+	report, err := newRebaseReport(baseImage, md)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.RebaseNeeded() {
+		if err := c.addSwappedLayers(ctx, report, appImage, baseImage, !opts.Publish); err != nil {
+			return nil, err
+		}
+	}
+
+	c.logRebaseReport(appImage.Name(), report)
+	return report, nil
+}
+
+// addSwappedLayers adds the run image layers of the app image, i.e. its layers up to the top layer of the
+// current run image, and the layers of the new run image to the report, without the layers they share
+func (c *Client) addSwappedLayers(ctx context.Context, report *RebaseReport, appImage, baseImage imgutil.Image, daemon bool) error {
+	appLayers, err := c.imageDiffIDs(ctx, appImage.Name(), daemon)
+	if err != nil {
+		return errors.Wrapf(err, "listing layers of image %s", style.Symbol(appImage.Name()))
+	}
+
+	baseLayers, err := c.imageDiffIDs(ctx, baseImage.Name(), daemon)
+	if err != nil {
+		return errors.Wrapf(err, "listing layers of run image %s", style.Symbol(baseImage.Name()))
+	}
+
+	var currentLayers []string
+	if report.Current.TopLayer != "" {
+		for i, layer := range appLayers {
+			if layer == report.Current.TopLayer {
+				currentLayers = appLayers[:i+1]
+				break
+			}
+		}
+		if currentLayers == nil {
+			return errors.Errorf("run image top layer %s not found in image %s", style.Symbol(report.Current.TopLayer), style.Symbol(appImage.Name()))
+		}
+	}
+
+	report.RemovedLayers = layersNotIn(currentLayers, baseLayers)
+	report.AddedLayers = layersNotIn(baseLayers, currentLayers)
+	return nil
+}
+
+// imageDiffIDs returns the diff IDs of the layers of an image in the daemon or a registry, bottom first
+func (c *Client) imageDiffIDs(ctx context.Context, imageName string, daemon bool) ([]string, error) {
+	if daemon {
+		inspect, _, err := c.docker.ImageInspectWithRaw(ctx, imageName)
+		if err != nil {
+			return nil, err
+		}
+		return inspect.RootFS.Layers, nil
+	}
+
+	ref, err := name.ParseReference(imageName, name.WeakValidation)
+	if err != nil {
+		return nil, err
+	}
+
+	keychain := c.keychain
+	if keychain == nil {
+		keychain = authn.DefaultKeychain
+	}
+	img, err := remote.Image(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain))
+	if err != nil {
+		return nil, err
+	}
+
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var diffIDs []string
+	for _, diffID := range configFile.RootFS.DiffIDs {
+		diffIDs = append(diffIDs, diffID.String())
+	}
+	return diffIDs, nil
+}
+
+func layersNotIn(layers, others []string) []string {
+	otherSet := stringset.FromSlice(others)
+	var result []string
+	for _, layer := range layers {
+		if _, ok := otherSet[layer]; !ok {
+			result = append(result, layer)
+		}
+	}
+	return result
+}
+
+func (c *Client) logRebaseReport(appImageName string, report *RebaseReport) {
+	c.logger.Infof("Run image: %s", style.Symbol(report.RunImage))
+	c.logger.Infof("  Current top layer: %s", report.Current.TopLayer)
+	c.logger.Infof("  Current reference: %s", report.Current.Reference)
+	c.logger.Infof("  New top layer:     %s", report.Next.TopLayer)
+	c.logger.Infof("  New reference:     %s", report.Next.Reference)
+
+	if !report.RebaseNeeded() {
+		c.logger.Infof("Image %s is up to date with run image %s", style.Symbol(appImageName), style.Symbol(report.RunImage))
+		return
+	}
+
+	c.logger.Infof("Image %s would be rebased on run image %s", style.Symbol(appImageName), style.Symbol(report.RunImage))
+	c.logger.Infof("Layers that would be removed:")
+	for _, layer := range report.RemovedLayers {
+		c.logger.Infof("  %s", layer)
+	}
+	c.logger.Infof("Layers that would be added:")
+	for _, layer := range report.AddedLayers {
+		c.logger.Infof("  %s", layer)
+	}
+}
+
+func (c *Client) fetchRebaseImages(ctx context.Context, opts RebaseOptions) (imgutil.Image, imgutil.Image, platform.LayersMetadataCompat, error) {
 	var md platform.LayersMetadataCompat
+
+	imageRef, err := c.parseTagReference(opts.RepoName)
+	if err != nil {
+		return nil, nil, md, errors.Wrapf(err, "invalid image name '%s'", opts.RepoName)
+	}
+
+	appImage, err := c.imageFetcher.Fetch(ctx, opts.RepoName, image.FetchOptions{Daemon: !opts.Publish, PullPolicy: opts.PullPolicy})
+	if err != nil {
+		return nil, nil, md, err
+	}
+
 	if ok, err := dist.GetLabel(appImage, platform.LayerMetadataLabel, &md); err != nil {
-		return err
+		return nil, nil, md, err
 	} else if !ok {
-		return errors.Errorf("could not find label %s on image", style.Symbol(platform.LayerMetadataLabel))
+		return nil, nil, md, errors.Errorf("could not find label %s on image", style.Symbol(platform.LayerMetadataLabel))
 	}
 
 	runImageName := c.resolveRunImage(
@@ -70,26 +282,199 @@ func (c *Client) Rebase(ctx context.Context, opts RebaseOptions) error {
 		opts.Publish)
 
 	if runImageName == "" {
-		return errors.New("run image must be specified")
+		return nil, nil, md, errors.New("run image must be specified")
 	}
 
 	baseImage, err := c.imageFetcher.Fetch(ctx, runImageName, image.FetchOptions{Daemon: !opts.Publish, PullPolicy: opts.PullPolicy})
 	if err != nil {
-		return err
+		return nil, nil, md, err
 	}
 
-	c.logger.Infof("Rebasing %s on run image %s", style.Symbol(appImage.Name()), style.Symbol(baseImage.Name()))
-	rebaser := &lifecycle.Rebaser{Logger: c.logger, PlatformAPI: build.SupportedPlatformAPIVersions.Latest()}
-	_, err = rebaser.Rebase(appImage, baseImage, nil)
+	return appImage, baseImage, md, nil
+}
+
+func newRebaseReport(baseImage imgutil.Image, md platform.LayersMetadataCompat) (*RebaseReport, error) {
+	topLayer, err := baseImage.TopLayer()
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting top layer of run image %s", style.Symbol(baseImage.Name()))
+	}
+
+	identifier, err := baseImage.Identifier()
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting identifier of run image %s", style.Symbol(baseImage.Name()))
+	}
+
+	return &RebaseReport{
+		RunImage: baseImage.Name(),
+		Current:  md.RunImage,
+		Next: platform.RunImageMetadata{
+			TopLayer:  topLayer,
+			Reference: identifier.String(),
+		},
+	}, nil
+}
+
+func (c *Client) validateRebaseRunImage(ctx context.Context, appImage, baseImage imgutil.Image, md platform.LayersMetadataCompat, opts RebaseOptions) error {
+	if err := validateRebaseCompatibility(appImage, baseImage); err != nil {
+		return errors.Wrapf(err, "invalid run image %s", style.Symbol(baseImage.Name()))
+	}
+
+	if md.RunImage.Reference == "" {
+		return nil
+	}
+
+	// the run image layers wouldn't change, so the run image can't be older
+	if topLayer, err := baseImage.TopLayer(); err == nil && topLayer == md.RunImage.TopLayer {
+		return nil
+	}
+
+	currentImage, err := c.fetchCurrentRunImage(ctx, md.RunImage.Reference, opts.Publish)
+	if err != nil {
+		c.logger.Warnf("Skipping run image age check, current run image %s is not available: %s", style.Symbol(md.RunImage.Reference), err)
+		return nil
+	}
+
+	problems, err := compareRunImageAge(currentImage, baseImage)
 	if err != nil {
 		return err
 	}
 
-	appImageIdentifier, err := appImage.Identifier()
+	for _, problem := range problems {
+		if !opts.Force {
+			return errors.Errorf("%s, refusing to rebase onto an older run image", problem)
+		}
+		c.logger.Warnf("%s", problem)
+	}
+
+	return nil
+}
+
+// fetchCurrentRunImage fetches the run image the app image is based on, from the daemon if it's still
+// there and the app image isn't published, or else from its registry
+func (c *Client) fetchCurrentRunImage(ctx context.Context, reference string, publish bool) (imgutil.Image, error) {
+	// the run image of an app built on the daemon is referenced by its image ID, which no registry can resolve
+	isImageID := strings.HasPrefix(reference, "sha256:")
+
+	if !publish || isImageID {
+		currentImage, err := c.imageFetcher.Fetch(ctx, reference, image.FetchOptions{Daemon: true, PullPolicy: image.PullNever})
+		if err == nil || isImageID {
+			return currentImage, err
+		}
+		c.logger.Debugf("Current run image %s is not available locally, fetching it from the registry: %s", style.Symbol(reference), err)
+	}
+
+	return c.imageFetcher.Fetch(ctx, reference, image.FetchOptions{Daemon: false})
+}
+
+func validateRebaseCompatibility(appImage, baseImage imgutil.Image) error {
+	appStackID, err := appImage.Label(platform.StackIDLabel)
+	if err != nil {
+		return errors.Wrap(err, "getting app image stack")
+	}
+
+	baseStackID, err := baseImage.Label(platform.StackIDLabel)
+	if err != nil {
+		return errors.Wrap(err, "getting run image stack")
+	}
+
+	if baseStackID != appStackID {
+		return errors.Errorf("stack %s does not match app image stack %s", style.Symbol(baseStackID), style.Symbol(appStackID))
+	}
+
+	appOS, err := appImage.OS()
 	if err != nil {
+		return errors.Wrap(err, "getting app image OS")
+	}
+
+	baseOS, err := baseImage.OS()
+	if err != nil {
+		return errors.Wrap(err, "getting run image OS")
+	}
+
+	if baseOS != appOS {
+		return errors.Errorf("OS %s does not match app image OS %s", style.Symbol(baseOS), style.Symbol(appOS))
+	}
+
+	appArch, err := appImage.Architecture()
+	if err != nil {
+		return errors.Wrap(err, "getting app image architecture")
+	}
+
+	baseArch, err := baseImage.Architecture()
+	if err != nil {
+		return errors.Wrap(err, "getting run image architecture")
+	}
+
+	if baseArch != appArch {
+		return errors.Errorf("architecture %s does not match app image architecture %s", style.Symbol(baseArch), style.Symbol(appArch))
+	}
+
+	var appMixins, baseMixins []string
+	if _, err := dist.GetLabel(appImage, stack.MixinsLabel, &appMixins); err != nil {
 		return err
 	}
 
-	c.logger.Infof("Rebased Image: %s", style.Symbol(appImageIdentifier.String()))
+	if _, err := dist.GetLabel(baseImage, stack.MixinsLabel, &baseMixins); err != nil {
+		return err
+	}
+
+	if _, missing, _ := stringset.Compare(removeMixinStages(baseMixins), removeMixinStages(appMixins)); len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.Errorf("missing required mixin(s): %s", strings.Join(missing, ", "))
+	}
+
 	return nil
 }
+
+func removeMixinStages(mixins []string) []string {
+	var result []string
+	for _, m := range mixins {
+		if i := strings.Index(m, ":"); i >= 0 {
+			m = m[i+1:]
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+func compareRunImageAge(currentImage, baseImage imgutil.Image) ([]string, error) {
+	var problems []string
+
+	currentCreated, err := currentImage.CreatedAt()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting current run image creation time")
+	}
+
+	baseCreated, err := baseImage.CreatedAt()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting run image creation time")
+	}
+
+	if baseCreated.Before(currentCreated) {
+		problems = append(problems, fmt.Sprintf("run image %s was created at %s, before the current run image (created at %s)",
+			style.Symbol(baseImage.Name()), baseCreated.Format(time.RFC3339), currentCreated.Format(time.RFC3339)))
+	}
+
+	currentVersion, err := currentImage.Label(imageVersionLabel)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting current run image version")
+	}
+
+	baseVersion, err := baseImage.Label(imageVersionLabel)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting run image version")
+	}
+
+	if currentVersion == "" || baseVersion == "" {
+		return problems, nil
+	}
+
+	currentSemver, currentErr := semver.NewVersion(currentVersion)
+	baseSemver, baseErr := semver.NewVersion(baseVersion)
+	if currentErr == nil && baseErr == nil && baseSemver.LessThan(currentSemver) {
+		problems = append(problems, fmt.Sprintf("run image %s has version %s, older than the current run image version %s",
+			style.Symbol(baseImage.Name()), style.Symbol(baseVersion), style.Symbol(currentVersion)))
+	}
+
+	return problems, nil
+}
@@ -3,9 +3,12 @@ package client
 import (
 	"bytes"
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/buildpacks/imgutil/fakes"
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
 	"github.com/heroku/color"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
@@ -13,6 +16,7 @@ import (
 	ifakes "github.com/buildpacks/pack/internal/fakes"
 	"github.com/buildpacks/pack/pkg/image"
 	"github.com/buildpacks/pack/pkg/logging"
+	"github.com/buildpacks/pack/pkg/testmocks"
 	h "github.com/buildpacks/pack/testhelpers"
 )
 
@@ -30,9 +34,18 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 			fakeAppImage       *fakes.Image
 			fakeRunImage       *fakes.Image
 			fakeRunImageMirror *fakes.Image
+			fakeOldRunImage    *fakes.Image
+			mockController     *gomock.Controller
+			mockDockerClient   *testmocks.MockCommonAPIClient
 			out                bytes.Buffer
 		)
 
+		expectLayers := func(imageName string, layers ...string) {
+			mockDockerClient.EXPECT().
+				ImageInspectWithRaw(gomock.Any(), imageName).
+				Return(types.ImageInspect{RootFS: types.RootFS{Layers: layers}}, nil, nil)
+		}
+
 		it.Before(func() {
 			fakeImageFetcher = ifakes.NewFakeImageFetcher()
 
@@ -42,6 +55,10 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 			h.AssertNil(t, fakeAppImage.SetLabel("io.buildpacks.stack.id", "io.buildpacks.stacks.bionic"))
 			fakeImageFetcher.LocalImages["some/app"] = fakeAppImage
 
+			// created before the other run images, so it's older than each of them
+			fakeOldRunImage = fakes.NewImage("old-digest", "old-top-layer-sha", &fakeIdentifier{name: "old-digest"})
+			fakeImageFetcher.RemoteImages["old-digest"] = fakeOldRunImage
+
 			fakeRunImage = fakes.NewImage("some/run", "run-image-top-layer-sha", &fakeIdentifier{name: "run-image-digest"})
 			h.AssertNil(t, fakeRunImage.SetLabel("io.buildpacks.stack.id", "io.buildpacks.stacks.bionic"))
 			fakeImageFetcher.LocalImages["some/run"] = fakeRunImage
@@ -50,10 +67,14 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 			h.AssertNil(t, fakeRunImageMirror.SetLabel("io.buildpacks.stack.id", "io.buildpacks.stacks.bionic"))
 			fakeImageFetcher.LocalImages["example.com/some/run"] = fakeRunImageMirror
 
+			mockController = gomock.NewController(t)
+			mockDockerClient = testmocks.NewMockCommonAPIClient(mockController)
+
 			fakeLogger := logging.NewLogWithWriters(&out, &out)
 			subject = &Client{
 				logger:       fakeLogger,
 				imageFetcher: fakeImageFetcher,
+				docker:       mockDockerClient,
 			}
 		})
 
@@ -61,6 +82,8 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 			h.AssertNilE(t, fakeAppImage.Cleanup())
 			h.AssertNilE(t, fakeRunImage.Cleanup())
 			h.AssertNilE(t, fakeRunImageMirror.Cleanup())
+			h.AssertNilE(t, fakeOldRunImage.Cleanup())
+			mockController.Finish()
 		})
 
 		when("#Rebase", func() {
@@ -159,6 +182,237 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 				})
 			})
 
+			when("run image compatibility", func() {
+				var fakeOtherRunImage *fakes.Image
+
+				it.Before(func() {
+					fakeOtherRunImage = fakes.NewImage("other/run", "other-top-layer-sha", &fakeIdentifier{name: "other-digest"})
+					h.AssertNil(t, fakeOtherRunImage.SetLabel("io.buildpacks.stack.id", "io.buildpacks.stacks.bionic"))
+					fakeImageFetcher.LocalImages["other/run"] = fakeOtherRunImage
+				})
+
+				it.After(func() {
+					h.AssertNilE(t, fakeOtherRunImage.Cleanup())
+				})
+
+				when("the stack does not match", func() {
+					it("returns an error", func() {
+						h.AssertNil(t, fakeOtherRunImage.SetLabel("io.buildpacks.stack.id", "io.buildpacks.stacks.other"))
+						err := subject.Rebase(context.TODO(), RebaseOptions{
+							RepoName: "some/app",
+							RunImage: "other/run",
+						})
+						h.AssertError(t, err, "invalid run image 'other/run': stack 'io.buildpacks.stacks.other' does not match app image stack 'io.buildpacks.stacks.bionic'")
+						h.AssertEq(t, fakeAppImage.Base(), "")
+					})
+				})
+
+				when("the OS does not match", func() {
+					it("returns an error", func() {
+						h.AssertNil(t, fakeOtherRunImage.SetOS("windows"))
+						err := subject.Rebase(context.TODO(), RebaseOptions{
+							RepoName: "some/app",
+							RunImage: "other/run",
+						})
+						h.AssertError(t, err, "OS 'windows' does not match app image OS 'linux'")
+					})
+				})
+
+				when("the architecture does not match", func() {
+					it("returns an error", func() {
+						h.AssertNil(t, fakeOtherRunImage.SetArchitecture("arm64"))
+						err := subject.Rebase(context.TODO(), RebaseOptions{
+							RepoName: "some/app",
+							RunImage: "other/run",
+						})
+						h.AssertError(t, err, "architecture 'arm64' does not match app image architecture 'amd64'")
+					})
+				})
+
+				when("mixins are missing", func() {
+					it("returns an error", func() {
+						h.AssertNil(t, fakeAppImage.SetLabel("io.buildpacks.stack.mixins", `["mixinA", "run:mixinC"]`))
+						h.AssertNil(t, fakeOtherRunImage.SetLabel("io.buildpacks.stack.mixins", `["mixinA"]`))
+						err := subject.Rebase(context.TODO(), RebaseOptions{
+							RepoName: "some/app",
+							RunImage: "other/run",
+						})
+						h.AssertError(t, err, "missing required mixin(s): mixinC")
+					})
+				})
+
+				when("the run image has a superset of mixins", func() {
+					it("rebases", func() {
+						h.AssertNil(t, fakeAppImage.SetLabel("io.buildpacks.stack.mixins", `["mixinA", "run:mixinB"]`))
+						h.AssertNil(t, fakeOtherRunImage.SetLabel("io.buildpacks.stack.mixins", `["mixinA", "run:mixinB", "mixinD"]`))
+						h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+							RepoName: "some/app",
+							RunImage: "other/run",
+						}))
+						h.AssertEq(t, fakeAppImage.Base(), "other/run")
+					})
+				})
+
+				when("the current run image is available", func() {
+					var fakeCurrentRunImage *fakes.Image
+
+					it.Before(func() {
+						h.AssertNil(t, fakeAppImage.SetLabel("io.buildpacks.lifecycle.metadata",
+							`{"runImage":{"topLayer":"current-top-layer-sha","reference":"current-digest"},"stack":{"runImage":{"image":"some/run"}}}`))
+					})
+
+					it.After(func() {
+						h.AssertNilE(t, fakeCurrentRunImage.Cleanup())
+					})
+
+					when("the new run image is older", func() {
+						it.Before(func() {
+							fakeCurrentRunImage = fakes.NewImage("current-digest", "current-top-layer-sha", &fakeIdentifier{name: "current-digest"})
+							fakeImageFetcher.LocalImages["current-digest"] = fakeCurrentRunImage
+						})
+
+						it("returns an error", func() {
+							err := subject.Rebase(context.TODO(), RebaseOptions{
+								RepoName: "some/app",
+								RunImage: "other/run",
+							})
+							h.AssertError(t, err, "run image 'other/run' was created at")
+							h.AssertError(t, err, "refusing to rebase onto an older run image")
+							h.AssertEq(t, fakeAppImage.Base(), "")
+						})
+
+						when("force is set", func() {
+							it("warns and rebases", func() {
+								h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+									RepoName: "some/app",
+									RunImage: "other/run",
+									Force:    true,
+								}))
+								h.AssertContains(t, out.String(), "Warning: run image 'other/run' was created at")
+								h.AssertEq(t, fakeAppImage.Base(), "other/run")
+							})
+						})
+					})
+
+					when("the new run image has an older version", func() {
+						it.Before(func() {
+							fakeCurrentRunImage = fakes.NewImage("current-digest", "current-top-layer-sha", &fakeIdentifier{name: "current-digest"})
+							fakeImageFetcher.LocalImages["current-digest"] = fakeCurrentRunImage
+							h.AssertNil(t, fakeCurrentRunImage.SetLabel("org.opencontainers.image.version", "1.2.0"))
+
+							fakeNewerRunImage := fakes.NewImage("newer/run", "newer-top-layer-sha", &fakeIdentifier{name: "newer-digest"})
+							h.AssertNil(t, fakeNewerRunImage.SetLabel("io.buildpacks.stack.id", "io.buildpacks.stacks.bionic"))
+							h.AssertNil(t, fakeNewerRunImage.SetLabel("org.opencontainers.image.version", "1.1.9"))
+							fakeImageFetcher.LocalImages["newer/run"] = fakeNewerRunImage
+						})
+
+						it("returns an error", func() {
+							err := subject.Rebase(context.TODO(), RebaseOptions{
+								RepoName: "some/app",
+								RunImage: "newer/run",
+							})
+							h.AssertError(t, err, "run image 'newer/run' has version '1.1.9', older than the current run image version '1.2.0'")
+						})
+					})
+
+					when("the current run image is only available in the registry", func() {
+						it.Before(func() {
+							fakeCurrentRunImage = fakes.NewImage("current-digest", "current-top-layer-sha", &fakeIdentifier{name: "current-digest"})
+							fakeImageFetcher.RemoteImages["current-digest"] = fakeCurrentRunImage
+						})
+
+						it("compares against the image in the registry", func() {
+							err := subject.Rebase(context.TODO(), RebaseOptions{
+								RepoName: "some/app",
+								RunImage: "other/run",
+							})
+							h.AssertError(t, err, "refusing to rebase onto an older run image")
+						})
+					})
+
+					when("the current run image can't be fetched", func() {
+						it.Before(func() {
+							fakeCurrentRunImage = fakes.NewImage("unrelated", "", nil)
+						})
+
+						it("warns and rebases", func() {
+							h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+								RepoName: "some/app",
+								RunImage: "other/run",
+							}))
+							h.AssertContains(t, out.String(), "Warning: Skipping run image age check, current run image 'current-digest' is not available")
+							h.AssertEq(t, fakeAppImage.Base(), "other/run")
+						})
+
+						when("it's referenced by an image ID", func() {
+							it("doesn't look for it in a registry", func() {
+								imageID := "sha256:" + strings.Repeat("a", 64)
+								h.AssertNil(t, fakeAppImage.SetLabel("io.buildpacks.lifecycle.metadata",
+									`{"runImage":{"topLayer":"current-top-layer-sha","reference":"`+imageID+`"},"stack":{"runImage":{"image":"some/run"}}}`))
+
+								h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+									RepoName: "some/app",
+									RunImage: "other/run",
+								}))
+								h.AssertContains(t, out.String(), "Warning: Skipping run image age check")
+								h.AssertEq(t, fakeImageFetcher.FetchCalls[imageID].Daemon, true)
+								h.AssertEq(t, fakeAppImage.Base(), "other/run")
+							})
+						})
+					})
+
+					when("the new run image is newer", func() {
+						it.Before(func() {
+							fakeCurrentRunImage = fakes.NewImage("current-digest", "current-top-layer-sha", &fakeIdentifier{name: "current-digest"})
+							fakeImageFetcher.LocalImages["current-digest"] = fakeCurrentRunImage
+
+							fakeNewerRunImage := fakes.NewImage("newer/run", "newer-top-layer-sha", &fakeIdentifier{name: "newer-digest"})
+							h.AssertNil(t, fakeNewerRunImage.SetLabel("io.buildpacks.stack.id", "io.buildpacks.stacks.bionic"))
+							fakeImageFetcher.LocalImages["newer/run"] = fakeNewerRunImage
+						})
+
+						it("rebases", func() {
+							h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+								RepoName: "some/app",
+								RunImage: "newer/run",
+							}))
+							h.AssertEq(t, fakeAppImage.Base(), "newer/run")
+						})
+					})
+				})
+			})
+
+			when("dry run", func() {
+				it("reports the rebase without modifying the image", func() {
+					expectLayers("some/app", "app-layer-sha")
+					expectLayers("some/run", "run-image-base-layer-sha", "run-image-top-layer-sha")
+
+					h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+						RepoName: "some/app",
+						DryRun:   true,
+					}))
+					h.AssertEq(t, fakeAppImage.Base(), "")
+					h.AssertContains(t, out.String(), "Image 'some/app' would be rebased on run image 'some/run'")
+					h.AssertContains(t, out.String(), "Layers that would be added:\n  run-image-base-layer-sha\n  run-image-top-layer-sha")
+				})
+
+				when("the image is up to date", func() {
+					it.Before(func() {
+						h.AssertNil(t, fakeAppImage.SetLabel("io.buildpacks.lifecycle.metadata",
+							`{"runImage":{"topLayer":"run-image-top-layer-sha","reference":"run-image-digest"},"stack":{"runImage":{"image":"some/run"}}}`))
+					})
+
+					it("reports that no rebase is needed", func() {
+						h.AssertNil(t, subject.Rebase(context.TODO(), RebaseOptions{
+							RepoName: "some/app",
+							DryRun:   true,
+						}))
+						h.AssertEq(t, fakeAppImage.Base(), "")
+						h.AssertContains(t, out.String(), "Image 'some/app' is up to date with run image 'some/run'")
+					})
+				})
+			})
+
 			when("publish", func() {
 				var (
 					fakeRemoteRunImage *fakes.Image
@@ -219,6 +473,95 @@ func testRebase(t *testing.T, when spec.G, it spec.S) {
 				})
 			})
 		})
+
+		when("#CheckRebase", func() {
+			when("the run image has changed", func() {
+				it.Before(func() {
+					h.AssertNil(t, fakeAppImage.SetLabel("io.buildpacks.lifecycle.metadata",
+						`{"runImage":{"topLayer":"old-top-layer-sha","reference":"old-digest"},"stack":{"runImage":{"image":"some/run"}}}`))
+				})
+
+				it("reports that a rebase is needed", func() {
+					expectLayers("some/app", "shared-layer-sha", "old-top-layer-sha", "app-layer-sha")
+					expectLayers("some/run", "shared-layer-sha", "run-image-top-layer-sha")
+
+					report, err := subject.CheckRebase(context.TODO(), RebaseOptions{
+						RepoName: "some/app",
+					})
+					h.AssertNil(t, err)
+					h.AssertEq(t, report.RunImage, "some/run")
+					h.AssertEq(t, report.Current.TopLayer, "old-top-layer-sha")
+					h.AssertEq(t, report.Current.Reference, "old-digest")
+					h.AssertEq(t, report.Next.TopLayer, "run-image-top-layer-sha")
+					h.AssertEq(t, report.Next.Reference, "run-image-digest")
+					h.AssertEq(t, report.RebaseNeeded(), true)
+					h.AssertEq(t, report.RemovedLayers, []string{"old-top-layer-sha"})
+					h.AssertEq(t, report.AddedLayers, []string{"run-image-top-layer-sha"})
+					h.AssertEq(t, fakeAppImage.Base(), "")
+					h.AssertContains(t, out.String(), "Layers that would be removed:\n  old-top-layer-sha\nLayers that would be added:\n  run-image-top-layer-sha")
+				})
+
+				when("the app image doesn't contain the top layer of its run image", func() {
+					it("returns an error", func() {
+						expectLayers("some/app", "app-layer-sha")
+						expectLayers("some/run", "run-image-top-layer-sha")
+
+						_, err := subject.CheckRebase(context.TODO(), RebaseOptions{
+							RepoName: "some/app",
+						})
+						h.AssertError(t, err, "run image top layer 'old-top-layer-sha' not found in image 'some/app'")
+					})
+				})
+			})
+
+			when("the run image is older than the current run image", func() {
+				it.Before(func() {
+					h.AssertNil(t, fakeAppImage.SetLabel("io.buildpacks.lifecycle.metadata",
+						`{"runImage":{"topLayer":"mirror-top-layer-sha","reference":"mirror-digest"},"stack":{"runImage":{"image":"some/run"}}}`))
+					fakeImageFetcher.RemoteImages["mirror-digest"] = fakeRunImageMirror
+				})
+
+				it("returns the error the rebase would fail with", func() {
+					_, err := subject.CheckRebase(context.TODO(), RebaseOptions{
+						RepoName: "some/app",
+					})
+					h.AssertError(t, err, "refusing to rebase onto an older run image")
+				})
+			})
+
+			when("the run image is unchanged", func() {
+				it.Before(func() {
+					h.AssertNil(t, fakeAppImage.SetLabel("io.buildpacks.lifecycle.metadata",
+						`{"runImage":{"topLayer":"run-image-top-layer-sha","reference":"run-image-digest"},"stack":{"runImage":{"image":"some/run"}}}`))
+				})
+
+				it("reports that no rebase is needed", func() {
+					report, err := subject.CheckRebase(context.TODO(), RebaseOptions{
+						RepoName: "some/app",
+					})
+					h.AssertNil(t, err)
+					h.AssertEq(t, report.RebaseNeeded(), false)
+				})
+			})
+
+			when("a mirror matches the app image registry", func() {
+				it.Before(func() {
+					fakeImageFetcher.LocalImages["example.com/some/app"] = fakeAppImage
+				})
+
+				it("compares against the mirror", func() {
+					expectLayers("some/app", "app-layer-sha")
+					expectLayers("example.com/some/run", "mirror-top-layer-sha")
+
+					report, err := subject.CheckRebase(context.TODO(), RebaseOptions{
+						RepoName: "example.com/some/app",
+					})
+					h.AssertNil(t, err)
+					h.AssertEq(t, report.RunImage, "example.com/some/run")
+					h.AssertEq(t, report.Next.TopLayer, "mirror-top-layer-sha")
+				})
+			})
+		})
 	})
 }
 
@@ -77,7 +77,7 @@ func (c *Client) RegisterBuildpack(ctx context.Context, opts RegisterBuildpackOp
 
 		return cmd.Start()
 	} else if opts.Type == "git" {
-		registryCache, err := getRegistry(c.logger, opts.Name)
+		registryCache, err := getRegistry(c.logger, opts.Name, c.offline)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/style"
+	projectTypes "github.com/buildpacks/pack/pkg/project/types"
+)
+
+// the id of a secret is the name of its file in the secrets dir of the build containers
+var secretIDRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Secret is a file on the host made available to buildpacks during detect and build,
+// at /platform/secrets/<ID>. Secrets are never part of the cache or the app image,
+// and their values are redacted from the output of the build.
+type Secret struct {
+	// ID names the secret, it must be usable as a file name.
+	ID string
+
+	// Source is the path of the file with the contents of the secret.
+	Source string
+}
+
+// processSecrets reads the secrets of the project descriptor and the given secrets, which override
+// secrets of the descriptor with the same id. Relative sources of the descriptor are resolved against baseDir.
+func (c *Client) processSecrets(secrets []Secret, descriptor []projectTypes.Secret, baseDir string) (map[string][]byte, error) {
+	var all []Secret
+	for _, secret := range descriptor {
+		source := expandHome(secret.Source)
+		if !filepath.IsAbs(source) && baseDir != "" {
+			source = filepath.Join(baseDir, source)
+		}
+		all = append(all, Secret{ID: secret.ID, Source: source})
+	}
+	all = append(all, secrets...)
+
+	if len(all) == 0 {
+		return nil, nil
+	}
+
+	// the secrets are bind-mounted from this host, a remote daemon can't see them
+	if host := c.docker.DaemonHost(); !strings.HasPrefix(host, "unix://") && !strings.HasPrefix(host, "npipe://") {
+		return nil, errors.Errorf("secrets require a local docker daemon, the daemon at %s is remote", style.Symbol(host))
+	}
+
+	contents := map[string][]byte{}
+	for _, secret := range all {
+		if !secretIDRegexp.MatchString(secret.ID) {
+			return nil, errors.Errorf("invalid secret id %s, it must start with a letter or digit and contain only letters, digits, '_', '.' and '-'", style.Symbol(secret.ID))
+		}
+
+		data, err := ioutil.ReadFile(expandHome(secret.Source))
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading secret %s", style.Symbol(secret.ID))
+		}
+		contents[secret.ID] = data
+	}
+	return contents, nil
+}
+
+// expandHome replaces a leading ~ of path with the home directory of the current user
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
@@ -0,0 +1,64 @@
+package dist
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks/pack/internal/style"
+)
+
+const (
+	CompressionGzip    = "gzip"
+	CompressionZstd    = "zstd"
+	CompressionEstargz = "estargz"
+)
+
+// LayerCompression is how the layers of a buildpack package or builder are compressed
+type LayerCompression struct {
+	// Algorithm is one of gzip (the default), zstd or estargz. zstd and estargz aren't supported for builders yet.
+	Algorithm string `toml:"algorithm,omitempty" json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+
+	// Level is the compression level of the algorithm, 1-9 for gzip and estargz and 1-22 for zstd,
+	// or 0 for the default level of the algorithm
+	Level int `toml:"level,omitempty" json:"level,omitempty" yaml:"level,omitempty"`
+}
+
+// IsDefault returns true if layers are compressed with gzip at its default level
+func (c LayerCompression) IsDefault() bool {
+	return (c.Algorithm == "" || c.Algorithm == CompressionGzip) && c.Level == 0
+}
+
+// IsGzip returns true if layers are compressed with plain gzip, at any level
+func (c LayerCompression) IsGzip() bool {
+	return c.Algorithm == "" || c.Algorithm == CompressionGzip
+}
+
+// Validate returns an error if the algorithm is unknown, or the level is out of its range
+func (c LayerCompression) Validate() error {
+	maxLevel := 9
+	switch c.Algorithm {
+	case "", CompressionGzip, CompressionEstargz:
+	case CompressionZstd:
+		maxLevel = 22
+	default:
+		return errors.Errorf(
+			"compression algorithm %s must be one of %s, %s or %s",
+			style.Symbol(c.Algorithm),
+			style.Symbol(CompressionGzip),
+			style.Symbol(CompressionZstd),
+			style.Symbol(CompressionEstargz),
+		)
+	}
+
+	if c.Level < 0 || c.Level > maxLevel {
+		return errors.Errorf("compression level for %s must be between 1 and %d", style.Symbol(c.algorithm()), maxLevel)
+	}
+
+	return nil
+}
+
+func (c LayerCompression) algorithm() string {
+	if c.Algorithm == "" {
+		return CompressionGzip
+	}
+	return c.Algorithm
+}
@@ -8,6 +8,9 @@ const BuildpackLayersLabel = "io.buildpacks.buildpack.layers"
 
 type BuildpackURI struct {
 	URI string `toml:"uri"`
+
+	// Digest pins a buildpack downloaded from a file or URL to its contents, in the format 'sha256:<hex>'
+	Digest string `toml:"digest,omitempty"`
 }
 
 type ImageRef struct {
@@ -1,11 +1,13 @@
 package image
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/buildpacks/imgutil"
 	"github.com/buildpacks/imgutil/local"
@@ -40,11 +42,30 @@ func WithKeychain(keychain authn.Keychain) FetcherOption {
 	}
 }
 
+// WithPullRecords supply records of when images were last pulled, used by interval based pull policies.
+func WithPullRecords(pullRecords *PullRecords) FetcherOption {
+	return func(c *Fetcher) {
+		c.pullRecords = pullRecords
+	}
+}
+
+// WithOffline sets whether images are only fetched from the daemon, as if the pull policy was always PullNever.
+func WithOffline(offline bool) FetcherOption {
+	return func(c *Fetcher) {
+		c.offline = offline
+	}
+}
+
 type Fetcher struct {
 	docker          client.CommonAPIClient
 	logger          logging.Logger
 	registryMirrors map[string]string
 	keychain        authn.Keychain
+	pullRecords     *PullRecords
+	offline         bool
+
+	// held by the pull whose progress is written, so that the progress of concurrent pulls doesn't interleave
+	progressLock chan struct{}
 }
 
 type FetchOptions struct {
@@ -55,9 +76,10 @@ type FetchOptions struct {
 
 func NewFetcher(logger logging.Logger, docker client.CommonAPIClient, opts ...FetcherOption) *Fetcher {
 	fetcher := &Fetcher{
-		logger:   logger,
-		docker:   docker,
-		keychain: authn.DefaultKeychain,
+		logger:       logger,
+		docker:       docker,
+		keychain:     authn.DefaultKeychain,
+		progressLock: make(chan struct{}, 1),
 	}
 
 	for _, opt := range opts {
@@ -75,10 +97,15 @@ func (f *Fetcher) Fetch(ctx context.Context, name string, options FetchOptions)
 		return nil, err
 	}
 
+	if f.offline {
+		return f.fetchOfflineImage(name, options)
+	}
+
 	if !options.Daemon {
 		return f.fetchRemoteImage(name)
 	}
 
+	var localImage imgutil.Image
 	switch options.PullPolicy {
 	case PullNever:
 		img, err := f.fetchDaemonImage(name)
@@ -88,17 +115,68 @@ func (f *Fetcher) Fetch(ctx context.Context, name string, options FetchOptions)
 		if err == nil || !errors.Is(err, ErrNotFound) {
 			return img, err
 		}
+	case PullHourly, PullDaily, PullWeekly:
+		img, err := f.fetchDaemonImage(name)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		if err == nil && f.isFresh(name, options.PullPolicy) {
+			return img, nil
+		}
+		localImage = img
 	}
 
 	f.logger.Debugf("Pulling image %s", style.Symbol(name))
 	err = f.pullImage(ctx, name, options.Platform)
 	if err != nil && !errors.Is(err, ErrNotFound) {
+		// a stale image is still better than none, interval policies don't need the latest image
+		if localImage != nil {
+			f.logger.Warnf("Unable to pull image %s, using the image on the daemon: %s", style.Symbol(name), err)
+			return localImage, nil
+		}
 		return nil, err
 	}
 
+	if err == nil && f.pullRecords != nil {
+		if err := f.pullRecords.Record(name, time.Now()); err != nil {
+			f.logger.Warnf("Unable to record pull of image %s: %s", style.Symbol(name), err)
+		}
+	}
+
 	return f.fetchDaemonImage(name)
 }
 
+func (f *Fetcher) isFresh(name string, policy PullPolicy) bool {
+	if f.pullRecords == nil {
+		return false
+	}
+
+	lastPulled, err := f.pullRecords.LastPulled(name)
+	if err != nil {
+		f.logger.Warnf("Unable to read pull records: %s", err)
+		return false
+	}
+
+	if time.Since(lastPulled) > policy.Interval() {
+		return false
+	}
+
+	f.logger.Debugf("Image %s was pulled at %s, skipping pull", style.Symbol(name), lastPulled.Format(time.RFC3339))
+	return true
+}
+
+func (f *Fetcher) fetchOfflineImage(name string, options FetchOptions) (imgutil.Image, error) {
+	if !options.Daemon {
+		return nil, errors.Errorf("image %s can't be fetched from a registry in offline mode", style.Symbol(name))
+	}
+
+	img, err := f.fetchDaemonImage(name)
+	if errors.Is(err, ErrNotFound) {
+		return nil, errors.Wrapf(ErrNotFound, "image %s does not exist on the daemon and can't be pulled in offline mode", style.Symbol(name))
+	}
+	return img, err
+}
+
 func (f *Fetcher) fetchDaemonImage(name string) (imgutil.Image, error) {
 	image, err := local.NewImage(name, f.docker, local.FromBaseImage(name))
 	if err != nil {
@@ -140,15 +218,36 @@ func (f *Fetcher) pullImage(ctx context.Context, imageID string, platform string
 		return err
 	}
 
+	if err := f.displayPullProgress(rc); err != nil {
+		return err
+	}
+
+	return rc.Close()
+}
+
+// displayPullProgress writes the progress of a pull as it goes, unless another pull is writing its progress.
+// The progress of a pull running alongside is buffered and written once both are done, without the terminal
+// updates, so the progress of concurrent pulls never interleaves.
+func (f *Fetcher) displayPullProgress(rc io.Reader) error {
 	writer := logging.GetWriterForLevel(f.logger, logging.InfoLevel)
-	termFd, isTerm := term.IsTerminal(writer)
 
-	err = jsonmessage.DisplayJSONMessagesStream(rc, &colorizedWriter{writer}, termFd, isTerm, nil)
-	if err != nil {
+	select {
+	case f.progressLock <- struct{}{}:
+		defer func() { <-f.progressLock }()
+		termFd, isTerm := term.IsTerminal(writer)
+		return jsonmessage.DisplayJSONMessagesStream(rc, &colorizedWriter{writer}, termFd, isTerm, nil)
+	default:
+	}
+
+	var progress bytes.Buffer
+	if err := jsonmessage.DisplayJSONMessagesStream(rc, &progress, 0, false, nil); err != nil {
 		return err
 	}
 
-	return rc.Close()
+	f.progressLock <- struct{}{}
+	defer func() { <-f.progressLock }()
+	_, err := io.Copy(&colorizedWriter{writer}, &progress)
+	return err
 }
 
 func (f *Fetcher) registryAuth(ref string) (string, error) {
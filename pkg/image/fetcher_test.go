@@ -4,21 +4,30 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/buildpacks/imgutil/local"
 	"github.com/buildpacks/imgutil/remote"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/golang/mock/gomock"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/heroku/color"
+	"github.com/pkg/errors"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 
 	"github.com/buildpacks/pack/pkg/image"
 	"github.com/buildpacks/pack/pkg/logging"
+	"github.com/buildpacks/pack/pkg/testmocks"
 	h "github.com/buildpacks/pack/testhelpers"
 )
 
@@ -141,6 +150,46 @@ func testFetcher(t *testing.T, when spec.G, it spec.S) {
 				})
 			})
 
+			when("offline", func() {
+				it.Before(func() {
+					imageFetcher = image.NewFetcher(logging.NewLogWithWriters(&outBuf, &outBuf), docker, image.WithOffline(true))
+				})
+
+				when("there is a local image", func() {
+					it.Before(func() {
+						repoName = "invalidhost" + repoName
+
+						img, err := local.NewImage(repoName, docker)
+						h.AssertNil(t, err)
+
+						h.AssertNil(t, img.Save())
+					})
+
+					it.After(func() {
+						h.DockerRmi(docker, repoName)
+					})
+
+					it("returns the local image regardless of the pull policy", func() {
+						_, err := imageFetcher.Fetch(context.TODO(), repoName, image.FetchOptions{Daemon: true, PullPolicy: image.PullAlways})
+						h.AssertNil(t, err)
+					})
+				})
+
+				when("there is no local image", func() {
+					it("returns an error without pulling", func() {
+						_, err := imageFetcher.Fetch(context.TODO(), repoName, image.FetchOptions{Daemon: true, PullPolicy: image.PullAlways})
+						h.AssertError(t, err, fmt.Sprintf("image '%s' does not exist on the daemon and can't be pulled in offline mode", repoName))
+					})
+				})
+
+				when("daemon is false", func() {
+					it("returns an error", func() {
+						_, err := imageFetcher.Fetch(context.TODO(), repoName, image.FetchOptions{Daemon: false, PullPolicy: image.PullIfNotPresent})
+						h.AssertError(t, err, fmt.Sprintf("image '%s' can't be fetched from a registry in offline mode", repoName))
+					})
+				})
+			})
+
 			when("PullAlways", func() {
 				when("there is a remote image", func() {
 					var (
@@ -327,3 +376,117 @@ func testFetcher(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 }
+
+func TestFetcherIntervalPullPolicies(t *testing.T) {
+	color.Disable(true)
+	defer color.Disable(false)
+
+	spec.Run(t, "FetcherIntervalPullPolicies", testFetcherIntervalPullPolicies, spec.Report(report.Terminal{}))
+}
+
+func testFetcherIntervalPullPolicies(t *testing.T, when spec.G, it spec.S) {
+	var (
+		mockController   *gomock.Controller
+		mockDockerClient *testmocks.MockCommonAPIClient
+		pullRecords      *image.PullRecords
+		imageFetcher     *image.Fetcher
+		tmpDir           string
+		outBuf           bytes.Buffer
+		imageName        = "some.registry/some/image:latest"
+	)
+
+	var expectLocalImage = func(found bool) {
+		if found {
+			mockDockerClient.EXPECT().ImageInspectWithRaw(gomock.Any(), imageName).
+				Return(types.ImageInspect{ID: "sha256:some-id", Os: "linux", Config: &container.Config{}}, nil, nil).AnyTimes()
+		} else {
+			mockDockerClient.EXPECT().ImageInspectWithRaw(gomock.Any(), imageName).
+				Return(types.ImageInspect{}, nil, errdefs.NotFound(errors.New("no such image"))).AnyTimes()
+		}
+	}
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "fetcher-pull-records")
+		h.AssertNil(t, err)
+
+		mockController = gomock.NewController(t)
+		mockDockerClient = testmocks.NewMockCommonAPIClient(mockController)
+		mockDockerClient.EXPECT().Info(gomock.Any()).Return(types.Info{OSType: "linux"}, nil).AnyTimes()
+
+		pullRecords = image.NewPullRecords(filepath.Join(tmpDir, "image-pull-records.json"))
+		imageFetcher = image.NewFetcher(
+			logging.NewLogWithWriters(&outBuf, &outBuf),
+			mockDockerClient,
+			image.WithPullRecords(pullRecords),
+			image.WithKeychain(authn.NewMultiKeychain()),
+		)
+	})
+
+	it.After(func() {
+		mockController.Finish()
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	when("the image was pulled within the interval", func() {
+		it.Before(func() {
+			h.AssertNil(t, pullRecords.Record(imageName, time.Now().Add(-time.Hour)))
+		})
+
+		it("returns the local image without pulling", func() {
+			expectLocalImage(true)
+
+			img, err := imageFetcher.Fetch(context.TODO(), imageName, image.FetchOptions{Daemon: true, PullPolicy: image.PullDaily})
+			h.AssertNil(t, err)
+			h.AssertTrue(t, img.Found())
+		})
+	})
+
+	when("the image was pulled before the interval", func() {
+		var pulledAt = time.Now().Add(-48 * time.Hour)
+
+		it.Before(func() {
+			h.AssertNil(t, pullRecords.Record(imageName, pulledAt))
+		})
+
+		it("pulls the image and records the pull", func() {
+			expectLocalImage(true)
+			mockDockerClient.EXPECT().ImagePull(gomock.Any(), imageName, gomock.Any()).
+				Return(ioutil.NopCloser(strings.NewReader(`{"status":"Pull complete"}`)), nil)
+
+			_, err := imageFetcher.Fetch(context.TODO(), imageName, image.FetchOptions{Daemon: true, PullPolicy: image.PullDaily})
+			h.AssertNil(t, err)
+
+			lastPulled, err := pullRecords.LastPulled(imageName)
+			h.AssertNil(t, err)
+			h.AssertTrue(t, lastPulled.After(pulledAt))
+		})
+
+		when("the pull fails", func() {
+			it.Before(func() {
+				mockDockerClient.EXPECT().ImagePull(gomock.Any(), imageName, gomock.Any()).
+					Return(nil, errors.New("registry unavailable"))
+			})
+
+			it("falls back to the local image", func() {
+				expectLocalImage(true)
+
+				img, err := imageFetcher.Fetch(context.TODO(), imageName, image.FetchOptions{Daemon: true, PullPolicy: image.PullDaily})
+				h.AssertNil(t, err)
+				h.AssertTrue(t, img.Found())
+				h.AssertContains(t, outBuf.String(), "Unable to pull image 'some.registry/some/image:latest', using the image on the daemon: registry unavailable")
+
+				lastPulled, err := pullRecords.LastPulled(imageName)
+				h.AssertNil(t, err)
+				h.AssertTrue(t, lastPulled.Equal(pulledAt))
+			})
+
+			it("returns the error when there is no local image", func() {
+				expectLocalImage(false)
+
+				_, err := imageFetcher.Fetch(context.TODO(), imageName, image.FetchOptions{Daemon: true, PullPolicy: image.PullDaily})
+				h.AssertError(t, err, "registry unavailable")
+			})
+		})
+	})
+}
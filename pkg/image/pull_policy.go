@@ -1,6 +1,8 @@
 package image
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 )
 
@@ -14,9 +16,23 @@ const (
 	PullNever
 	// PullIfNotPresent pulls images if they aren't present
 	PullIfNotPresent
+	// PullHourly pulls images if they aren't present or were last pulled more than an hour ago
+	PullHourly
+	// PullDaily pulls images if they aren't present or were last pulled more than a day ago
+	PullDaily
+	// PullWeekly pulls images if they aren't present or were last pulled more than a week ago
+	PullWeekly
 )
 
-var nameMap = map[string]PullPolicy{"always": PullAlways, "never": PullNever, "if-not-present": PullIfNotPresent, "": PullAlways}
+var nameMap = map[string]PullPolicy{
+	"always":         PullAlways,
+	"never":          PullNever,
+	"if-not-present": PullIfNotPresent,
+	"hourly":         PullHourly,
+	"daily":          PullDaily,
+	"weekly":         PullWeekly,
+	"":               PullAlways,
+}
 
 // ParsePullPolicy from string
 func ParsePullPolicy(policy string) (PullPolicy, error) {
@@ -35,7 +51,28 @@ func (p PullPolicy) String() string {
 		return "never"
 	case PullIfNotPresent:
 		return "if-not-present"
+	case PullHourly:
+		return "hourly"
+	case PullDaily:
+		return "daily"
+	case PullWeekly:
+		return "weekly"
 	}
 
 	return ""
 }
+
+// Interval returns how long a pulled image is considered fresh under the policy,
+// or zero if the policy is not interval based.
+func (p PullPolicy) Interval() time.Duration {
+	switch p {
+	case PullHourly:
+		return time.Hour
+	case PullDaily:
+		return 24 * time.Hour
+	case PullWeekly:
+		return 7 * 24 * time.Hour
+	}
+
+	return 0
+}
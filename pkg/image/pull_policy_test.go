@@ -2,6 +2,7 @@ package image_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
@@ -34,6 +35,24 @@ func testPullPolicy(t *testing.T, when spec.G, it spec.S) {
 			h.AssertEq(t, policy, image.PullIfNotPresent)
 		})
 
+		it("returns PullHourly for hourly", func() {
+			policy, err := image.ParsePullPolicy("hourly")
+			h.AssertNil(t, err)
+			h.AssertEq(t, policy, image.PullHourly)
+		})
+
+		it("returns PullDaily for daily", func() {
+			policy, err := image.ParsePullPolicy("daily")
+			h.AssertNil(t, err)
+			h.AssertEq(t, policy, image.PullDaily)
+		})
+
+		it("returns PullWeekly for weekly", func() {
+			policy, err := image.ParsePullPolicy("weekly")
+			h.AssertNil(t, err)
+			h.AssertEq(t, policy, image.PullWeekly)
+		})
+
 		it("defaults to PullAlways, if empty string", func() {
 			policy, err := image.ParsePullPolicy("")
 			h.AssertNil(t, err)
@@ -51,6 +70,23 @@ func testPullPolicy(t *testing.T, when spec.G, it spec.S) {
 			h.AssertEq(t, image.PullAlways.String(), "always")
 			h.AssertEq(t, image.PullNever.String(), "never")
 			h.AssertEq(t, image.PullIfNotPresent.String(), "if-not-present")
+			h.AssertEq(t, image.PullHourly.String(), "hourly")
+			h.AssertEq(t, image.PullDaily.String(), "daily")
+			h.AssertEq(t, image.PullWeekly.String(), "weekly")
+		})
+	})
+
+	when("#Interval", func() {
+		it("returns the interval of interval based policies", func() {
+			h.AssertEq(t, image.PullHourly.Interval(), time.Hour)
+			h.AssertEq(t, image.PullDaily.Interval(), 24*time.Hour)
+			h.AssertEq(t, image.PullWeekly.Interval(), 7*24*time.Hour)
+		})
+
+		it("returns zero for other policies", func() {
+			h.AssertEq(t, image.PullAlways.Interval(), time.Duration(0))
+			h.AssertEq(t, image.PullNever.Interval(), time.Duration(0))
+			h.AssertEq(t, image.PullIfNotPresent.Interval(), time.Duration(0))
 		})
 	})
 }
@@ -0,0 +1,151 @@
+package image
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
+)
+
+const (
+	// pullRecordsLockTimeout is how long to wait for another process to release the pull records
+	pullRecordsLockTimeout = 10 * time.Second
+
+	// pullRecordsStaleLock is the age of a lock file that is considered left behind by a process that exited
+	pullRecordsStaleLock = time.Minute
+)
+
+// PullRecords keeps track of when images were last pulled, so that interval based
+// pull policies can skip pulling images that are still fresh. The records are shared
+// by pack processes running at the same time, which update them under a lock file.
+type PullRecords struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewPullRecords returns PullRecords stored in the file at path.
+func NewPullRecords(path string) *PullRecords {
+	return &PullRecords{path: path}
+}
+
+// LastPulled returns the time the image with the given name was last pulled,
+// or the zero time if no pull was recorded.
+func (r *PullRecords) LastPulled(name string) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records, err := r.read()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return records[pullRecordKey(name)], nil
+}
+
+// Record stores the time the image with the given name was pulled.
+func (r *PullRecords) Record(name string, pulledAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0750); err != nil {
+		return errors.Wrap(err, "creating pull records directory")
+	}
+
+	unlock, err := r.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	records, err := r.read()
+	if err != nil {
+		return err
+	}
+	records[pullRecordKey(name)] = pulledAt
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshalling pull records")
+	}
+
+	return errors.Wrap(r.write(data), "writing pull records")
+}
+
+// pullRecordKey returns the fully qualified name of an image, so that names referring to the same image,
+// e.g. ubuntu and docker.io/library/ubuntu:latest, share a record
+func pullRecordKey(imageName string) string {
+	ref, err := name.ParseReference(imageName, name.WeakValidation)
+	if err != nil {
+		return imageName
+	}
+	return ref.Name()
+}
+
+// lock creates the lock file of the records, waiting for other processes that hold it, and returns a
+// function that removes it
+func (r *PullRecords) lock() (func(), error) {
+	lockPath := r.path + ".lock"
+	deadline := time.Now().Add(pullRecordsLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, errors.Wrap(err, "locking pull records")
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > pullRecordsStaleLock {
+			os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errors.Errorf("timed out waiting for lock on pull records at %s", lockPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// write replaces the records file with data through a rename, so that readers never see a partial file
+func (r *PullRecords) write(data []byte) error {
+	tmpFile, err := ioutil.TempFile(filepath.Dir(r.path), filepath.Base(r.path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), r.path)
+}
+
+func (r *PullRecords) read() (map[string]time.Time, error) {
+	records := map[string]time.Time{}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return records, nil
+		}
+		return nil, errors.Wrap(err, "reading pull records")
+	}
+
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, errors.Wrapf(err, "parsing pull records at %s", r.path)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,135 @@
+package image_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/buildpacks/pack/pkg/image"
+	h "github.com/buildpacks/pack/testhelpers"
+)
+
+func TestPullRecords(t *testing.T) {
+	spec.Run(t, "PullRecords", testPullRecords, spec.Parallel(), spec.Report(report.Terminal{}))
+}
+
+func testPullRecords(t *testing.T, when spec.G, it spec.S) {
+	var (
+		tmpDir  string
+		subject *image.PullRecords
+	)
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "pull-records")
+		h.AssertNil(t, err)
+		subject = image.NewPullRecords(filepath.Join(tmpDir, "nested", "image-pull-records.json"))
+	})
+
+	it.After(func() {
+		h.AssertNil(t, os.RemoveAll(tmpDir))
+	})
+
+	when("#LastPulled", func() {
+		it("returns the zero time when no pull was recorded", func() {
+			lastPulled, err := subject.LastPulled("some/image")
+			h.AssertNil(t, err)
+			h.AssertTrue(t, lastPulled.IsZero())
+		})
+
+		it("returns an error when the records can't be parsed", func() {
+			path := filepath.Join(tmpDir, "invalid.json")
+			h.AssertNil(t, os.WriteFile(path, []byte("not json"), 0600))
+
+			_, err := image.NewPullRecords(path).LastPulled("some/image")
+			h.AssertError(t, err, "parsing pull records")
+		})
+	})
+
+	when("#Record", func() {
+		it("stores the pull time per image", func() {
+			pulledAt := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+			h.AssertNil(t, subject.Record("some/image", pulledAt))
+			h.AssertNil(t, subject.Record("other/image", pulledAt.Add(time.Hour)))
+
+			lastPulled, err := subject.LastPulled("some/image")
+			h.AssertNil(t, err)
+			h.AssertTrue(t, lastPulled.Equal(pulledAt))
+
+			lastPulled, err = subject.LastPulled("other/image")
+			h.AssertNil(t, err)
+			h.AssertTrue(t, lastPulled.Equal(pulledAt.Add(time.Hour)))
+		})
+
+		it("overwrites previous records", func() {
+			pulledAt := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+			h.AssertNil(t, subject.Record("some/image", pulledAt))
+			h.AssertNil(t, subject.Record("some/image", pulledAt.Add(time.Hour)))
+
+			lastPulled, err := subject.LastPulled("some/image")
+			h.AssertNil(t, err)
+			h.AssertTrue(t, lastPulled.Equal(pulledAt.Add(time.Hour)))
+		})
+
+		it("shares the record of names referring to the same image", func() {
+			pulledAt := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+			h.AssertNil(t, subject.Record("ubuntu", pulledAt))
+
+			lastPulled, err := subject.LastPulled("docker.io/library/ubuntu:latest")
+			h.AssertNil(t, err)
+			h.AssertTrue(t, lastPulled.Equal(pulledAt))
+
+			lastPulled, err = subject.LastPulled("ubuntu:other")
+			h.AssertNil(t, err)
+			h.AssertTrue(t, lastPulled.IsZero())
+		})
+
+		it("keeps the records of other processes recording at the same time", func() {
+			path := filepath.Join(tmpDir, "shared.json")
+			pulledAt := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, 10)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs <- image.NewPullRecords(path).Record(fmt.Sprintf("some/image-%d", i), pulledAt)
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				h.AssertNil(t, err)
+			}
+
+			for i := 0; i < 10; i++ {
+				lastPulled, err := image.NewPullRecords(path).LastPulled(fmt.Sprintf("some/image-%d", i))
+				h.AssertNil(t, err)
+				h.AssertTrue(t, lastPulled.Equal(pulledAt))
+			}
+
+			entries, err := os.ReadDir(tmpDir)
+			h.AssertNil(t, err)
+			h.AssertEq(t, len(entries), 1)
+		})
+
+		it("takes over a lock left behind by a process that exited", func() {
+			path := filepath.Join(tmpDir, "records.json")
+			h.AssertNil(t, os.WriteFile(path+".lock", nil, 0600))
+			hourAgo := time.Now().Add(-time.Hour)
+			h.AssertNil(t, os.Chtimes(path+".lock", hourAgo, hourAgo))
+
+			h.AssertNil(t, image.NewPullRecords(path).Record("some/image", time.Now()))
+
+			_, err := os.Stat(path + ".lock")
+			h.AssertTrue(t, os.IsNotExist(err))
+		})
+	})
+}
@@ -9,12 +9,14 @@ import (
 // NewSimpleLogger creates a simple logger for the pack library.
 func NewSimpleLogger(w io.Writer) Logger {
 	return &simpleLogger{
-		out: log.New(w, "", log.LstdFlags|log.Lmicroseconds),
+		out:      log.New(w, "", log.LstdFlags|log.Lmicroseconds),
+		redactor: NewRedactor(),
 	}
 }
 
 type simpleLogger struct {
-	out *log.Logger
+	out      *log.Logger
+	redactor *Redactor
 }
 
 const (
@@ -26,41 +28,45 @@ const (
 )
 
 func (l *simpleLogger) Debug(msg string) {
-	l.out.Printf(prefixFmt, debugPrefix, msg)
+	l.out.Printf(prefixFmt, debugPrefix, l.redactor.Redact(msg))
 }
 
 func (l *simpleLogger) Debugf(format string, v ...interface{}) {
-	l.out.Printf(prefixFmt, debugPrefix, fmt.Sprintf(format, v...))
+	l.out.Printf(prefixFmt, debugPrefix, l.redactor.Redact(fmt.Sprintf(format, v...)))
 }
 
 func (l *simpleLogger) Info(msg string) {
-	l.out.Printf(prefixFmt, infoPrefix, msg)
+	l.out.Printf(prefixFmt, infoPrefix, l.redactor.Redact(msg))
 }
 
 func (l *simpleLogger) Infof(format string, v ...interface{}) {
-	l.out.Printf(prefixFmt, infoPrefix, fmt.Sprintf(format, v...))
+	l.out.Printf(prefixFmt, infoPrefix, l.redactor.Redact(fmt.Sprintf(format, v...)))
 }
 
 func (l *simpleLogger) Warn(msg string) {
-	l.out.Printf(prefixFmt, warnPrefix, msg)
+	l.out.Printf(prefixFmt, warnPrefix, l.redactor.Redact(msg))
 }
 
 func (l *simpleLogger) Warnf(format string, v ...interface{}) {
-	l.out.Printf(prefixFmt, warnPrefix, fmt.Sprintf(format, v...))
+	l.out.Printf(prefixFmt, warnPrefix, l.redactor.Redact(fmt.Sprintf(format, v...)))
 }
 
 func (l *simpleLogger) Error(msg string) {
-	l.out.Printf(prefixFmt, errorPrefix, msg)
+	l.out.Printf(prefixFmt, errorPrefix, l.redactor.Redact(msg))
 }
 
 func (l *simpleLogger) Errorf(format string, v ...interface{}) {
-	l.out.Printf(prefixFmt, errorPrefix, fmt.Sprintf(format, v...))
+	l.out.Printf(prefixFmt, errorPrefix, l.redactor.Redact(fmt.Sprintf(format, v...)))
 }
 
 func (l *simpleLogger) Writer() io.Writer {
 	return l.out.Writer()
 }
 
+func (l *simpleLogger) Redactor() *Redactor {
+	return l.redactor
+}
+
 func (l *simpleLogger) IsVerbose() bool {
 	return false
 }
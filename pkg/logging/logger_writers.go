@@ -2,6 +2,7 @@
 package logging
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -41,6 +42,13 @@ type LogWithWriters struct {
 	clock    func() time.Time
 	out      io.Writer
 	errOut   io.Writer
+	redactor *Redactor
+
+	// writers are kept for the lifetime of the logger, so a line written in parts through different calls
+	// to WriterForLevel or Writer isn't lost
+	outWriter    *logWriter
+	errOutWriter *logWriter
+	baseWriter   *logWriter
 }
 
 // NewLogWithWriters creates a logger to be used with pack CLI.
@@ -53,6 +61,7 @@ func NewLogWithWriters(stdout, stderr io.Writer, opts ...func(*LogWithWriters))
 		clock:    time.Now,
 		out:      stdout,
 		errOut:   stderr,
+		redactor: NewRedactor(),
 	}
 	lw.Logger.Handler = lw
 
@@ -60,6 +69,10 @@ func NewLogWithWriters(stdout, stderr io.Writer, opts ...func(*LogWithWriters))
 		opt(lw)
 	}
 
+	lw.outWriter = newLogWriter(lw.out, lw.clock, lw.timestamps, lw.redactor)
+	lw.errOutWriter = newLogWriter(lw.errOut, lw.clock, lw.timestamps, lw.redactor)
+	lw.baseWriter = newLogWriter(lw.out, lw.clock, func() bool { return false }, lw.redactor)
+
 	return lw
 }
 
@@ -83,7 +96,7 @@ func (lw *LogWithWriters) HandleLog(e *log.Entry) error {
 	defer lw.Unlock()
 
 	writer := lw.WriterForLevel(Level(e.Level))
-	_, err := fmt.Fprint(writer, appendMissingLineFeed(fmt.Sprintf("%s%s", formatLevel(e.Level), e.Message)))
+	_, err := fmt.Fprint(writer, appendMissingLineFeed(fmt.Sprintf("%s%s", formatLevel(e.Level), lw.redactor.Redact(e.Message))))
 
 	return err
 }
@@ -95,15 +108,32 @@ func (lw *LogWithWriters) WriterForLevel(level Level) io.Writer {
 	}
 
 	if level == ErrorLevel {
-		return newLogWriter(lw.errOut, lw.clock, lw.wantTime)
+		return lw.errOutWriter
 	}
 
-	return newLogWriter(lw.out, lw.clock, lw.wantTime)
+	return lw.outWriter
 }
 
-// Writer returns the base Writer for the LogWithWriters
+// Writer returns a Writer for the LogWithWriters that isn't subject to the log level or timestamps.
+// Writes to it are redacted like the logs, see logWriter.
 func (lw *LogWithWriters) Writer() io.Writer {
-	return lw.out
+	return lw.baseWriter
+}
+
+// Flush writes the final lines without a line feed held back by the writers of the logger
+func (lw *LogWithWriters) Flush() error {
+	for _, writer := range []*logWriter{lw.outWriter, lw.errOutWriter, lw.baseWriter} {
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Redactor returns the Redactor applied to the logs and the writers for each level
+func (lw *LogWithWriters) Redactor() *Redactor {
+	return lw.redactor
 }
 
 // WantTime turns timestamps on in log entries
@@ -111,6 +141,10 @@ func (lw *LogWithWriters) WantTime(f bool) {
 	lw.wantTime = f
 }
 
+func (lw *LogWithWriters) timestamps() bool {
+	return lw.wantTime
+}
+
 // WantQuiet reduces the number of logs returned
 func (lw *LogWithWriters) WantQuiet(f bool) {
 	if f {
@@ -150,42 +184,74 @@ func appendMissingLineFeed(msg string) string {
 	return string(buff)
 }
 
-// logWriter is a writer used for logs
+// logWriter is a writer used for logs. It buffers writes until a line is complete, so values split across
+// writes are still redacted. Flush or Close should be called to write a final line without a line feed.
 type logWriter struct {
 	sync.Mutex
-	out         io.Writer
-	clock       func() time.Time
-	wantTime    bool
-	wantNoColor bool
+	out      io.Writer
+	clock    func() time.Time
+	wantTime func() bool
+	redactor *Redactor
+	pending  []byte
 }
 
-func newLogWriter(writer io.Writer, clock func() time.Time, wantTime bool) *logWriter {
-	wantNoColor := !color.Enabled()
+func newLogWriter(writer io.Writer, clock func() time.Time, wantTime func() bool, redactor *Redactor) *logWriter {
 	return &logWriter{
-		out:         writer,
-		clock:       clock,
-		wantTime:    wantTime,
-		wantNoColor: wantNoColor,
+		out:      writer,
+		clock:    clock,
+		wantTime: wantTime,
+		redactor: redactor,
 	}
 }
 
-// Write writes a message prepended by the time to the set io.Writer
+// Write writes the complete lines of a message prepended by the time to the set io.Writer, a line ends with
+// a line feed or a carriage return, so progress updates are written as they come
 func (lw *logWriter) Write(buf []byte) (n int, err error) {
 	lw.Lock()
 	defer lw.Unlock()
 
-	length := len(buf)
-	if lw.wantNoColor {
+	lw.pending = append(lw.pending, buf...)
+	end := bytes.LastIndexAny(lw.pending, "\r\n")
+	if end < 0 {
+		return len(buf), nil
+	}
+
+	lines := lw.pending[:end+1]
+	lw.pending = append([]byte(nil), lw.pending[end+1:]...)
+	return len(buf), lw.write(lines)
+}
+
+// Flush writes a pending line that doesn't end with a line feed
+func (lw *logWriter) Flush() error {
+	lw.Lock()
+	defer lw.Unlock()
+
+	if len(lw.pending) == 0 {
+		return nil
+	}
+
+	lines := lw.pending
+	lw.pending = nil
+	return lw.write(lines)
+}
+
+// Close flushes the writer, see Flush
+func (lw *logWriter) Close() error {
+	return lw.Flush()
+}
+
+func (lw *logWriter) write(buf []byte) error {
+	if !color.Enabled() {
 		buf = stripColor(buf)
 	}
 
 	prefix := ""
-	if lw.wantTime {
+	if lw.wantTime() {
 		prefix = fmt.Sprintf("%s ", lw.clock().Format(timeFmt))
 	}
 
-	_, err = fmt.Fprintf(lw.out, "%s%s", prefix, buf)
-	return length, err
+	_, err := fmt.Fprintf(lw.out, "%s%s", prefix, lw.redactor.Redact(string(buf)))
+	return err
 }
 
 // Writer returns the base Writer for the logWriter
@@ -60,6 +60,44 @@ func IsQuiet(logger Logger) bool {
 	return false
 }
 
+type isRedacting interface {
+	Redactor() *Redactor
+}
+
+// AddRedactions masks values in the output of logger from now on, if the logger redacts its output.
+//
+// See Redactor
+func AddRedactions(logger Logger, values ...string) {
+	if r, ok := logger.(isRedacting); ok {
+		r.Redactor().Add(values...)
+	}
+}
+
+// GetRedactor retrieves the Redactor applied to the output of logger, or a new Redactor if logger doesn't redact its output.
+//
+// See isRedacting
+func GetRedactor(logger Logger) *Redactor {
+	if r, ok := logger.(isRedacting); ok {
+		return r.Redactor()
+	}
+
+	return NewRedactor()
+}
+
+type flusher interface {
+	Flush() error
+}
+
+// FlushWriter writes any output buffered by a writer of a logger, e.g. a final line without a line feed.
+// It's a no-op for writers that don't buffer.
+func FlushWriter(writer io.Writer) error {
+	if f, ok := writer.(flusher); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
+
 // Tip logs a tip.
 func Tip(l Logger, format string, v ...interface{}) {
 	l.Infof(style.Tip("Tip: ")+format, v...)
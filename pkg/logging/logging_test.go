@@ -27,12 +27,12 @@ func testLogging(t *testing.T, when spec.G, it spec.S) {
 				logger := logging.NewLogWithWriters(outCons, errCons)
 
 				infoLogger := logging.GetWriterForLevel(logger, logging.InfoLevel)
-				_, _ = infoLogger.Write([]byte("info test"))
-				h.AssertEq(t, output(), "info test")
+				_, _ = infoLogger.Write([]byte("info test\n"))
+				h.AssertEq(t, output(), "info test\n")
 
 				errorLogger := logging.GetWriterForLevel(logger, logging.ErrorLevel)
-				_, _ = errorLogger.Write([]byte("error test"))
-				h.AssertEq(t, errOutput(), "error test")
+				_, _ = errorLogger.Write([]byte("error test\n"))
+				h.AssertEq(t, errOutput(), "error test\n")
 			})
 		})
 
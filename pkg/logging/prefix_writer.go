@@ -15,6 +15,7 @@ type PrefixWriter struct {
 	buf           *bytes.Buffer
 	prefix        string
 	readerFactory func(data []byte) io.Reader
+	redactor      *Redactor
 }
 
 type PrefixWriterOption func(c *PrefixWriter)
@@ -25,12 +26,25 @@ func WithReaderFactory(factory func(data []byte) io.Reader) PrefixWriterOption {
 	}
 }
 
-// NewPrefixWriter writes by w will be prefixed
+// WithRedactions replaces the values in each line written with [REDACTED], e.g. the values of secrets
+func WithRedactions(values ...string) PrefixWriterOption {
+	return func(writer *PrefixWriter) {
+		writer.redactor.Add(values...)
+	}
+}
+
+// NewPrefixWriter writes by w will be prefixed, lines aren't prefixed if prefix is empty.
+// Sensitive values are redacted from each line, see Redactor.
 func NewPrefixWriter(w io.Writer, prefix string, opts ...PrefixWriterOption) *PrefixWriter {
+	if prefix != "" {
+		prefix = fmt.Sprintf("[%s] ", style.Prefix(prefix))
+	}
+
 	writer := &PrefixWriter{
-		out:    w,
-		prefix: fmt.Sprintf("[%s] ", style.Prefix(prefix)),
-		buf:    &bytes.Buffer{},
+		out:      w,
+		prefix:   prefix,
+		buf:      &bytes.Buffer{},
+		redactor: NewRedactor(),
 		readerFactory: func(data []byte) io.Reader {
 			return bytes.NewReader(data)
 		},
@@ -92,7 +106,7 @@ func (w *PrefixWriter) flush() error {
 		bits = bits[i+1:]
 	}
 
-	_, err := fmt.Fprint(w.out, w.prefix+string(bits)+"\n")
+	_, err := fmt.Fprint(w.out, w.prefix+w.redactor.Redact(string(bits))+"\n")
 	return err
 }
 
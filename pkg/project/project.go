@@ -6,8 +6,10 @@ import (
 	"path/filepath"
 
 	"github.com/BurntSushi/toml"
+	"github.com/docker/go-units"
 	"github.com/pkg/errors"
 
+	"github.com/buildpacks/pack/pkg/blob"
 	"github.com/buildpacks/pack/pkg/project/types"
 	v01 "github.com/buildpacks/pack/pkg/project/v01"
 	v02 "github.com/buildpacks/pack/pkg/project/v02"
@@ -80,6 +82,37 @@ func validate(p types.Descriptor) error {
 		if bp.URI != "" && bp.Version != "" {
 			return errors.New("project.toml: buildpacks cannot have both uri and version defined")
 		}
+		if bp.Digest != "" {
+			if bp.URI == "" {
+				return errors.New("project.toml: buildpacks must have a uri defined to be pinned with a digest")
+			}
+			if err := blob.ValidateDigest(bp.Digest); err != nil {
+				return errors.Wrap(err, "project.toml")
+			}
+		}
+	}
+
+	for _, envVar := range p.Build.Env {
+		switch envVar.Action {
+		case "", "override", "default", "append", "prepend":
+		default:
+			return errors.Errorf("project.toml: env var %s must have an action of override, default, append or prepend", envVar.Name)
+		}
+	}
+
+	for _, secret := range p.Build.Secrets {
+		if secret.ID == "" || secret.Source == "" {
+			return errors.New("project.toml: secrets must have an id and src defined")
+		}
+	}
+
+	if p.Build.Container.CPUs < 0 {
+		return errors.New("project.toml: container cpus must not be negative")
+	}
+	if p.Build.Container.Memory != "" {
+		if _, err := units.RAMInBytes(p.Build.Container.Memory); err != nil {
+			return errors.Wrap(err, "project.toml: invalid container memory")
+		}
 	}
 
 	return nil
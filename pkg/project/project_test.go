@@ -14,6 +14,7 @@ import (
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 
+	"github.com/buildpacks/pack/pkg/project/types"
 	h "github.com/buildpacks/pack/testhelpers"
 )
 
@@ -241,6 +242,188 @@ name = "gallant"
 			}
 		})
 
+		it("should parse the container settings of a v0.1 project.toml file", func() {
+			projectToml := `
+[project]
+name = "constrained"
+
+[build.container]
+cpus = 1.5
+memory = "4g"
+ulimits = ["nofile=1024:2048"]
+add-hosts = ["registry.local:10.0.0.1"]
+dns = ["10.0.0.2"]
+tmpfs = ["/tmp:size=64m"]
+security-opts = ["no-new-privileges"]
+read-only-app = true
+`
+			tmpProjectToml, err := createTmpProjectTomlFile(projectToml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			projectDescriptor, err := ReadProjectDescriptor(tmpProjectToml.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h.AssertEq(t, projectDescriptor.Build.Container, types.Container{
+				CPUs:         1.5,
+				Memory:       "4g",
+				Ulimits:      []string{"nofile=1024:2048"},
+				AddHosts:     []string{"registry.local:10.0.0.1"},
+				DNS:          []string{"10.0.0.2"},
+				Tmpfs:        []string{"/tmp:size=64m"},
+				SecurityOpts: []string{"no-new-privileges"},
+				ReadOnlyApp:  true,
+			})
+		})
+
+		it("should parse the container settings of a v0.2 project.toml file", func() {
+			projectToml := `
+[_]
+name = "constrained 0.2"
+schema-version = "0.2"
+
+[io.buildpacks.container]
+memory = "512m"
+read-only-app = true
+`
+			tmpProjectToml, err := createTmpProjectTomlFile(projectToml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			projectDescriptor, err := ReadProjectDescriptor(tmpProjectToml.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h.AssertEq(t, projectDescriptor.Build.Container, types.Container{Memory: "512m", ReadOnlyApp: true})
+		})
+
+		it("should not allow an invalid container memory", func() {
+			projectToml := `
+[project]
+name = "invalid memory"
+
+[build.container]
+memory = "lots"
+`
+			tmpProjectToml, err := createTmpProjectTomlFile(projectToml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = ReadProjectDescriptor(tmpProjectToml.Name())
+			h.AssertError(t, err, "project.toml: invalid container memory")
+		})
+
+		it("should parse the actions of env vars", func() {
+			projectToml := `
+[project]
+name = "gallant"
+
+[[build.env]]
+name = "JAVA_TOOL_OPTIONS"
+value = "-Xmx1g"
+action = "append"
+delim = " "
+`
+			tmpProjectToml, err := createTmpProjectTomlFile(projectToml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			projectDescriptor, err := ReadProjectDescriptor(tmpProjectToml.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h.AssertEq(t, projectDescriptor.Build.Env, []types.EnvVar{{Name: "JAVA_TOOL_OPTIONS", Value: "-Xmx1g", Action: "append", Delim: " "}})
+		})
+
+		it("should not allow an invalid env var action", func() {
+			projectToml := `
+[project]
+name = "gallant"
+
+[[build.env]]
+name = "JAVA_TOOL_OPTIONS"
+value = "-Xmx1g"
+action = "insert"
+`
+			tmpProjectToml, err := createTmpProjectTomlFile(projectToml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = ReadProjectDescriptor(tmpProjectToml.Name())
+			h.AssertError(t, err, "project.toml: env var JAVA_TOOL_OPTIONS must have an action of override, default, append or prepend")
+		})
+
+		it("should parse the secrets of a v0.1 project.toml file", func() {
+			projectToml := `
+[project]
+name = "secretive"
+
+[[build.secrets]]
+id = "npmrc"
+src = "~/.npmrc"
+`
+			tmpProjectToml, err := createTmpProjectTomlFile(projectToml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			projectDescriptor, err := ReadProjectDescriptor(tmpProjectToml.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h.AssertEq(t, projectDescriptor.Build.Secrets, []types.Secret{{ID: "npmrc", Source: "~/.npmrc"}})
+		})
+
+		it("should parse the secrets of a v0.2 project.toml file", func() {
+			projectToml := `
+[_]
+name = "secretive 0.2"
+schema-version = "0.2"
+
+[[io.buildpacks.secrets]]
+id = "maven-settings"
+src = "settings.xml"
+`
+			tmpProjectToml, err := createTmpProjectTomlFile(projectToml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			projectDescriptor, err := ReadProjectDescriptor(tmpProjectToml.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h.AssertEq(t, projectDescriptor.Build.Secrets, []types.Secret{{ID: "maven-settings", Source: "settings.xml"}})
+		})
+
+		it("should not allow a secret without a src", func() {
+			projectToml := `
+[project]
+name = "incomplete secret"
+
+[[build.secrets]]
+id = "npmrc"
+`
+			tmpProjectToml, err := createTmpProjectTomlFile(projectToml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = ReadProjectDescriptor(tmpProjectToml.Name())
+			h.AssertError(t, err, "project.toml: secrets must have an id and src defined")
+		})
+
 		it("should fail for an invalid project.toml path", func() {
 			_, err := ReadProjectDescriptor("/path/that/does/not/exist/project.toml")
 
@@ -309,6 +492,73 @@ version = "1.2.3"
 			}
 		})
 
+		it("should read the digest of a buildpack uri", func() {
+			projectToml := `
+[project]
+name = "pinned buildpack"
+
+[[build.buildpacks]]
+uri = "https://example.com/buildpack"
+digest = "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+`
+			tmpProjectToml, err := createTmpProjectTomlFile(projectToml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			projectDescriptor, err := ReadProjectDescriptor(tmpProjectToml.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+			if projectDescriptor.Build.Buildpacks[0].Digest != expected {
+				t.Fatalf("Expected\n-----\n%#v\n-----\nbut got\n-----\n%#v\n",
+					expected, projectDescriptor.Build.Buildpacks[0].Digest)
+			}
+		})
+
+		it("should not allow a digest without a uri", func() {
+			projectToml := `
+[project]
+name = "digest without uri"
+
+[[build.buildpacks]]
+id = "example/lua"
+version = "1.0"
+digest = "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+`
+			tmpProjectToml, err := createTmpProjectTomlFile(projectToml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = ReadProjectDescriptor(tmpProjectToml.Name())
+			if err == nil {
+				t.Fatal("Expected error for having a digest without a uri defined for a buildpack")
+			}
+		})
+
+		it("should not allow an invalid digest", func() {
+			projectToml := `
+[project]
+name = "invalid digest"
+
+[[build.buildpacks]]
+uri = "https://example.com/buildpack"
+digest = "sha256:abc"
+`
+			tmpProjectToml, err := createTmpProjectTomlFile(projectToml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = ReadProjectDescriptor(tmpProjectToml.Name())
+			if err == nil {
+				t.Fatal("Expected error for having an invalid digest defined for a buildpack")
+			}
+		})
+
 		it("should require either a type or uri for licenses", func() {
 			projectToml := `
 [project]
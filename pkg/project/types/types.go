@@ -14,12 +14,33 @@ type Buildpack struct {
 	ID      string `toml:"id"`
 	Version string `toml:"version"`
 	URI     string `toml:"uri"`
+	Digest  string `toml:"digest"`
 	Script  Script `toml:"script"`
 }
 
 type EnvVar struct {
-	Name  string `toml:"name"`
-	Value string `toml:"value"`
+	Name   string `toml:"name"`
+	Value  string `toml:"value"`
+	Action string `toml:"action"`
+	Delim  string `toml:"delim"`
+}
+
+// Container configures the containers the build runs in
+type Container struct {
+	CPUs         float64  `toml:"cpus"`
+	Memory       string   `toml:"memory"`
+	Ulimits      []string `toml:"ulimits"`
+	AddHosts     []string `toml:"add-hosts"`
+	DNS          []string `toml:"dns"`
+	Tmpfs        []string `toml:"tmpfs"`
+	SecurityOpts []string `toml:"security-opts"`
+	ReadOnlyApp  bool     `toml:"read-only-app"`
+}
+
+// Secret is a file made available to buildpacks during detect and build, it's never part of the image or the cache
+type Secret struct {
+	ID     string `toml:"id"`
+	Source string `toml:"src"`
 }
 
 type Build struct {
@@ -28,6 +49,8 @@ type Build struct {
 	Buildpacks []Buildpack `toml:"buildpacks"`
 	Env        []EnvVar    `toml:"env"`
 	Builder    string      `toml:"builder"`
+	Container  Container   `toml:"container"`
+	Secrets    []Secret    `toml:"secrets"`
 }
 
 type Project struct {
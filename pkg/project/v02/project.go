@@ -8,11 +8,13 @@ import (
 )
 
 type Buildpacks struct {
-	Include []string          `toml:"include"`
-	Exclude []string          `toml:"exclude"`
-	Group   []types.Buildpack `toml:"group"`
-	Env     Env               `toml:"env"`
-	Builder string            `toml:"builder"`
+	Include   []string          `toml:"include"`
+	Exclude   []string          `toml:"exclude"`
+	Group     []types.Buildpack `toml:"group"`
+	Env       Env               `toml:"env"`
+	Builder   string            `toml:"builder"`
+	Container types.Container   `toml:"container"`
+	Secrets   []types.Secret    `toml:"secrets"`
 }
 
 type Env struct {
@@ -53,6 +55,8 @@ func NewDescriptor(projectTomlContents string) (types.Descriptor, error) {
 			Buildpacks: versionedDescriptor.IO.Buildpacks.Group,
 			Env:        versionedDescriptor.IO.Buildpacks.Env.Build,
 			Builder:    versionedDescriptor.IO.Buildpacks.Builder,
+			Container:  versionedDescriptor.IO.Buildpacks.Container,
+			Secrets:    versionedDescriptor.IO.Buildpacks.Secrets,
 		},
 		Metadata:      versionedDescriptor.Project.Metadata,
 		SchemaVersion: api.MustParse("0.2"),